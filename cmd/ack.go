@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/user/docker-image-reporter/internal/config"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// defaultAcksFile es el nombre del archivo de aplazamientos dentro del
+// directorio de configuración, junto a la caché persistente
+const defaultAcksFile = "acks.json"
+
+// acksFileVersion cambia cuando cambia el formato del archivo de aplazamientos
+const acksFileVersion = 1
+
+// acksFile es el contenido en disco del archivo de aplazamientos
+type acksFile struct {
+	Version int                    `json:"version"`
+	Acks    []types.Acknowledgment `json:"acks"`
+}
+
+// newAckCmd crea el comando ack
+func newAckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ack <service> <tag>",
+		Short: "Acknowledge an update so scan --hide-acked stops reporting it",
+		Long: `Record that the update of a service to a tag has been deliberately deferred.
+Scans with --hide-acked leave that update out of the output and notifications
+until the registry suggests a different tag, which supersedes the
+acknowledgment. Acknowledging a service again replaces its previous tag.
+
+Acknowledgments are kept in ~/.icr/acks.json by default.`,
+		Args: cobra.ExactArgs(2),
+		RunE: runAck,
+	}
+
+	cmd.Flags().String("file", "", "Acknowledgments file (default ~/.icr/acks.json)")
+
+	return cmd
+}
+
+func runAck(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("file")
+	if path == "" {
+		var err error
+		if path, err = defaultAcksPath(); err != nil {
+			return err
+		}
+	}
+
+	acks, err := loadAcks(path)
+	if err != nil {
+		return err
+	}
+
+	ack := types.Acknowledgment{Service: args[0], Tag: args[1], AckedAt: time.Now().UTC()}
+	acks = slices.DeleteFunc(acks, func(existing types.Acknowledgment) bool {
+		return existing.Service == ack.Service
+	})
+	acks = append(acks, ack)
+
+	if err := saveAcks(path, acks); err != nil {
+		return err
+	}
+	cmd.Printf("Acknowledged %s -> %s (%s)\n", ack.Service, ack.Tag, path)
+	return nil
+}
+
+// defaultAcksPath devuelve el archivo de aplazamientos junto a la configuración
+func defaultAcksPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(homeDir, config.DefaultConfigDir, defaultAcksFile), nil
+}
+
+// loadAcks lee los aplazamientos de path. Si el archivo aún no existe no hay
+// ninguno.
+func loadAcks(path string) ([]types.Acknowledgment, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read acknowledgments: %w", err)
+	}
+
+	var file acksFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse acknowledgments %s: %w", path, err)
+	}
+	if file.Version != acksFileVersion {
+		return nil, fmt.Errorf("acknowledgments file %s has unsupported version %d", path, file.Version)
+	}
+	return file.Acks, nil
+}
+
+// saveAcks escribe los aplazamientos en path, creando su directorio si hace falta
+func saveAcks(path string, acks []types.Acknowledgment) error {
+	data, err := json.MarshalIndent(acksFile{Version: acksFileVersion, Acks: acks}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format acknowledgments: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write acknowledgments: %w", err)
+	}
+	return nil
+}
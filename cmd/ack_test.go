@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// runAckCommand ejecuta icr ack <args>
+func runAckCommand(t *testing.T, args ...string) {
+	t.Helper()
+
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(append([]string{"ack"}, args...))
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute(%v) error = %v", args, err)
+	}
+}
+
+func TestAckCommand_ReplacesPreviousAck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "acks.json")
+
+	runAckCommand(t, "web", "1.25", "--file", path)
+	runAckCommand(t, "db", "16", "--file", path)
+	// Un nuevo ack del mismo servicio sustituye al anterior
+	runAckCommand(t, "web", "1.27", "--file", path)
+
+	acks, err := loadAcks(path)
+	if err != nil {
+		t.Fatalf("loadAcks() error = %v", err)
+	}
+	if len(acks) != 2 {
+		t.Fatalf("expected 2 acknowledgments, got %+v", acks)
+	}
+	got := map[string]string{}
+	for _, ack := range acks {
+		got[ack.Service] = ack.Tag
+		if ack.AckedAt.IsZero() {
+			t.Errorf("ack for %s has no timestamp", ack.Service)
+		}
+	}
+	if got["web"] != "1.27" || got["db"] != "16" {
+		t.Errorf("acknowledgments = %v, want web:1.27 and db:16", got)
+	}
+}
+
+func TestLoadAcks(t *testing.T) {
+	dir := t.TempDir()
+
+	// Sin archivo no hay aplazamientos
+	acks, err := loadAcks(filepath.Join(dir, "missing.json"))
+	if err != nil || acks != nil {
+		t.Errorf("loadAcks(missing) = %v, %v; want nil, nil", acks, err)
+	}
+
+	data, _ := json.Marshal(acksFile{Version: acksFileVersion + 1, Acks: []types.Acknowledgment{{Service: "web", Tag: "1"}}})
+	path := filepath.Join(dir, "acks.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("writing acks file: %v", err)
+	}
+	if _, err := loadAcks(path); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+}
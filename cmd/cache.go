@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/user/docker-image-reporter/internal/cache"
+	"github.com/user/docker-image-reporter/internal/config"
+)
+
+// newCacheCmd crea el comando cache
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and manage the persistent registry cache",
+		Long: `Inspect and manage the registry cache that scans keep on disk when run
+with --cache-file, to reuse tag lists between runs (~/.icr/cache.json by default).`,
+	}
+
+	cmd.PersistentFlags().String("file", "", "Cache file (default ~/.icr/cache.json)")
+	cmd.PersistentFlags().StringP("output", "o", "console", "Output format (console, json)")
+
+	// Subcomandos
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Show cache statistics and entries",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheShow,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Remove every cache entry",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheClear,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "prune",
+		Short: "Remove only the expired cache entries",
+		Args:  cobra.NoArgs,
+		RunE:  runCachePrune,
+	})
+
+	return cmd
+}
+
+// cacheShowResult es la salida JSON de cache show
+type cacheShowResult struct {
+	File    string               `json:"file"`
+	Size    int                  `json:"size"`
+	Expired int                  `json:"expired"`
+	Entries []cache.EntrySummary `json:"entries"`
+}
+
+// cacheChangeResult es la salida JSON de cache clear y cache prune
+type cacheChangeResult struct {
+	File      string `json:"file"`
+	Removed   int    `json:"removed"`
+	Remaining int    `json:"remaining"`
+}
+
+func runCacheShow(cmd *cobra.Command, args []string) error {
+	path, registryCache, err := loadCacheFile(cmd)
+	if err != nil {
+		return err
+	}
+	defer registryCache.Close()
+
+	result := cacheShowResult{File: path, Entries: registryCache.Entries()}
+	result.Size = len(result.Entries)
+	for _, entry := range result.Entries {
+		if entry.Expired {
+			result.Expired++
+		}
+	}
+
+	if isJSONOutput(cmd) {
+		return printCacheJSON(cmd, result)
+	}
+
+	cmd.Printf("Cache file: %s\n", result.File)
+	cmd.Printf("Entries: %d (%d expired)\n", result.Size, result.Expired)
+	for _, entry := range result.Entries {
+		state := "expires " + entry.ExpiresAt.Format(time.RFC3339)
+		if entry.Expired {
+			state = "expired " + entry.ExpiresAt.Format(time.RFC3339)
+		}
+		detail := ""
+		if entry.Kind == "tags" {
+			detail = fmt.Sprintf(", %d tags", entry.Tags)
+		}
+		cmd.Printf("  - [%s] %s (%s%s)\n", entry.Kind, entry.Key, state, detail)
+	}
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	path, registryCache, err := loadCacheFile(cmd)
+	if err != nil {
+		return err
+	}
+	defer registryCache.Close()
+
+	removed := len(registryCache.Entries())
+	registryCache.Clear()
+	return saveCacheFile(cmd, path, registryCache, removed)
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	path, registryCache, err := loadCacheFile(cmd)
+	if err != nil {
+		return err
+	}
+	defer registryCache.Close()
+
+	removed := registryCache.Prune()
+	return saveCacheFile(cmd, path, registryCache, removed)
+}
+
+// cacheFilePath devuelve --file o, por defecto, el archivo de caché de los escaneos
+func cacheFilePath(cmd *cobra.Command) (string, error) {
+	if path, _ := cmd.Flags().GetString("file"); path != "" {
+		return path, nil
+	}
+	return defaultCacheFile()
+}
+
+// defaultCacheFile devuelve el archivo de caché junto a la configuración
+func defaultCacheFile() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(homeDir, config.DefaultConfigDir, cache.DefaultFile), nil
+}
+
+// openPersistentCache carga la caché de disco para los escaneos desde path
+// (--cache-file). Un archivo ilegible o que no es una caché solo se avisa: el
+// escaneo empieza con la caché vacía y el archivo no se toca. La función devuelta guarda la caché sin las
+// entradas caducadas y la cierra.
+func openPersistentCache(path string, logger *slog.Logger) (*cache.RegistryCache, func()) {
+	registryCache, err := cache.NewPersistentCache(cache.DefaultConfig(), path)
+	if err != nil {
+		logger.Warn("Ignoring cache file, it will not be saved (remove it to start a new cache)", "path", path, "error", err)
+	}
+
+	return registryCache, func() {
+		if err := registryCache.Close(); err != nil {
+			logger.Warn("Failed to save cache", "path", path, "error", err)
+		}
+	}
+}
+
+// loadCacheFile carga el archivo de caché sin limpieza en segundo plano
+func loadCacheFile(cmd *cobra.Command) (string, *cache.RegistryCache, error) {
+	path, err := cacheFilePath(cmd)
+	if err != nil {
+		return "", nil, err
+	}
+
+	registryCache := cache.NewRegistryCache(cache.Config{DefaultTTL: cache.DefaultConfig().DefaultTTL})
+	if err := registryCache.Load(path); err != nil {
+		registryCache.Close()
+		return "", nil, fmt.Errorf("failed to load cache: %w", err)
+	}
+	return path, registryCache, nil
+}
+
+// saveCacheFile guarda la caché modificada e informa de cuántas entradas se eliminaron
+func saveCacheFile(cmd *cobra.Command, path string, registryCache *cache.RegistryCache, removed int) error {
+	if err := registryCache.Save(path); err != nil {
+		return fmt.Errorf("failed to save cache: %w", err)
+	}
+
+	result := cacheChangeResult{File: path, Removed: removed, Remaining: len(registryCache.Entries())}
+	if isJSONOutput(cmd) {
+		return printCacheJSON(cmd, result)
+	}
+	cmd.Printf("Removed %d cache entries, %d remaining\n", result.Removed, result.Remaining)
+	return nil
+}
+
+// isJSONOutput indica si se pidió --output json
+func isJSONOutput(cmd *cobra.Command) bool {
+	output, _ := cmd.Flags().GetString("output")
+	return strings.EqualFold(output, formatJSON)
+}
+
+// printCacheJSON escribe v como JSON respetando --json-compact
+func printCacheJSON(cmd *cobra.Command, v any) error {
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	if compact, _ := cmd.Flags().GetBool("json-compact"); !compact {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/user/docker-image-reporter/internal/cache"
+	"github.com/user/docker-image-reporter/internal/clock"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// seedCacheFile crea un archivo de caché con una entrada vigente (nginx) y
+// dos caducadas (redis y su ETag)
+func seedCacheFile(t *testing.T) string {
+	t.Helper()
+
+	fakeClock := clock.NewFake(time.Now().Add(-48 * time.Hour))
+	registryCache := cache.NewRegistryCache(cache.Config{DefaultTTL: 15 * time.Minute, Clock: fakeClock})
+	defer registryCache.Close()
+
+	registryCache.SetTags(types.DockerImage{Registry: "docker.io", Repository: "library/redis", Tag: "7"}, []string{"7", "8"})
+	registryCache.SetETag("https://registry.example.com/v2/org/app/tags/list", `"abc"`, []string{"1.0.0"})
+	registryCache.SetTagsWithTTL(types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}, []string{"1.25", "1.27"}, 72*time.Hour)
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := registryCache.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	return path
+}
+
+// runCacheCommand ejecuta icr cache <args> con salida JSON y devuelve la respuesta
+func runCacheCommand(t *testing.T, out any, args ...string) {
+	t.Helper()
+
+	root := NewRootCmd()
+	stdout := &bytes.Buffer{}
+	root.SetOut(stdout)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(append([]string{"cache"}, append(args, "--output", "json")...))
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute(%v) error = %v", args, err)
+	}
+	if err := json.Unmarshal(stdout.Bytes(), out); err != nil {
+		t.Fatalf("parsing output of %v: %v\n%s", args, err, stdout.String())
+	}
+}
+
+func TestCacheCommand_Prune(t *testing.T) {
+	path := seedCacheFile(t)
+
+	var pruned cacheChangeResult
+	runCacheCommand(t, &pruned, "prune", "--file", path)
+	if pruned.Removed != 2 || pruned.Remaining != 1 {
+		t.Errorf("prune removed %d and kept %d, want 2 and 1", pruned.Removed, pruned.Remaining)
+	}
+
+	// Solo queda la entrada vigente
+	var shown cacheShowResult
+	runCacheCommand(t, &shown, "show", "--file", path)
+	if shown.Size != 1 || shown.Expired != 0 || shown.Entries[0].Key != "docker.io/library/nginx:1.25#tags" {
+		t.Errorf("after prune show = %+v, want only the nginx entry", shown)
+	}
+}
+
+func TestCacheCommand_Clear(t *testing.T) {
+	path := seedCacheFile(t)
+
+	var shown cacheShowResult
+	runCacheCommand(t, &shown, "show", "--file", path)
+	if shown.Size != 3 || shown.Expired != 2 {
+		t.Fatalf("seeded cache show = %d entries (%d expired), want 3 (2 expired)", shown.Size, shown.Expired)
+	}
+
+	var cleared cacheChangeResult
+	runCacheCommand(t, &cleared, "clear", "--file", path)
+	if cleared.Removed != 3 || cleared.Remaining != 0 {
+		t.Errorf("clear removed %d and kept %d, want 3 and 0", cleared.Removed, cleared.Remaining)
+	}
+
+	runCacheCommand(t, &shown, "show", "--file", path)
+	if shown.Size != 0 {
+		t.Errorf("after clear size = %d, want 0", shown.Size)
+	}
+}
@@ -3,29 +3,61 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v3"
 
 	"github.com/user/docker-image-reporter/internal/config"
 	"github.com/user/docker-image-reporter/pkg/types"
+	"github.com/user/docker-image-reporter/pkg/utils"
 )
 
 // Configuration section and field constants
 const (
-	configTelegram  = "telegram"
-	configRegistry  = "registry"
-	configScan      = "scan"
-	configEnabled   = "enabled"
-	configTimeout   = "timeout"
-	configBotToken  = "bot_token"
-	configChatID    = "chat_id"
-	configTemplate  = "template"
-	configGHCR = "ghcr"
-	configToken     = "token"
-	configRecursive = "recursive"
-	configPatterns  = "patterns"
+	configTelegram      = "telegram"
+	configSlack         = "slack"
+	configDiscord       = "discord"
+	configEmail         = "email"
+	configWebhook       = "webhook"
+	configURL           = "url"
+	configSecret        = "secret"
+	configPort          = "port"
+	configFrom          = "from"
+	configTo            = "to"
+	configWebhookURL    = "webhook_url"
+	configChannel       = "channel"
+	configRegistry      = "registry"
+	configScan          = "scan"
+	configReport        = "report"
+	configEnabled       = "enabled"
+	configTimeout       = "timeout"
+	configBotToken      = "bot_token"
+	configChatID        = "chat_id"
+	configTemplate      = "template"
+	configMinUpdate     = "min_update_type"
+	configGHCR          = "ghcr"
+	configToken         = "token"
+	configTrustedHosts  = "trusted_hosts"
+	configMaxTags       = "max_tags"
+	configDockerHub     = "dockerhub"
+	configUsername      = "username"
+	configPassword      = "password"
+	configMirror        = "mirror"
+	configQuay          = "quay"
+	configGitLab        = "gitlab"
+	configHost          = "host"
+	configRecursive     = "recursive"
+	configPatterns      = "patterns"
+	configPriority      = "priority"
+	configRollingImages = "rolling_images"
+	configSuffixPolicy  = "suffix_policy"
+	configTargetOS      = "target_os"
+	configMaxUpdate     = "max_update_type"
+	configMaxUpToDate   = "max_uptodate"
+	configDefaultFormat = "default_format"
 )
 
 // newConfigCmd crea el comando config
@@ -40,6 +72,9 @@ func newConfigCmd() *cobra.Command {
 	cmd.AddCommand(newConfigShowCmd())
 	cmd.AddCommand(newConfigSetCmd())
 	cmd.AddCommand(newConfigGetCmd())
+	cmd.AddCommand(newConfigAddCmd())
+	cmd.AddCommand(newConfigRemoveCmd())
+	cmd.AddCommand(newConfigMigrateCmd())
 
 	return cmd
 }
@@ -55,14 +90,19 @@ func newConfigShowCmd() *cobra.Command {
 }
 
 func runConfigShow(cmd *cobra.Command, args []string) error {
-	configPath, _ := cmd.Flags().GetString("config")
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
 	// Mostrar configuración en formato JSON
-	output, err := json.MarshalIndent(cfg, "", "  ")
+	compact, _ := cmd.Flags().GetBool("json-compact")
+	var output []byte
+	if compact {
+		output, err = json.Marshal(cfg)
+	} else {
+		output, err = json.MarshalIndent(cfg, "", "  ")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to format configuration: %w", err)
 	}
@@ -86,22 +126,15 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 	key := args[0]
 	value := args[1]
 
-	configPath, _ := cmd.Flags().GetString("config")
-
-	// Cargar configuración existente
-	cfg, err := config.Load(configPath)
+	// Actualizar el valor según la clave y guardar la configuración
+	err := updateConfig(cmd, func(cfg *types.Config) (bool, error) {
+		if err := setConfigValue(cfg, key, value); err != nil {
+			return false, fmt.Errorf("failed to set configuration value: %w", err)
+		}
+		return true, nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
-	}
-
-	// Actualizar el valor según la clave
-	if err := setConfigValue(cfg, key, value); err != nil {
-		return fmt.Errorf("failed to set configuration value: %w", err)
-	}
-
-	// Guardar configuración
-	if err := config.Save(cfg, configPath); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+		return err
 	}
 
 	cmd.Printf("Configuration updated: %s = %s\n", key, value)
@@ -122,8 +155,7 @@ func newConfigGetCmd() *cobra.Command {
 func runConfigGet(cmd *cobra.Command, args []string) error {
 	key := args[0]
 
-	configPath, _ := cmd.Flags().GetString("config")
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
@@ -138,6 +170,227 @@ func runConfigGet(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// newConfigAddCmd crea el subcomando config add
+func newConfigAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <key> <value>",
+		Short: "Add a value to a list setting",
+		Long:  `Append a single value to a list-typed configuration key (e.g. scan.patterns). Adding a value that is already present does nothing.`,
+		Args:  cobra.ExactArgs(2),
+		RunE:  runConfigAdd,
+	}
+}
+
+// newConfigRemoveCmd crea el subcomando config remove
+func newConfigRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <key> <value>",
+		Short: "Remove a value from a list setting",
+		Long:  `Remove a single value from a list-typed configuration key (e.g. scan.patterns). Removing a value that is not present does nothing.`,
+		Args:  cobra.ExactArgs(2),
+		RunE:  runConfigRemove,
+	}
+}
+
+func runConfigAdd(cmd *cobra.Command, args []string) error {
+	return runConfigListEdit(cmd, args[0], args[1], addListValue)
+}
+
+func runConfigRemove(cmd *cobra.Command, args []string) error {
+	return runConfigListEdit(cmd, args[0], args[1], removeListValue)
+}
+
+// runConfigListEdit carga la configuración, aplica edit a la lista de key y
+// guarda el archivo solo si la lista ha cambiado
+func runConfigListEdit(cmd *cobra.Command, key, value string, edit func([]string, string) ([]string, bool)) error {
+	var result []string
+	changed := false
+	err := updateConfig(cmd, func(cfg *types.Config) (bool, error) {
+		list, err := listConfigValue(cfg, key)
+		if err != nil {
+			return false, fmt.Errorf("failed to update configuration value: %w", err)
+		}
+
+		var updated []string
+		updated, changed = edit(*list, strings.TrimSpace(value))
+		if !changed {
+			result = *list
+			return false, nil
+		}
+		if list == &cfg.Scan.Patterns {
+			if err := config.ValidatePatterns(updated); err != nil {
+				return false, fmt.Errorf("failed to update configuration value: %w", err)
+			}
+		}
+		*list = updated
+		result = updated
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		cmd.Printf("Configuration unchanged: %s = %s\n", key, strings.Join(result, ","))
+		return nil
+	}
+	cmd.Printf("Configuration updated: %s = %s\n", key, strings.Join(result, ","))
+	return nil
+}
+
+// loadConfig carga el archivo de --config con el perfil de --profile aplicado
+func loadConfig(cmd *cobra.Command) (*types.Config, error) {
+	configPath, _ := cmd.Flags().GetString("config")
+	profile, _ := cmd.Flags().GetString("profile")
+	return config.LoadProfile(configPath, profile)
+}
+
+// updateConfig carga la configuración, aplica edit y guarda el archivo si edit
+// indica que hubo cambios. Con --profile, edit trabaja sobre la configuración
+// con el perfil aplicado y solo las claves que cambian se guardan en el
+// perfil, que se crea si no existe; la base queda como estaba.
+func updateConfig(cmd *cobra.Command, edit func(cfg *types.Config) (bool, error)) error {
+	configPath, _ := cmd.Flags().GetString("config")
+	profile, _ := cmd.Flags().GetString("profile")
+
+	// Cargar configuración existente
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if profile == "" {
+		changed, err := edit(cfg)
+		if err != nil || !changed {
+			return err
+		}
+	} else {
+		// Configuración efectiva del perfil, si ya existe, antes de editarla
+		_, exists := cfg.Profiles[profile]
+		load := func() (*types.Config, error) {
+			if exists {
+				return config.LoadProfile(configPath, profile)
+			}
+			return config.Load(configPath)
+		}
+		current, err := load()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		updated, err := load()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		changed, err := edit(updated)
+		if err != nil || !changed {
+			return err
+		}
+		if err := config.UpdateProfile(cfg, profile, current, updated); err != nil {
+			return fmt.Errorf("failed to update profile %s: %w", profile, err)
+		}
+	}
+
+	// Guardar configuración
+	if err := config.Save(cfg, configPath); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	return nil
+}
+
+// addListValue añade value al final de list si no estaba ya
+func addListValue(list []string, value string) ([]string, bool) {
+	if value == "" {
+		return list, false
+	}
+	for _, item := range list {
+		if item == value {
+			return list, false
+		}
+	}
+	return append(list, value), true
+}
+
+// removeListValue elimina todas las apariciones de value de list
+func removeListValue(list []string, value string) ([]string, bool) {
+	kept := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != value {
+			kept = append(kept, item)
+		}
+	}
+	return kept, len(kept) != len(list)
+}
+
+// newConfigMigrateCmd crea el subcomando config migrate
+func newConfigMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate the configuration file to the current schema",
+		Long: `Load the configuration file, apply known migrations (rename deprecated keys,
+fill new defaults, warn on unknown keys) and rewrite it with the current schema_version.`,
+		Args: cobra.NoArgs,
+		RunE: runConfigMigrate,
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Print the migrated configuration without writing it")
+
+	return cmd
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	configPath, _ := cmd.Flags().GetString("config")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if configPath == "" {
+		defaultPath, err := config.GetConfigPath()
+		if err != nil {
+			return fmt.Errorf("failed to resolve configuration path: %w", err)
+		}
+		configPath = defaultPath
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	result, err := config.Migrate(data)
+	if err != nil {
+		return fmt.Errorf("failed to migrate configuration: %w", err)
+	}
+
+	for _, warning := range result.Warnings {
+		cmd.Printf("warning: %s\n", warning)
+	}
+
+	if !result.Changed() {
+		cmd.Printf("Configuration %s is already at schema version %d\n", configPath, config.CurrentSchemaVersion)
+		return nil
+	}
+
+	cmd.Printf("Migrating %s from schema version %d to %d:\n", configPath, result.FromVersion, config.CurrentSchemaVersion)
+	for _, change := range result.Changes {
+		cmd.Printf("  - %s\n", change)
+	}
+
+	if dryRun {
+		output, err := yaml.Marshal(result.Config)
+		if err != nil {
+			return fmt.Errorf("failed to format configuration: %w", err)
+		}
+		cmd.Printf("\n%s", output)
+		return nil
+	}
+
+	if err := config.Save(result.Config, configPath); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	cmd.Printf("Configuration written to %s\n", configPath)
+	return nil
+}
+
 // setConfigValue establece un valor en la configuración según la clave
 func setConfigValue(cfg *types.Config, key, value string) error {
 	parts := strings.Split(key, ".")
@@ -151,10 +404,20 @@ func setConfigValue(cfg *types.Config, key, value string) error {
 	switch section {
 	case configTelegram:
 		return setTelegramConfig(cfg, subkey, value)
+	case configSlack:
+		return setSlackConfig(cfg, subkey, value)
+	case configDiscord:
+		return setDiscordConfig(cfg, subkey, value)
+	case configEmail:
+		return setEmailConfig(cfg, subkey, value)
+	case configWebhook:
+		return setWebhookConfig(cfg, subkey, value)
 	case configRegistry:
 		return setRegistryConfig(cfg, parts[1:], value)
 	case configScan:
 		return setScanConfig(cfg, subkey, value)
+	case configReport:
+		return setReportConfig(cfg, subkey, value)
 	default:
 		return fmt.Errorf("unknown configuration section: %s", section)
 	}
@@ -173,15 +436,53 @@ func getConfigValue(cfg *types.Config, key string) (string, error) {
 	switch section {
 	case configTelegram:
 		return getTelegramConfig(cfg, subkey)
+	case configSlack:
+		return getSlackConfig(cfg, subkey)
+	case configDiscord:
+		return getDiscordConfig(cfg, subkey)
+	case configEmail:
+		return getEmailConfig(cfg, subkey)
+	case configWebhook:
+		return getWebhookConfig(cfg, subkey)
 	case configRegistry:
 		return getRegistryConfig(cfg, parts[1:])
 	case configScan:
 		return getScanConfig(cfg, subkey)
+	case configReport:
+		return getReportConfig(cfg, subkey)
 	default:
 		return "", fmt.Errorf("unknown configuration section: %s", section)
 	}
 }
 
+// listConfigValue devuelve la lista de la configuración asociada a la clave.
+// Las claves que existen pero no son listas devuelven un error explícito.
+func listConfigValue(cfg *types.Config, key string) (*[]string, error) {
+	parts := strings.Split(key, ".")
+	if len(parts) == 2 && strings.ToLower(parts[0]) == configRegistry && strings.ToLower(parts[1]) == configTrustedHosts {
+		return &cfg.Registry.TrustedHosts, nil
+	}
+	if len(parts) == 2 && strings.ToLower(parts[0]) == configEmail && strings.ToLower(parts[1]) == configTo {
+		return &cfg.Email.To, nil
+	}
+	if len(parts) == 2 && strings.ToLower(parts[0]) == configScan {
+		switch strings.ToLower(parts[1]) {
+		case configPatterns:
+			return &cfg.Scan.Patterns, nil
+		case configPriority:
+			return &cfg.Scan.Priority, nil
+		case configRollingImages:
+			return &cfg.Scan.RollingImages, nil
+		}
+	}
+
+	// Reutilizar el enrutado de get para distinguir claves desconocidas de escalares
+	if _, err := getConfigValue(cfg, key); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("configuration key %s is not a list", key)
+}
+
 // Funciones auxiliares para Telegram
 func setTelegramConfig(cfg *types.Config, key, value string) error {
 	switch key {
@@ -197,6 +498,12 @@ func setTelegramConfig(cfg *types.Config, key, value string) error {
 		cfg.Telegram.ChatID = value
 	case configTemplate:
 		cfg.Telegram.Template = value
+	case configMinUpdate:
+		minUpdateType := strings.ToLower(strings.TrimSpace(value))
+		if err := config.ValidateMinUpdateType(minUpdateType); err != nil {
+			return err
+		}
+		cfg.Telegram.MinUpdateType = minUpdateType
 	default:
 		return fmt.Errorf("unknown telegram key: %s", key)
 	}
@@ -213,32 +520,256 @@ func getTelegramConfig(cfg *types.Config, key string) (string, error) {
 		return cfg.Telegram.ChatID, nil
 	case configTemplate:
 		return cfg.Telegram.Template, nil
+	case configMinUpdate:
+		return cfg.Telegram.MinUpdateType, nil
 	default:
 		return "", fmt.Errorf("unknown telegram key: %s", key)
 	}
 }
 
-// Funciones auxiliares para Registry
-func setRegistryConfig(cfg *types.Config, keys []string, value string) error {
-	if len(keys) < 2 {
-		return fmt.Errorf("registry key must be in format 'registry.subkey' or 'registry.provider.key'")
+// Funciones auxiliares para Slack
+func setSlackConfig(cfg *types.Config, key, value string) error {
+	switch key {
+	case configEnabled:
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean value: %s", value)
+		}
+		cfg.Slack.Enabled = val
+	case configWebhookURL:
+		cfg.Slack.WebhookURL = value
+	case configChannel:
+		cfg.Slack.Channel = value
+	default:
+		return fmt.Errorf("unknown slack key: %s", key)
 	}
+	return nil
+}
+
+func getSlackConfig(cfg *types.Config, key string) (string, error) {
+	switch key {
+	case configEnabled:
+		return strconv.FormatBool(cfg.Slack.Enabled), nil
+	case configWebhookURL:
+		return cfg.Slack.WebhookURL, nil
+	case configChannel:
+		return cfg.Slack.Channel, nil
+	default:
+		return "", fmt.Errorf("unknown slack key: %s", key)
+	}
+}
 
+// Funciones auxiliares para Discord
+func setDiscordConfig(cfg *types.Config, key, value string) error {
+	switch key {
+	case configEnabled:
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean value: %s", value)
+		}
+		cfg.Discord.Enabled = val
+	case configWebhookURL:
+		cfg.Discord.WebhookURL = value
+	default:
+		return fmt.Errorf("unknown discord key: %s", key)
+	}
+	return nil
+}
+
+func getDiscordConfig(cfg *types.Config, key string) (string, error) {
+	switch key {
+	case configEnabled:
+		return strconv.FormatBool(cfg.Discord.Enabled), nil
+	case configWebhookURL:
+		return cfg.Discord.WebhookURL, nil
+	default:
+		return "", fmt.Errorf("unknown discord key: %s", key)
+	}
+}
+
+// Funciones auxiliares para Email
+func setEmailConfig(cfg *types.Config, key, value string) error {
+	switch key {
+	case configEnabled:
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean value: %s", value)
+		}
+		cfg.Email.Enabled = val
+	case configHost:
+		cfg.Email.Host = value
+	case configPort:
+		val, err := strconv.Atoi(value)
+		if err != nil || val < 0 || val > 65535 {
+			return fmt.Errorf("invalid port value: %s", value)
+		}
+		cfg.Email.Port = val
+	case configUsername:
+		cfg.Email.Username = value
+	case configPassword:
+		cfg.Email.Password = value
+	case configFrom:
+		cfg.Email.From = value
+	case configTo:
+		cfg.Email.To = nil
+		for _, to := range strings.Split(value, ",") {
+			if to = strings.TrimSpace(to); to != "" {
+				cfg.Email.To = append(cfg.Email.To, to)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown email key: %s", key)
+	}
+	return nil
+}
+
+func getEmailConfig(cfg *types.Config, key string) (string, error) {
+	switch key {
+	case configEnabled:
+		return strconv.FormatBool(cfg.Email.Enabled), nil
+	case configHost:
+		return cfg.Email.Host, nil
+	case configPort:
+		return strconv.Itoa(cfg.Email.SMTPPort()), nil
+	case configUsername:
+		return cfg.Email.Username, nil
+	case configPassword:
+		return cfg.Email.Password, nil
+	case configFrom:
+		return cfg.Email.From, nil
+	case configTo:
+		return strings.Join(cfg.Email.To, ","), nil
+	default:
+		return "", fmt.Errorf("unknown email key: %s", key)
+	}
+}
+
+// Funciones auxiliares para Webhook. Las cabeceras (webhook.headers) solo se
+// configuran en el archivo
+func setWebhookConfig(cfg *types.Config, key, value string) error {
+	switch key {
+	case configEnabled:
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean value: %s", value)
+		}
+		cfg.Webhook.Enabled = val
+	case configURL:
+		cfg.Webhook.URL = value
+	case configSecret:
+		cfg.Webhook.Secret = value
+	default:
+		return fmt.Errorf("unknown webhook key: %s", key)
+	}
+	return nil
+}
+
+func getWebhookConfig(cfg *types.Config, key string) (string, error) {
+	switch key {
+	case configEnabled:
+		return strconv.FormatBool(cfg.Webhook.Enabled), nil
+	case configURL:
+		return cfg.Webhook.URL, nil
+	case configSecret:
+		return cfg.Webhook.Secret, nil
+	default:
+		return "", fmt.Errorf("unknown webhook key: %s", key)
+	}
+}
+
+// Funciones auxiliares para Registry
+func setRegistryConfig(cfg *types.Config, keys []string, value string) error {
 	provider := strings.ToLower(keys[0])
-	subkey := strings.ToLower(keys[1])
 
 	switch provider {
 	case configGHCR:
-		if subkey != configToken {
-			return fmt.Errorf("unknown ghcr key: %s (use 'registry.ghcr.token')", subkey)
+		if len(keys) < 2 || strings.ToLower(keys[1]) != configToken {
+			return fmt.Errorf("unknown ghcr key (use 'registry.ghcr.token')")
 		}
 		cfg.Registry.GHCRToken = value
+	case configDockerHub:
+		if len(keys) < 2 {
+			return fmt.Errorf("missing dockerhub key (use 'registry.dockerhub.username', '.password', '.token' or '.mirror')")
+		}
+		switch strings.ToLower(keys[1]) {
+		case configUsername:
+			cfg.Registry.DockerHub.Username = value
+		case configPassword:
+			cfg.Registry.DockerHub.Password = value
+		case configToken:
+			cfg.Registry.DockerHub.Token = value
+		case configMirror:
+			cfg.Registry.DockerHub.Mirror = value
+		default:
+			return fmt.Errorf("unknown dockerhub key: %s", keys[1])
+		}
+	case configQuay:
+		if len(keys) < 2 {
+			return fmt.Errorf("missing quay key (use 'registry.quay.enabled', '.timeout' or '.token')")
+		}
+		switch strings.ToLower(keys[1]) {
+		case configEnabled:
+			val, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean value: %s", value)
+			}
+			cfg.Registry.Quay.Enabled = val
+		case configTimeout:
+			val, err := strconv.Atoi(value)
+			if err != nil || val < 0 {
+				return fmt.Errorf("invalid timeout value: %s", value)
+			}
+			cfg.Registry.Quay.Timeout = val
+		case configToken:
+			cfg.Registry.Quay.Token = value
+		default:
+			return fmt.Errorf("unknown quay key: %s", keys[1])
+		}
+	case configGitLab:
+		if len(keys) < 2 {
+			return fmt.Errorf("missing gitlab key (use 'registry.gitlab.enabled', '.host', '.username', '.token' or '.timeout')")
+		}
+		switch strings.ToLower(keys[1]) {
+		case configEnabled:
+			val, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean value: %s", value)
+			}
+			cfg.Registry.GitLab.Enabled = val
+		case configHost:
+			cfg.Registry.GitLab.Host = value
+		case configUsername:
+			cfg.Registry.GitLab.Username = value
+		case configToken:
+			cfg.Registry.GitLab.Token = value
+		case configTimeout:
+			val, err := strconv.Atoi(value)
+			if err != nil || val < 0 {
+				return fmt.Errorf("invalid timeout value: %s", value)
+			}
+			cfg.Registry.GitLab.Timeout = val
+		default:
+			return fmt.Errorf("unknown gitlab key: %s", keys[1])
+		}
+	case configTrustedHosts:
+		cfg.Registry.TrustedHosts = nil
+		for _, host := range strings.Split(value, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				cfg.Registry.TrustedHosts = append(cfg.Registry.TrustedHosts, host)
+			}
+		}
 	case configTimeout:
 		val, err := strconv.Atoi(value)
 		if err != nil {
 			return fmt.Errorf("invalid timeout value: %s", value)
 		}
 		cfg.Registry.Timeout = val
+	case configMaxTags:
+		val, err := strconv.Atoi(value)
+		if err != nil || val < 0 {
+			return fmt.Errorf("invalid max_tags value: %s", value)
+		}
+		cfg.Registry.MaxTags = val
 	default:
 		return fmt.Errorf("unknown registry provider: %s", provider)
 	}
@@ -246,24 +777,82 @@ func setRegistryConfig(cfg *types.Config, keys []string, value string) error {
 }
 
 func getRegistryConfig(cfg *types.Config, keys []string) (string, error) {
-	if len(keys) < 2 {
-		return "", fmt.Errorf("registry key must be in format 'registry.subkey' or 'registry.provider.key'")
-	}
-
 	provider := strings.ToLower(keys[0])
-	subkey := strings.ToLower(keys[1])
 
 	switch provider {
 	case configGHCR:
-		if subkey != configToken {
-			return "", fmt.Errorf("unknown ghcr key: %s (use 'registry.ghcr.token')", subkey)
+		if len(keys) < 2 || strings.ToLower(keys[1]) != configToken {
+			return "", fmt.Errorf("unknown ghcr key (use 'registry.ghcr.token')")
 		}
 		if cfg.Registry.GHCRToken == "" {
 			return "", nil
 		}
 		return "[REDACTED]", nil
+	case configDockerHub:
+		if len(keys) < 2 {
+			return "", fmt.Errorf("missing dockerhub key (use 'registry.dockerhub.username', '.password', '.token' or '.mirror')")
+		}
+		var secret string
+		switch strings.ToLower(keys[1]) {
+		case configUsername:
+			return cfg.Registry.DockerHub.Username, nil
+		case configMirror:
+			return cfg.Registry.DockerHub.Mirror, nil
+		case configPassword:
+			secret = cfg.Registry.DockerHub.Password
+		case configToken:
+			secret = cfg.Registry.DockerHub.Token
+		default:
+			return "", fmt.Errorf("unknown dockerhub key: %s", keys[1])
+		}
+		if secret == "" {
+			return "", nil
+		}
+		return "[REDACTED]", nil
+	case configQuay:
+		if len(keys) < 2 {
+			return "", fmt.Errorf("missing quay key (use 'registry.quay.enabled', '.timeout' or '.token')")
+		}
+		switch strings.ToLower(keys[1]) {
+		case configEnabled:
+			return strconv.FormatBool(cfg.Registry.Quay.Enabled), nil
+		case configTimeout:
+			return strconv.Itoa(cfg.Registry.Quay.Timeout), nil
+		case configToken:
+			if cfg.Registry.Quay.Token == "" {
+				return "", nil
+			}
+			return "[REDACTED]", nil
+		default:
+			return "", fmt.Errorf("unknown quay key: %s", keys[1])
+		}
+	case configGitLab:
+		if len(keys) < 2 {
+			return "", fmt.Errorf("missing gitlab key (use 'registry.gitlab.enabled', '.host', '.username', '.token' or '.timeout')")
+		}
+		switch strings.ToLower(keys[1]) {
+		case configEnabled:
+			return strconv.FormatBool(cfg.Registry.GitLab.Enabled), nil
+		case configHost:
+			return cfg.Registry.GitLab.Host, nil
+		case configUsername:
+			return cfg.Registry.GitLab.Username, nil
+		case configTimeout:
+			return strconv.Itoa(cfg.Registry.GitLab.Timeout), nil
+		case configToken:
+			if cfg.Registry.GitLab.Token == "" {
+				return "", nil
+			}
+			return "[REDACTED]", nil
+		default:
+			return "", fmt.Errorf("unknown gitlab key: %s", keys[1])
+		}
 	case configTimeout:
 		return strconv.Itoa(cfg.Registry.Timeout), nil
+	case configTrustedHosts:
+		return strings.Join(cfg.Registry.TrustedHosts, ","), nil
+	case configMaxTags:
+		return strconv.Itoa(cfg.Registry.MaxTags), nil
 	default:
 		return "", fmt.Errorf("unknown registry provider: %s", provider)
 	}
@@ -285,11 +874,47 @@ func setScanConfig(cfg *types.Config, key, value string) error {
 		}
 		cfg.Scan.Timeout = val
 	case configPatterns:
-		cfg.Scan.Patterns = strings.Split(value, ",")
+		patterns := strings.Split(value, ",")
 		// Trim whitespace
-		for i, pattern := range cfg.Scan.Patterns {
-			cfg.Scan.Patterns[i] = strings.TrimSpace(pattern)
+		for i, pattern := range patterns {
+			patterns[i] = strings.TrimSpace(pattern)
+		}
+		if err := config.ValidatePatterns(patterns); err != nil {
+			return err
+		}
+		cfg.Scan.Patterns = patterns
+	case configPriority:
+		cfg.Scan.Priority = nil
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				cfg.Scan.Priority = append(cfg.Scan.Priority, pattern)
+			}
+		}
+	case configRollingImages:
+		cfg.Scan.RollingImages = nil
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				cfg.Scan.RollingImages = append(cfg.Scan.RollingImages, pattern)
+			}
+		}
+	case configMaxUpdate:
+		maxUpdateType := strings.ToLower(strings.TrimSpace(value))
+		if err := config.ValidateMaxUpdateType(maxUpdateType); err != nil {
+			return err
+		}
+		cfg.Scan.MaxUpdateType = maxUpdateType
+	case configSuffixPolicy:
+		policy, err := utils.ParseSuffixPolicy(value)
+		if err != nil {
+			return err
+		}
+		cfg.Scan.SuffixPolicy = string(policy)
+	case configTargetOS:
+		target, err := utils.ParseTargetOS(value)
+		if err != nil {
+			return err
 		}
+		cfg.Scan.TargetOS = string(target)
 	default:
 		return fmt.Errorf("unknown scan key: %s", key)
 	}
@@ -304,7 +929,49 @@ func getScanConfig(cfg *types.Config, key string) (string, error) {
 		return strconv.Itoa(cfg.Scan.Timeout), nil
 	case configPatterns:
 		return strings.Join(cfg.Scan.Patterns, ","), nil
+	case configPriority:
+		return strings.Join(cfg.Scan.Priority, ","), nil
+	case configRollingImages:
+		return strings.Join(cfg.Scan.RollingImages, ","), nil
+	case configMaxUpdate:
+		return cfg.Scan.MaxUpdateType, nil
+	case configSuffixPolicy:
+		return cfg.Scan.SuffixPolicy, nil
+	case configTargetOS:
+		return cfg.Scan.TargetOS, nil
 	default:
 		return "", fmt.Errorf("unknown scan key: %s", key)
 	}
 }
+
+// Funciones auxiliares para Report
+func setReportConfig(cfg *types.Config, key, value string) error {
+	switch key {
+	case configMaxUpToDate:
+		val, err := strconv.Atoi(value)
+		if err != nil || val < 0 {
+			return fmt.Errorf("invalid max_uptodate value: %s", value)
+		}
+		cfg.Report.MaxUpToDate = val
+	case configDefaultFormat:
+		format := strings.ToLower(strings.TrimSpace(value))
+		if err := config.ValidateOutputFormat(format); err != nil {
+			return err
+		}
+		cfg.Report.DefaultFormat = format
+	default:
+		return fmt.Errorf("unknown report key: %s", key)
+	}
+	return nil
+}
+
+func getReportConfig(cfg *types.Config, key string) (string, error) {
+	switch key {
+	case configMaxUpToDate:
+		return strconv.Itoa(cfg.Report.MaxUpToDate), nil
+	case configDefaultFormat:
+		return cfg.Report.DefaultFormat, nil
+	default:
+		return "", fmt.Errorf("unknown report key: %s", key)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	yaml "gopkg.in/yaml.v3"
 
+	"github.com/user/docker-image-reporter/internal/config"
 	"github.com/user/docker-image-reporter/pkg/types"
 )
 
@@ -26,7 +27,7 @@ func TestNewConfigCmd(t *testing.T) {
 
 	// Check subcommands exist
 	subcommands := cmd.Commands()
-	expectedSubs := []string{"show", "get <key>", "set <key> <value>"}
+	expectedSubs := []string{"show", "get <key>", "set <key> <value>", "add <key> <value>", "remove <key> <value>", "migrate"}
 	if len(subcommands) != len(expectedSubs) {
 		t.Errorf("Expected %d subcommands, got %d", len(expectedSubs), len(subcommands))
 	}
@@ -116,6 +117,87 @@ func TestRunConfigSet(t *testing.T) {
 	}
 }
 
+func TestRunConfigAddRemove(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	testConfig := &types.Config{
+		Registry: types.RegistryConfig{Timeout: 30},
+		Scan: types.ScanConfig{
+			Timeout:  300,
+			Patterns: []string{"docker-compose.yml"},
+		},
+	}
+	if err := saveTestConfig(testConfig, configPath); err != nil {
+		t.Fatalf("Failed to save test config: %v", err)
+	}
+
+	run := func(args ...string) error {
+		root := NewRootCmd()
+		root.SetOut(&bytes.Buffer{})
+		root.SetArgs(append(args, "--config", configPath))
+		return root.Execute()
+	}
+	patterns := func() []string {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		return cfg.Scan.Patterns
+	}
+
+	// Añadir varias veces el mismo valor no genera duplicados
+	for i := 0; i < 3; i++ {
+		if err := run("config", "add", "scan.patterns", "compose.yaml"); err != nil {
+			t.Fatalf("config add error = %v", err)
+		}
+	}
+	if got := strings.Join(patterns(), ","); got != "docker-compose.yml,compose.yaml" {
+		t.Errorf("patterns after add = %s, want docker-compose.yml,compose.yaml", got)
+	}
+
+	// Quitar un valor ausente no es un error
+	for i := 0; i < 2; i++ {
+		if err := run("config", "remove", "scan.patterns", "docker-compose.yml"); err != nil {
+			t.Fatalf("config remove error = %v", err)
+		}
+	}
+	if got := strings.Join(patterns(), ","); got != "compose.yaml" {
+		t.Errorf("patterns after remove = %s, want compose.yaml", got)
+	}
+}
+
+func TestListConfigValue(t *testing.T) {
+	cfg := &types.Config{}
+
+	tests := []struct {
+		key     string
+		wantErr string
+	}{
+		{key: "scan.patterns"},
+		{key: "scan.priority"},
+		{key: "scan.rolling_images"},
+		{key: "registry.trusted_hosts"},
+		{key: "scan.timeout", wantErr: "not a list"},
+		{key: "telegram.chat_id", wantErr: "not a list"},
+		{key: "scan.unknown", wantErr: "unknown scan key"},
+		{key: "invalid", wantErr: "invalid key format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			list, err := listConfigValue(cfg, tt.key)
+			if tt.wantErr == "" {
+				if err != nil || list == nil {
+					t.Errorf("listConfigValue(%s) = %v, %v; want a list", tt.key, list, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("listConfigValue(%s) error = %v, want %q", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestNewConfigGetCmd(t *testing.T) {
 	cmd := newConfigGetCmd()
 
@@ -214,6 +296,60 @@ func TestSetConfigValue_Telegram(t *testing.T) {
 	}
 }
 
+func TestSetConfigValue_Notifiers(t *testing.T) {
+	cfg := &types.Config{}
+
+	tests := []struct {
+		key   string
+		value string
+		check func(*types.Config) bool
+	}{
+		{
+			key:   "slack.enabled",
+			value: "true",
+			check: func(c *types.Config) bool { return c.Slack.Enabled },
+		},
+		{
+			key:   "slack.webhook_url",
+			value: "https://hooks.slack.com/services/T0/B0/x",
+			check: func(c *types.Config) bool { return c.Slack.WebhookURL == "https://hooks.slack.com/services/T0/B0/x" },
+		},
+		{
+			key:   "slack.channel",
+			value: "#ops",
+			check: func(c *types.Config) bool { return c.Slack.Channel == "#ops" },
+		},
+		{
+			key:   "email.to",
+			value: "ops@example.com, dev@example.com",
+			check: func(c *types.Config) bool {
+				return len(c.Email.To) == 2 && c.Email.To[0] == "ops@example.com" && c.Email.To[1] == "dev@example.com"
+			},
+		},
+		{
+			key:   "email.port",
+			value: "2525",
+			check: func(c *types.Config) bool { return c.Email.Port == 2525 },
+		},
+		{
+			key:   "discord.webhook_url",
+			value: "https://discord.com/api/webhooks/1/x",
+			check: func(c *types.Config) bool { return c.Discord.WebhookURL == "https://discord.com/api/webhooks/1/x" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := setConfigValue(cfg, tt.key, tt.value); err != nil {
+				t.Errorf("Expected no error for %s, got %v", tt.key, err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("Config value not set correctly for %s", tt.key)
+			}
+		})
+	}
+}
+
 func TestSetConfigValue_Registry(t *testing.T) {
 	cfg := &types.Config{}
 
@@ -227,6 +363,43 @@ func TestSetConfigValue_Registry(t *testing.T) {
 			value: "ghcr_token",
 			check: func(c *types.Config) bool { return c.Registry.GHCRToken == "ghcr_token" },
 		},
+		{
+			key:   "registry.timeout",
+			value: "45",
+			check: func(c *types.Config) bool { return c.Registry.Timeout == 45 },
+		},
+		{
+			key:   "registry.quay.enabled",
+			value: "true",
+			check: func(c *types.Config) bool { return c.Registry.Quay.Enabled },
+		},
+		{
+			key:   "registry.quay.timeout",
+			value: "60",
+			check: func(c *types.Config) bool { return c.Registry.Quay.Timeout == 60 },
+		},
+		{
+			key:   "registry.quay.token",
+			value: "quay_token",
+			check: func(c *types.Config) bool { return c.Registry.Quay.Token == "quay_token" },
+		},
+		{
+			key:   "registry.gitlab.host",
+			value: "registry.example.com",
+			check: func(c *types.Config) bool { return c.Registry.GitLab.Host == "registry.example.com" },
+		},
+		{
+			key:   "registry.gitlab.token",
+			value: "glpat-token",
+			check: func(c *types.Config) bool { return c.Registry.GitLab.Token == "glpat-token" },
+		},
+		{
+			key:   "registry.trusted_hosts",
+			value: "cdn.example.com, mirror.example.com",
+			check: func(c *types.Config) bool {
+				return strings.Join(c.Registry.TrustedHosts, ",") == "cdn.example.com,mirror.example.com"
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -284,6 +457,19 @@ func TestSetConfigValue_Scan(t *testing.T) {
 	}
 }
 
+func TestSetConfigValue_InvalidPattern(t *testing.T) {
+	cfg := &types.Config{Scan: types.ScanConfig{Patterns: []string{"docker-compose.yml"}}}
+
+	err := setConfigValue(cfg, "scan.patterns", "compose.yml,[unclosed")
+	if err == nil || !strings.Contains(err.Error(), "[unclosed") {
+		t.Fatalf("setConfigValue() error = %v, want invalid pattern error", err)
+	}
+	// El valor anterior se conserva
+	if len(cfg.Scan.Patterns) != 1 || cfg.Scan.Patterns[0] != "docker-compose.yml" {
+		t.Errorf("Patterns = %v, want unchanged", cfg.Scan.Patterns)
+	}
+}
+
 func TestGetConfigValue(t *testing.T) {
 	cfg := &types.Config{
 		Telegram: types.TelegramConfig{
@@ -354,3 +540,97 @@ func saveTestConfig(cfg *types.Config, path string) error {
 	}
 	return os.WriteFile(path, data, 0600)
 }
+
+func TestRunConfigMigrate(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	legacy := "registry:\n  ghcr:\n    token: ghp_legacy\n    timeout: 45\n"
+	if err := os.WriteFile(configPath, []byte(legacy), 0600); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+
+	// --dry-run muestra el resultado sin tocar el archivo
+	root := NewRootCmd()
+	buf := &bytes.Buffer{}
+	root.SetOut(buf)
+	root.SetArgs([]string{"--config", configPath, "config", "migrate", "--dry-run"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("dry-run failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "ghcr_token: ghp_legacy") {
+		t.Errorf("expected migrated YAML in output, got:\n%s", buf.String())
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != legacy {
+		t.Errorf("dry-run modified the config file:\n%s", data)
+	}
+
+	root = NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetArgs([]string{"--config", configPath, "config", "migrate"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("loading migrated config: %v", err)
+	}
+	if cfg.SchemaVersion != config.CurrentSchemaVersion || cfg.Registry.GHCRToken != "ghp_legacy" || cfg.Registry.Timeout != 45 {
+		t.Errorf("unexpected migrated config: schema=%d ghcr_token=%q timeout=%d",
+			cfg.SchemaVersion, cfg.Registry.GHCRToken, cfg.Registry.Timeout)
+	}
+}
+
+func TestRunConfigSetGet_Profile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	testConfig := &types.Config{
+		Registry: types.RegistryConfig{Timeout: 30},
+		Scan: types.ScanConfig{
+			Timeout:  300,
+			Patterns: []string{"docker-compose.yml"},
+		},
+	}
+	if err := saveTestConfig(testConfig, configPath); err != nil {
+		t.Fatalf("Failed to save test config: %v", err)
+	}
+
+	run := func(args ...string) string {
+		root := NewRootCmd()
+		out := &bytes.Buffer{}
+		root.SetOut(out)
+		root.SetArgs(append(args, "--config", configPath))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return out.String()
+	}
+
+	// config set --profile crea el perfil solo con la clave cambiada
+	run("config", "set", "registry.timeout", "5", "--profile", "dev")
+	run("config", "add", "scan.patterns", "compose.yaml", "--profile", "dev")
+
+	if got := run("config", "get", "registry.timeout", "--profile", "dev"); !strings.Contains(got, "5") {
+		t.Errorf("dev registry.timeout = %q, want 5", got)
+	}
+	if got := run("config", "get", "scan.patterns", "--profile", "dev"); !strings.Contains(got, "docker-compose.yml,compose.yaml") {
+		t.Errorf("dev scan.patterns = %q, want the base pattern plus compose.yaml", got)
+	}
+
+	// La base no cambia
+	base, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if base.Registry.Timeout != 30 || strings.Join(base.Scan.Patterns, ",") != "docker-compose.yml" {
+		t.Errorf("base changed: timeout %d, patterns %v", base.Registry.Timeout, base.Scan.Patterns)
+	}
+	if _, ok := base.Profiles["dev"]["registry"]; !ok {
+		t.Errorf("profile dev = %v, want registry settings", base.Profiles["dev"])
+	}
+
+	// Los cambios posteriores de la base siguen llegando al perfil
+	run("config", "set", "scan.timeout", "600")
+	if got := run("config", "get", "scan.timeout", "--profile", "dev"); !strings.Contains(got, "600") {
+		t.Errorf("dev scan.timeout = %q, want 600 from the base", got)
+	}
+}
@@ -1,9 +1,50 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
 	"github.com/spf13/cobra"
 )
 
+// errCommandTimedOut es la causa de cancelación cuando vence --timeout
+var errCommandTimedOut = errors.New("command timed out")
+
+// Códigos de salida de los fallos solicitados por flags (--fail-on-*)
+const (
+	ExitCodeUpdatesFound = 1
+	ExitCodeScanErrors   = 2
+)
+
+// Niveles de -v: cada repetición añade detalle al anterior
+const (
+	verbosityUpToDate = 1 // -v: lista de servicios al día y resumen por archivo
+	verbosityLookups  = 2 // -vv: registro y latencia de cada consulta
+	verbosityDebug    = 3 // -vvv: logs de depuración
+)
+
+// LogLevel es el nivel del logger por defecto. main lo pasa al handler que
+// instala y -vvv lo baja a debug
+var LogLevel = new(slog.LevelVar)
+
+// ExitError es un error que indica con qué código debe terminar el proceso
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error implementa la interfaz error
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap devuelve el error original
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 // NewRootCmd crea el comando raíz de la aplicación
 func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -21,10 +62,79 @@ via Telegram when updates are found.`,
 	cmd.AddCommand(newScanCmd())
 	cmd.AddCommand(newConfigCmd())
 	cmd.AddCommand(newTestCmd())
+	cmd.AddCommand(newCacheCmd())
+	cmd.AddCommand(newAckCmd())
 
 	// Flags globales
 	cmd.PersistentFlags().StringP("config", "c", "", "Path to configuration file")
-	cmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
+	cmd.PersistentFlags().String("profile", "", "Configuration profile to apply over the base settings (see profiles in the config file)")
+	cmd.PersistentFlags().CountP("verbose", "v", "Increase output detail: -v lists up-to-date services, -vv adds registry lookups and timings, -vvv enables debug logs")
+	cmd.PersistentFlags().Bool("json-compact", false, "Write JSON output on a single line instead of indented")
+	cmd.PersistentFlags().Duration("timeout", 0, "Maximum total run time of the command (e.g. 5m); 0 disables the limit")
+
+	cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		applyLogLevel(verbosity(cmd))
+	}
+
+	applyTimeout(cmd)
 
 	return cmd
 }
+
+// verbosity devuelve cuántas veces se pasó -v
+func verbosity(cmd *cobra.Command) int {
+	count, _ := cmd.Flags().GetCount("verbose")
+	return count
+}
+
+// applyLogLevel ajusta LogLevel: debug con -vvv, info en otro caso
+func applyLogLevel(verbosity int) {
+	level := slog.LevelInfo
+	if verbosity >= verbosityDebug {
+		level = slog.LevelDebug
+	}
+	LogLevel.Set(level)
+}
+
+// applyTimeout envuelve el RunE de cada subcomando para que su contexto venza
+// tras --timeout. Al vencer, el error del comando se reemplaza por un mensaje
+// claro; la salida parcial que el comando ya haya escrito se conserva.
+func applyTimeout(root *cobra.Command) {
+	for _, sub := range root.Commands() {
+		applyTimeout(sub)
+
+		run := sub.RunE
+		if run == nil {
+			continue
+		}
+
+		sub.RunE = func(cmd *cobra.Command, args []string) error {
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			if timeout < 0 {
+				return fmt.Errorf("--timeout must not be negative, got %s", timeout)
+			}
+			if timeout == 0 {
+				return run(cmd, args)
+			}
+
+			ctx, cancel := context.WithTimeoutCause(commandContext(cmd), timeout, errCommandTimedOut)
+			defer cancel()
+			cmd.SetContext(ctx)
+
+			err := run(cmd, args)
+			if errors.Is(context.Cause(ctx), errCommandTimedOut) {
+				return fmt.Errorf("%w after %s", errCommandTimedOut, timeout)
+			}
+			return err
+		}
+	}
+}
+
+// commandContext devuelve el contexto del comando o context.Background()
+// cuando se invoca sin Execute (por ejemplo, desde tests)
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
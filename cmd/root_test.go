@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRootCmd_TimeoutBoundsScan(t *testing.T) {
+	// Registro lento: no responde hasta que el cliente cancela la petición
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	registryHost := strings.TrimPrefix(srv.URL, "http://")
+	compose := "services:\n  app:\n    image: " + registryHost + "/org/app:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	root := NewRootCmd()
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(out)
+	root.SetArgs([]string{"--timeout", "200ms", "scan", dir})
+
+	start := time.Now()
+	err := root.Execute()
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, errCommandTimedOut) {
+		t.Fatalf("Execute() error = %v, want %v", err, errCommandTimedOut)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("command ran for %s, expected it to stop shortly after the timeout", elapsed)
+	}
+
+	// El resultado parcial se muestra antes de devolver el error
+	if !strings.Contains(out.String(), "Scan Results for:") {
+		t.Errorf("expected partial scan result in output, got:\n%s", out.String())
+	}
+}
+
+func TestRootCmd_NegativeTimeout(t *testing.T) {
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"--timeout", "-1s", "test"})
+
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "--timeout") {
+		t.Errorf("Execute() error = %v, want --timeout validation error", err)
+	}
+}
@@ -2,31 +2,59 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"io"
 	"log/slog"
+	"net"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/user/docker-image-reporter/internal/cache"
+	"github.com/user/docker-image-reporter/internal/clock"
 	"github.com/user/docker-image-reporter/internal/compose"
 	"github.com/user/docker-image-reporter/internal/config"
 	"github.com/user/docker-image-reporter/internal/docker"
 	"github.com/user/docker-image-reporter/internal/extraimages"
+	"github.com/user/docker-image-reporter/internal/metrics"
 	"github.com/user/docker-image-reporter/internal/notifier"
 	"github.com/user/docker-image-reporter/internal/registry"
 	"github.com/user/docker-image-reporter/internal/report"
 	"github.com/user/docker-image-reporter/internal/scanner"
 	"github.com/user/docker-image-reporter/pkg/types"
+	"github.com/user/docker-image-reporter/pkg/utils"
 )
 
+// preflightTimeout limita la comprobación de conectividad de cada registro
+const preflightTimeout = 5 * time.Second
+
+// telegramChannelPrefix precede al nombre de canal en el label de notificación
+const telegramChannelPrefix = "telegram:"
+
 // Output format constants
 const (
-	formatHTML = "html"
-	formatJSON = "json"
+	formatHTML     = "html"
+	formatJSON     = "json"
+	formatSARIF    = "sarif"
+	formatInflux   = "influx"
+	formatMarkdown = "markdown"
+	formatMD       = "md"
+	formatJSONL    = "jsonl"
+	formatCSV      = "csv"
 )
 
+// defaultOutputBase es el nombre base de los archivos que escribe --output
+// con varios formatos cuando no se indica --output-file
+const defaultOutputBase = "docker-updates-report"
+
 // newScanCmd crea el comando scan
 func newScanCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -39,11 +67,41 @@ or running Docker containers for image updates. Reports available updates from c
 	}
 
 	cmd.Flags().BoolP("notify", "n", false, "Send notifications for found updates")
-	cmd.Flags().StringP("output", "o", "console", "Output format (console, json, html)")
+	cmd.Flags().Bool("dry-run", false, "With --notify, print the notifications instead of sending them")
+	cmd.Flags().String("notify-threshold", "", "With --notify, only notify when any condition is met, e.g. major>=1,total>=5 (minor and patch include more severe updates)")
+	cmd.Flags().StringP("output", "o", "console", "Output format (console, json, html, sarif, influx, markdown (or md), jsonl, csv), or several separated by commas; without it, report.default_format is used")
 	cmd.Flags().String("output-file", "", "Write output to file instead of stdout")
+	cmd.Flags().String("project-name", "", "Project name shown in reports and notifications (default: x-image-reporter.project of the compose files, or the directory name)")
+	cmd.Flags().Bool("github", false, "In GitHub Actions, also write a Markdown table to the job summary and emit a warning annotation per update")
 	cmd.Flags().Bool("docker-daemon", false, "Scan running containers via Docker daemon instead of compose files")
 	cmd.Flags().Bool("fail-on-updates", false, "Exit with non-zero code if updates are found")
+	cmd.Flags().Bool("fail-on-errors", false, "Exit with non-zero code (2) if the scan reported errors, e.g. unreachable registries")
+	cmd.Flags().String("archive", "", "Scan the compose files inside this tar or tar.gz archive instead of a directory")
 	cmd.Flags().String("extra-images-file", "", "YAML file with additional images to scan (see docs for format)")
+	cmd.Flags().Bool("deep-parse", false, "Also extract images from build args and x-* extension blocks")
+	cmd.Flags().Bool("follow-symlinks", false, "Also scan compose files inside symlinked directories; each real directory is visited once")
+	cmd.Flags().String("max-update-type", "", "Also report the newest update of at most this type (patch, minor or major) as the recommended version")
+	cmd.Flags().String("suffix-policy", "prefer", "How strictly updates keep the current tag suffix (e.g. -alpine): strict, prefer or any")
+	cmd.Flags().Bool("resolve-latest", false, "Also show which version the registry's latest tag points to when it is not the newest")
+	cmd.Flags().Bool("check-built-images", false, "Also check the image of compose services that have a build section, instead of treating it as a local build")
+	cmd.Flags().Bool("registries-from-compose", false, "Enable the Quay and GitLab clients for this run when the scanned images use their registries, even if registry.quay.enabled or registry.gitlab.enabled is off")
+	cmd.Flags().Bool("only-digest-changes", false, "Only check images pinned by tag and digest and report those whose digest drifted; version updates are ignored")
+	cmd.Flags().String("target-os", "", "Apply the tag conventions of this OS: windows orders release tags such as ltsc2022 or 20H2 by OS build")
+	cmd.Flags().Bool("watch", false, "Keep running and rescan every --interval")
+	cmd.Flags().Duration("interval", time.Hour, "Time between scans in watch mode")
+	cmd.Flags().Bool("on-change", false, "With --watch, also rescan when a matching compose file is created, written or renamed; --interval becomes the maximum time between scans")
+	cmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics at this address in watch mode (e.g. :9090)")
+	cmd.Flags().String("sort-by", "severity", "Order updates in reports by severity, behind (versions behind), recency (publish date of the suggested tag) or name")
+	cmd.Flags().String("group-by", "", "Group updates in console/HTML output by file, service, registry or severity")
+	cmd.Flags().String("max-concurrency", "10", "Maximum number of images checked at once, or auto to size it from the image count and CPUs")
+	cmd.Flags().Bool("registry-auth-from-env", false, "Read each registry's credentials from REGISTRY_<HOST>_USERNAME/_PASSWORD or REGISTRY_<HOST>_TOKEN (host uppercased, non-alphanumerics as _)")
+	cmd.Flags().String("cache-file", "", "Load the registry cache from this file and save it back after the scan (e.g. ~/.icr/cache.json, or a path restored between CI runs); without it nothing is cached across runs")
+	cmd.Flags().Bool("no-preflight", false, "Skip the registry connectivity check performed before scanning")
+	cmd.Flags().String("events-file", "", "Write newline-delimited JSON scan events to this file (- for stdout)")
+	cmd.Flags().String("baseline", "", "Report and notify only updates and errors that are not in this previous JSON result")
+	cmd.Flags().String("write-baseline", "", "Write the full scan result as JSON to this file, for use with --baseline")
+	cmd.Flags().Bool("hide-acked", false, "Leave out updates acknowledged with 'icr ack' until a newer tag is suggested")
+	cmd.Flags().Int("max-uptodate", 0, "Collapse the up-to-date list to a count above N services in console/HTML output (0 = no limit)")
 
 	return cmd
 }
@@ -52,122 +110,495 @@ func runScan(cmd *cobra.Command, args []string) error {
 	logger := slog.Default()
 
 	// Obtener configuración
-	configPath, _ := cmd.Flags().GetString("config")
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
 	// Obtener flags
-	notify, _ := cmd.Flags().GetBool("notify")
-	outputFormat, _ := cmd.Flags().GetString("output")
-	outputFile, _ := cmd.Flags().GetString("output-file")
-	useDockerDaemon, _ := cmd.Flags().GetBool("docker-daemon")
+	opts := scanOptions{scanConfig: scanner.DefaultConfig()}
+	opts.notify, _ = cmd.Flags().GetBool("notify")
+	opts.dryRun, _ = cmd.Flags().GetBool("dry-run")
+	notifyThreshold, _ := cmd.Flags().GetString("notify-threshold")
+	if opts.notifyThreshold, err = types.ParseNotifyThreshold(notifyThreshold); err != nil {
+		return err
+	}
+	opts.outputFormat, _ = cmd.Flags().GetString("output")
+	if !cmd.Flags().Changed("output") && cfg.Report.DefaultFormat != "" {
+		opts.outputFormat = cfg.Report.DefaultFormat
+	}
+	if err := config.ValidateOutputFormat(opts.outputFormat); err != nil {
+		return err
+	}
+	opts.outputFile, _ = cmd.Flags().GetString("output-file")
+	opts.projectName, _ = cmd.Flags().GetString("project-name")
+	opts.github, _ = cmd.Flags().GetBool("github")
+	opts.cacheFile, _ = cmd.Flags().GetString("cache-file")
+	opts.useDockerDaemon, _ = cmd.Flags().GetBool("docker-daemon")
+	opts.extraImagesFile, _ = cmd.Flags().GetString("extra-images-file")
+	opts.onlyDigestChanges, _ = cmd.Flags().GetBool("only-digest-changes")
+	opts.checkBuiltImages, _ = cmd.Flags().GetBool("check-built-images")
+	opts.registriesFromCompose, _ = cmd.Flags().GetBool("registries-from-compose")
+	opts.jsonCompact, _ = cmd.Flags().GetBool("json-compact")
+	sortBy, _ := cmd.Flags().GetString("sort-by")
+	if opts.sortBy, err = types.ParseSortBy(sortBy); err != nil {
+		return err
+	}
+	groupBy, _ := cmd.Flags().GetString("group-by")
+	if opts.groupBy, err = types.ParseGroupBy(groupBy); err != nil {
+		return err
+	}
 	failOnUpdates, _ := cmd.Flags().GetBool("fail-on-updates")
+	failOnErrors, _ := cmd.Flags().GetBool("fail-on-errors")
+	watch, _ := cmd.Flags().GetBool("watch")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	onChange, _ := cmd.Flags().GetBool("on-change")
+	metricsAddr, _ := cmd.Flags().GetString("metrics-addr")
+	if cmd.Flags().Changed("deep-parse") {
+		cfg.Scan.DeepParse, _ = cmd.Flags().GetBool("deep-parse")
+	}
+	if cmd.Flags().Changed("follow-symlinks") {
+		cfg.Scan.FollowSymlinks, _ = cmd.Flags().GetBool("follow-symlinks")
+	}
+	opts.scanConfig.FollowSymlinks = cfg.Scan.FollowSymlinks
+	if cmd.Flags().Changed("registry-auth-from-env") {
+		cfg.Registry.AuthFromEnv, _ = cmd.Flags().GetBool("registry-auth-from-env")
+	}
+	if cmd.Flags().Changed("resolve-latest") {
+		cfg.Scan.ResolveLatest, _ = cmd.Flags().GetBool("resolve-latest")
+	}
+	if cmd.Flags().Changed("max-update-type") {
+		value, _ := cmd.Flags().GetString("max-update-type")
+		maxUpdateType := strings.ToLower(strings.TrimSpace(value))
+		if err := config.ValidateMaxUpdateType(maxUpdateType); err != nil {
+			return err
+		}
+		cfg.Scan.MaxUpdateType = maxUpdateType
+	}
+	if cmd.Flags().Changed("suffix-policy") {
+		value, _ := cmd.Flags().GetString("suffix-policy")
+		policy, err := utils.ParseSuffixPolicy(value)
+		if err != nil {
+			return err
+		}
+		cfg.Scan.SuffixPolicy = string(policy)
+	}
+	if cmd.Flags().Changed("target-os") {
+		value, _ := cmd.Flags().GetString("target-os")
+		target, err := utils.ParseTargetOS(value)
+		if err != nil {
+			return err
+		}
+		cfg.Scan.TargetOS = string(target)
+	}
+	if cmd.Flags().Changed("max-uptodate") {
+		cfg.Report.MaxUpToDate, _ = cmd.Flags().GetInt("max-uptodate")
+		if cfg.Report.MaxUpToDate < 0 {
+			return fmt.Errorf("--max-uptodate must not be negative, got %d", cfg.Report.MaxUpToDate)
+		}
+	}
+
+	// Determinar el path a escanear
+	opts.scanPath = "."
+	if len(args) > 0 {
+		opts.scanPath = args[0]
+	}
+
+	if archive, _ := cmd.Flags().GetString("archive"); archive != "" {
+		if len(args) > 0 || opts.useDockerDaemon {
+			return fmt.Errorf("--archive cannot be combined with a scan path or --docker-daemon")
+		}
+		if onChange {
+			return fmt.Errorf("--on-change watches compose files and cannot be used with --archive")
+		}
+		extractDir, cleanup, err := extractArchive(archive, opts.scanConfig.Patterns, logger)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		opts.scanPath, opts.archive = extractDir, archive
+	}
+
+	if cmd.Flags().Changed("max-concurrency") {
+		value, _ := cmd.Flags().GetString("max-concurrency")
+		if opts.scanConfig.MaxConcurrency, err = parseMaxConcurrency(value); err != nil {
+			return err
+		}
+	}
+
+	if noPreflight, _ := cmd.Flags().GetBool("no-preflight"); !noPreflight {
+		opts.preflight = preflightTimeout
+	}
+
+	if baselineFile, _ := cmd.Flags().GetString("baseline"); baselineFile != "" {
+		if opts.baseline, err = loadBaseline(baselineFile, logger); err != nil {
+			return err
+		}
+	}
+	opts.writeBaseline, _ = cmd.Flags().GetString("write-baseline")
+	opts.hideAcked, _ = cmd.Flags().GetBool("hide-acked")
+
+	eventsFile, _ := cmd.Flags().GetString("events-file")
+	if eventsFile != "" {
+		events, closeEvents, err := openEventsFile(cmd, eventsFile)
+		if err != nil {
+			return err
+		}
+		defer closeEvents()
+		opts.events = events
+	}
 
 	ctx := cmd.Context()
 
+	if watch {
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be positive, got %s", interval)
+		}
+		if onChange && opts.useDockerDaemon {
+			return fmt.Errorf("--on-change watches compose files and cannot be used with --docker-daemon")
+		}
+		return runWatch(ctx, cmd, cfg, opts, interval, onChange, metricsAddr, logger)
+	}
+	if onChange {
+		return fmt.Errorf("--on-change requires --watch")
+	}
+	if metricsAddr != "" {
+		logger.Warn("--metrics-addr is only used with --watch, ignoring")
+	}
+
+	var registryCache *cache.RegistryCache
+	if opts.cacheFile != "" {
+		var saveCache func()
+		registryCache, saveCache = openPersistentCache(opts.cacheFile, logger)
+		defer saveCache()
+	}
+
+	result, err := runScanCycle(ctx, cmd, cfg, opts, createScanService(cfg, registryCache, opts), logger)
+	if err != nil {
+		return err
+	}
+
+	// Fallar si hubo errores y se solicitó: un escaneo incompleto tiene prioridad
+	// sobre las actualizaciones encontradas
+	if failOnErrors && result.HasErrors() {
+		return &ExitError{Code: ExitCodeScanErrors, Err: fmt.Errorf("scan reported %d errors", len(result.Errors))}
+	}
+
+	// Fallar si hay actualizaciones y se solicitó
+	if failOnUpdates && result.HasUpdates() {
+		return &ExitError{Code: ExitCodeUpdatesFound, Err: fmt.Errorf("found %d image updates", len(result.UpdatesAvailable))}
+	}
+
+	return nil
+}
+
+// scanOptions agrupa los flags que afectan a cada ciclo de escaneo
+type scanOptions struct {
+	scanPath          string
+	notify            bool
+	notifyThreshold   types.NotifyThreshold
+	outputFormat      string
+	outputFile        string
+	github            bool
+	cacheFile         string
+	useDockerDaemon   bool
+	extraImagesFile   string
+	onlyDigestChanges bool
+	checkBuiltImages  bool
+	sortBy            types.SortBy
+	archive           string // archivo tar extraído en scanPath, si se usó --archive
+	projectName       string // sustituye el nombre de proyecto del escaneo
+	groupBy           types.GroupBy
+	events            *scanner.EventWriter
+	preflight         time.Duration
+	scanConfig        scanner.Config
+	dryRun            bool
+	jsonCompact       bool
+	baseline          *types.ScanResult // nil = informar del resultado completo
+	writeBaseline     string
+	hideAcked         bool
+	// registriesFromCompose activa los clientes de Quay y GitLab que necesiten
+	// las imágenes encontradas aunque no estén habilitados
+	registriesFromCompose bool
+}
+
+// extractArchive extrae los archivos compose de --archive en un directorio
+// temporal. cleanup lo elimina al terminar el escaneo
+func extractArchive(archive string, patterns []string, logger *slog.Logger) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "icr-archive-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	files, err := compose.ExtractArchive(archive, dir, patterns)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	logger.Info("Extracted archive", "archive", archive, "files", len(files))
+	return dir, cleanup, nil
+}
+
+// hideAcknowledged quita del resultado las actualizaciones aplazadas con ack
+func hideAcknowledged(result types.ScanResult, logger *slog.Logger) (types.ScanResult, error) {
+	path, err := defaultAcksPath()
+	if err != nil {
+		return result, err
+	}
+	acks, err := loadAcks(path)
+	if err != nil {
+		return result, err
+	}
+	result, hidden := result.HideAcknowledged(acks)
+	if hidden > 0 {
+		logger.Info("Hid acknowledged updates", "hidden", hidden, "path", path)
+	}
+	return result, nil
+}
+
+// loadBaseline lee un resultado JSON anterior para --baseline. Si el archivo
+// aún no existe (primera ejecución) se usa un baseline vacío.
+func loadBaseline(path string, logger *slog.Logger) (*types.ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Info("Baseline file not found, reporting the full result", "path", path)
+		return &types.ScanResult{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline types.ScanResult
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+// saveBaseline escribe el resultado completo como JSON para --write-baseline
+func saveBaseline(path string, result types.ScanResult) error {
+	output, err := report.JSONFormatter{}.Format(result)
+	if err != nil {
+		return fmt.Errorf("failed to format baseline: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(output), 0600); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// parseMaxConcurrency interpreta --max-concurrency: un entero positivo o "auto"
+func parseMaxConcurrency(value string) (int, error) {
+	if strings.EqualFold(value, "auto") {
+		return scanner.AutoConcurrency, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("--max-concurrency must be a positive integer or auto, got %q", value)
+	}
+	return n, nil
+}
+
+// openEventsFile abre el destino de --events-file ("-" es la salida estándar)
+// y devuelve el writer de eventos junto con la función que lo cierra
+func openEventsFile(cmd *cobra.Command, path string) (*scanner.EventWriter, func(), error) {
+	if path == "-" {
+		return scanner.NewEventWriter(cmd.OutOrStdout()), func() {}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create events file: %w", err)
+	}
+	return scanner.NewEventWriter(file), func() { _ = file.Close() }, nil
+}
+
+// runWatch ejecuta ciclos de escaneo cada interval hasta que se cancele el contexto.
+// Con onChange también se reescanea, tras un debounce, cuando cambia un archivo
+// compose del directorio escaneado; interval queda como tiempo máximo entre escaneos.
+// Si metricsAddr no está vacío, expone /metrics con la telemetría del último ciclo.
+func runWatch(ctx context.Context, cmd *cobra.Command, cfg *types.Config, opts scanOptions, interval time.Duration, onChange bool, metricsAddr string, logger *slog.Logger) error {
+	// En watch mode la caché se mantiene en memoria entre ciclos y, con
+	// --cache-file, también entre ejecuciones
+	var registryCache *cache.RegistryCache
+	if opts.cacheFile == "" {
+		registryCache = cache.NewRegistryCache(cache.DefaultConfig())
+		defer registryCache.Close()
+	} else {
+		var saveCache func()
+		registryCache, saveCache = openPersistentCache(opts.cacheFile, logger)
+		defer saveCache()
+	}
+
+	scanSvc := createScanService(cfg, registryCache, opts)
+	scanMetrics := metrics.New()
+	clk := clock.New()
+
+	if metricsAddr != "" {
+		ln, err := net.Listen("tcp", metricsAddr)
+		if err != nil {
+			return fmt.Errorf("failed to start metrics server: %w", err)
+		}
+		logger.Info("Serving metrics", "addr", ln.Addr().String())
+
+		go func() {
+			if err := metrics.Serve(ctx, ln, scanMetrics.Handler()); err != nil {
+				logger.Error("Metrics server failed", "error", err)
+			}
+		}()
+	}
+
+	// Un canal nil nunca recibe, así que sin onChange solo cuenta el intervalo
+	var changes <-chan struct{}
+	if onChange {
+		var err error
+		// Observar los mismos archivos que recorre el escaneo
+		watchConfig := types.ScanConfig{Recursive: opts.scanConfig.Recursive, Patterns: opts.scanConfig.Patterns}
+		changes, err = compose.NewWatcher(watchConfig, compose.DefaultDebounce, logger).Watch(ctx, opts.scanPath)
+		if err != nil {
+			return fmt.Errorf("failed to watch %s: %w", opts.scanPath, err)
+		}
+	}
+
+	logger.Info("Starting watch mode", "interval", interval, "on_change", onChange)
+
+	for {
+		start := clk.Now()
+		result, err := runScanCycle(ctx, cmd, cfg, opts, scanSvc, logger)
+		if err != nil {
+			logger.Error("Scan cycle failed", "error", err)
+			scanMetrics.RecordFailure()
+		} else {
+			scanMetrics.RecordScan(result, clk.Now().Sub(start))
+		}
+		scanMetrics.RecordCache(registryCache.Stats())
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Watch mode stopped")
+			return nil
+		case <-clk.After(interval):
+		case <-changes:
+			logger.Info("Compose files changed, rescanning")
+		}
+	}
+}
+
+// runScanCycle ejecuta un escaneo completo: escaneo, salida y notificaciones
+func runScanCycle(ctx context.Context, cmd *cobra.Command, cfg *types.Config, opts scanOptions, scanSvc *scanner.Service, logger *slog.Logger) (types.ScanResult, error) {
 	var result types.ScanResult
 
-	if useDockerDaemon {
+	if opts.useDockerDaemon {
 		logger.Info("Starting Docker daemon scan")
 
 		// Crear cliente Docker
 		dockerClient, err := docker.NewClient(logger)
 		if err != nil {
-			return fmt.Errorf("failed to create Docker client: %w", err)
+			return result, fmt.Errorf("failed to create Docker client: %w", err)
 		}
 		defer dockerClient.Close()
 
 		// Probar conexión
 		if err := dockerClient.Ping(ctx); err != nil {
-			return fmt.Errorf("failed to connect to Docker daemon: %w", err)
+			return result, fmt.Errorf("failed to connect to Docker daemon: %w", err)
 		}
 
 		// Escanear contenedores en ejecución
-		result, err = scanDockerDaemon(ctx, dockerClient, cfg, logger)
+		result, err = scanDockerDaemon(ctx, dockerClient, scanSvc, opts.scanConfig, logger)
 		if err != nil {
-			return fmt.Errorf("docker daemon scan failed: %w", err)
+			return result, fmt.Errorf("docker daemon scan failed: %w", err)
 		}
 	} else {
 		logger.Info("Starting compose files scan")
 
-		// Determinar el path a escanear
-		scanPath := "."
-		if len(args) > 0 {
-			scanPath = args[0]
-		}
-
 		// Verificar que el path existe
-		if _, err := os.Stat(scanPath); os.IsNotExist(err) {
-			return fmt.Errorf("path does not exist: %s", scanPath)
+		if _, err := os.Stat(opts.scanPath); os.IsNotExist(err) {
+			return result, fmt.Errorf("path does not exist: %s", opts.scanPath)
 		}
 
-		logger.Info("Starting scan", "path", scanPath)
-
-		// Crear servicios
-		scanSvc := createScanService(cfg)
+		logger.Info("Starting scan", "path", opts.scanPath)
 
 		// Ejecutar el escaneo
-		scanConfig := scanner.DefaultConfig()
-		scanResultPtr, err := scanSvc.ScanDirectory(ctx, scanPath, scanConfig)
+		scanResultPtr, err := scanSvc.ScanDirectory(ctx, opts.scanPath, opts.scanConfig)
 		if err != nil {
-			return fmt.Errorf("scan failed: %w", err)
+			return result, fmt.Errorf("scan failed: %w", err)
 		}
 		result = *scanResultPtr
+		// El directorio temporal no dice nada: usar el nombre del archivo,
+		// salvo que los compose declaren su proyecto
+		if opts.archive != "" && result.ProjectName == filepath.Base(opts.scanPath) {
+			result.ProjectName = filepath.Base(opts.archive)
+		}
+	}
+	if opts.projectName != "" {
+		result.ProjectName = opts.projectName
 	}
 
 	// Scan extra images from optional YAML file
-	extraImagesFile, _ := cmd.Flags().GetString("extra-images-file")
-	if extraImagesFile != "" {
-		result = scanExtraImages(ctx, extraImagesFile, cfg, result, logger)
+	if opts.extraImagesFile != "" {
+		result = scanExtraImages(ctx, opts.extraImagesFile, scanSvc, opts.scanConfig, result, logger)
+	}
+
+	// Guardar el resultado completo y quedarse solo con los cambios respecto al baseline
+	if opts.writeBaseline != "" {
+		if err := saveBaseline(opts.writeBaseline, result); err != nil {
+			return result, err
+		}
+	}
+	if opts.baseline != nil {
+		full := result
+		result = result.Diff(*opts.baseline)
+		logger.Info("Compared with baseline",
+			"updates", len(full.UpdatesAvailable), "new_updates", len(result.UpdatesAvailable),
+			"errors", len(full.Errors), "new_errors", len(result.Errors))
 	}
 
+	// Ocultar las actualizaciones aplazadas; el archivo se relee en cada escaneo
+	// para que watch mode tenga en cuenta los ack hechos mientras se ejecuta
+	if opts.hideAcked {
+		visible, err := hideAcknowledged(result, logger)
+		if err != nil {
+			return result, err
+		}
+		result = visible
+	}
+
+	result.SortUpdates(opts.sortBy)
+
 	// Crear servicios comunes
-	reportSvc := createReportService()
-	notifySvc := createNotificationService(cfg)
+	reportSvc := createReportService(cfg, opts.groupBy, opts.jsonCompact)
+	var dryRun io.Writer
+	if opts.dryRun {
+		dryRun = cmd.OutOrStdout()
+	}
+	notifySvc := createNotificationService(cfg, dryRun)
 
 	// Mostrar resultados según el formato solicitado
-	if err := outputResult(cmd, result, outputFormat, outputFile, reportSvc); err != nil {
-		return fmt.Errorf("failed to output result: %w", err)
+	if err := outputResult(cmd, result, opts.outputFormat, opts.outputFile, reportSvc); err != nil {
+		return result, fmt.Errorf("failed to output result: %w", err)
+	}
+	if opts.github {
+		if err := outputGitHub(cmd, result, logger); err != nil {
+			return result, fmt.Errorf("failed to write GitHub Actions output: %w", err)
+		}
 	}
 
 	// Enviar notificaciones si está habilitado
-	logger.Info("Notification check", "notify_flag", notify, "has_clients", notifySvc.HasClients(), "has_updates", result.HasUpdates(), "has_errors", result.HasErrors())
-	if notify && notifySvc.HasClients() {
-		// Para notificaciones, generar HTML y enviarlo como archivo adjunto
-		htmlFormatter := reportSvc.htmlFormatter
-		htmlContent, err := htmlFormatter.Format(result)
-		if err != nil {
-			logger.Error("Failed to format HTML report", "error", err)
-		} else {
-			// Crear archivo temporal
-			tempFile, err := os.CreateTemp("", "docker-report-*.html")
-			if err != nil {
-				logger.Error("Failed to create temp file", "error", err)
-			} else {
-				defer os.Remove(tempFile.Name()) // Limpiar archivo temporal
-
-				// Escribir contenido HTML
-				if _, err := tempFile.WriteString(htmlContent); err != nil {
-					logger.Error("Failed to write HTML to temp file", "error", err)
-				} else {
-					tempFile.Close()
-
-					// Enviar archivo como adjunto
-					caption := fmt.Sprintf("🐳 <b>Docker Image Updates Report</b>\n\n📊 <b>Summary:</b> %s\n📅 <b>Scanned:</b> %s",
-						result.Summary(),
-						result.ScanTimestamp.Format("2006-01-02 15:04:05"))
-
-					if err := notifySvc.SendFile(ctx, tempFile.Name(), "docker-updates-report.html", caption); err != nil {
-						logger.Error("Failed to send HTML report", "error", err)
-					} else {
-						logger.Info("HTML report sent successfully")
-					}
-				}
-			}
+	// Las notificaciones cubren actualizaciones y errores; un escaneo sin
+	// ninguno de los dos no envía mensaje ni informe
+	logger.Info("Notification check", "notify_flag", opts.notify, "has_clients", notifySvc.HasClients(), "has_updates", result.HasUpdates(), "has_errors", result.HasErrors())
+	if opts.notify && !result.HasActionable(true) {
+		logger.Info("Nothing to notify", "summary", result.Summary())
+	} else if opts.notify && !opts.notifyThreshold.Met(result) {
+		logger.Info("Notification threshold not met, skipping notifications", "threshold", opts.notifyThreshold.String(), "summary", result.Summary())
+	} else if opts.notify && notifySvc.HasClients() {
+		// Mensaje agrupado por severidad (o el template configurado) y el informe HTML adjunto
+		if err := notifySvc.NotifyScanResult(ctx, result, notifier.TelegramFormatter{Template: cfg.Telegram.Template}); err != nil {
+			logger.Error("Failed to send notification message", "error", err)
 		}
-	} else if notify && !notifySvc.HasClients() {
+		sendHTMLReport(ctx, result, reportSvc, notifySvc, logger)
+	} else if opts.notify && !notifySvc.HasClients() {
 		logger.Warn("Notification requested but no clients configured")
 	}
 
@@ -176,54 +607,268 @@ func runScan(cmd *cobra.Command, args []string) error {
 		"services_found", result.TotalServicesFound,
 		"updates_available", len(result.UpdatesAvailable))
 
-	// Fallar si hay actualizaciones y se solicitó
-	if failOnUpdates && len(result.UpdatesAvailable) > 0 {
-		return fmt.Errorf("found %d image updates", len(result.UpdatesAvailable))
+	return result, nil
+}
+
+// sendHTMLReport genera el informe HTML y lo envía como archivo adjunto.
+// Cada cliente recibe un informe con solo las actualizaciones que le interesan.
+func sendHTMLReport(ctx context.Context, result types.ScanResult, reportSvc *reportService, notifySvc *notifier.NotificationService, logger *slog.Logger) {
+	var tempFiles []string
+	defer func() {
+		for _, name := range tempFiles {
+			os.Remove(name) // Limpiar archivos temporales
+		}
+	}()
+
+	render := func(view types.ScanResult) (string, string, error) {
+		// Crear archivo temporal
+		tempFile, err := os.CreateTemp("", "docker-report-*.html")
+		if err != nil {
+			return "", "", fmt.Errorf("creating temp file: %w", err)
+		}
+		tempFiles = append(tempFiles, tempFile.Name())
+
+		// Escribir el HTML directamente en el archivo
+		err = reportSvc.htmlFormatter.FormatTo(tempFile, view)
+		tempFile.Close()
+		if err != nil {
+			return "", "", fmt.Errorf("writing HTML report: %w", err)
+		}
+
+		return tempFile.Name(), reportCaption(view), nil
 	}
 
-	return nil
+	// Enviar archivo como adjunto
+	if err := notifySvc.SendScanReport(ctx, result, "docker-updates-report.html", render); err != nil {
+		logger.Error("Failed to send HTML report", "error", err)
+	} else {
+		logger.Info("HTML report sent successfully")
+	}
 }
 
-func createScanService(cfg *types.Config) *scanner.Service {
+// reportCaption es el texto que acompaña al informe HTML adjunto
+func reportCaption(result types.ScanResult) string {
+	return fmt.Sprintf("🐳 <b>Docker Image Updates Report</b> — %s\n\n📊 <b>Summary:</b> %s\n📅 <b>Scanned:</b> %s",
+		html.EscapeString(result.ProjectName),
+		result.Summary(),
+		result.ScanTimestamp.Format("2006-01-02 15:04:05"))
+}
+
+// createScanService crea el servicio de escaneo. Si registryCache no es nil,
+// las consultas al registry se cachean y se reutilizan ETags entre ciclos.
+func createScanService(cfg *types.Config, registryCache *cache.RegistryCache, opts scanOptions) *scanner.Service {
 	// Crear parser de compose
-	composeParser := compose.NewParser()
+	composeParser := compose.NewParserWithOptions(compose.Options{DeepParse: cfg.Scan.DeepParse})
+
+	genericClient := registry.NewGenericRegistryClient(time.Duration(cfg.Registry.Timeout)*time.Second, cfg.Registry.GHCRToken).
+		WithTrustedHosts(cfg.Registry.TrustedHosts).
+		WithDockerHubCredentials(cfg.Registry.DockerHub.Username, cfg.Registry.DockerHub.Secret()).
+		WithDockerHubMirror(cfg.Registry.DockerHub.Mirror).
+		WithEnvCredentials(cfg.Registry.AuthFromEnv).
+		WithHostTimeouts(cfg.Registry.HostTimeouts())
+
+	var client types.RegistryClient = genericClient
+	if registryCache != nil {
+		client = cache.NewCachedRegistryClient(genericClient.WithETagCache(registryCache), registryCache)
+	}
+
+	cached := func(c types.RegistryClient) types.RegistryClient {
+		if registryCache != nil {
+			return cache.NewCachedRegistryClient(c, registryCache)
+		}
+		return c
+	}
+	quayClient := cached(registry.NewQuayClient(
+		cfg.Registry.Quay.TimeoutOr(time.Duration(cfg.Registry.Timeout)*time.Second), cfg.Registry.Quay.Token))
+	gitlabClient := cached(registry.NewGitLabClient(
+		cfg.Registry.GitLab.TimeoutOr(time.Duration(cfg.Registry.Timeout)*time.Second),
+		cfg.Registry.GitLab.Username, cfg.Registry.GitLab.Token).
+		WithTrustedHosts(cfg.Registry.TrustedHosts))
+
+	// Los clientes de Quay y GitLab van delante del genérico para que atiendan
+	// sus registros. Con --registries-from-compose los no habilitados se
+	// activan en cuanto el escaneo encuentra una imagen de su registro.
+	clients := []types.RegistryClient{client}
+	var autoRegistries []scanner.AutoRegistry
+	if cfg.Registry.Quay.Enabled {
+		clients = append([]types.RegistryClient{quayClient}, clients...)
+	} else if opts.registriesFromCompose {
+		autoRegistries = append(autoRegistries, scanner.AutoRegistry{Client: quayClient, Anonymous: cfg.Registry.Quay.Token == ""})
+	}
+	if cfg.Registry.GitLab.Enabled {
+		clients = append([]types.RegistryClient{gitlabClient}, clients...)
+	} else if opts.registriesFromCompose {
+		autoRegistries = append(autoRegistries, scanner.AutoRegistry{Client: gitlabClient, Anonymous: cfg.Registry.GitLab.Token == ""})
+	}
 
-	genericClient := registry.NewGenericRegistryClient(time.Duration(cfg.Registry.Timeout)*time.Second, cfg.Registry.GHCRToken)
+	// La política ya se validó al cargar la configuración
+	suffixPolicy, _ := utils.ParseSuffixPolicy(cfg.Scan.SuffixPolicy)
+	targetOS, _ := utils.ParseTargetOS(cfg.Scan.TargetOS)
+	// Los codenames propios también se validaron; si aun así fallan se avisa
+	// y se usan solo los incorporados
+	codenames, err := utils.NewCodenames(cfg.Scan.Codenames)
+	if err != nil {
+		slog.Default().Warn("Ignoring invalid scan.codenames", "error", err)
+		codenames = utils.DefaultCodenames()
+	}
 
 	// Crear scanner
-	scanSvc := scanner.NewService(composeParser, []types.RegistryClient{genericClient}, slog.Default())
+	scanSvc := scanner.NewService(composeParser, clients, slog.Default()).
+		WithPriority(cfg.Scan.Priority).
+		WithRollingImages(cfg.Scan.RollingImages).
+		WithSuffixPolicy(suffixPolicy).
+		WithTargetOS(targetOS).
+		WithCodenames(codenames).
+		WithAutoRegistries(autoRegistries).
+		WithMaxUpdateType(types.UpdateType(cfg.Scan.MaxUpdateType)).
+		WithLatestAlias(cfg.Scan.ResolveLatest).
+		WithStrictSemver(cfg.Scan.StrictSemver).
+		WithRepositoryAliases(cfg.Registry.RepositoryAliases).
+		WithRegistryTimeouts(cfg.Registry.HostTimeouts()).
+		WithRegistryConcurrency(cfg.Registry.Concurrency).
+		WithGitLabHost(cfg.Registry.GitLab.Host).
+		WithMaxTags(cfg.Registry.MaxTags).
+		WithEvents(opts.events).
+		WithPreflight(opts.preflight).
+		WithDigestChangesOnly(opts.onlyDigestChanges).
+		WithCheckBuiltImages(opts.checkBuiltImages)
 
 	return scanSvc
 }
 
-func createReportService() *reportService {
-	jsonFormatter := &report.JSONFormatter{}
-	htmlFormatter := &report.HTMLFormatter{}
+func createReportService(cfg *types.Config, groupBy types.GroupBy, jsonCompact bool) *reportService {
+	jsonFormatter := &report.JSONFormatter{Compact: jsonCompact}
+	htmlFormatter := &report.HTMLFormatter{MaxUpToDate: cfg.Report.MaxUpToDate, GroupBy: groupBy}
+	sarifFormatter := &report.SARIFFormatter{}
+	influxFormatter := &report.InfluxFormatter{}
 
 	return &reportService{
-		jsonFormatter: jsonFormatter,
-		htmlFormatter: htmlFormatter,
+		jsonFormatter:     jsonFormatter,
+		htmlFormatter:     htmlFormatter,
+		sarifFormatter:    sarifFormatter,
+		influxFormatter:   influxFormatter,
+		markdownFormatter: &report.MarkdownFormatter{},
+		jsonlFormatter:    &report.JSONLinesFormatter{},
+		csvFormatter:      &report.CSVFormatter{},
+		maxUpToDate:       cfg.Report.MaxUpToDate,
+		groupBy:           groupBy,
 	}
 }
 
-func createNotificationService(cfg *types.Config) *notifier.NotificationService {
+// createNotificationService crea el servicio de notificaciones. Si dryRun no es
+// nil, los mensajes se escriben ahí en lugar de enviarse.
+func createNotificationService(cfg *types.Config, dryRun io.Writer) *notifier.NotificationService {
 	notifySvc := notifier.NewNotificationService()
 
+	newClient := func(name, chatID string) types.NotificationClient {
+		if dryRun != nil {
+			return notifier.NewDryRunClient(name, dryRun)
+		}
+		return notifier.NewTelegramClient(cfg.Telegram.BotToken, chatID)
+	}
+
 	// Agregar cliente de Telegram si está configurado
 	logger := slog.Default()
 	logger.Info("Telegram config check", "enabled", cfg.Telegram.Enabled, "bot_token_set", cfg.Telegram.BotToken != "", "chat_id_set", cfg.Telegram.ChatID != "")
 	if cfg.Telegram.Enabled && cfg.Telegram.BotToken != "" && cfg.Telegram.ChatID != "" {
-		telegramClient := notifier.NewTelegramClient(cfg.Telegram.BotToken, cfg.Telegram.ChatID)
-		notifySvc.AddClient(telegramClient)
-		logger.Info("Telegram client added to notification service")
+		minUpdateType := types.UpdateType(cfg.Telegram.MinUpdateType)
+		notifySvc.AddClientWithFilter(newClient("telegram", cfg.Telegram.ChatID), minUpdateType)
+
+		// Un cliente por canal para los servicios con label image-reporter.notify-channel
+		channels := make([]string, 0, len(cfg.Telegram.Channels))
+		for name := range cfg.Telegram.Channels {
+			channels = append(channels, name)
+		}
+		sort.Strings(channels)
+		for _, name := range channels {
+			channel := telegramChannelPrefix + name
+			notifySvc.AddRoutedClient(channel, newClient(channel, cfg.Telegram.Channels[name]), minUpdateType)
+		}
+		logger.Info("Telegram client added to notification service", "channels", len(channels))
 	} else {
 		logger.Warn("Telegram client not added due to missing configuration")
 	}
 
+	// Incoming webhook de Slack
+	if cfg.Slack.Enabled && cfg.Slack.WebhookURL != "" {
+		var client types.NotificationClient = notifier.NewSlackClient(cfg.Slack.WebhookURL, cfg.Slack.Channel)
+		if dryRun != nil {
+			client = notifier.NewDryRunClient("slack", dryRun)
+		}
+		notifySvc.AddClient(client)
+		logger.Info("Slack client added to notification service")
+	}
+
+	// Webhook de Discord
+	if cfg.Discord.Enabled && cfg.Discord.WebhookURL != "" {
+		var client types.NotificationClient = notifier.NewDiscordClient(cfg.Discord.WebhookURL)
+		if dryRun != nil {
+			client = notifier.NewDryRunClient("discord", dryRun)
+		}
+		notifySvc.AddClient(client)
+		logger.Info("Discord client added to notification service")
+	}
+
+	// Correo por SMTP
+	if cfg.Email.Enabled && cfg.Email.Host != "" && len(cfg.Email.To) > 0 {
+		var client types.NotificationClient = notifier.NewEmailClient(cfg.Email)
+		if dryRun != nil {
+			client = notifier.NewDryRunClient("email", dryRun)
+		}
+		notifySvc.AddClient(client)
+		logger.Info("Email client added to notification service", "recipients", len(cfg.Email.To))
+	}
+
+	// Webhook genérico con el resultado en JSON
+	if cfg.Webhook.Enabled && cfg.Webhook.URL != "" {
+		var client types.NotificationClient = notifier.NewWebhookClient(cfg.Webhook.URL, cfg.Webhook.Headers, cfg.Webhook.Secret)
+		if dryRun != nil {
+			client = notifier.NewDryRunClient("webhook", dryRun)
+		}
+		notifySvc.AddClient(client)
+		logger.Info("Webhook client added to notification service", "signed", cfg.Webhook.Secret != "")
+	}
+
+	// Comando que recibe el mensaje por stdin (exec.command)
+	if len(cfg.Exec.Command) > 0 {
+		var client types.NotificationClient = notifier.NewExecClient(cfg.Exec.Command, time.Duration(cfg.Exec.Timeout)*time.Second)
+		if dryRun != nil {
+			client = notifier.NewDryRunClient("exec", dryRun)
+		}
+		notifySvc.AddClient(client)
+		logger.Info("Exec client added to notification service", "command", cfg.Exec.Command[0])
+	}
+
 	return notifySvc
 }
 
+// outputResult muestra el resultado en cada formato de la lista separada por
+// comas. Con varios formatos, outputFile es el nombre base de los archivos
+// (base.json, base.html...); sin él, el primer formato se imprime y el resto
+// se escribe en defaultOutputBase con su extensión. console siempre se imprime.
 func outputResult(cmd *cobra.Command, result types.ScanResult, format, outputFile string, reportSvc *reportService) error {
+	formats := config.SplitOutputFormats(format)
+	if len(formats) == 0 {
+		return outputFormat(cmd, result, "", outputFile, reportSvc)
+	}
+	if len(formats) == 1 {
+		return outputFormat(cmd, result, formats[0], outputFile, reportSvc)
+	}
+
+	for i, format := range formats {
+		file := outputFile
+		if file == "" && i > 0 {
+			file = defaultOutputBase
+		}
+		if err := outputFormat(cmd, result, format, file, reportSvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputFormat muestra el resultado en un formato, en outputFile si se indica
+func outputFormat(cmd *cobra.Command, result types.ScanResult, format, outputFile string, reportSvc *reportService) error {
 	var formatter types.ReportFormatter
 	var ext string
 
@@ -234,14 +879,24 @@ func outputResult(cmd *cobra.Command, result types.ScanResult, format, outputFil
 	case formatHTML:
 		formatter = reportSvc.htmlFormatter
 		ext = ".html"
+	case formatSARIF:
+		formatter = reportSvc.sarifFormatter
+		ext = ".sarif"
+	case formatInflux:
+		formatter = reportSvc.influxFormatter
+		ext = ".lp"
+	case formatMarkdown, formatMD:
+		formatter = reportSvc.markdownFormatter
+		ext = ".md"
+	case formatJSONL:
+		formatter = reportSvc.jsonlFormatter
+		ext = ".jsonl"
+	case formatCSV:
+		formatter = reportSvc.csvFormatter
+		ext = ".csv"
 	default:
 		// Formato console - mostrar resumen
-		return outputConsole(cmd, result)
-	}
-
-	output, err := formatter.Format(result)
-	if err != nil {
-		return err
+		return outputConsole(cmd, result, reportSvc)
 	}
 
 	if outputFile != "" {
@@ -250,48 +905,222 @@ func outputResult(cmd *cobra.Command, result types.ScanResult, format, outputFil
 			outputFile += ext
 		}
 
-		if err := os.WriteFile(outputFile, []byte(output), 0600); err != nil {
+		if err := writeOutputFile(outputFile, formatter, result); err != nil {
 			return fmt.Errorf("failed to write output file: %w", err)
 		}
 
 		cmd.Printf("Results written to %s\n", outputFile)
-	} else {
-		cmd.Println(output)
+		return nil
+	}
+
+	// Los formatters que lo soportan escriben directamente en la salida
+	if streamer, ok := formatter.(types.StreamFormatter); ok {
+		return streamer.FormatTo(cmd.OutOrStdout(), result)
 	}
 
+	output, err := formatter.Format(result)
+	if err != nil {
+		return err
+	}
+	cmd.Println(output)
 	return nil
 }
 
-func outputConsole(cmd *cobra.Command, result types.ScanResult) error {
+// writeOutputFile escribe el reporte en path, en streaming si el formatter lo
+// soporta para no construir el reporte completo en memoria
+func writeOutputFile(path string, formatter types.ReportFormatter, result types.ScanResult) error {
+	streamer, ok := formatter.(types.StreamFormatter)
+	if !ok {
+		output, err := formatter.Format(result)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(output), 0600)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := streamer.FormatTo(file, result); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// outputGitHub añade el resumen Markdown al archivo GITHUB_STEP_SUMMARY y
+// escribe una anotación ::warning por actualización en stdout. Fuera de
+// GitHub Actions (GITHUB_ACTIONS distinto de "true") no hace nada.
+func outputGitHub(cmd *cobra.Command, result types.ScanResult, logger *slog.Logger) error {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		logger.Warn("--github ignored: not running in GitHub Actions")
+		return nil
+	}
+
+	formatter := report.GitHubFormatter{Workspace: os.Getenv("GITHUB_WORKSPACE")}
+
+	// GitHub concatena lo que escriben los distintos pasos, así que se añade al final
+	if summaryFile := os.Getenv("GITHUB_STEP_SUMMARY"); summaryFile != "" {
+		summary, err := formatter.Format(result)
+		if err != nil {
+			return err
+		}
+		file, err := os.OpenFile(summaryFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, summary); err != nil {
+			_ = file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	for _, line := range formatter.Annotations(result) {
+		if _, err := fmt.Fprintln(cmd.OutOrStdout(), line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputConsoleUpdate imprime una línea por actualización disponible
+func outputConsoleUpdate(cmd *cobra.Command, update types.ImageUpdate) {
+	marker := ""
+	if update.Priority {
+		marker = "[PRIORITY] "
+	}
+	if update.UpdateType == types.UpdateTypeDigest {
+		cmd.Printf("  %s%s (pinned digest is stale for tag %s) [%s]\n",
+			marker,
+			update.ServiceName,
+			update.CurrentImage.Tag,
+			update.UpdateType)
+		return
+	}
+	var notes []string
+	if text := update.BehindText(); text != "" {
+		notes = append(notes, text)
+	}
+	if text := update.SizeDeltaText(); text != "" {
+		notes = append(notes, text)
+	}
+	details := ""
+	if len(notes) > 0 {
+		details = " " + strings.Join(notes, ", ")
+	}
+	cmd.Printf("  %s%s (%s -> %s) [%s]%s\n",
+		marker,
+		update.ServiceName,
+		update.CurrentImage.Tag,
+		update.LatestImage.Tag,
+		update.UpdateType,
+		details)
+	if update.RecommendedImage != nil && update.RecommendedImage.Tag != update.LatestImage.Tag {
+		cmd.Printf("    recommended: %s, latest: %s\n", update.RecommendedImage.Tag, update.LatestImage.Tag)
+	}
+	if text := update.LatestAliasText(); text != "" {
+		cmd.Printf("    %s\n", text)
+	}
+	if update.OriginalReference != "" {
+		cmd.Printf("    checked upstream %s, referenced as %s\n", update.CurrentImage.Repository, update.OriginalReference)
+	}
+}
+
+func outputConsole(cmd *cobra.Command, result types.ScanResult, reportSvc *reportService) error {
 	cmd.Printf("Scan Results for: %s\n", result.ProjectName)
 	cmd.Printf("Timestamp: %s\n", result.ScanTimestamp.Format("2006-01-02 15:04:05"))
 	cmd.Printf("Files scanned: %d\n", len(result.FilesScanned))
 	cmd.Printf("Total services found: %d\n", result.TotalServicesFound)
-	cmd.Printf("Services up to date: %d\n", len(result.UpToDateServices))
+	cmd.Printf("Summary: %s\n", result.Summary())
 
-	if len(result.UpdatesAvailable) > 0 {
+	if result.HasUpdates() {
 		cmd.Printf("\nAvailable Updates (%d):\n", len(result.UpdatesAvailable))
-		for _, update := range result.UpdatesAvailable {
-			cmd.Printf("  %s (%s -> %s) [%s]\n",
-				update.ServiceName,
-				update.CurrentImage.Tag,
-				update.LatestImage.Tag,
-				update.UpdateType)
+		for _, group := range result.GroupUpdates(reportSvc.groupBy) {
+			if group.Name != "" {
+				cmd.Printf("\n [%s: %s] (%d)\n", reportSvc.groupBy, group.Name, len(group.Updates))
+			}
+			for _, update := range group.Updates {
+				outputConsoleUpdate(cmd, update)
+			}
+		}
+	}
+
+	// La lista de servicios al día solo se muestra con -v; el resumen ya
+	// incluye el recuento
+	level := verbosity(cmd)
+	if level < verbosityUpToDate {
+		// Sin detalle
+	} else if result.UpToDateCollapsed(reportSvc.maxUpToDate) {
+		cmd.Printf("\n...and %d services up to date\n", len(result.UpToDateServices))
+	} else if len(result.UpToDateServices) > 0 {
+		cmd.Printf("\nUp to Date (%d):\n", len(result.UpToDateServices))
+		for _, service := range result.UpToDateServices {
+			cmd.Printf("  - %s\n", service)
 		}
 	}
 
-	if len(result.Errors) > 0 {
+	// Con -v, cuántos servicios e imágenes aportó cada archivo compose
+	if level >= verbosityUpToDate && len(result.FilesSummary) > 0 {
+		cmd.Printf("\nFiles (%d):\n", len(result.FilesSummary))
+		for _, file := range result.FilesSummary {
+			switch {
+			case file.Error != "":
+				cmd.Printf("  - %s: not parsed\n", file.File)
+			case file.Empty():
+				cmd.Printf("  - %s: %d services, no images\n", file.File, file.Services)
+			default:
+				cmd.Printf("  - %s: %d services, %d images\n", file.File, file.Services, file.Images)
+			}
+		}
+	}
+
+	if result.HasErrors() {
 		cmd.Printf("\nErrors (%d):\n", len(result.Errors))
 		for _, err := range result.Errors {
 			cmd.Printf("  - %s\n", err)
 		}
 	}
 
+	if len(result.Notes) > 0 {
+		cmd.Printf("\nNotes (%d):\n", len(result.Notes))
+		for _, note := range result.Notes {
+			cmd.Printf("  - %s\n", note)
+		}
+	}
+
+	if len(result.LocallyBuilt) > 0 {
+		cmd.Printf("\nLocally built, not checked (%d):\n", len(result.LocallyBuilt))
+		for _, service := range result.LocallyBuilt {
+			cmd.Printf("  - %s\n", service)
+		}
+	}
+
+	for _, limit := range result.RateLimits {
+		cmd.Printf("\n%s\n", limit)
+	}
+
+	// Con -vv, mostrar qué registro resolvió cada imagen y cuánto tardó
+	if level >= verbosityLookups && len(result.Lookups) > 0 {
+		cmd.Printf("\nRegistry Lookups (%d):\n", len(result.Lookups))
+		for _, lookup := range result.Lookups {
+			client := lookup.Client
+			if client == "" {
+				client = "no client"
+			}
+			cmd.Printf("  - %s %s via %s (%s) in %s\n",
+				lookup.Service, lookup.Image, lookup.Registry, client, lookup.Latency.Round(time.Millisecond))
+		}
+	}
+
 	return nil
 }
 
 // scanDockerDaemon executes a scan using Docker daemon to inspect running containers
-func scanDockerDaemon(ctx context.Context, dockerClient *docker.Client, cfg *types.Config, logger *slog.Logger) (types.ScanResult, error) {
+func scanDockerDaemon(ctx context.Context, dockerClient *docker.Client, scanSvc *scanner.Service, scanConfig scanner.Config, logger *slog.Logger) (types.ScanResult, error) {
 	images, err := dockerClient.ScanRunningContainers(ctx)
 	if err != nil {
 		return types.ScanResult{}, fmt.Errorf("scanning running containers: %w", err)
@@ -318,7 +1147,7 @@ func scanDockerDaemon(ctx context.Context, dockerClient *docker.Client, cfg *typ
 		}
 	}
 
-	result, err := createScanService(cfg).ScanImages(ctx, scannable, "docker-daemon")
+	result, err := scanSvc.ScanImagesWithConfig(ctx, scannable, "docker-daemon", scanConfig)
 	if err != nil {
 		return types.ScanResult{}, err
 	}
@@ -391,7 +1220,7 @@ func isLocalImage(image types.DockerImage) bool {
 
 // scanExtraImages parses an extra images YAML file, scans them, and merges into base result.
 // A missing file is silently skipped; a file that exists but is invalid produces an error entry.
-func scanExtraImages(ctx context.Context, filePath string, cfg *types.Config, base types.ScanResult, logger *slog.Logger) types.ScanResult {
+func scanExtraImages(ctx context.Context, filePath string, scanSvc *scanner.Service, scanConfig scanner.Config, base types.ScanResult, logger *slog.Logger) types.ScanResult {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		logger.Debug("Extra images file not found, skipping", "file", filePath)
 		return base
@@ -408,7 +1237,7 @@ func scanExtraImages(ctx context.Context, filePath string, cfg *types.Config, ba
 	}
 
 	logger.Info("Scanning extra images", "file", filePath, "count", len(imgs))
-	extraResult, err := createScanService(cfg).ScanImages(ctx, imgs, "extra-images")
+	extraResult, err := scanSvc.ScanImagesWithConfig(ctx, imgs, "extra-images", scanConfig)
 	if err != nil {
 		logger.Error("Extra images scan failed", "error", err)
 		base.Errors = append(base.Errors, fmt.Sprintf("extra-images scan: %v", err))
@@ -418,12 +1247,23 @@ func scanExtraImages(ctx context.Context, filePath string, cfg *types.Config, ba
 	base.UpdatesAvailable = append(base.UpdatesAvailable, extraResult.UpdatesAvailable...)
 	base.UpToDateServices = append(base.UpToDateServices, extraResult.UpToDateServices...)
 	base.Errors = append(base.Errors, extraResult.Errors...)
+	base.Lookups = append(base.Lookups, extraResult.Lookups...)
+	base.LocallyBuilt = append(base.LocallyBuilt, extraResult.LocallyBuilt...)
+	base.RateLimits = extraResult.RateLimits // valores más recientes
 	base.TotalServicesFound += extraResult.TotalServicesFound
+	base.PrioritizeUpdates()
 	return base
 }
 
 // reportService es un helper para manejar los formateadores
 type reportService struct {
-	jsonFormatter *report.JSONFormatter
-	htmlFormatter *report.HTMLFormatter
+	jsonFormatter     *report.JSONFormatter
+	htmlFormatter     *report.HTMLFormatter
+	sarifFormatter    *report.SARIFFormatter
+	influxFormatter   *report.InfluxFormatter
+	markdownFormatter *report.MarkdownFormatter
+	jsonlFormatter    *report.JSONLinesFormatter
+	csvFormatter      *report.CSVFormatter
+	maxUpToDate       int
+	groupBy           types.GroupBy
 }
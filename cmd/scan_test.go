@@ -0,0 +1,870 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/user/docker-image-reporter/internal/cache"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// newFailingRegistry crea un registro falso que responde a org/ok y rechaza org/broken
+func newFailingRegistry(t *testing.T) string {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/org/ok/tags/list":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"org/ok","tags":["1.0.0"]}`))
+		default:
+			http.Error(w, `{"errors":[{"code":"DENIED","message":"denied"}]}`, http.StatusForbidden)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestRunScan_FailOnErrors(t *testing.T) {
+	registryHost := newFailingRegistry(t)
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	compose := "services:\n" +
+		"  ok:\n    image: " + registryHost + "/org/ok:1.0.0\n" +
+		"  broken:\n    image: " + registryHost + "/org/broken:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		flags    []string
+		wantCode int // 0 = sin error
+	}{
+		{name: "no flags", flags: nil},
+		{name: "fail-on-updates alone ignores errors", flags: []string{"--fail-on-updates"}},
+		{name: "fail-on-errors", flags: []string{"--fail-on-errors"}, wantCode: ExitCodeScanErrors},
+		{name: "both flags", flags: []string{"--fail-on-updates", "--fail-on-errors"}, wantCode: ExitCodeScanErrors},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewRootCmd()
+			out := &bytes.Buffer{}
+			root.SetOut(out)
+			root.SetErr(out)
+			root.SetArgs(append([]string{"scan", dir}, tt.flags...))
+
+			err := root.Execute()
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("Execute() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) {
+				t.Fatalf("Execute() error = %v, want *ExitError", err)
+			}
+			if exitErr.Code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", exitErr.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+// newUpdatesRegistry crea un registro falso en el que todos los repositorios
+// tienen disponible la versión 2.0.0
+func newUpdatesRegistry(t *testing.T) string {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"tags":["1.0.0","2.0.0"]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestRunScan_DryRunRoutesNotifications(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configYAML := "telegram:\n" +
+		"  enabled: true\n  bot_token: \"token\"\n  chat_id: \"default\"\n" +
+		"  channels:\n    team-a: \"chat-a\"\n    team-b: \"chat-b\"\n"
+	if err := os.MkdirAll(filepath.Join(home, ".icr"), 0700); err != nil {
+		t.Fatalf("creating config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".icr", "config.yml"), []byte(configYAML), 0600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+
+	dir := t.TempDir()
+	compose := "services:\n" +
+		"  api:\n    image: " + registryHost + "/org/api:1.0.0\n" +
+		"    labels:\n      image-reporter.notify-channel: telegram:team-a\n" +
+		"  worker:\n    image: " + registryHost + "/org/worker:1.0.0\n" +
+		"    labels:\n      image-reporter.notify-channel: telegram:team-b\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	root := NewRootCmd()
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(out)
+	root.SetArgs([]string{"scan", dir, "--notify", "--dry-run"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Cada servicio se notifica solo a su canal y el destino por defecto no recibe nada
+	output := out.String()
+	for _, want := range []string{
+		"--- dry-run notification to telegram:team-a ---",
+		"--- dry-run notification to telegram:team-b ---",
+	} {
+		if strings.Count(output, want) != 1 {
+			t.Errorf("expected exactly one %q in output:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "dry-run notification to telegram ---") {
+		t.Errorf("default client should not be notified:\n%s", output)
+	}
+}
+
+func TestRunScan_NotifyThreshold(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configYAML := "telegram:\n  enabled: true\n  bot_token: \"token\"\n  chat_id: \"default\"\n"
+	if err := os.MkdirAll(filepath.Join(home, ".icr"), 0700); err != nil {
+		t.Fatalf("creating config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".icr", "config.yml"), []byte(configYAML), 0600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+
+	// Un único servicio con una actualización major (1.0.0 -> 2.0.0)
+	dir := t.TempDir()
+	compose := "services:\n  api:\n    image: " + registryHost + "/org/api:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		threshold  string
+		wantNotify bool
+	}{
+		{name: "no threshold", threshold: "", wantNotify: true},
+		{name: "major met", threshold: "major>=1", wantNotify: true},
+		{name: "total not met", threshold: "total>=5", wantNotify: false},
+		{name: "any condition met", threshold: "total>=5,minor>=1", wantNotify: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewRootCmd()
+			out := &bytes.Buffer{}
+			root.SetOut(out)
+			root.SetErr(out)
+			root.SetArgs([]string{"scan", dir, "--notify", "--dry-run", "--notify-threshold", tt.threshold})
+			if err := root.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			output := out.String()
+			if got := strings.Contains(output, "dry-run notification to telegram"); got != tt.wantNotify {
+				t.Errorf("notified = %v, want %v:\n%s", got, tt.wantNotify, output)
+			}
+			// La salida normal del escaneo se produce igualmente
+			if !strings.Contains(output, "2.0.0") {
+				t.Errorf("expected the scan output to list the update:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestRunScan_NotifyThresholdInvalid(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"scan", t.TempDir(), "--notify-threshold", "critical>=1"})
+	if err := root.Execute(); err == nil {
+		t.Fatal("expected an error for an unknown threshold metric")
+	}
+}
+
+func TestRunScan_NotifyNothingActionable(t *testing.T) {
+	registryHost := newFailingRegistry(t)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configYAML := "telegram:\n  enabled: true\n  bot_token: \"token\"\n  chat_id: \"default\"\n"
+	if err := os.MkdirAll(filepath.Join(home, ".icr"), 0700); err != nil {
+		t.Fatalf("creating config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".icr", "config.yml"), []byte(configYAML), 0600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+
+	// org/ok ya está en su última versión: no hay actualizaciones ni errores
+	dir := t.TempDir()
+	compose := "services:\n  ok:\n    image: " + registryHost + "/org/ok:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	root := NewRootCmd()
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(out)
+	root.SetArgs([]string{"scan", dir, "--notify", "--dry-run"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Ni el mensaje ni el informe HTML se envían
+	if strings.Contains(out.String(), "--- dry-run") {
+		t.Errorf("expected no notification for a clean scan:\n%s", out.String())
+	}
+}
+
+func TestRunScan_ExecNotifier(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	received := filepath.Join(t.TempDir(), "received.txt")
+	configYAML := "exec:\n  command: [\"sh\", \"-c\", \"cat >> \\\"$1\\\"\", \"sh\", \"" + received + "\"]\n"
+	if err := os.MkdirAll(filepath.Join(home, ".icr"), 0700); err != nil {
+		t.Fatalf("creating config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".icr", "config.yml"), []byte(configYAML), 0600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+
+	dir := t.TempDir()
+	compose := "services:\n  api:\n    image: " + registryHost + "/org/api:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"scan", dir, "--notify"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// El comando recibe el mensaje y después el informe HTML por stdin
+	data, err := os.ReadFile(received)
+	if err != nil {
+		t.Fatalf("reading command input: %v", err)
+	}
+	if !strings.Contains(string(data), "2.0.0") || !strings.Contains(string(data), "<html") {
+		t.Errorf("expected the message and the HTML report, got:\n%s", data)
+	}
+}
+
+func TestRunScan_Baseline(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	composeFile := filepath.Join(dir, "docker-compose.yml")
+	baselineFile := filepath.Join(t.TempDir(), "baseline.json")
+	outputFile := filepath.Join(t.TempDir(), "delta.json")
+
+	run := func(compose string, args ...string) {
+		t.Helper()
+		if err := os.WriteFile(composeFile, []byte(compose), 0600); err != nil {
+			t.Fatalf("writing compose file: %v", err)
+		}
+		root := NewRootCmd()
+		root.SetOut(&bytes.Buffer{})
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(append([]string{"scan", dir}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+	}
+
+	api := "  api:\n    image: " + registryHost + "/org/api:1.0.0\n"
+	worker := "  worker:\n    image: " + registryHost + "/org/worker:1.0.0\n"
+
+	// Primera ejecución: sin baseline previo se guarda el resultado completo
+	run("services:\n"+api, "--baseline", baselineFile, "--write-baseline", baselineFile, "--output", "json", "--output-file", outputFile)
+	if services := updatedServices(t, outputFile); strings.Join(services, ",") != "api" {
+		t.Fatalf("first run reported %v, want [api]", services)
+	}
+
+	// Segunda ejecución: solo se informa de la actualización nueva
+	run("services:\n"+api+worker, "--baseline", baselineFile, "--write-baseline", baselineFile, "--output", "json", "--output-file", outputFile)
+	if services := updatedServices(t, outputFile); strings.Join(services, ",") != "worker" {
+		t.Errorf("second run reported %v, want [worker]", services)
+	}
+
+	// El baseline escrito contiene el resultado completo
+	if services := updatedServices(t, baselineFile); len(services) != 2 {
+		t.Errorf("baseline contains %v, want both services", services)
+	}
+}
+
+// updatedServices devuelve los servicios con actualización de un resultado JSON
+func updatedServices(t *testing.T, path string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	var result types.ScanResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("parsing %s: %v", path, err)
+	}
+
+	services := make([]string, 0, len(result.UpdatesAvailable))
+	for _, update := range result.UpdatesAvailable {
+		services = append(services, update.ServiceName)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// lookupTiming reconoce la latencia al final de cada línea de consulta
+var lookupTiming = regexp.MustCompile(` in [0-9.]+(ns|µs|ms|s)\n`)
+
+func TestRunScan_Verbosity(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	compose := "services:\n" +
+		"  api:\n    image: " + registryHost + "/org/api:1.0.0\n" +
+		"  worker:\n    image: " + registryHost + "/org/worker:2.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	// Logger por defecto como el de main, con el nivel controlado por LogLevel
+	logs := &bytes.Buffer{}
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: LogLevel})))
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		LogLevel.Set(slog.LevelInfo)
+	})
+
+	tests := []struct {
+		name         string
+		flags        []string
+		wantUpToDate bool
+		wantLookups  bool
+		wantDebug    bool
+	}{
+		{name: "default", flags: nil},
+		{name: "-v", flags: []string{"-v"}, wantUpToDate: true},
+		{name: "-vv", flags: []string{"-vv"}, wantUpToDate: true, wantLookups: true},
+		{name: "-vvv", flags: []string{"-vvv"}, wantUpToDate: true, wantLookups: true, wantDebug: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			root := NewRootCmd()
+			out := &bytes.Buffer{}
+			root.SetOut(out)
+			root.SetErr(out)
+			root.SetArgs(append([]string{"scan", dir}, tt.flags...))
+			if err := root.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			output := out.String()
+			if got := strings.Contains(output, "Up to Date (1)"); got != tt.wantUpToDate {
+				t.Errorf("up-to-date list shown = %v, want %v:\n%s", got, tt.wantUpToDate, output)
+			}
+			// Con -vv cada consulta muestra su latencia
+			if got := strings.Contains(output, "Registry Lookups (2)") && lookupTiming.MatchString(output); got != tt.wantLookups {
+				t.Errorf("lookup timings shown = %v, want %v:\n%s", got, tt.wantLookups, output)
+			}
+			// Solo -vvv deja pasar los logs de depuración
+			if got := strings.Contains(logs.String(), "level=DEBUG"); got != tt.wantDebug {
+				t.Errorf("debug logs shown = %v, want %v:\n%s", got, tt.wantDebug, logs.String())
+			}
+		})
+	}
+}
+
+func TestRunScan_GitHub(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	compose := "services:\n  api:\n    image: " + registryHost + "/org/api:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	summaryFile := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", summaryFile)
+	t.Setenv("GITHUB_WORKSPACE", dir)
+
+	root := NewRootCmd()
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"scan", dir, "--github"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	summary, err := os.ReadFile(summaryFile)
+	if err != nil {
+		t.Fatalf("reading summary file: %v", err)
+	}
+	wantRow := "| api | `" + registryHost + "/org/api:1.0.0` | `" + registryHost + "/org/api:2.0.0` | major | docker-compose.yml |"
+	if !strings.Contains(string(summary), wantRow) {
+		t.Errorf("summary file missing %q:\n%s", wantRow, summary)
+	}
+
+	wantWarning := "::warning file=docker-compose.yml::api update available"
+	if !strings.Contains(out.String(), wantWarning) {
+		t.Errorf("output missing %q:\n%s", wantWarning, out.String())
+	}
+}
+
+func TestRunScan_DefaultFormat(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".icr"), 0700); err != nil {
+		t.Fatalf("creating config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".icr", "config.yml"), []byte("report:\n  default_format: json\n"), 0600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+
+	dir := t.TempDir()
+	compose := "services:\n  api:\n    image: " + registryHost + "/org/api:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		root := NewRootCmd()
+		out := &bytes.Buffer{}
+		root.SetOut(out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(append([]string{"scan", dir}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return out.String()
+	}
+
+	// Sin --output se usa el formato de la configuración
+	var result types.ScanResult
+	if output := run(); json.Unmarshal([]byte(output), &result) != nil || len(result.UpdatesAvailable) != 1 {
+		t.Errorf("expected a JSON result with one update, got:\n%s", output)
+	}
+
+	// --output explícito tiene prioridad
+	if output := run("--output", "console"); json.Valid([]byte(output)) || !strings.Contains(output, "Summary:") {
+		t.Errorf("expected console output with --output console, got:\n%s", output)
+	}
+}
+
+func TestRunScan_MultipleOutputFormats(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	compose := "services:\n  api:\n    image: " + registryHost + "/org/api:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		root := NewRootCmd()
+		out := &bytes.Buffer{}
+		root.SetOut(out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(append([]string{"scan", dir, "--output", "json,html,md,csv"}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return out.String()
+	}
+
+	// checkFiles verifica el contenido de base.json, base.html, base.md y base.csv
+	checkFiles := func(base string) {
+		t.Helper()
+		var result types.ScanResult
+		data, err := os.ReadFile(base + ".json")
+		if err != nil || json.Unmarshal(data, &result) != nil || len(result.UpdatesAvailable) != 1 {
+			t.Errorf("expected %s.json with one update, got %q (error %v)", base, data, err)
+		}
+		if data, err := os.ReadFile(base + ".html"); err != nil || !strings.Contains(string(data), "<html") || !strings.Contains(string(data), "2.0.0") {
+			t.Errorf("expected an HTML report in %s.html (error %v)", base, err)
+		}
+		if data, err := os.ReadFile(base + ".md"); err != nil || !strings.Contains(string(data), "| Service | Current | Latest | Type |\n") || !strings.Contains(string(data), "| api |") {
+			t.Errorf("expected a Markdown table in %s.md, got %q (error %v)", base, data, err)
+		}
+		if data, err := os.ReadFile(base + ".csv"); err != nil || !strings.Contains(string(data), "\napi,"+registryHost+",org/api,1.0.0,2.0.0,major,") {
+			t.Errorf("expected a CSV row for api in %s.csv, got %q (error %v)", base, data, err)
+		}
+	}
+
+	// Con --output-file, cada formato va a base.<ext>
+	base := filepath.Join(t.TempDir(), "report")
+	run("--output-file", base)
+	checkFiles(base)
+
+	// Sin --output-file, el primer formato se imprime y el resto va a archivos por defecto
+	work := t.TempDir()
+	t.Chdir(work)
+	var result types.ScanResult
+	output := run()
+	if !strings.HasPrefix(strings.TrimSpace(output), "{") {
+		t.Fatalf("expected the JSON result on stdout, got:\n%s", output)
+	}
+	if err := json.NewDecoder(strings.NewReader(output)).Decode(&result); err != nil || len(result.UpdatesAvailable) != 1 {
+		t.Errorf("expected a JSON result with one update on stdout (error %v)", err)
+	}
+	if _, err := os.Stat(filepath.Join(work, defaultOutputBase+".json")); err == nil {
+		t.Error("the printed format should not also be written to a file")
+	}
+	for _, ext := range []string{".html", ".md", ".csv"} {
+		if _, err := os.Stat(filepath.Join(work, defaultOutputBase+ext)); err != nil {
+			t.Errorf("expected %s: %v", defaultOutputBase+ext, err)
+		}
+	}
+}
+
+func TestRunScan_InvalidOutputFormat(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"scan", t.TempDir(), "--output", "json,yaml"})
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), `"yaml"`) {
+		t.Fatalf("Execute() error = %v, want an invalid output format error", err)
+	}
+}
+
+func TestParseMaxConcurrency(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "1", want: 1},
+		{value: "25", want: 25},
+		{value: "auto", want: 0},
+		{value: "AUTO", want: 0},
+		{value: "0", wantErr: true},
+		{value: "-3", wantErr: true},
+		{value: "many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseMaxConcurrency(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMaxConcurrency(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseMaxConcurrency(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunScan_CheckBuiltImages(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	compose := "services:\n" +
+		"  api:\n    build: .\n    image: " + registryHost + "/org/api:1.0.0\n" +
+		"  web:\n    image: " + registryHost + "/org/web:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	run := func(args ...string) types.ScanResult {
+		t.Helper()
+		root := NewRootCmd()
+		out := &bytes.Buffer{}
+		root.SetOut(out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(append([]string{"scan", dir, "--output", "json"}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		var result types.ScanResult
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("parsing JSON output: %v\n%s", err, out.String())
+		}
+		return result
+	}
+
+	// Por defecto, la imagen de un servicio con build: es una construcción local
+	result := run()
+	if len(result.UpdatesAvailable) != 1 || result.UpdatesAvailable[0].ServiceName != "web" {
+		t.Errorf("expected only the web update, got %+v", result.UpdatesAvailable)
+	}
+	if len(result.LocallyBuilt) != 1 || result.LocallyBuilt[0] != "api" {
+		t.Errorf("LocallyBuilt = %v, want [api]", result.LocallyBuilt)
+	}
+
+	// Con --check-built-images se comprueba también en el registro
+	result = run("--check-built-images")
+	if len(result.UpdatesAvailable) != 2 || len(result.LocallyBuilt) != 0 {
+		t.Errorf("expected both updates and no locally built services, got %+v / %v", result.UpdatesAvailable, result.LocallyBuilt)
+	}
+}
+
+func TestRunScan_CacheFile(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	compose := "services:\n  web:\n    image: " + registryHost + "/org/web:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	// Sin --cache-file no se guarda nada en disco
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"scan", dir, "--output", "json"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	defaultPath, _ := defaultCacheFile()
+	if _, err := os.Stat(defaultPath); err == nil {
+		t.Errorf("default cache file %s should not be written without --cache-file", defaultPath)
+	}
+
+	cacheFile := filepath.Join(t.TempDir(), "ci-cache", "registry.json")
+	root = NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"scan", dir, "--output", "json", "--cache-file", cacheFile})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// La caché se guarda en --cache-file y no en el archivo por defecto
+	registryCache := cache.NewRegistryCache(cache.DefaultConfig())
+	defer registryCache.Close()
+	if err := registryCache.Load(cacheFile); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, found := registryCache.GetTags(types.DockerImage{Registry: registryHost, Repository: "org/web", Tag: "1.0.0"}); !found {
+		t.Errorf("expected the web tags in %s, got %+v", cacheFile, registryCache.Entries())
+	}
+	if _, err := os.Stat(defaultPath); err == nil {
+		t.Errorf("default cache file %s should not be written", defaultPath)
+	}
+
+	// Un --cache-file que no es una caché se ignora sin sobrescribirlo
+	composeFile := filepath.Join(dir, "docker-compose.yml")
+	root = NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"scan", dir, "--output", "json", "--cache-file", composeFile})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if data, _ := os.ReadFile(composeFile); string(data) != compose {
+		t.Errorf("compose file overwritten by the cache:\n%s", data)
+	}
+}
+
+func TestRunScan_HideAcked(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := t.TempDir()
+	compose := "services:\n" +
+		"  api:\n    image: " + registryHost + "/org/api:1.0.0\n" +
+		"  web:\n    image: " + registryHost + "/org/web:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	// web aplazado en la versión propuesta; api aplazado en una versión
+	// anterior, que el tag 2.0.0 ya supera
+	runAckCommand(t, "web", "2.0.0")
+	runAckCommand(t, "api", "1.5.0")
+
+	run := func(args ...string) types.ScanResult {
+		t.Helper()
+		root := NewRootCmd()
+		out := &bytes.Buffer{}
+		root.SetOut(out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(append([]string{"scan", dir, "--output", "json"}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		var result types.ScanResult
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("parsing JSON output: %v\n%s", err, out.String())
+		}
+		return result
+	}
+
+	// Sin --hide-acked se informan las dos
+	if result := run(); len(result.UpdatesAvailable) != 2 {
+		t.Errorf("expected both updates without --hide-acked, got %+v", result.UpdatesAvailable)
+	}
+
+	result := run("--hide-acked")
+	if len(result.UpdatesAvailable) != 1 || result.UpdatesAvailable[0].ServiceName != "api" {
+		t.Errorf("expected only the api update with a newer target, got %+v", result.UpdatesAvailable)
+	}
+}
+
+func TestRunScan_ProjectName(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configYAML := "telegram:\n  enabled: true\n  bot_token: \"token\"\n  chat_id: \"default\"\n"
+	if err := os.MkdirAll(filepath.Join(home, ".icr"), 0700); err != nil {
+		t.Fatalf("creating config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".icr", "config.yml"), []byte(configYAML), 0600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+
+	// El compose declara un proyecto, pero --project-name tiene prioridad
+	dir := t.TempDir()
+	compose := "x-image-reporter:\n  project: from-compose\n" +
+		"services:\n  api:\n    image: " + registryHost + "/org/api:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		root := NewRootCmd()
+		out := &bytes.Buffer{}
+		root.SetOut(out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(append([]string{"scan", dir, "--project-name", "Home <Lab>"}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return out.String()
+	}
+
+	// Título del informe HTML
+	if output := run("--output", "html"); !strings.Contains(output, "<title>Docker Image Scan Report - Home &lt;Lab&gt;</title>") {
+		t.Errorf("HTML title does not use the project name:\n%s", output)
+	}
+
+	// Caption del informe adjunto a la notificación de Telegram
+	output := run("--notify", "--dry-run")
+	if !strings.Contains(output, "--- dry-run file docker-updates-report.html to telegram ---\n🐳 <b>Docker Image Updates Report</b> — Home &lt;Lab&gt;") {
+		t.Errorf("report caption does not use the project name:\n%s", output)
+	}
+	if strings.Contains(output, "from-compose") {
+		t.Errorf("--project-name should override the compose project:\n%s", output)
+	}
+}
+
+func TestRunScan_Archive(t *testing.T) {
+	registryHost := newUpdatesRegistry(t)
+	t.Setenv("HOME", t.TempDir())
+
+	// Archivo tar.gz con un compose en un subdirectorio
+	compose := "services:\n  api:\n    image: " + registryHost + "/org/api:1.0.0\n"
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "stack/docker-compose.yml", Mode: 0600, Size: int64(len(compose)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("writing tar header: %v", err)
+	}
+	if _, err := tw.Write([]byte(compose)); err != nil {
+		t.Fatalf("writing tar entry: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("closing gzip: %v", err)
+	}
+	archive := filepath.Join(t.TempDir(), "config.tar.gz")
+	if err := os.WriteFile(archive, buf.Bytes(), 0600); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+
+	root := NewRootCmd()
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"scan", "--archive", archive, "--output", "json"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var result types.ScanResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("parsing JSON output: %v\n%s", err, out.String())
+	}
+	if result.ProjectName != "config.tar.gz" {
+		t.Errorf("ProjectName = %q, want config.tar.gz", result.ProjectName)
+	}
+	if len(result.UpdatesAvailable) != 1 || result.UpdatesAvailable[0].ServiceName != "api" {
+		t.Errorf("expected the api update from the archive, got %+v", result.UpdatesAvailable)
+	}
+
+	// El directorio temporal se elimina al terminar
+	if len(result.FilesScanned) != 1 {
+		t.Fatalf("FilesScanned = %v, want one file", result.FilesScanned)
+	}
+	if _, err := os.Stat(result.FilesScanned[0]); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed after the scan, got %v", result.FilesScanned[0], err)
+	}
+}
@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/user/docker-image-reporter/internal/config"
 	"github.com/user/docker-image-reporter/internal/notifier"
 	"github.com/user/docker-image-reporter/internal/registry"
 	"github.com/user/docker-image-reporter/pkg/types"
@@ -21,11 +21,15 @@ func newTestCmd() *cobra.Command {
 		Use:   "test",
 		Short: "Test connectivity to services",
 		Long: `Test connectivity to configured services including Telegram bot,
-Docker registries, and other external services.`,
+Slack and Discord webhooks, email, Docker registries, and other external services.`,
 		RunE: runTest,
 	}
 
 	cmd.Flags().Bool("telegram", false, "Test Telegram bot connectivity")
+	cmd.Flags().Bool("slack", false, "Send a test message to the Slack webhook")
+	cmd.Flags().Bool("discord", false, "Send a test message to the Discord webhook")
+	cmd.Flags().Bool("email", false, "Send a test email to the configured recipients")
+	cmd.Flags().Bool("notifiers", false, "Send a test notification through every configured notifier")
 	cmd.Flags().Bool("registries", false, "Test registry connectivity")
 	cmd.Flags().Bool("all", false, "Test all services")
 
@@ -35,21 +39,46 @@ Docker registries, and other external services.`,
 func runTest(cmd *cobra.Command, args []string) error {
 	logger := slog.Default()
 
-	configPath, _ := cmd.Flags().GetString("config")
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
 	telegram, _ := cmd.Flags().GetBool("telegram")
+	slack, _ := cmd.Flags().GetBool("slack")
+	discord, _ := cmd.Flags().GetBool("discord")
+	email, _ := cmd.Flags().GetBool("email")
+	notifiers, _ := cmd.Flags().GetBool("notifiers")
 	registries, _ := cmd.Flags().GetBool("registries")
 	all, _ := cmd.Flags().GetBool("all")
 
-	if all || telegram {
+	// --notifiers ya incluye Telegram, Slack, Discord y el correo: no enviar dos mensajes de prueba
+	if telegram && !all && !notifiers {
 		if err := testTelegram(cmd, cfg); err != nil {
 			logger.Error("Telegram test failed", "error", err)
 		}
 	}
+	if slack && !all && !notifiers {
+		if err := testSlack(cmd, cfg); err != nil {
+			logger.Error("Slack test failed", "error", err)
+		}
+	}
+	if discord && !all && !notifiers {
+		if err := testDiscord(cmd, cfg); err != nil {
+			logger.Error("Discord test failed", "error", err)
+		}
+	}
+	if email && !all && !notifiers {
+		if err := testEmail(cmd, cfg); err != nil {
+			logger.Error("Email test failed", "error", err)
+		}
+	}
+
+	if all || notifiers {
+		if err := testNotifiers(cmd, createNotificationService(cfg, nil)); err != nil {
+			logger.Error("Notifier test failed", "error", err)
+		}
+	}
 
 	if all || registries {
 		if err := testRegistries(cmd, cfg); err != nil {
@@ -57,10 +86,14 @@ func runTest(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if !telegram && !registries && !all {
-		cmd.Println("Use --telegram, --registries, or --all flags to specify what to test")
+	if !telegram && !slack && !discord && !email && !notifiers && !registries && !all {
+		cmd.Println("Use --telegram, --slack, --discord, --email, --notifiers, --registries, or --all flags to specify what to test")
 		cmd.Println("\nAvailable test options:")
 		cmd.Println("  --telegram    Test Telegram bot connectivity")
+		cmd.Println("  --slack       Send a test message to the Slack webhook")
+		cmd.Println("  --discord     Send a test message to the Discord webhook")
+		cmd.Println("  --email       Send a test email to the configured recipients")
+		cmd.Println("  --notifiers   Send a test notification through every configured notifier")
 		cmd.Println("  --registries  Test registry connectivity")
 		cmd.Println("  --all         Test all services")
 	}
@@ -90,15 +123,10 @@ func testTelegram(cmd *cobra.Command, cfg *types.Config) error {
 	client := notifier.NewTelegramClient(cfg.Telegram.BotToken, cfg.Telegram.ChatID)
 
 	// Crear un contexto con timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(commandContext(cmd), 10*time.Second)
 	defer cancel()
 
-	// Intentar enviar un mensaje de prueba
-	testMessage := fmt.Sprintf("🧪 *Docker Image Reporter Test*\n\nTest message sent at %s\n\n✅ Bot connectivity successful!",
-		time.Now().Format("2006-01-02 15:04:05"))
-
-	err := client.SendNotification(ctx, testMessage)
-	if err != nil {
+	if err := client.SendNotification(ctx, testNotificationMessage()); err != nil {
 		cmd.Printf("❌ Telegram test failed: %v\n", err)
 		cmd.Println("💡 Make sure your bot token and chat ID are correct")
 		cmd.Println("💡 You can get a bot token from @BotFather on Telegram")
@@ -110,12 +138,142 @@ func testTelegram(cmd *cobra.Command, cfg *types.Config) error {
 	return nil
 }
 
+func testSlack(cmd *cobra.Command, cfg *types.Config) error {
+	cmd.Println("🔄 Testing Slack connectivity...")
+
+	if !cfg.Slack.Enabled {
+		cmd.Println("⚠️  Slack is disabled in configuration")
+		return nil
+	}
+
+	if cfg.Slack.WebhookURL == "" {
+		cmd.Println("❌ Slack webhook URL is not configured")
+		return fmt.Errorf("slack webhook URL is required")
+	}
+
+	client := notifier.NewSlackClient(cfg.Slack.WebhookURL, cfg.Slack.Channel)
+
+	ctx, cancel := context.WithTimeout(commandContext(cmd), 10*time.Second)
+	defer cancel()
+
+	if err := client.SendNotification(ctx, testNotificationMessage()); err != nil {
+		cmd.Printf("❌ Slack test failed: %v\n", err)
+		cmd.Println("💡 Make sure the incoming webhook URL is correct and still active")
+		return err
+	}
+
+	cmd.Println("✅ Slack webhook connectivity successful")
+	cmd.Println("📨 Test message sent to the webhook's channel")
+	return nil
+}
+
+func testDiscord(cmd *cobra.Command, cfg *types.Config) error {
+	cmd.Println("🔄 Testing Discord connectivity...")
+
+	if !cfg.Discord.Enabled {
+		cmd.Println("⚠️  Discord is disabled in configuration")
+		return nil
+	}
+
+	if cfg.Discord.WebhookURL == "" {
+		cmd.Println("❌ Discord webhook URL is not configured")
+		return fmt.Errorf("discord webhook URL is required")
+	}
+
+	client := notifier.NewDiscordClient(cfg.Discord.WebhookURL)
+
+	ctx, cancel := context.WithTimeout(commandContext(cmd), 10*time.Second)
+	defer cancel()
+
+	if err := client.SendNotification(ctx, testNotificationMessage()); err != nil {
+		cmd.Printf("❌ Discord test failed: %v\n", err)
+		cmd.Println("💡 Make sure the webhook URL is correct and the webhook was not deleted")
+		return err
+	}
+
+	cmd.Println("✅ Discord webhook connectivity successful")
+	cmd.Println("📨 Test message sent to the webhook's channel")
+	return nil
+}
+
+func testEmail(cmd *cobra.Command, cfg *types.Config) error {
+	cmd.Println("🔄 Testing email delivery...")
+
+	if !cfg.Email.Enabled {
+		cmd.Println("⚠️  Email is disabled in configuration")
+		return nil
+	}
+
+	if cfg.Email.Host == "" {
+		cmd.Println("❌ SMTP host is not configured")
+		return fmt.Errorf("email host is required")
+	}
+
+	if len(cfg.Email.To) == 0 {
+		cmd.Println("❌ No email recipients are configured")
+		return fmt.Errorf("at least one email recipient is required")
+	}
+
+	client := notifier.NewEmailClient(cfg.Email)
+
+	ctx, cancel := context.WithTimeout(commandContext(cmd), 30*time.Second)
+	defer cancel()
+
+	if err := client.SendNotification(ctx, testNotificationMessage()); err != nil {
+		cmd.Printf("❌ Email test failed: %v\n", err)
+		cmd.Println("💡 Check the SMTP host, port and credentials, and that the server accepts the sender address")
+		return err
+	}
+
+	cmd.Printf("✅ Test email sent to %s\n", strings.Join(cfg.Email.To, ", "))
+	return nil
+}
+
+// testNotificationMessage es el mensaje que se envía al probar los notificadores
+func testNotificationMessage() string {
+	return fmt.Sprintf("🧪 *Docker Image Reporter Test*\n\nTest message sent at %s\n\n✅ Notifier connectivity successful!",
+		time.Now().Format("2006-01-02 15:04:05"))
+}
+
+// testNotifiers envía un mensaje de prueba por cada cliente configurado y
+// muestra el resultado de cada uno. Devuelve un error si alguno falló
+func testNotifiers(cmd *cobra.Command, notifySvc *notifier.NotificationService) error {
+	cmd.Println("🔄 Testing notifiers...")
+
+	if !notifySvc.HasClients() {
+		cmd.Println("⚠️  No notifiers are configured")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(commandContext(cmd), 10*time.Second)
+	defer cancel()
+
+	var failed []string
+	for _, result := range notifySvc.TestClients(ctx, testNotificationMessage()) {
+		if result.Err != nil {
+			cmd.Printf("❌ %s: %v\n", result.Name, result.Err)
+			failed = append(failed, result.Name)
+			continue
+		}
+		cmd.Printf("✅ %s: test notification sent\n", result.Name)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("test notification failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
 func testRegistries(cmd *cobra.Command, cfg *types.Config) error {
 	cmd.Println("🔄 Testing registry connectivity...")
 
-	client := registry.NewGenericRegistryClient(time.Duration(cfg.Registry.Timeout)*time.Second, cfg.Registry.GHCRToken)
+	client := registry.NewGenericRegistryClient(time.Duration(cfg.Registry.Timeout)*time.Second, cfg.Registry.GHCRToken).
+		WithDockerHubCredentials(cfg.Registry.DockerHub.Username, cfg.Registry.DockerHub.Secret()).
+		WithDockerHubMirror(cfg.Registry.DockerHub.Mirror).
+		WithEnvCredentials(cfg.Registry.AuthFromEnv).
+		WithHostTimeouts(cfg.Registry.HostTimeouts())
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(commandContext(cmd), 30*time.Second)
 	defer cancel()
 
 	testImage := types.DockerImage{
@@ -2,10 +2,17 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
 
+	"github.com/user/docker-image-reporter/internal/notifier"
 	"github.com/user/docker-image-reporter/pkg/types"
 )
 
@@ -50,8 +57,12 @@ func TestRunTest_NoFlags(t *testing.T) {
 
 	output := buf.String()
 	expectedParts := []string{
-		"Use --telegram, --registries, or --all flags",
+		"Use --telegram, --slack, --discord, --email, --notifiers, --registries, or --all flags",
+		"--notifiers",
 		"--telegram",
+		"--slack",
+		"--discord",
+		"--email",
 		"--registries",
 		"--all",
 	}
@@ -134,6 +145,88 @@ func TestTestTelegram_MissingChatID(t *testing.T) {
 	}
 }
 
+func TestTestSlack(t *testing.T) {
+	var received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		slack   types.SlackConfig
+		wantErr bool
+		output  string
+	}{
+		{name: "disabled", slack: types.SlackConfig{WebhookURL: srv.URL}, output: "Slack is disabled in configuration"},
+		{name: "missing webhook", slack: types.SlackConfig{Enabled: true}, wantErr: true, output: "Slack webhook URL is not configured"},
+		{name: "sends test message", slack: types.SlackConfig{Enabled: true, WebhookURL: srv.URL}, output: "Slack webhook connectivity successful"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			cmd := &cobra.Command{}
+			buf := &bytes.Buffer{}
+			cmd.SetOut(buf)
+
+			err := testSlack(cmd, &types.Config{Slack: tt.slack})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("testSlack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(buf.String(), tt.output) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.output, buf.String())
+			}
+			// Solo el caso configurado llega al webhook
+			if sent := strings.Contains(received, "Docker Image Reporter Test"); sent != (tt.name == "sends test message") {
+				t.Errorf("webhook received %q", received)
+			}
+		})
+	}
+}
+
+func TestTestDiscord(t *testing.T) {
+	var received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cmd := &cobra.Command{}
+	buf := &bytes.Buffer{}
+	cmd.SetOut(buf)
+
+	if err := testDiscord(cmd, &types.Config{Discord: types.DiscordConfig{Enabled: true, WebhookURL: srv.URL}}); err != nil {
+		t.Fatalf("testDiscord() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Discord webhook connectivity successful") {
+		t.Errorf("Expected a success message, got:\n%s", buf.String())
+	}
+	if !strings.Contains(received, "Docker Image Reporter Test") {
+		t.Errorf("webhook received %q, want the test message", received)
+	}
+}
+
+func TestTestEmail_MissingRecipients(t *testing.T) {
+	cmd := &cobra.Command{}
+	buf := &bytes.Buffer{}
+	cmd.SetOut(buf)
+
+	err := testEmail(cmd, &types.Config{Email: types.EmailConfig{Enabled: true, Host: "smtp.example.com"}})
+
+	if err == nil {
+		t.Error("Expected error for missing recipients")
+	}
+	if !strings.Contains(buf.String(), "No email recipients are configured") {
+		t.Errorf("Expected output to mention missing recipients, got:\n%s", buf.String())
+	}
+}
+
 func TestTestRegistries_AlwaysRuns(t *testing.T) {
 	cfg := &types.Config{
 		Registry: types.RegistryConfig{Timeout: 1}, // 1s timeout → fails fast in tests
@@ -155,3 +248,61 @@ func TestTestRegistries_AlwaysRuns(t *testing.T) {
 		t.Error("Expected output to mention OCI registry test")
 	}
 }
+
+// fakeNotifier es un cliente de notificación que cuenta los envíos y falla si tiene err
+type fakeNotifier struct {
+	name string
+	err  error
+	sent int
+}
+
+func (f *fakeNotifier) SendNotification(ctx context.Context, message string) error {
+	f.sent++
+	return f.err
+}
+
+func (f *fakeNotifier) SendFile(ctx context.Context, filePath, fileName, caption string) error {
+	return f.err
+}
+
+func (f *fakeNotifier) Name() string {
+	return f.name
+}
+
+func TestTestNotifiers_ReportsEachClient(t *testing.T) {
+	failing := &fakeNotifier{name: "telegram", err: errors.New("chat not found")}
+	working := &fakeNotifier{name: "exec"}
+
+	cmd := &cobra.Command{}
+	buf := &bytes.Buffer{}
+	cmd.SetOut(buf)
+
+	err := testNotifiers(cmd, notifier.NewNotificationService(failing, working))
+
+	if err == nil || !strings.Contains(err.Error(), "telegram") || strings.Contains(err.Error(), "exec") {
+		t.Errorf("Expected an error naming only telegram, got %v", err)
+	}
+	if failing.sent != 1 || working.sent != 1 {
+		t.Errorf("Expected one attempt per notifier, got telegram=%d exec=%d", failing.sent, working.sent)
+	}
+
+	output := buf.String()
+	for _, part := range []string{"❌ telegram: chat not found", "✅ exec: test notification sent"} {
+		if !strings.Contains(output, part) {
+			t.Errorf("Expected output to contain %q, got:\n%s", part, output)
+		}
+	}
+}
+
+func TestTestNotifiers_NoneConfigured(t *testing.T) {
+	cmd := &cobra.Command{}
+	buf := &bytes.Buffer{}
+	cmd.SetOut(buf)
+
+	if err := testNotifiers(cmd, notifier.NewNotificationService()); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "No notifiers are configured") {
+		t.Errorf("Expected output to mention no notifiers, got:\n%s", buf.String())
+	}
+}
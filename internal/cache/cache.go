@@ -6,20 +6,31 @@ import (
 	"sync/atomic"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
+	"github.com/user/docker-image-reporter/internal/clock"
+	"github.com/user/docker-image-reporter/pkg/errors"
 	"github.com/user/docker-image-reporter/pkg/types"
 )
 
 // CacheEntry represents a cached registry response
 type CacheEntry struct {
 	Tags      []string
+	TagInfos  []types.TagInfo // metadata for Tags, when set through SetTagInfos
 	ImageInfo *types.ImageInfo
+	ETag      string // validator sent back as If-None-Match on the next request
 	Timestamp time.Time
 	TTL       time.Duration
 }
 
 // IsExpired checks if the cache entry has expired
 func (e *CacheEntry) IsExpired() bool {
-	return time.Since(e.Timestamp) > e.TTL
+	return e.ExpiredAt(time.Now())
+}
+
+// ExpiredAt checks if the cache entry has expired at the given time
+func (e *CacheEntry) ExpiredAt(now time.Time) bool {
+	return now.Sub(e.Timestamp) > e.TTL
 }
 
 // CacheStats holds statistics about cache usage
@@ -41,19 +52,28 @@ func (s *CacheStats) HitRate() float64 {
 
 // RegistryCache provides in-memory caching for registry responses
 type RegistryCache struct {
-	cache       sync.Map
-	defaultTTL  time.Duration
-	stats       CacheStats
-	cleanupTick *time.Ticker
-	stopCleanup chan struct{}
+	cache           sync.Map
+	defaultTTL      time.Duration
+	stats           CacheStats
+	clock           clock.Clock
+	cleanupInterval time.Duration
+	stopCleanup     chan struct{}
+	stopOnce        sync.Once
+	// path is the file a persistent cache is flushed to on Close, if any
+	path string
 }
 
 // Config holds cache configuration
 type Config struct {
 	DefaultTTL      time.Duration
 	CleanupInterval time.Duration
+	Clock           clock.Clock // defaults to the wall clock when nil
 }
 
+// etagTTL bounds how long ETag validators are kept. They are revalidated on
+// every request, so they can safely outlive regular tag entries.
+const etagTTL = 24 * time.Hour
+
 // DefaultConfig returns sensible default cache configuration
 func DefaultConfig() Config {
 	return Config{
@@ -64,14 +84,19 @@ func DefaultConfig() Config {
 
 // NewRegistryCache creates a new registry cache with the given configuration
 func NewRegistryCache(config Config) *RegistryCache {
+	if config.Clock == nil {
+		config.Clock = clock.New()
+	}
+
 	cache := &RegistryCache{
-		defaultTTL:  config.DefaultTTL,
-		stopCleanup: make(chan struct{}),
+		defaultTTL:      config.DefaultTTL,
+		clock:           config.Clock,
+		cleanupInterval: config.CleanupInterval,
+		stopCleanup:     make(chan struct{}),
 	}
 
 	// Start background cleanup goroutine
 	if config.CleanupInterval > 0 {
-		cache.cleanupTick = time.NewTicker(config.CleanupInterval)
 		go cache.cleanupLoop()
 	}
 
@@ -85,7 +110,7 @@ func (c *RegistryCache) GetTags(image types.DockerImage) ([]string, bool) {
 	if value, ok := c.cache.Load(key); ok {
 		entry := value.(*CacheEntry)
 
-		if !entry.IsExpired() {
+		if !entry.ExpiredAt(c.clock.Now()) {
 			atomic.AddInt64(&c.stats.Hits, 1)
 			return entry.Tags, true
 		}
@@ -111,7 +136,7 @@ func (c *RegistryCache) SetTagsWithTTL(image types.DockerImage, tags []string, t
 
 	entry := &CacheEntry{
 		Tags:      make([]string, len(tags)), // Create a copy to avoid external modifications
-		Timestamp: time.Now(),
+		Timestamp: c.clock.Now(),
 		TTL:       ttl,
 	}
 	copy(entry.Tags, tags)
@@ -126,6 +151,59 @@ func (c *RegistryCache) SetTagsWithTTL(image types.DockerImage, tags []string, t
 	}
 }
 
+// GetTagInfos retrieves cached tags with their metadata for an image. Entries
+// stored with SetTags carry names only.
+func (c *RegistryCache) GetTagInfos(image types.DockerImage) ([]types.TagInfo, bool) {
+	key := c.makeKey(image, "tags")
+
+	if value, ok := c.cache.Load(key); ok {
+		entry := value.(*CacheEntry)
+
+		if !entry.ExpiredAt(c.clock.Now()) {
+			atomic.AddInt64(&c.stats.Hits, 1)
+			if entry.TagInfos != nil {
+				return entry.TagInfos, true
+			}
+			infos := make([]types.TagInfo, 0, len(entry.Tags))
+			for _, tag := range entry.Tags {
+				infos = append(infos, types.TagInfo{Name: tag})
+			}
+			return infos, true
+		}
+
+		// Entry expired, remove it
+		c.cache.Delete(key)
+		atomic.AddInt64(&c.stats.Evicted, 1)
+		atomic.AddInt64(&c.stats.Size, -1)
+	}
+
+	atomic.AddInt64(&c.stats.Misses, 1)
+	return nil, false
+}
+
+// SetTagInfos caches tags with their metadata for an image. GetTags returns
+// their names.
+func (c *RegistryCache) SetTagInfos(image types.DockerImage, tags []types.TagInfo) {
+	key := c.makeKey(image, "tags")
+
+	entry := &CacheEntry{
+		Tags:      types.TagNames(tags),
+		TagInfos:  make([]types.TagInfo, len(tags)), // Create a copy to avoid external modifications
+		Timestamp: c.clock.Now(),
+		TTL:       c.defaultTTL,
+	}
+	copy(entry.TagInfos, tags)
+
+	// Check if this is a new entry
+	_, existed := c.cache.LoadOrStore(key, entry)
+	if !existed {
+		atomic.AddInt64(&c.stats.Size, 1)
+	} else {
+		// Update existing entry
+		c.cache.Store(key, entry)
+	}
+}
+
 // GetImageInfo retrieves cached image info
 func (c *RegistryCache) GetImageInfo(image types.DockerImage) (*types.ImageInfo, bool) {
 	key := c.makeKey(image, "info")
@@ -133,7 +211,7 @@ func (c *RegistryCache) GetImageInfo(image types.DockerImage) (*types.ImageInfo,
 	if value, ok := c.cache.Load(key); ok {
 		entry := value.(*CacheEntry)
 
-		if !entry.IsExpired() {
+		if !entry.ExpiredAt(c.clock.Now()) {
 			atomic.AddInt64(&c.stats.Hits, 1)
 			return entry.ImageInfo, true
 		}
@@ -159,7 +237,7 @@ func (c *RegistryCache) SetImageInfoWithTTL(image types.DockerImage, info *types
 
 	entry := &CacheEntry{
 		ImageInfo: info,
-		Timestamp: time.Now(),
+		Timestamp: c.clock.Now(),
 		TTL:       ttl,
 	}
 
@@ -173,6 +251,51 @@ func (c *RegistryCache) SetImageInfoWithTTL(image types.DockerImage, info *types
 	}
 }
 
+// GetETag retrieves the ETag validator and the tags listed for a request URL
+func (c *RegistryCache) GetETag(url string) (*CacheEntry, bool) {
+	key := url + "#etag"
+
+	if value, ok := c.cache.Load(key); ok {
+		entry := value.(*CacheEntry)
+
+		if !entry.ExpiredAt(c.clock.Now()) {
+			atomic.AddInt64(&c.stats.Hits, 1)
+			return entry, true
+		}
+
+		// Entry expired, remove it
+		c.cache.Delete(key)
+		atomic.AddInt64(&c.stats.Evicted, 1)
+		atomic.AddInt64(&c.stats.Size, -1)
+	}
+
+	atomic.AddInt64(&c.stats.Misses, 1)
+	return nil, false
+}
+
+// SetETag stores the ETag validator returned for a request URL and the tags
+// parsed from that response, reused as they are on 304 Not Modified
+func (c *RegistryCache) SetETag(url, etag string, tags []string) {
+	key := url + "#etag"
+
+	entry := &CacheEntry{
+		Tags:      make([]string, len(tags)),
+		ETag:      etag,
+		Timestamp: c.clock.Now(),
+		TTL:       etagTTL,
+	}
+	copy(entry.Tags, tags)
+
+	// Check if this is a new entry
+	_, existed := c.cache.LoadOrStore(key, entry)
+	if !existed {
+		atomic.AddInt64(&c.stats.Size, 1)
+	} else {
+		// Update existing entry
+		c.cache.Store(key, entry)
+	}
+}
+
 // Clear removes all entries from the cache
 func (c *RegistryCache) Clear() {
 	c.cache.Range(func(key, value interface{}) bool {
@@ -180,8 +303,7 @@ func (c *RegistryCache) Clear() {
 		return true
 	})
 
-	atomic.StoreInt64(&c.stats.Size, 0)
-	atomic.AddInt64(&c.stats.Evicted, atomic.LoadInt64(&c.stats.Size))
+	atomic.AddInt64(&c.stats.Evicted, atomic.SwapInt64(&c.stats.Size, 0))
 }
 
 // Stats returns current cache statistics
@@ -194,12 +316,19 @@ func (c *RegistryCache) Stats() CacheStats {
 	}
 }
 
-// Close stops the cache cleanup goroutine
-func (c *RegistryCache) Close() {
-	if c.cleanupTick != nil {
-		c.cleanupTick.Stop()
+// Close stops the cache cleanup goroutine. A persistent cache also writes its
+// unexpired entries back to its file; the returned error reports a failed
+// write. Only the first call has any effect.
+func (c *RegistryCache) Close() error {
+	var err error
+	c.stopOnce.Do(func() {
 		close(c.stopCleanup)
-	}
+		if c.path != "" {
+			c.cleanupExpired()
+			err = c.Save(c.path)
+		}
+	})
+	return err
 }
 
 // makeKey creates a cache key for an image and operation type
@@ -212,7 +341,7 @@ func (c *RegistryCache) makeKey(image types.DockerImage, operation string) strin
 func (c *RegistryCache) cleanupLoop() {
 	for {
 		select {
-		case <-c.cleanupTick.C:
+		case <-c.clock.After(c.cleanupInterval):
 			c.cleanupExpired()
 		case <-c.stopCleanup:
 			return
@@ -220,14 +349,17 @@ func (c *RegistryCache) cleanupLoop() {
 	}
 }
 
-// cleanupExpired removes all expired entries from the cache
-func (c *RegistryCache) cleanupExpired() {
+// cleanupExpired removes all expired entries from the cache and returns how
+// many were removed
+func (c *RegistryCache) cleanupExpired() int {
 	var keysToDelete []interface{}
 
+	now := c.clock.Now()
+
 	// First pass: collect expired keys
 	c.cache.Range(func(key, value interface{}) bool {
 		entry := value.(*CacheEntry)
-		if entry.IsExpired() {
+		if entry.ExpiredAt(now) {
 			keysToDelete = append(keysToDelete, key)
 		}
 		return true
@@ -239,12 +371,15 @@ func (c *RegistryCache) cleanupExpired() {
 		atomic.AddInt64(&c.stats.Evicted, 1)
 		atomic.AddInt64(&c.stats.Size, -1)
 	}
+	return len(keysToDelete)
 }
 
-// CachedRegistryClient wraps a registry client with caching capabilities
+// CachedRegistryClient wraps a registry client with caching capabilities.
+// Concurrent cache misses for the same image share a single registry call.
 type CachedRegistryClient struct {
-	client types.RegistryClient
-	cache  *RegistryCache
+	client   types.RegistryClient
+	cache    *RegistryCache
+	inflight singleflight.Group
 }
 
 // NewCachedRegistryClient creates a new cached registry client
@@ -260,23 +395,67 @@ func (c *CachedRegistryClient) Name() string {
 	return c.client.Name()
 }
 
-// GetLatestTags gets tags with caching
+// GetLatestTags gets tag names with caching, as a thin wrapper around GetTags
 func (c *CachedRegistryClient) GetLatestTags(ctx context.Context, image types.DockerImage) ([]string, error) {
+	tags, err := c.GetTags(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	return types.TagNames(tags), nil
+}
+
+// GetTags gets tags and their metadata with caching
+func (c *CachedRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
 	// Try cache first
-	if tags, found := c.cache.GetTags(image); found {
+	if tags, found := c.cache.GetTagInfos(image); found {
 		return tags, nil
 	}
 
-	// Cache miss, fetch from registry
-	tags, err := c.client.GetLatestTags(ctx, image)
+	// Cache miss, fetch from registry once for all concurrent callers
+	v, err, _ := c.inflight.Do(c.cache.makeKey(image, "tags"), func() (interface{}, error) {
+		tags, err := c.client.GetTags(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cache the result
+		c.cache.SetTagInfos(image, tags)
+		return tags, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Cache the result
-	c.cache.SetTags(image, tags)
+	return v.([]types.TagInfo), nil
+}
+
+// GetDigest resolves the current digest of the image tag through the underlying
+// client. Digests are not cached since detecting a changed digest is the point.
+func (c *CachedRegistryClient) GetDigest(ctx context.Context, image types.DockerImage) (string, error) {
+	resolver, ok := c.client.(types.DigestResolver)
+	if !ok {
+		return "", errors.Wrapf("cache.GetDigest", errors.ErrUnsupported, "resolving digest via %s", c.client.Name())
+	}
+	return resolver.GetDigest(ctx, image)
+}
+
+// Ping checks registry connectivity through the underlying client, if supported.
+// Connectivity is never cached.
+func (c *CachedRegistryClient) Ping(ctx context.Context, registry string) error {
+	pinger, ok := c.client.(types.RegistryPinger)
+	if !ok {
+		return nil
+	}
+	return pinger.Ping(ctx, registry)
+}
 
-	return tags, nil
+// RateLimits reports the rate limits seen by the underlying client, if supported.
+func (c *CachedRegistryClient) RateLimits() []types.RateLimit {
+	reporter, ok := c.client.(types.RateLimitReporter)
+	if !ok {
+		return nil
+	}
+	return reporter.RateLimits()
 }
 
 // GetImageInfo gets image info with caching
@@ -286,14 +465,20 @@ func (c *CachedRegistryClient) GetImageInfo(ctx context.Context, image types.Doc
 		return info, nil
 	}
 
-	// Cache miss, fetch from registry
-	info, err := c.client.GetImageInfo(ctx, image)
+	// Cache miss, fetch from registry once for all concurrent callers
+	v, err, _ := c.inflight.Do(c.cache.makeKey(image, "info"), func() (interface{}, error) {
+		info, err := c.client.GetImageInfo(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cache the result
+		c.cache.SetImageInfo(image, info)
+		return info, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Cache the result
-	c.cache.SetImageInfo(image, info)
-
-	return info, nil
+	return v.(*types.ImageInfo), nil
 }
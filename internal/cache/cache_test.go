@@ -3,9 +3,15 @@ package cache
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/user/docker-image-reporter/internal/clock"
 	"github.com/user/docker-image-reporter/pkg/types"
 )
 
@@ -13,6 +19,7 @@ import (
 type mockRegistryClient struct {
 	name      string
 	tags      []string
+	tagInfos  []types.TagInfo // takes precedence over tags in GetTags
 	imageInfo *types.ImageInfo
 	err       error
 	callCount int
@@ -30,6 +37,21 @@ func (m *mockRegistryClient) GetLatestTags(ctx context.Context, image types.Dock
 	return m.tags, nil
 }
 
+func (m *mockRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	m.callCount++
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.tagInfos != nil {
+		return m.tagInfos, nil
+	}
+	tags := make([]types.TagInfo, 0, len(m.tags))
+	for _, tag := range m.tags {
+		tags = append(tags, types.TagInfo{Name: tag})
+	}
+	return tags, nil
+}
+
 func (m *mockRegistryClient) GetImageInfo(ctx context.Context, image types.DockerImage) (*types.ImageInfo, error) {
 	m.callCount++
 	if m.err != nil {
@@ -76,9 +98,11 @@ func TestRegistryCache_GetSetTags(t *testing.T) {
 }
 
 func TestRegistryCache_TTLExpiration(t *testing.T) {
+	fakeClock := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
 	config := Config{
 		DefaultTTL:      50 * time.Millisecond,
 		CleanupInterval: 0, // Disable automatic cleanup for this test
+		Clock:           fakeClock,
 	}
 	cache := NewRegistryCache(config)
 	defer cache.Close()
@@ -99,8 +123,14 @@ func TestRegistryCache_TTLExpiration(t *testing.T) {
 		t.Error("Expected cache hit immediately after setting")
 	}
 
-	// Wait for expiration
-	time.Sleep(60 * time.Millisecond)
+	// Exactly at the TTL boundary the entry is still valid
+	fakeClock.Advance(50 * time.Millisecond)
+	if _, found := cache.GetTags(image); !found {
+		t.Error("Expected cache hit at TTL boundary")
+	}
+
+	// Move past expiration
+	fakeClock.Advance(10 * time.Millisecond)
 
 	// Should be expired now
 	if _, found := cache.GetTags(image); found {
@@ -181,9 +211,11 @@ func TestRegistryCache_Clear(t *testing.T) {
 }
 
 func TestRegistryCache_CleanupExpired(t *testing.T) {
+	fakeClock := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
 	config := Config{
 		DefaultTTL:      100 * time.Millisecond,
 		CleanupInterval: 50 * time.Millisecond,
+		Clock:           fakeClock,
 	}
 	cache := NewRegistryCache(config)
 	defer cache.Close()
@@ -195,15 +227,18 @@ func TestRegistryCache_CleanupExpired(t *testing.T) {
 	}
 
 	// Set entry with short TTL
-	cache.SetTagsWithTTL(image, []string{"latest"}, 50*time.Millisecond)
+	cache.SetTagsWithTTL(image, []string{"latest"}, 40*time.Millisecond)
 
 	// Verify it's there
 	if _, found := cache.GetTags(image); !found {
 		t.Error("Expected cache hit immediately after setting")
 	}
 
-	// Wait for cleanup to run (should happen after 50ms + some buffer)
-	time.Sleep(150 * time.Millisecond)
+	// Wait for the cleanup loop to arm its timer, fire it, and wait for it to
+	// re-arm: at that point the cleanup pass has completed.
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(50 * time.Millisecond)
+	fakeClock.BlockUntil(1)
 
 	// Entry should be cleaned up
 	stats := cache.Stats()
@@ -311,6 +346,98 @@ func TestCachedRegistryClient_ErrorHandling(t *testing.T) {
 	}
 }
 
+// blockingRegistryClient blocks every lookup until release is closed and
+// counts how many calls reach the registry. Each call is announced on
+// started before it blocks.
+type blockingRegistryClient struct {
+	release chan struct{}
+	started chan struct{}
+	tags    atomic.Int32
+	info    atomic.Int32
+}
+
+func (b *blockingRegistryClient) Name() string {
+	return "docker.io"
+}
+
+func (b *blockingRegistryClient) GetLatestTags(ctx context.Context, image types.DockerImage) ([]string, error) {
+	b.tags.Add(1)
+	b.started <- struct{}{}
+	<-b.release
+	return []string{"1.21", "1.20"}, nil
+}
+
+func (b *blockingRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	b.tags.Add(1)
+	b.started <- struct{}{}
+	<-b.release
+	return []types.TagInfo{{Name: "1.21"}, {Name: "1.20"}}, nil
+}
+
+func (b *blockingRegistryClient) GetImageInfo(ctx context.Context, image types.DockerImage) (*types.ImageInfo, error) {
+	b.info.Add(1)
+	b.started <- struct{}{}
+	<-b.release
+	return &types.ImageInfo{Tags: []string{"1.21"}}, nil
+}
+
+func TestCachedRegistryClient_ConcurrentMissesShareOneCall(t *testing.T) {
+	const callers = 20
+
+	client := &blockingRegistryClient{release: make(chan struct{}), started: make(chan struct{}, 2*callers)}
+	// A fake clock keeps the entries fresh for callers that arrive after the
+	// registry answered
+	config := DefaultConfig()
+	config.Clock = clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	cache := NewRegistryCache(config)
+	defer cache.Close()
+
+	cachedClient := NewCachedRegistryClient(client, cache)
+	image := types.DockerImage{Registry: "docker.io", Repository: "nginx", Tag: "1.20"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			tags, err := cachedClient.GetLatestTags(context.Background(), image)
+			if err == nil && len(tags) != 2 {
+				err = errors.New("unexpected tags")
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			info, err := cachedClient.GetImageInfo(context.Background(), image)
+			if err == nil && info == nil {
+				err = errors.New("unexpected nil image info")
+			}
+			errs <- err
+		}()
+	}
+
+	// Hold the registry until both lookups are in flight
+	<-client.started
+	<-client.started
+	close(client.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("lookup failed: %v", err)
+		}
+	}
+
+	if got := client.tags.Load(); got != 1 {
+		t.Errorf("GetLatestTags reached the registry %d times, want 1", got)
+	}
+	if got := client.info.Load(); got != 1 {
+		t.Errorf("GetImageInfo reached the registry %d times, want 1", got)
+	}
+}
+
 func TestRegistryCache_ConcurrentAccess(t *testing.T) {
 	cache := NewRegistryCache(DefaultConfig())
 	defer cache.Close()
@@ -423,3 +550,200 @@ func TestCacheStats_HitRate(t *testing.T) {
 		})
 	}
 }
+
+func TestCachedRegistryClient_GetTagsKeepsMetadata(t *testing.T) {
+	updated := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	mockClient := &mockRegistryClient{
+		name: "docker.io",
+		tagInfos: []types.TagInfo{
+			{Name: "1.25", LastUpdated: updated, Digest: "sha256:abc", Architectures: []string{"amd64", "arm64"}},
+			{Name: "1.24"},
+		},
+	}
+	cache := NewRegistryCache(DefaultConfig())
+	defer cache.Close()
+	client := NewCachedRegistryClient(mockClient, cache)
+
+	image := types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.24"}
+	for i := 0; i < 2; i++ {
+		tags, err := client.GetTags(context.Background(), image)
+		if err != nil {
+			t.Fatalf("GetTags() error = %v", err)
+		}
+		if len(tags) != 2 || !tags[0].LastUpdated.Equal(updated) || tags[0].Digest != "sha256:abc" || len(tags[0].Architectures) != 2 {
+			t.Fatalf("GetTags() = %+v, want metadata preserved", tags)
+		}
+	}
+
+	// GetLatestTags reuses the cached entry
+	names, err := client.GetLatestTags(context.Background(), image)
+	if err != nil {
+		t.Fatalf("GetLatestTags() error = %v", err)
+	}
+	if len(names) != 2 || names[0] != "1.25" {
+		t.Errorf("GetLatestTags() = %v, want [1.25 1.24]", names)
+	}
+	if mockClient.callCount != 1 {
+		t.Errorf("registry calls = %d, want 1", mockClient.callCount)
+	}
+}
+
+func TestRegistryCache_GetTagInfosFromNames(t *testing.T) {
+	cache := NewRegistryCache(DefaultConfig())
+	defer cache.Close()
+
+	image := types.DockerImage{Registry: "docker.io", Repository: "library/nginx"}
+	cache.SetTags(image, []string{"1.25", "1.24"})
+
+	tags, found := cache.GetTagInfos(image)
+	if !found {
+		t.Fatal("expected cache hit")
+	}
+	if len(tags) != 2 || tags[0].Name != "1.25" || tags[1].Name != "1.24" {
+		t.Errorf("GetTagInfos() = %+v, want names only", tags)
+	}
+}
+
+func TestRegistryCache_SaveLoad(t *testing.T) {
+	fakeClock := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	config := Config{DefaultTTL: time.Hour, Clock: fakeClock}
+
+	image := types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}
+	saved := NewRegistryCache(config)
+	defer saved.Close()
+	saved.SetTagInfos(image, []types.TagInfo{{Name: "1.25", Digest: "sha256:abc"}, {Name: "1.27"}})
+	saved.SetETag("https://registry.example.com/v2/org/app/tags/list", `"abc"`, []string{"1.0.0", "1.1.0"})
+
+	path := filepath.Join(t.TempDir(), "cache", DefaultFile)
+	if err := saved.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded := NewRegistryCache(config)
+	defer loaded.Close()
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if size := loaded.Stats().Size; size != 2 {
+		t.Errorf("loaded size = %d, want 2", size)
+	}
+	tags, found := loaded.GetTagInfos(image)
+	if !found || len(tags) != 2 || tags[0].Digest != "sha256:abc" {
+		t.Errorf("GetTagInfos() = %+v, %v after load", tags, found)
+	}
+	if entry, found := loaded.GetETag("https://registry.example.com/v2/org/app/tags/list"); !found || entry.ETag != `"abc"` || strings.Join(entry.Tags, ",") != "1.0.0,1.1.0" {
+		t.Errorf("GetETag() = %+v, %v after load", entry, found)
+	}
+
+	// Entries keep their timestamps and expire as if they had stayed in memory
+	fakeClock.Advance(2 * time.Hour)
+	if removed := loaded.Prune(); removed != 1 {
+		t.Errorf("Prune() removed %d entries, want 1 (the tags entry)", removed)
+	}
+
+	// A missing file leaves the cache empty without an error
+	if err := NewRegistryCache(config).Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("Load() of a missing file error = %v", err)
+	}
+}
+
+func TestNewPersistentCache_Restart(t *testing.T) {
+	fakeClock := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	config := Config{DefaultTTL: time.Hour, Clock: fakeClock}
+	path := filepath.Join(t.TempDir(), "cache", DefaultFile)
+
+	nginx := types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}
+	redis := types.DockerImage{Registry: "docker.io", Repository: "library/redis", Tag: "7"}
+
+	// First run: a missing file starts empty and Close writes the entries
+	first, err := NewPersistentCache(config, path)
+	if err != nil {
+		t.Fatalf("NewPersistentCache() error = %v", err)
+	}
+	first.SetTags(nginx, []string{"1.25", "1.27"})
+	first.SetImageInfoWithTTL(nginx, &types.ImageInfo{Tags: []string{"1.27"}}, time.Hour)
+	first.SetTagsWithTTL(redis, []string{"7", "8"}, 3*time.Hour)
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Second run, still within the TTL: every entry survives the restart
+	second, err := NewPersistentCache(config, path)
+	if err != nil {
+		t.Fatalf("NewPersistentCache() error = %v", err)
+	}
+	if tags, found := second.GetTags(nginx); !found || len(tags) != 2 {
+		t.Errorf("GetTags(nginx) = %v, %v after restart", tags, found)
+	}
+	if info, found := second.GetImageInfo(nginx); !found || len(info.Tags) != 1 {
+		t.Errorf("GetImageInfo(nginx) = %+v, %v after restart", info, found)
+	}
+	if err := second.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Third run, two hours later: the expired nginx entries are not loaded
+	fakeClock.Advance(2 * time.Hour)
+	third, err := NewPersistentCache(config, path)
+	if err != nil {
+		t.Fatalf("NewPersistentCache() error = %v", err)
+	}
+	if size := third.Stats().Size; size != 1 {
+		t.Errorf("size after expiry = %d, want 1 (redis)", size)
+	}
+	if _, found := third.GetTags(redis); !found {
+		t.Error("expected the redis entry to survive within its TTL")
+	}
+	if err := third.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// The flushed file no longer holds the expired entries
+	reloaded := NewRegistryCache(config)
+	defer reloaded.Close()
+	if err := reloaded.Load(path); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if entries := reloaded.Entries(); len(entries) != 1 {
+		t.Errorf("file entries = %+v, want only redis", entries)
+	}
+}
+
+func TestNewPersistentCache_UnreadableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	if err := os.WriteFile(path, []byte("not json"), 0600); err != nil {
+		t.Fatalf("writing cache file: %v", err)
+	}
+
+	c, err := NewPersistentCache(Config{DefaultTTL: time.Hour}, path)
+	if err == nil {
+		t.Error("expected an error for an unreadable cache file")
+	}
+	// The cache is still usable but leaves the file alone on Close
+	c.SetTags(types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1"}, []string{"1"})
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "not json" {
+		t.Errorf("file after Close = %q, want it untouched", data)
+	}
+}
+
+func TestNewPersistentCache_OtherVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	content := `{"version":1,"entries":{}}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("writing cache file: %v", err)
+	}
+
+	c, err := NewPersistentCache(Config{DefaultTTL: time.Hour}, path)
+	if err == nil {
+		t.Error("expected an error for another cache version")
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("file after Close = %q, want it untouched", data)
+	}
+}
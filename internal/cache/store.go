@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+)
+
+// DefaultFile is the name of the persistent cache file inside the config directory
+const DefaultFile = "cache.json"
+
+// storeVersion is bumped when the on-disk layout changes; files written with
+// another version are ignored rather than misread
+const storeVersion = 2
+
+// storeFile is the on-disk layout of a persisted cache
+type storeFile struct {
+	Version int                    `json:"version"`
+	Entries map[string]*CacheEntry `json:"entries"`
+}
+
+// EntrySummary describes a cached entry without its payload
+type EntrySummary struct {
+	Key       string    `json:"key"`
+	Kind      string    `json:"kind"` // tags, info or etag
+	StoredAt  time.Time `json:"stored_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Expired   bool      `json:"expired"`
+	Tags      int       `json:"tags,omitempty"`
+}
+
+// NewPersistentCache creates a cache backed by the file at path: the entries
+// that have not expired yet are loaded now, and Close writes the cache back.
+// A file that is unreadable, is not a cache or has another version is
+// reported together with a usable empty cache that is never saved, so a
+// mistyped path does not destroy an unrelated file.
+func NewPersistentCache(config Config, path string) (*RegistryCache, error) {
+	c := NewRegistryCache(config)
+	if err := c.load(path, false); err != nil {
+		return c, err
+	}
+	c.path = path
+	return c, nil
+}
+
+// Load merges the entries persisted at path into the cache, keeping their
+// original timestamps so they expire as if they had never left memory. A
+// missing file is not an error: the cache simply starts empty.
+func (c *RegistryCache) Load(path string) error {
+	return c.load(path, true)
+}
+
+// load merges the entries persisted at path, skipping the expired ones
+// unless keepExpired is set (cache show lists them)
+func (c *RegistryCache) load(path string, keepExpired bool) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap("cache.Load", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return errors.Wrapf("cache.Load", err, "parsing %s", path)
+	}
+	if file.Version != storeVersion {
+		return errors.Newf("cache.Load", "%s has unsupported version %d", path, file.Version)
+	}
+
+	now := c.clock.Now()
+	for key, entry := range file.Entries {
+		if entry == nil || (!keepExpired && entry.ExpiredAt(now)) {
+			continue
+		}
+		if _, existed := c.cache.LoadOrStore(key, entry); !existed {
+			atomic.AddInt64(&c.stats.Size, 1)
+		}
+	}
+	return nil
+}
+
+// Save writes every entry to path, replacing the file atomically so a
+// concurrent reader never sees a partial cache
+func (c *RegistryCache) Save(path string) error {
+	file := storeFile{Version: storeVersion, Entries: make(map[string]*CacheEntry)}
+	c.cache.Range(func(key, value interface{}) bool {
+		file.Entries[key.(string)] = value.(*CacheEntry)
+		return true
+	})
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return errors.Wrap("cache.Save", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return errors.Wrap("cache.Save", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap("cache.Save", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // already renamed on success
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap("cache.Save", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap("cache.Save", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrap("cache.Save", err)
+	}
+	return nil
+}
+
+// Prune removes the expired entries and returns how many were removed
+func (c *RegistryCache) Prune() int {
+	return c.cleanupExpired()
+}
+
+// Entries returns a summary of every entry, expired ones included, sorted by key
+func (c *RegistryCache) Entries() []EntrySummary {
+	now := c.clock.Now()
+
+	var entries []EntrySummary
+	c.cache.Range(func(key, value interface{}) bool {
+		entry := value.(*CacheEntry)
+		name := key.(string)
+		kind := "tags"
+		if i := strings.LastIndex(name, "#"); i >= 0 {
+			kind = name[i+1:]
+		}
+		entries = append(entries, EntrySummary{
+			Key:       name,
+			Kind:      kind,
+			StoredAt:  entry.Timestamp,
+			ExpiresAt: entry.Timestamp.Add(entry.TTL),
+			Expired:   entry.ExpiredAt(now),
+			Tags:      len(entry.Tags),
+		})
+		return true
+	})
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
@@ -0,0 +1,107 @@
+// Package clock abstracts time so TTLs, timestamps and delays can be
+// driven deterministically in tests.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time and timers.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is a Clock backed by the time package.
+type Real struct{}
+
+// New returns the real wall clock.
+func New() Clock {
+	return Real{}
+}
+
+// Now returns time.Now().
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// After returns time.After(d).
+func (Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Fake is a manually advanced Clock for tests. Channels returned by After
+// fire once Advance moves the clock past their deadline.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFake returns a Fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives the fake time once the clock has
+// been advanced by at least d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+
+	f.waiters = append(f.waiters, fakeWaiter{deadline: f.now.Add(d), ch: ch})
+	f.cond.Broadcast()
+	return ch
+}
+
+// Advance moves the clock forward by d and fires any expired timers.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.deadline.After(f.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- f.now
+	}
+	f.waiters = pending
+	f.cond.Broadcast()
+}
+
+// BlockUntil blocks until at least n goroutines are waiting on After.
+// It lets tests synchronise with background loops before advancing.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
@@ -0,0 +1,84 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake_Now(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+
+	if !fake.Now().Equal(start) {
+		t.Errorf("Now() = %v, want %v", fake.Now(), start)
+	}
+
+	fake.Advance(90 * time.Second)
+
+	want := start.Add(90 * time.Second)
+	if !fake.Now().Equal(want) {
+		t.Errorf("Now() after Advance = %v, want %v", fake.Now(), want)
+	}
+}
+
+func TestFake_After(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		advance time.Duration
+		fired   bool
+	}{
+		{"not yet due", time.Minute, 30 * time.Second, false},
+		{"exactly due", time.Minute, time.Minute, true},
+		{"overdue", time.Minute, time.Hour, true},
+		{"zero delay", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			ch := fake.After(tt.delay)
+			fake.Advance(tt.advance)
+
+			select {
+			case <-ch:
+				if !tt.fired {
+					t.Error("timer fired before its deadline")
+				}
+			default:
+				if tt.fired {
+					t.Error("timer did not fire after its deadline")
+				}
+			}
+		})
+	}
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	fake := NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	done := make(chan struct{})
+
+	go func() {
+		<-fake.After(time.Second)
+		close(done)
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	<-done
+}
+
+func TestReal(t *testing.T) {
+	c := New()
+
+	before := time.Now()
+	if c.Now().Before(before) {
+		t.Error("Real.Now() returned a time before time.Now()")
+	}
+
+	select {
+	case <-c.After(time.Millisecond):
+	case <-time.After(time.Second):
+		t.Error("Real.After() did not fire")
+	}
+}
@@ -0,0 +1,113 @@
+package compose
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+)
+
+// maxArchiveFileSize limita el tamaño de cada archivo extraído de un archivo
+// tar, para no llenar el disco con entradas malformadas o maliciosas
+const maxArchiveFileSize = 10 << 20
+
+// ExtractArchive extrae de un archivo tar o tar.gz los archivos compose que
+// coinciden con patterns, junto con los .env de sus directorios, bajo
+// destDir y manteniendo sus rutas relativas. Devuelve las rutas extraídas.
+// Las entradas con rutas absolutas o que salen del archivo (../) son un
+// error; enlaces, directorios y el resto de archivos se ignoran.
+func ExtractArchive(archivePath, destDir string, patterns []string) ([]string, error) {
+	const op = "compose.ExtractArchive"
+
+	file, err := os.Open(archivePath) //nolint:gosec
+	if err != nil {
+		return nil, errors.Wrapf(op, err, "opening archive %s", archivePath)
+	}
+	defer func() { _ = file.Close() }()
+
+	reader, err := archiveReader(file)
+	if err != nil {
+		return nil, errors.Wrapf(op, err, "reading archive %s", archivePath)
+	}
+
+	matcher := &Scanner{}
+	var extracted []string
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(op, errors.ErrParseError, "reading archive %s: %v", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name, ok := archiveEntryPath(header.Name)
+		if !ok {
+			return nil, errors.Newf(op, "archive %s: entry %q escapes the archive", archivePath, header.Name)
+		}
+		if path.Base(name) != ".env" && !matcher.matchesPatterns(name, patterns) {
+			continue
+		}
+		if header.Size > maxArchiveFileSize {
+			return nil, errors.Newf(op, "archive %s: entry %s is larger than %d bytes", archivePath, name, maxArchiveFileSize)
+		}
+
+		target := filepath.Join(destDir, filepath.FromSlash(name))
+		if err := writeArchiveFile(target, tr); err != nil {
+			return nil, errors.Wrapf(op, err, "extracting %s", name)
+		}
+		extracted = append(extracted, target)
+	}
+
+	return extracted, nil
+}
+
+// archiveReader descomprime el archivo si empieza por la cabecera gzip
+func archiveReader(file io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(file)
+	magic, err := buffered.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(buffered)
+	}
+	return buffered, nil
+}
+
+// archiveEntryPath limpia el nombre de una entrada del archivo. ok es false
+// si la entrada es absoluta o sale del directorio de extracción
+func archiveEntryPath(name string) (string, bool) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", false
+	}
+	cleaned := path.Clean(name)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+	return cleaned, true
+}
+
+// writeArchiveFile copia el contenido de la entrada actual a target
+func writeArchiveFile(target string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, io.LimitReader(r, maxArchiveFileSize)); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,114 @@
+package compose
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// writeTestArchive crea un tar.gz con las entradas indicadas (ruta -> contenido)
+func writeTestArchive(t *testing.T, entries map[string]string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		content := entries[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("writing tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("writing tar entry: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("closing gzip: %v", err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "config.tar.gz")
+	if err := os.WriteFile(archive, buf.Bytes(), 0600); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+	return archive
+}
+
+func TestExtractArchive(t *testing.T) {
+	archive := writeTestArchive(t, map[string]string{
+		"docker-compose.yml":       "services:\n  web:\n    image: nginx:${NGINX_TAG}\n",
+		".env":                     "NGINX_TAG=1.20\n",
+		"stack/docker-compose.yml": "services:\n  db:\n    image: postgres:15\n",
+		"README.md":                "not a compose file",
+	})
+	destDir := t.TempDir()
+
+	extracted, err := ExtractArchive(archive, destDir, []string{"docker-compose.yml"})
+	if err != nil {
+		t.Fatalf("ExtractArchive() error = %v", err)
+	}
+
+	// Solo los archivos compose y los .env
+	want := []string{
+		filepath.Join(destDir, ".env"),
+		filepath.Join(destDir, "docker-compose.yml"),
+		filepath.Join(destDir, "stack", "docker-compose.yml"),
+	}
+	if !slices.Equal(extracted, want) {
+		t.Errorf("extracted = %v, want %v", extracted, want)
+	}
+
+	// Las imágenes se descubren como en un directorio, con las variables del .env
+	images, files, err := NewScanner().ScanDirectory(t.Context(), destDir, types.ScanConfig{Recursive: true, Patterns: []string{"docker-compose.yml"}})
+	if err != nil {
+		t.Fatalf("ScanDirectory() error = %v", err)
+	}
+	if len(files) != 2 || len(images) != 2 {
+		t.Fatalf("expected 2 files and 2 images, got %v and %v", files, images)
+	}
+	for _, image := range images {
+		if image.ServiceName == "web" && image.Tag != "1.20" {
+			t.Errorf("web tag = %s, want 1.20", image.Tag)
+		}
+	}
+}
+
+func TestExtractArchive_PathTraversal(t *testing.T) {
+	for _, name := range []string{"../docker-compose.yml", "stack/../../docker-compose.yml", "/etc/docker-compose.yml"} {
+		t.Run(name, func(t *testing.T) {
+			archive := writeTestArchive(t, map[string]string{name: "services: {}\n"})
+			destDir := t.TempDir()
+
+			if _, err := ExtractArchive(archive, destDir, []string{"docker-compose.yml"}); err == nil {
+				t.Fatal("expected an error for an entry outside the archive")
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(destDir), "docker-compose.yml")); err == nil {
+				t.Error("entry was written outside the destination directory")
+			}
+		})
+	}
+}
+
+func TestExtractArchive_NotAnArchive(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.tar")
+	if err := os.WriteFile(file, []byte("services:\n  web:\n    image: nginx\n"), 0600); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+
+	if _, err := ExtractArchive(file, t.TempDir(), nil); err == nil {
+		t.Error("expected an error for a file that is not a tar archive")
+	}
+}
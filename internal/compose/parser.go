@@ -2,69 +2,527 @@ package compose
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/user/docker-image-reporter/pkg/errors"
 	"github.com/user/docker-image-reporter/pkg/types"
+	"github.com/user/docker-image-reporter/pkg/utils"
 	yaml "gopkg.in/yaml.v3"
 )
 
+// imageRefRegex reconoce valores que parecen referencias de imagen con tag explícito
+// (ej: "golang:1.22", "ghcr.io/org/app:v1"). Se usa en el modo deep-parse para
+// evitar falsos positivos con build args arbitrarios.
+var imageRefRegex = regexp.MustCompile(`^(?:[a-zA-Z0-9.-]+(?::\d+)?/)?[a-z0-9]+(?:[._-][a-z0-9]+)*(?:/[a-z0-9]+(?:[._-][a-z0-9]+)*)*:[\w][\w.-]{0,127}(?:@sha256:[a-f0-9]{64})?$`)
+
+// Options configura el comportamiento opcional del parser
+type Options struct {
+	// DeepParse extrae también imágenes de build args y bloques x-* de nivel superior
+	DeepParse bool
+	// Logger recibe los avisos del parser (por defecto slog.Default())
+	Logger *slog.Logger
+}
+
 // Parser implementa la interfaz ComposeParser para parsear archivos docker-compose
-type Parser struct{}
+type Parser struct {
+	options Options
+}
 
 // NewParser crea una nueva instancia del parser
 func NewParser() *Parser {
-	return &Parser{}
+	return NewParserWithOptions(Options{})
+}
+
+// NewParserWithOptions crea una nueva instancia del parser con las opciones dadas
+func NewParserWithOptions(options Options) *Parser {
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+	return &Parser{options: options}
 }
 
 // ParseFile parsea un archivo docker-compose y extrae las imágenes Docker
 func (p *Parser) ParseFile(ctx context.Context, filePath string) ([]types.DockerImage, error) {
+	images, _, err := p.ParseFileWithServices(ctx, filePath)
+	return images, err
+}
+
+// ProjectName devuelve el nombre de proyecto declarado en el bloque
+// x-image-reporter de nivel superior del archivo, o "" si no declara ninguno
+// o no se puede leer
+func (p *Parser) ProjectName(filePath string) string {
+	expandedData, _, err := p.readExpanded(filePath)
+	if err != nil {
+		return ""
+	}
+	var compose ComposeFile
+	if err := unmarshalCompose([]byte(expandedData), &compose); err != nil || compose.ImageReporter == nil {
+		return ""
+	}
+	return strings.TrimSpace(compose.ImageReporter.Project)
+}
+
+// readExpanded lee el archivo y expande sus variables con el .env de su
+// directorio, si existe. Devuelve también las variables del .env
+func (p *Parser) readExpanded(filePath string) (string, map[string]string, error) {
 	data, err := os.ReadFile(filePath) //nolint:gosec
 	if err != nil {
-		return nil, errors.Wrapf("compose.ParseFile", err, "reading file %s", filePath)
+		return "", nil, err
 	}
 
 	// Load environment variables from .env file if it exists
 	envVars := make(map[string]string)
-	composeDir := filepath.Dir(filePath)
-	envFile := filepath.Join(composeDir, ".env")
+	envFile := filepath.Join(filepath.Dir(filePath), ".env")
 	if envData, err := os.ReadFile(envFile); err == nil { //nolint:gosec
 		// Parse .env file content
 		envVars = p.parseEnvFile(string(envData))
 	}
 
 	// Expand environment variables in the compose file content
-	expandedData := p.expandEnvVars(string(data), envVars)
+	return p.expandEnvVars(string(data), envVars), envVars, nil
+}
+
+// ParseFileWithServices es ParseFile que además devuelve cuántos servicios
+// declara el archivo, incluidos los que no aportan imagen (solo build:)
+func (p *Parser) ParseFileWithServices(ctx context.Context, filePath string) ([]types.DockerImage, int, error) {
+	expandedData, envVars, err := p.readExpanded(filePath)
+	if err != nil {
+		return nil, 0, errors.Wrapf("compose.ParseFile", err, "reading file %s", filePath)
+	}
 
 	var compose ComposeFile
-	if err := yaml.Unmarshal([]byte(expandedData), &compose); err != nil {
-		return nil, errors.Wrapf("compose.ParseFile", err, "parsing YAML file %s", filePath)
+	if err := unmarshalCompose([]byte(expandedData), &compose); err != nil {
+		return nil, 0, errors.Wrapf("compose.ParseFile", err, "parsing YAML file %s", filePath)
 	}
 
+	// Resolver extends antes de extraer imágenes, para que los servicios que
+	// heredan su imagen no se consideren sin imagen
+	p.resolveExtends(&compose, filePath, envVars)
+
+	// Los comentarios se pierden al decodificar en structs: leerlos del árbol YAML
+	directives := p.imageDirectives([]byte(expandedData), filePath)
+
 	var images []types.DockerImage
 	for serviceName, service := range compose.Services {
+		images = append(images, p.extraServiceImages(serviceName, service, filePath)...)
+
 		if service.Image == "" {
 			// Skip services without image (they might use build instead)
 			continue
 		}
 
+		directive := directives[serviceName]
+		if directive.ignore {
+			p.options.Logger.Debug("Skipping image ignored by comment", "service", serviceName, "image", service.Image, "file", filePath)
+			continue
+		}
+
 		image, err := p.parseImageString(service.Image)
 		if err != nil {
 			// Log warning but continue with other services
+			p.options.Logger.Warn("Skipping service image", "service", serviceName, "file", filePath, "error", err)
 			continue
 		}
 
 		// Add service context to the image for better tracking
 		image.ServiceName = serviceName
 		image.ComposeFile = filePath
+		image.NotifyChannel = service.Labels[types.NotifyChannelLabel]
+		image.Constraint = directive.allow
+		image.Built = service.Build != nil
+
+		images = append(images, image)
+	}
+
+	if p.options.DeepParse {
+		images = append(images, p.deepParseImages(compose, filePath, images)...)
+	}
+
+	return images, len(compose.Services), nil
+}
+
+// directivePrefix inicia los comentarios con directivas al final de una línea
+// image:, p. ej. "image: nginx:1.20 # image-reporter: allow=~1.20"
+const directivePrefix = "image-reporter:"
+
+// imageDirective son las directivas del comentario de la línea image: de un servicio
+type imageDirective struct {
+	ignore bool   // "ignore": no se comprueba la imagen
+	allow  string // "allow=<restricción semver>": limita las versiones candidatas
+}
+
+// imageDirectives lee los comentarios "# image-reporter: ..." al final de la
+// línea image: de cada servicio. Las directivas desconocidas o con una
+// restricción inválida se avisan y se ignoran.
+func (p *Parser) imageDirectives(data []byte, filePath string) map[string]imageDirective {
+	var root yaml.Node
+	if yaml.Unmarshal(data, &root) != nil || len(root.Content) == 0 {
+		return nil
+	}
+	services := mappingValue(root.Content[0], "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	directives := make(map[string]imageDirective)
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		serviceName := services.Content[i].Value
+		image := mappingValue(services.Content[i+1], "image")
+		if image == nil {
+			continue
+		}
+		comment := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(image.LineComment), "#"))
+		rest, ok := strings.CutPrefix(comment, directivePrefix)
+		if !ok {
+			continue
+		}
+
+		rest = strings.TrimSpace(rest)
+		switch {
+		case rest == "ignore":
+			directives[serviceName] = imageDirective{ignore: true}
+		case strings.HasPrefix(rest, "allow="):
+			constraint := strings.TrimSpace(strings.TrimPrefix(rest, "allow="))
+			if _, err := utils.FilterTagsByConstraint(nil, constraint); err != nil {
+				p.options.Logger.Warn("Ignoring image directive", "service", serviceName, "file", filePath, "error", err)
+				continue
+			}
+			directives[serviceName] = imageDirective{allow: constraint}
+		default:
+			p.options.Logger.Warn("Ignoring unknown image directive", "service", serviceName, "file", filePath, "directive", rest)
+		}
+	}
+	return directives
+}
+
+// mappingValue devuelve el valor de key en un nodo mapa, o nil si no está
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// unmarshalCompose decodifica un archivo compose. Si falla porque el archivo o
+// services no tienen la forma esperada (p. ej. services es una lista), devuelve
+// un error que lo explica en lugar del error de tipos de YAML.
+func unmarshalCompose(data []byte, compose *ComposeFile) error {
+	err := yaml.Unmarshal(data, compose)
+	if err == nil {
+		return nil
+	}
+
+	var root yaml.Node
+	if yaml.Unmarshal(data, &root) != nil || len(root.Content) == 0 {
+		return err
+	}
+	if shapeErr := checkComposeShape(root.Content[0]); shapeErr != nil {
+		return shapeErr
+	}
+	return err
+}
+
+// checkComposeShape comprueba que el documento sea un mapa y que services
+// sea un mapa de nombre de servicio a su configuración
+func checkComposeShape(doc *yaml.Node) error {
+	if doc.Kind != yaml.MappingNode {
+		return fmt.Errorf("%w: line %d: compose file must be a mapping of top-level keys (services, volumes...), found %s",
+			errors.ErrParseError, doc.Line, nodeKindName(doc))
+	}
+
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value != "services" {
+			continue
+		}
+		services := doc.Content[i+1]
+		if services.Kind == yaml.ScalarNode && services.Tag == "!!null" {
+			return nil
+		}
+		if services.Kind != yaml.MappingNode {
+			return fmt.Errorf("%w: line %d: services must be a mapping of service name to config, found %s",
+				errors.ErrParseError, services.Line, nodeKindName(services))
+		}
+		for j := 0; j+1 < len(services.Content); j += 2 {
+			name, config := services.Content[j], services.Content[j+1]
+			if config.Kind != yaml.MappingNode && config.Tag != "!!null" {
+				return fmt.Errorf("%w: line %d: service %q must be a mapping of config (image, ports...), found %s",
+					errors.ErrParseError, config.Line, name.Value, nodeKindName(config))
+			}
+		}
+	}
+	return nil
+}
+
+// nodeKindName describe el tipo de un nodo YAML para los mensajes de error
+func nodeKindName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.MappingNode:
+		return "a mapping"
+	default:
+		return fmt.Sprintf("the value %q", node.Value)
+	}
+}
+
+// extraServiceImages extrae las imágenes adicionales declaradas en
+// x-image-reporter.extra-images (sidecars, init containers...). Se asocian al
+// mismo servicio que la imagen principal.
+func (p *Parser) extraServiceImages(serviceName string, service Service, filePath string) []types.DockerImage {
+	if service.ImageReporter == nil {
+		return nil
+	}
+
+	var images []types.DockerImage
+	for _, ref := range service.ImageReporter.ExtraImages {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+
+		image, err := p.parseImageString(ref)
+		if err != nil {
+			p.options.Logger.Warn("Invalid extra image", "service", serviceName, "image", ref, "error", err)
+			continue
+		}
+
+		image.ServiceName = serviceName
+		image.ComposeFile = filePath
+		image.NotifyChannel = service.Labels[types.NotifyChannelLabel]
+		images = append(images, image)
+	}
+	return images
+}
+
+// resolveExtends completa la imagen de los servicios que usan extends y no la
+// definen, siguiendo la cadena en el mismo archivo o en otros archivos.
+// Los ciclos y los destinos inexistentes se registran como aviso.
+func (p *Parser) resolveExtends(compose *ComposeFile, filePath string, envVars map[string]string) {
+	resolver := &extendsResolver{
+		parser:  p,
+		envVars: envVars,
+		files:   map[string]*ComposeFile{filepath.Clean(filePath): compose},
+	}
+
+	for _, serviceName := range sortedKeys(compose.Services) {
+		service := compose.Services[serviceName]
+		if service.Image != "" || service.Extends == nil {
+			continue
+		}
 
+		image, err := resolver.resolveImage(filePath, serviceName, make(map[string]bool))
+		if err != nil {
+			p.options.Logger.Warn("Could not resolve extends", "service", serviceName, "file", filePath, "error", err)
+			continue
+		}
+
+		service.Image = image
+		compose.Services[serviceName] = service
+	}
+}
+
+// extendsResolver sigue cadenas de extends cacheando los archivos ya cargados
+type extendsResolver struct {
+	parser  *Parser
+	envVars map[string]string
+	files   map[string]*ComposeFile
+}
+
+// resolveImage devuelve la imagen efectiva de un servicio, heredándola vía
+// extends si el servicio no la define. Devuelve "" si ningún nivel la define.
+func (r *extendsResolver) resolveImage(filePath, serviceName string, visiting map[string]bool) (string, error) {
+	filePath = filepath.Clean(filePath)
+	key := filePath + "#" + serviceName
+	if visiting[key] {
+		return "", errors.Newf("compose.resolveImage", "extends cycle detected at service %s in %s", serviceName, filePath)
+	}
+	visiting[key] = true
+
+	compose, err := r.load(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	service, ok := compose.Services[serviceName]
+	if !ok {
+		return "", errors.Newf("compose.resolveImage", "extends target service %s not found in %s", serviceName, filePath)
+	}
+	if service.Image != "" {
+		return service.Image, nil
+	}
+
+	targetFile, targetService, ok := parseExtends(service.Extends)
+	if !ok {
+		return "", nil
+	}
+	if targetFile == "" {
+		targetFile = filePath
+	} else if !filepath.IsAbs(targetFile) {
+		targetFile = filepath.Join(filepath.Dir(filePath), targetFile)
+	}
+
+	return r.resolveImage(targetFile, targetService, visiting)
+}
+
+// load lee y parsea un archivo compose referenciado por extends
+func (r *extendsResolver) load(filePath string) (*ComposeFile, error) {
+	if compose, ok := r.files[filePath]; ok {
+		return compose, nil
+	}
+
+	data, err := os.ReadFile(filePath) //nolint:gosec
+	if err != nil {
+		return nil, errors.Wrapf("compose.load", err, "reading extends file %s", filePath)
+	}
+
+	var compose ComposeFile
+	if err := unmarshalCompose([]byte(r.parser.expandEnvVars(string(data), r.envVars)), &compose); err != nil {
+		return nil, errors.Wrapf("compose.load", err, "parsing extends file %s", filePath)
+	}
+
+	r.files[filePath] = &compose
+	return &compose, nil
+}
+
+// parseExtends interpreta la clave extends, que puede ser el nombre de un
+// servicio del mismo archivo o un mapa {file, service}
+func parseExtends(extends interface{}) (file, service string, ok bool) {
+	switch value := extends.(type) {
+	case string:
+		return "", value, value != ""
+	case map[string]interface{}:
+		service, _ = value["service"].(string)
+		file, _ = value["file"].(string)
+		return file, service, service != ""
+	}
+	return "", "", false
+}
+
+// deepParseImages extrae imágenes referenciadas en build args y en bloques x-*
+// de nivel superior. Las imágenes ya extraídas de servicios se omiten.
+func (p *Parser) deepParseImages(compose ComposeFile, filePath string, existing []types.DockerImage) []types.DockerImage {
+	seen := make(map[string]bool, len(existing))
+	for _, image := range existing {
+		seen[image.String()] = true
+	}
+
+	var images []types.DockerImage
+	add := func(name, ref string) {
+		image, err := p.parseImageString(ref)
+		if err != nil || seen[image.String()] {
+			return
+		}
+		seen[image.String()] = true
+		image.ServiceName = name
+		image.ComposeFile = filePath
 		images = append(images, image)
 	}
 
-	return images, nil
+	for _, serviceName := range sortedKeys(compose.Services) {
+		args := buildArgs(compose.Services[serviceName].Build)
+		for _, argName := range sortedKeys(args) {
+			if value := args[argName]; looksLikeImageRef(value) {
+				add(serviceName+".args."+argName, value)
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(compose.Extensions) {
+		if strings.HasPrefix(key, "x-") {
+			collectExtensionImages(key, compose.Extensions[key], add)
+		}
+	}
+
+	return images
+}
+
+// looksLikeImageRef indica si el valor de un build arg parece una referencia de
+// imagen. Un nombre de un solo segmento con tag numérico ("redis:6379",
+// "db:5432") se toma por host:puerto; esas imágenes necesitan registry o
+// namespace ("library/node:20") para detectarse.
+func looksLikeImageRef(value string) bool {
+	if !imageRefRegex.MatchString(value) {
+		return false
+	}
+	if strings.Contains(value, "/") {
+		return true
+	}
+	_, tag, _ := strings.Cut(value, ":")
+	tag, _, _ = strings.Cut(tag, "@")
+	return strings.Trim(tag, "0123456789") != ""
+}
+
+// buildArgs devuelve los args de una sección build en forma de mapa.
+// Soporta tanto la sintaxis de mapa como la de lista ("KEY=VALUE").
+func buildArgs(build interface{}) map[string]string {
+	buildMap, ok := build.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	args := make(map[string]string)
+	switch raw := buildMap["args"].(type) {
+	case map[string]interface{}:
+		for key, value := range raw {
+			if str, ok := value.(string); ok {
+				args[key] = strings.TrimSpace(str)
+			}
+		}
+	case []interface{}:
+		for _, item := range raw {
+			str, ok := item.(string)
+			if !ok {
+				continue
+			}
+			if key, value, found := strings.Cut(str, "="); found {
+				args[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	return args
+}
+
+// collectExtensionImages recorre un bloque x-* y llama a add por cada clave image
+func collectExtensionImages(path string, node interface{}, add func(name, ref string)) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			if key == "image" {
+				if ref, ok := value[key].(string); ok && ref != "" {
+					add(path, ref)
+				}
+				continue
+			}
+			collectExtensionImages(path+"."+key, value[key], add)
+		}
+	case []interface{}:
+		for i, item := range value {
+			collectExtensionImages(fmt.Sprintf("%s[%d]", path, i), item, add)
+		}
+	}
+}
+
+// sortedKeys devuelve las claves de un mapa ordenadas, para un resultado determinista
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // CanParse determina si el parser puede manejar el archivo dado
@@ -101,100 +559,33 @@ func (p *Parser) ParseImageString(imageStr string) (types.DockerImage, error) {
 
 // parseImageString parsea una string de imagen Docker en sus componentes
 func (p *Parser) parseImageString(imageStr string) (types.DockerImage, error) {
-	if imageStr == "" {
-		return types.DockerImage{}, errors.New("compose.parseImageString", "empty image string")
+	// Sin valor en .env ni por defecto, ${VAR} queda literal y produciría una
+	// referencia sin sentido que acabaría fallando contra el registro
+	if unresolved := unresolvedVariables(imageStr); len(unresolved) > 0 {
+		return types.DockerImage{}, errors.Wrapf("compose.parseImageString", errors.ErrInvalidImage,
+			"unresolved variables in image %s: %s", imageStr, strings.Join(unresolved, ", "))
 	}
 
-	// Remover espacios en blanco
-	imageStr = strings.TrimSpace(imageStr)
-
-	// Separar tag/digest
-	var tag, digest string
-
-	// Verificar si tiene digest (@sha256:...)
-	if strings.Contains(imageStr, "@") {
-		parts := strings.Split(imageStr, "@")
-		if len(parts) != 2 {
-			return types.DockerImage{}, errors.Newf("compose.parseImageString", "invalid image format with digest: %s", imageStr)
-		}
-		imageStr = parts[0]
-		digest = parts[1]
-	}
-
-	// Separar tag (:tag) - necesitamos ser cuidadosos con registries que tienen puerto
-	// Primero verificar si hay un / en la string - esto nos ayuda a distinguir registry:port/image de image:tag
-	if strings.Contains(imageStr, "/") {
-		// Hay un slash, buscar el último : después del último /
-		lastSlashIndex := strings.LastIndex(imageStr, "/")
-		afterSlash := imageStr[lastSlashIndex:]
-
-		if strings.Contains(afterSlash, ":") {
-			// Hay un : después del último /, probablemente es un tag
-			colonIndex := strings.LastIndex(imageStr, ":")
-			tag = imageStr[colonIndex+1:]
-			imageStr = imageStr[:colonIndex]
-		} else {
-			tag = "latest"
-		}
-	} else {
-		// No hay slash, parsing normal
-		parts := strings.Split(imageStr, ":")
-		switch len(parts) {
-		case 1:
-			tag = "latest"
-		case 2:
-			tag = parts[1]
-			imageStr = parts[0]
-		default:
-			// Múltiples :, usar el último como tag
-			lastColonIndex := strings.LastIndex(imageStr, ":")
-			tag = imageStr[lastColonIndex+1:]
-			imageStr = imageStr[:lastColonIndex]
-		}
+	image, err := types.ParseImageReference(imageStr)
+	if err != nil {
+		return types.DockerImage{}, errors.Wrap("compose.parseImageString", err)
 	}
-
-	// Parsear registry y repository
-	registry, repository := p.parseRegistryAndRepository(imageStr)
-
-	return types.DockerImage{
-		Registry:   registry,
-		Repository: repository,
-		Tag:        tag,
-		Digest:     digest,
-	}, nil
+	return image, nil
 }
 
-// parseRegistryAndRepository separa el registry del repository
-func (p *Parser) parseRegistryAndRepository(imageStr string) (string, string) {
-	parts := strings.Split(imageStr, "/")
-
-	switch len(parts) {
-	case 1:
-		// Solo nombre de imagen (ej: "nginx")
-		// Asumir Docker Hub con library/
-		return "docker.io", "library/" + parts[0]
+// unresolvedVarRegex encuentra las variables ${VAR} que quedaron sin expandir
+var unresolvedVarRegex = regexp.MustCompile(`\$\{([^}:]+)[^}]*\}`)
 
-	case 2:
-		// Puede ser:
-		// - usuario/imagen en Docker Hub (ej: "user/nginx")
-		// - registry/imagen (ej: "localhost:5000/nginx")
-
-		// Si el primer parte contiene un punto, dos puntos, o localhost, es un registry
-		if strings.Contains(parts[0], ".") || strings.Contains(parts[0], ":") || parts[0] == "localhost" {
-			return parts[0], parts[1]
+// unresolvedVariables devuelve los nombres de las variables sin expandir de
+// una referencia de imagen, sin repetir
+func unresolvedVariables(imageStr string) []string {
+	var names []string
+	for _, match := range unresolvedVarRegex.FindAllStringSubmatch(imageStr, -1) {
+		if !slices.Contains(names, match[1]) {
+			names = append(names, match[1])
 		}
-
-		// Si no, es usuario/imagen en Docker Hub
-		return "docker.io", imageStr
-
-	default:
-		// 3 o más partes: registry/namespace/imagen o registry/user/imagen
-		// El primer parte es el registry
-		registry := parts[0]
-		repository := strings.Join(parts[1:], "/")
-
-		return registry, repository
 	}
+	return names
 }
 
 // parseEnvFile parsea el contenido de un archivo .env y retorna un mapa de variables
@@ -263,8 +654,18 @@ func (p *Parser) expandEnvVars(content string, envVars map[string]string) string
 
 // ComposeFile representa la estructura de un archivo docker-compose
 type ComposeFile struct {
-	Version  string             `yaml:"version,omitempty"`
-	Services map[string]Service `yaml:"services"`
+	Version    string                 `yaml:"version,omitempty"`
+	Services   map[string]Service     `yaml:"services"`
+	Extensions map[string]interface{} `yaml:",inline"` // x-*, networks, volumes, etc.
+
+	ImageReporter *ProjectExtension `yaml:"x-image-reporter,omitempty"`
+}
+
+// ProjectExtension es el bloque x-image-reporter de nivel superior del archivo
+type ProjectExtension struct {
+	// Project es el nombre del proyecto en informes y notificaciones, en
+	// lugar del nombre del directorio escaneado
+	Project string `yaml:"project,omitempty"`
 }
 
 // Service representa un servicio en docker-compose
@@ -277,4 +678,13 @@ type Service struct {
 	DependsOn   interface{}       `yaml:"depends_on,omitempty"`
 	Networks    interface{}       `yaml:"networks,omitempty"`
 	Labels      map[string]string `yaml:"labels,omitempty"`
+	Extends     interface{}       `yaml:"extends,omitempty"` // string o {file, service}
+
+	ImageReporter *ImageReporterExtension `yaml:"x-image-reporter,omitempty"`
+}
+
+// ImageReporterExtension es el bloque x-image-reporter de un servicio
+type ImageReporterExtension struct {
+	// ExtraImages lista imágenes adicionales del servicio que también se comprueban
+	ExtraImages []string `yaml:"extra-images,omitempty"`
 }
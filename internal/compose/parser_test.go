@@ -1,9 +1,12 @@
 package compose
 
 import (
+	"bytes"
 	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/user/docker-image-reporter/pkg/types"
@@ -58,6 +61,15 @@ func TestParser_parseImageString(t *testing.T) {
 				Tag:        "latest",
 			},
 		},
+		{
+			name:     "single segment on a custom registry",
+			imageStr: "myregistry.com/app:2.1",
+			expectedImage: types.DockerImage{
+				Registry:   "myregistry.com",
+				Repository: "app",
+				Tag:        "2.1",
+			},
+		},
 		{
 			name:     "image with tag",
 			imageStr: "nginx:1.20",
@@ -123,6 +135,16 @@ func TestParser_parseImageString(t *testing.T) {
 				Digest:     "sha256:abc123",
 			},
 		},
+		{
+			name:     "registry with port and nested path",
+			imageStr: "registry:5000/a/b/c:1.2@sha256:abc123",
+			expectedImage: types.DockerImage{
+				Registry:   "registry:5000",
+				Repository: "a/b/c",
+				Tag:        "1.2",
+				Digest:     "sha256:abc123",
+			},
+		},
 		{
 			name:        "empty image",
 			imageStr:    "",
@@ -296,6 +318,52 @@ services:
 	}
 }
 
+func TestParser_ParseFile_MalformedServices(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "services as a list",
+			content: "services:\n  - web:\n      image: nginx:1.20\n",
+			wantErr: "line 2: services must be a mapping of service name to config, found a list",
+		},
+		{
+			name:    "service as a list",
+			content: "services:\n  web:\n    - image: nginx:1.20\n",
+			wantErr: `line 3: service "web" must be a mapping of config (image, ports...), found a list`,
+		},
+		{
+			name:    "top-level list",
+			content: "- services:\n    web:\n      image: nginx:1.20\n",
+			wantErr: "line 1: compose file must be a mapping of top-level keys (services, volumes...), found a list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			composeFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+			if err := os.WriteFile(composeFile, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			_, err := parser.ParseFile(context.Background(), composeFile)
+			if err == nil {
+				t.Fatal("Expected error for malformed services, but got none")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), composeFile) {
+				t.Errorf("error = %q, want it to name %s and contain %q", err, composeFile, tt.wantErr)
+			}
+			if strings.Contains(err.Error(), "cannot unmarshal") {
+				t.Errorf("error should not expose the raw YAML type error: %q", err)
+			}
+		})
+	}
+}
+
 func TestParser_ParseFile_WithEnvFile(t *testing.T) {
 	parser := NewParser()
 
@@ -460,3 +528,478 @@ services:
 		}
 	}
 }
+
+func TestParser_ParseFile_DeepParse(t *testing.T) {
+	tempDir := t.TempDir()
+	composeFile := filepath.Join(tempDir, "docker-compose.yml")
+
+	composeContent := `services:
+  api:
+    build:
+      context: .
+      args:
+        BASE_IMAGE: golang:1.22
+        APP_VERSION: "1.0.0"
+        REDIS_ADDR: redis:6379
+        DB_HOST: db:5432
+        NODE_IMAGE: library/node:20
+  worker:
+    build:
+      context: ./worker
+      args:
+        - RUNTIME_IMAGE=ghcr.io/org/runtime:v2.1.0
+        - DEBUG=true
+  web:
+    image: nginx:1.25
+
+x-templates:
+  cache: &cache
+    image: redis:7.2
+  proxy:
+    image: nginx:1.25
+`
+
+	if err := os.WriteFile(composeFile, []byte(composeContent), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		deepParse bool
+		expected  map[string]string // ServiceName -> imagen
+	}{
+		{
+			name:      "deep parse disabled",
+			deepParse: false,
+			expected: map[string]string{
+				"web": "docker.io/library/nginx:1.25",
+			},
+		},
+		{
+			name:      "deep parse enabled",
+			deepParse: true,
+			expected: map[string]string{
+				"web":                       "docker.io/library/nginx:1.25",
+				"api.args.BASE_IMAGE":       "docker.io/library/golang:1.22",
+				"api.args.NODE_IMAGE":       "docker.io/library/node:20",
+				"worker.args.RUNTIME_IMAGE": "ghcr.io/org/runtime:v2.1.0",
+				"x-templates.cache":         "docker.io/library/redis:7.2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParserWithOptions(Options{DeepParse: tt.deepParse})
+
+			images, err := parser.ParseFile(context.Background(), composeFile)
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+
+			if len(images) != len(tt.expected) {
+				t.Fatalf("Expected %d images, got %d: %v", len(tt.expected), len(images), images)
+			}
+
+			for _, image := range images {
+				want, ok := tt.expected[image.ServiceName]
+				if !ok {
+					t.Errorf("Unexpected image %s for %s", image.String(), image.ServiceName)
+					continue
+				}
+				if image.FullName() != want {
+					t.Errorf("%s image = %s, want %s", image.ServiceName, image.FullName(), want)
+				}
+				if image.ComposeFile != composeFile {
+					t.Errorf("%s ComposeFile = %s, want %s", image.ServiceName, image.ComposeFile, composeFile)
+				}
+			}
+		})
+	}
+}
+
+func TestParser_ParseFile_DeepParseOrder(t *testing.T) {
+	tempDir := t.TempDir()
+	composeFile := filepath.Join(tempDir, "docker-compose.yml")
+
+	// Dos args con la misma imagen: gana siempre el primero en orden alfabético
+	composeContent := `services:
+  api:
+    build:
+      args:
+        RUNTIME: alpine:3.19
+        BUILDER: golang:1.22
+        BASE: alpine:3.19
+`
+	if err := os.WriteFile(composeFile, []byte(composeContent), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	parser := NewParserWithOptions(Options{DeepParse: true})
+	for i := 0; i < 20; i++ {
+		images, err := parser.ParseFile(context.Background(), composeFile)
+		if err != nil {
+			t.Fatalf("ParseFile failed: %v", err)
+		}
+		var names []string
+		for _, image := range images {
+			names = append(names, image.ServiceName)
+		}
+		if got := strings.Join(names, ","); got != "api.args.BASE,api.args.BUILDER" {
+			t.Fatalf("images = %s, want api.args.BASE,api.args.BUILDER", got)
+		}
+	}
+}
+
+func TestParser_ParseFile_Extends(t *testing.T) {
+	tempDir := t.TempDir()
+
+	commonContent := `services:
+  base:
+    image: node:20-alpine
+  chained:
+    extends:
+      service: base
+`
+	if err := os.MkdirAll(filepath.Join(tempDir, "shared"), 0750); err != nil {
+		t.Fatalf("Failed to create shared dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "shared", "common.yml"), []byte(commonContent), 0600); err != nil {
+		t.Fatalf("Failed to create common file: %v", err)
+	}
+
+	composeFile := filepath.Join(tempDir, "docker-compose.yml")
+	composeContent := `services:
+  web:
+    image: nginx:1.25
+  same-file:
+    extends: web
+  same-file-override:
+    extends:
+      service: web
+    image: nginx:1.26
+  cross-file:
+    extends:
+      file: shared/common.yml
+      service: base
+  cross-file-chained:
+    extends:
+      file: shared/common.yml
+      service: chained
+  cross-file-override:
+    extends:
+      file: shared/common.yml
+      service: base
+    image: node:22-alpine
+  cycle-a:
+    extends: cycle-b
+  cycle-b:
+    extends: cycle-a
+  missing-service:
+    extends:
+      service: does-not-exist
+  missing-file:
+    extends:
+      file: nope.yml
+      service: base
+`
+	if err := os.WriteFile(composeFile, []byte(composeContent), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	images, err := NewParser().ParseFile(context.Background(), composeFile)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"web":                 "docker.io/library/nginx:1.25",
+		"same-file":           "docker.io/library/nginx:1.25",
+		"same-file-override":  "docker.io/library/nginx:1.26",
+		"cross-file":          "docker.io/library/node:20-alpine",
+		"cross-file-chained":  "docker.io/library/node:20-alpine",
+		"cross-file-override": "docker.io/library/node:22-alpine",
+	}
+
+	got := make(map[string]string, len(images))
+	for _, image := range images {
+		got[image.ServiceName] = image.FullName()
+		if image.ComposeFile != composeFile {
+			t.Errorf("%s ComposeFile = %s, want %s", image.ServiceName, image.ComposeFile, composeFile)
+		}
+	}
+
+	if len(got) != len(expected) {
+		t.Errorf("Expected %d images, got %d: %v", len(expected), len(got), got)
+	}
+	for service, want := range expected {
+		if got[service] != want {
+			t.Errorf("%s image = %q, want %q", service, got[service], want)
+		}
+	}
+}
+
+func TestParser_ParseFile_ExtraImages(t *testing.T) {
+	tempDir := t.TempDir()
+	composeFile := filepath.Join(tempDir, "docker-compose.yml")
+
+	composeContent := `services:
+  app:
+    image: ghcr.io/org/app:1.4.0
+    x-image-reporter:
+      extra-images:
+        - busybox:1.36
+  db:
+    image: postgres:16
+`
+
+	if err := os.WriteFile(composeFile, []byte(composeContent), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	images, err := NewParser().ParseFile(context.Background(), composeFile)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	// Las imágenes extra quedan asociadas al mismo servicio que la principal
+	got := map[string]string{}
+	for _, image := range images {
+		if image.ComposeFile != composeFile {
+			t.Errorf("%s ComposeFile = %s, want %s", image.FullName(), image.ComposeFile, composeFile)
+		}
+		got[image.FullName()] = image.ServiceName
+	}
+
+	expected := map[string]string{
+		"ghcr.io/org/app:1.4.0":          "app",
+		"docker.io/library/busybox:1.36": "app",
+		"docker.io/library/postgres:16":  "db",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d images, got %d: %v", len(expected), len(images), images)
+	}
+	for ref, service := range expected {
+		if got[ref] != service {
+			t.Errorf("image %s service = %q, want %q", ref, got[ref], service)
+		}
+	}
+}
+
+func TestParser_ParseFile_NotifyChannelLabel(t *testing.T) {
+	tempDir := t.TempDir()
+	composeFile := filepath.Join(tempDir, "docker-compose.yml")
+
+	composeContent := `services:
+  app:
+    image: ghcr.io/org/app:1.4.0
+    labels:
+      image-reporter.notify-channel: telegram:team-a
+    x-image-reporter:
+      extra-images:
+        - busybox:1.36
+  db:
+    image: postgres:16
+`
+
+	if err := os.WriteFile(composeFile, []byte(composeContent), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	images, err := NewParser().ParseFile(context.Background(), composeFile)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	// El canal del servicio se aplica también a sus imágenes extra
+	expected := map[string]string{
+		"ghcr.io/org/app:1.4.0":          "telegram:team-a",
+		"docker.io/library/busybox:1.36": "telegram:team-a",
+		"docker.io/library/postgres:16":  "",
+	}
+	for _, image := range images {
+		if want := expected[image.FullName()]; image.NotifyChannel != want {
+			t.Errorf("%s NotifyChannel = %q, want %q", image.FullName(), image.NotifyChannel, want)
+		}
+	}
+}
+
+func TestParser_ParseFile_ImageDirectives(t *testing.T) {
+	tempDir := t.TempDir()
+	composeFile := filepath.Join(tempDir, "docker-compose.yml")
+
+	composeContent := `services:
+  web:
+    image: nginx:1.20 # image-reporter: allow=~1.20
+  legacy:
+    image: mysql:5.7 # image-reporter: ignore
+  cache:
+    image: redis:7 # renovate: datasource=docker
+  db:
+    image: postgres:16 # image-reporter: allow=not-a-constraint
+`
+
+	if err := os.WriteFile(composeFile, []byte(composeContent), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	images, err := NewParser().ParseFile(context.Background(), composeFile)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	// legacy se omite; los comentarios ajenos o inválidos no añaden restricción
+	expected := map[string]string{
+		"web":   "~1.20",
+		"cache": "",
+		"db":    "",
+	}
+	if len(images) != len(expected) {
+		t.Fatalf("Expected %d images, got %d", len(expected), len(images))
+	}
+	for _, image := range images {
+		want, ok := expected[image.ServiceName]
+		if !ok {
+			t.Errorf("unexpected image for service %s", image.ServiceName)
+			continue
+		}
+		if image.Constraint != want {
+			t.Errorf("%s Constraint = %q, want %q", image.ServiceName, image.Constraint, want)
+		}
+	}
+}
+
+func TestParser_ParseFile_BuildAndImage(t *testing.T) {
+	tempDir := t.TempDir()
+	composeFile := filepath.Join(tempDir, "docker-compose.yml")
+
+	composeContent := `services:
+  api:
+    build: ./api
+    image: myorg/api:1.0
+  worker:
+    build:
+      context: ./worker
+    image: ghcr.io/myorg/worker:2.1
+  db:
+    image: postgres:15
+`
+
+	if err := os.WriteFile(composeFile, []byte(composeContent), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	images, err := NewParser().ParseFile(context.Background(), composeFile)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	// Con build: la imagen es el tag de la construcción local; sin él, una imagen del registro
+	expected := map[string]bool{"api": true, "worker": true, "db": false}
+	if len(images) != len(expected) {
+		t.Fatalf("Expected %d images, got %d", len(expected), len(images))
+	}
+	for _, image := range images {
+		if want := expected[image.ServiceName]; image.Built != want {
+			t.Errorf("%s Built = %v, want %v", image.ServiceName, image.Built, want)
+		}
+	}
+}
+
+func TestParser_ParseFile_UnresolvedVariables(t *testing.T) {
+	tempDir := t.TempDir()
+	composeFile := filepath.Join(tempDir, "docker-compose.yml")
+
+	// TAG tiene valor en .env; REGISTRY y APP no están definidas
+	composeContent := `services:
+  app:
+    image: ${REGISTRY}/${APP}:${TAG}
+  web:
+    image: nginx:${TAG}
+  cache:
+    image: redis:${REDIS_TAG:-7}
+`
+	if err := os.WriteFile(composeFile, []byte(composeContent), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("TAG=1.25\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env file: %v", err)
+	}
+
+	var logs bytes.Buffer
+	parser := NewParserWithOptions(Options{Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+
+	images, err := parser.ParseFile(context.Background(), composeFile)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	// Solo app se omite; las variables resueltas o con valor por defecto funcionan
+	services := make(map[string]string, len(images))
+	for _, image := range images {
+		services[image.ServiceName] = image.String()
+	}
+	want := map[string]string{"web": "library/nginx:1.25", "cache": "library/redis:7"}
+	if len(services) != len(want) {
+		t.Fatalf("images = %v, want %v", services, want)
+	}
+	for service, ref := range want {
+		if services[service] != ref {
+			t.Errorf("%s image = %q, want %q", service, services[service], ref)
+		}
+	}
+
+	if !strings.Contains(logs.String(), "unresolved variables in image ${REGISTRY}/${APP}:1.25: REGISTRY, APP") {
+		t.Errorf("expected an unresolved variables warning, got logs:\n%s", logs.String())
+	}
+}
+
+func TestParser_ProjectName(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     string
+		want    string
+	}{
+		{
+			name:    "declared project",
+			content: "x-image-reporter:\n  project: \"Media Stack\"\nservices:\n  web:\n    image: nginx:1.25\n",
+			want:    "Media Stack",
+		},
+		{
+			name:    "project from .env",
+			content: "x-image-reporter:\n  project: ${STACK_NAME}\nservices:\n  web:\n    image: nginx:1.25\n",
+			env:     "STACK_NAME=home-lab\n",
+			want:    "home-lab",
+		},
+		{
+			name:    "no extension",
+			content: "services:\n  web:\n    image: nginx:1.25\n",
+			want:    "",
+		},
+		{
+			name:    "invalid file",
+			content: "services: [\n",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			composeFile := filepath.Join(dir, "docker-compose.yml")
+			if err := os.WriteFile(composeFile, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			if tt.env != "" {
+				if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(tt.env), 0600); err != nil {
+					t.Fatalf("Failed to create .env file: %v", err)
+				}
+			}
+
+			if got := NewParser().ProjectName(composeFile); got != tt.want {
+				t.Errorf("ProjectName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
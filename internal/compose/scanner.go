@@ -3,6 +3,7 @@ package compose
 import (
 	"context"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -100,6 +101,13 @@ func (s *Scanner) FindComposeFiles(ctx context.Context, rootPath string, config
 
 // walkDirectory camina por el directorio aplicando la función a cada archivo
 func (s *Scanner) walkDirectory(ctx context.Context, rootPath string, config types.ScanConfig, fn func(string) error) error {
+	if config.Recursive && config.FollowSymlinks {
+		realRoot, err := filepath.EvalSymlinks(rootPath)
+		if err != nil {
+			realRoot = rootPath
+		}
+		return s.walkFollowingSymlinks(rootPath, realRoot, make(map[string]bool), fn)
+	}
 	if config.Recursive {
 		return filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
@@ -160,6 +168,65 @@ func (s *Scanner) walkDirectory(ctx context.Context, rootPath string, config typ
 	return nil
 }
 
+// walkFollowingSymlinks recorre realDir como walkDirectory, pero entra
+// también en los directorios enlazados. Cada directorio y archivo real se
+// visita una sola vez, así un enlace a un directorio padre no crea un bucle
+// y un archivo enlazado no se escanea dos veces. Las rutas se informan bajo
+// dir, tal como se llega a ellas desde la raíz del escaneo.
+func (s *Scanner) walkFollowingSymlinks(dir, realDir string, visited map[string]bool, fn func(string) error) error {
+	return filepath.WalkDir(realDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip directories that can't be accessed
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		shown := dir
+		if rel, err := filepath.Rel(realDir, path); err == nil {
+			shown = filepath.Join(dir, rel)
+		}
+
+		if d.IsDir() {
+			if s.shouldSkipDirectory(d.Name()) || visited[path] {
+				return filepath.SkipDir
+			}
+			visited[path] = true
+			return nil
+		}
+
+		// Skip hidden files
+		if strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
+		target := path
+		if d.Type()&fs.ModeSymlink != 0 {
+			// Los enlaces rotos se ignoran
+			if target, err = filepath.EvalSymlinks(path); err != nil {
+				return nil
+			}
+			info, err := os.Stat(target)
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				if s.shouldSkipDirectory(d.Name()) {
+					return nil
+				}
+				return s.walkFollowingSymlinks(shown, target, visited, fn)
+			}
+		}
+
+		if visited[target] {
+			return nil
+		}
+		visited[target] = true
+		return fn(shown)
+	})
+}
+
 // matchesPatterns verifica si un archivo coincide con los patrones configurados
 func (s *Scanner) matchesPatterns(filePath string, patterns []string) bool {
 	if len(patterns) == 0 {
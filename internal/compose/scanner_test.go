@@ -4,6 +4,8 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"testing"
 
 	"github.com/user/docker-image-reporter/pkg/types"
@@ -117,6 +119,74 @@ func TestScanner_FindComposeFiles(t *testing.T) {
 	}
 }
 
+func TestScanner_FindComposeFiles_FollowSymlinks(t *testing.T) {
+	scanner := NewScanner()
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	shared := filepath.Join(base, "shared", "media")
+
+	content := "services:\n  web:\n    image: nginx:1.25\n"
+	for _, file := range []string{filepath.Join(root, "docker-compose.yml"), filepath.Join(shared, "docker-compose.yml")} {
+		if err := os.MkdirAll(filepath.Dir(file), 0750); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to create file %s: %v", file, err)
+		}
+	}
+
+	// root/media apunta a un directorio fuera de la raíz; shared/media/loop
+	// vuelve a la raíz y crearía un bucle si no se detectara; root/compose.yml
+	// enlaza a un archivo ya escaneado
+	links := map[string]string{
+		filepath.Join(root, "media"):       shared,
+		filepath.Join(shared, "loop"):      root,
+		filepath.Join(root, "compose.yml"): filepath.Join(root, "docker-compose.yml"),
+		filepath.Join(root, "broken"):      filepath.Join(base, "missing"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	config := types.ScanConfig{Recursive: true, Patterns: []string{"docker-compose.yml", "compose.yml"}}
+
+	relative := func(files []string) []string {
+		var rel []string
+		for _, file := range files {
+			r, err := filepath.Rel(root, file)
+			if err != nil {
+				t.Fatalf("Failed to get relative path: %v", err)
+			}
+			rel = append(rel, r)
+		}
+		sort.Strings(rel)
+		return rel
+	}
+
+	// Por defecto los directorios enlazados no se recorren
+	files, err := scanner.FindComposeFiles(context.Background(), root, config)
+	if err != nil {
+		t.Fatalf("FindComposeFiles failed: %v", err)
+	}
+	if got := relative(files); slices.Contains(got, filepath.Join("media", "docker-compose.yml")) {
+		t.Errorf("symlinked directory scanned without FollowSymlinks: %v", got)
+	}
+
+	// Con FollowSymlinks se encuentra el compose enlazado con la ruta del
+	// enlace, y el archivo de la raíz una sola vez por cualquiera de sus rutas
+	config.FollowSymlinks = true
+	files, err = scanner.FindComposeFiles(context.Background(), root, config)
+	if err != nil {
+		t.Fatalf("FindComposeFiles failed: %v", err)
+	}
+	got := relative(files)
+	if len(got) != 2 || !slices.Contains(got, filepath.Join("media", "docker-compose.yml")) {
+		t.Errorf("FindComposeFiles() = %v, want the root compose file once and media/docker-compose.yml", got)
+	}
+}
+
 func TestScanner_ScanDirectory(t *testing.T) {
 	scanner := NewScanner()
 
@@ -0,0 +1,162 @@
+package compose
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// DefaultDebounce agrupa las ráfagas de eventos de un guardado (editores que
+// escriben, renombran y vuelven a escribir) en un solo aviso
+const DefaultDebounce = 2 * time.Second
+
+// Watcher observa un directorio y avisa cuando cambia un archivo compose
+type Watcher struct {
+	scanner  *Scanner
+	config   types.ScanConfig
+	debounce time.Duration
+	logger   *slog.Logger
+}
+
+// NewWatcher crea un watcher que aplica los patrones, la recursividad y los
+// directorios omitidos de config, igual que ScanDirectory
+func NewWatcher(config types.ScanConfig, debounce time.Duration, logger *slog.Logger) *Watcher {
+	return &Watcher{
+		scanner:  NewScanner(),
+		config:   config,
+		debounce: debounce,
+		logger:   logger,
+	}
+}
+
+// Watch empieza a observar rootPath y devuelve un canal que recibe un valor
+// cada vez que se crea, modifica o renombra un archivo que coincide con los
+// patrones o el .env que lo acompaña, una vez transcurrido debounce sin nuevos
+// eventos. Los avisos pendientes se agrupan en uno. El watcher se cierra al cancelar ctx.
+func (w *Watcher) Watch(ctx context.Context, rootPath string) (<-chan struct{}, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap("compose.Watch", err)
+	}
+
+	if err := w.addDirectories(fsWatcher, rootPath); err != nil {
+		_ = fsWatcher.Close()
+		return nil, errors.Wrapf("compose.Watch", err, "watching %s", rootPath)
+	}
+
+	changes := make(chan struct{}, 1)
+	go w.loop(ctx, fsWatcher, changes)
+	return changes, nil
+}
+
+// loop procesa los eventos hasta que se cancela ctx
+func (w *Watcher) loop(ctx context.Context, fsWatcher *fsnotify.Watcher, changes chan<- struct{}) {
+	defer fsWatcher.Close() //nolint:errcheck
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if w.handleEvent(fsWatcher, event) {
+				pending = time.After(w.debounce)
+			}
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Warn("File watcher error", "error", err)
+		case <-pending:
+			pending = nil
+			select {
+			case changes <- struct{}{}:
+			default:
+				// Ya hay un aviso pendiente de consumir
+			}
+		}
+	}
+}
+
+// handleEvent indica si event debe disparar un nuevo escaneo. Los directorios
+// creados en modo recursivo se añaden al watcher.
+func (w *Watcher) handleEvent(fsWatcher *fsnotify.Watcher, event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+
+	if event.Has(fsnotify.Create) && w.config.Recursive {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := w.addDirectories(fsWatcher, event.Name); err != nil {
+				w.logger.Warn("Failed to watch new directory", "path", event.Name, "error", err)
+			}
+			return false
+		}
+	}
+
+	name := filepath.Base(event.Name)
+	// El .env junto a un compose cambia las variables que se interpolan en él
+	if name == ".env" {
+		return w.hasComposeFile(filepath.Dir(event.Name))
+	}
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	return w.isComposeFile(event.Name)
+}
+
+// isComposeFile indica si path es un archivo compose que coincide con los patrones
+func (w *Watcher) isComposeFile(path string) bool {
+	return w.scanner.parser.CanParse(path) && w.scanner.matchesPatterns(path, w.config.Patterns)
+}
+
+// hasComposeFile indica si dir contiene algún archivo compose observado
+func (w *Watcher) hasComposeFile(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && w.isComposeFile(filepath.Join(dir, entry.Name())) {
+			return true
+		}
+	}
+	return false
+}
+
+// addDirectories añade root y, en modo recursivo, sus subdirectorios no
+// omitidos al watcher
+func (w *Watcher) addDirectories(fsWatcher *fsnotify.Watcher, root string) error {
+	if !w.config.Recursive {
+		return fsWatcher.Add(root)
+	}
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Omitir los directorios inaccesibles, como ScanDirectory
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && w.scanner.shouldSkipDirectory(d.Name()) {
+			return filepath.SkipDir
+		}
+		return fsWatcher.Add(path)
+	})
+}
@@ -0,0 +1,82 @@
+package compose
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+func TestWatcher_Watch(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := filepath.Join(dir, "stack", "docker-compose.yml")
+	for _, path := range []string{composeFile, filepath.Join(dir, "node_modules", "docker-compose.yml")} {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatalf("creating dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("services: {}\n"), 0600); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := types.ScanConfig{Recursive: true, Patterns: []string{"docker-compose.yml"}}
+	watcher := NewWatcher(config, 50*time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	changes, err := watcher.Watch(ctx, dir)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	write := func(path string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("services:\n  web:\n    image: nginx:1.25\n"), 0600); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+	}
+	expectChange := func(want bool) {
+		t.Helper()
+		select {
+		case <-changes:
+			if !want {
+				t.Fatal("unexpected change notification")
+			}
+		case <-time.After(500 * time.Millisecond):
+			if want {
+				t.Fatal("expected a change notification")
+			}
+		}
+	}
+
+	// Archivos que no coinciden con los patrones o en directorios omitidos no disparan nada
+	write(filepath.Join(dir, "notes.yml"))
+	write(filepath.Join(dir, "node_modules", "docker-compose.yml"))
+	expectChange(false)
+
+	// Modificar un compose observado dispara un único aviso aunque haya varias escrituras
+	write(composeFile)
+	write(composeFile)
+	expectChange(true)
+	expectChange(false)
+
+	// El .env junto a un compose observado dispara un aviso; uno sin compose al lado no
+	write(filepath.Join(dir, ".env"))
+	expectChange(false)
+	write(filepath.Join(dir, "stack", ".env"))
+	expectChange(true)
+
+	// Los directorios creados después también se observan
+	newFile := filepath.Join(dir, "new", "docker-compose.yml")
+	if err := os.Mkdir(filepath.Dir(newFile), 0750); err != nil {
+		t.Fatalf("creating dir: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	write(newFile)
+	expectChange(true)
+}
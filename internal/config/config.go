@@ -1,13 +1,18 @@
 package config
 
 import (
+	"maps"
+	"net/mail"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/user/docker-image-reporter/pkg/errors"
 	"github.com/user/docker-image-reporter/pkg/types"
+	"github.com/user/docker-image-reporter/pkg/utils"
 	yaml "gopkg.in/yaml.v3"
 )
 
@@ -18,6 +23,13 @@ const (
 
 // Load carga la configuración desde archivo y variables de entorno
 func Load(configPath string) (*types.Config, error) {
+	return LoadProfile(configPath, "")
+}
+
+// LoadProfile es Load aplicando además el perfil profile del archivo sobre la
+// configuración base, antes de las variables de entorno. Un perfil vacío
+// carga solo la base; uno que no existe es un error.
+func LoadProfile(configPath, profile string) (*types.Config, error) {
 	cfg := DefaultConfig()
 
 	// Si no se especifica path, usar el directorio home del usuario
@@ -37,6 +49,12 @@ func Load(configPath string) (*types.Config, error) {
 		}
 	}
 
+	if profile != "" {
+		if err := ApplyProfile(cfg, profile); err != nil {
+			return nil, errors.Wrap("config.Load", err)
+		}
+	}
+
 	// Sobrescribir con variables de entorno
 	loadFromEnv(cfg)
 
@@ -51,12 +69,13 @@ func Load(configPath string) (*types.Config, error) {
 // DefaultConfig devuelve la configuración por defecto
 func DefaultConfig() *types.Config {
 	return &types.Config{
+		SchemaVersion: CurrentSchemaVersion,
 		Telegram: types.TelegramConfig{
-			Enabled:  false,
-			Template: defaultTelegramTemplate(),
+			Enabled: false,
 		},
 		Registry: types.RegistryConfig{
 			Timeout: 30,
+			MaxTags: utils.DefaultMaxTags,
 		},
 		Scan: types.ScanConfig{
 			Recursive: true,
@@ -66,6 +85,10 @@ func DefaultConfig() *types.Config {
 				"compose.yml",
 			},
 			Timeout: 300, // 5 minutos
+			RollingImages: []string{
+				"gcr.io/distroless/*",
+				"cgr.dev/chainguard/*",
+			},
 		},
 	}
 }
@@ -77,6 +100,8 @@ func loadFromFile(cfg *types.Config, filePath string) error {
 		return err
 	}
 
+	// Un archivo sin schema_version es anterior al versionado (versión 0)
+	cfg.SchemaVersion = 0
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return errors.Wrapf("config.loadFromFile", err, "parsing YAML file %s", filePath)
 	}
@@ -84,6 +109,102 @@ func loadFromFile(cfg *types.Config, filePath string) error {
 	return nil
 }
 
+// ApplyProfile mezcla el perfil name sobre cfg: las claves del perfil
+// sustituyen a las de la base, los mapas se combinan y el resto de la base
+// se mantiene
+func ApplyProfile(cfg *types.Config, name string) error {
+	values, ok := cfg.Profiles[name]
+	if !ok {
+		return errors.Newf("config.ApplyProfile", "profile %q not found (available: %s)", name, strings.Join(ProfileNames(cfg), ", "))
+	}
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return errors.Wrapf("config.ApplyProfile", err, "encoding profile %s", name)
+	}
+	profiles := cfg.Profiles
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return errors.Wrapf("config.ApplyProfile", err, "parsing profile %s", name)
+	}
+	cfg.Profiles = profiles
+	return nil
+}
+
+// ProfileNames devuelve los nombres de los perfiles de cfg, ordenados
+func ProfileNames(cfg *types.Config) []string {
+	return slices.Sorted(maps.Keys(cfg.Profiles))
+}
+
+// UpdateProfile guarda en el perfil name de cfg los valores en que updated
+// difiere de current, que es la configuración con ese perfil aplicada antes
+// del cambio. Así el perfil solo recoge las claves modificadas y la base no
+// cambia. El perfil se crea si no existe.
+func UpdateProfile(cfg *types.Config, name string, current, updated *types.Config) error {
+	before, err := configMap(current)
+	if err != nil {
+		return errors.Wrap("config.UpdateProfile", err)
+	}
+	after, err := configMap(updated)
+	if err != nil {
+		return errors.Wrap("config.UpdateProfile", err)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]map[string]interface{})
+	}
+	profile := cfg.Profiles[name]
+	if profile == nil {
+		profile = make(map[string]interface{})
+	}
+	mergeChanges(profile, before, after)
+	cfg.Profiles[name] = profile
+	return nil
+}
+
+// configMap convierte cfg en el mapa genérico de su YAML, sin los perfiles
+func configMap(cfg *types.Config) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	delete(doc, "profiles")
+	return doc, nil
+}
+
+// mergeChanges copia a dst las claves en que after difiere de before,
+// bajando por las secciones anidadas. Una clave que desaparece (un valor
+// vaciado con omitempty) se guarda como null para vaciarla también al
+// aplicar el perfil
+func mergeChanges(dst, before, after map[string]interface{}) {
+	for key, value := range after {
+		nestedAfter, afterIsMap := value.(map[string]interface{})
+		nestedBefore, beforeIsMap := before[key].(map[string]interface{})
+		if afterIsMap && beforeIsMap {
+			nestedDst, ok := dst[key].(map[string]interface{})
+			if !ok {
+				nestedDst = make(map[string]interface{})
+			}
+			mergeChanges(nestedDst, nestedBefore, nestedAfter)
+			if len(nestedDst) > 0 {
+				dst[key] = nestedDst
+			}
+			continue
+		}
+		if !reflect.DeepEqual(value, before[key]) {
+			dst[key] = value
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			dst[key] = nil
+		}
+	}
+}
+
 // loadFromEnv carga configuración desde variables de entorno
 func loadFromEnv(cfg *types.Config) {
 	// Telegram configuration
@@ -98,12 +219,109 @@ func loadFromEnv(cfg *types.Config) {
 			cfg.Telegram.Enabled = val
 		}
 	}
+	if minUpdateType := os.Getenv("TELEGRAM_MIN_UPDATE_TYPE"); minUpdateType != "" {
+		cfg.Telegram.MinUpdateType = strings.ToLower(minUpdateType)
+	}
+
+	// Slack configuration
+	if enabled := os.Getenv("SLACK_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			cfg.Slack.Enabled = val
+		}
+	}
+	if webhookURL := os.Getenv("SLACK_WEBHOOK_URL"); webhookURL != "" {
+		cfg.Slack.WebhookURL = webhookURL
+	}
+
+	// Discord configuration
+	if enabled := os.Getenv("DISCORD_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			cfg.Discord.Enabled = val
+		}
+	}
+	if webhookURL := os.Getenv("DISCORD_WEBHOOK_URL"); webhookURL != "" {
+		cfg.Discord.WebhookURL = webhookURL
+	}
+
+	// Email configuration
+	if enabled := os.Getenv("EMAIL_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			cfg.Email.Enabled = val
+		}
+	}
+	if host := os.Getenv("EMAIL_HOST"); host != "" {
+		cfg.Email.Host = host
+	}
+	if port := os.Getenv("EMAIL_PORT"); port != "" {
+		if val, err := strconv.Atoi(port); err == nil {
+			cfg.Email.Port = val
+		}
+	}
+	if username := os.Getenv("EMAIL_USERNAME"); username != "" {
+		cfg.Email.Username = username
+	}
+	if password := os.Getenv("EMAIL_PASSWORD"); password != "" {
+		cfg.Email.Password = password
+	}
+	if from := os.Getenv("EMAIL_FROM"); from != "" {
+		cfg.Email.From = from
+	}
+	if to := os.Getenv("EMAIL_TO"); to != "" {
+		cfg.Email.To = splitList(to)
+	}
+
+	// Webhook configuration
+	if enabled := os.Getenv("WEBHOOK_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			cfg.Webhook.Enabled = val
+		}
+	}
+	if url := os.Getenv("WEBHOOK_URL"); url != "" {
+		cfg.Webhook.URL = url
+	}
+	if secret := os.Getenv("WEBHOOK_SECRET"); secret != "" {
+		cfg.Webhook.Secret = secret
+	}
 
 	// GitHub Container Registry token
 	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
 		cfg.Registry.GHCRToken = token
 	}
 
+	// Docker Hub credentials
+	if username := os.Getenv("DOCKER_USERNAME"); username != "" {
+		cfg.Registry.DockerHub.Username = username
+	}
+	if password := os.Getenv("DOCKER_PASSWORD"); password != "" {
+		cfg.Registry.DockerHub.Password = password
+	}
+	if token := os.Getenv("DOCKER_TOKEN"); token != "" {
+		cfg.Registry.DockerHub.Token = token
+	}
+	if mirror := os.Getenv("DOCKER_HUB_MIRROR"); mirror != "" {
+		cfg.Registry.DockerHub.Mirror = mirror
+	}
+
+	// Quay
+	if enabled := os.Getenv("QUAY_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			cfg.Registry.Quay.Enabled = val
+		}
+	}
+	if token := os.Getenv("QUAY_TOKEN"); token != "" {
+		cfg.Registry.Quay.Token = token
+	}
+
+	// GitLab
+	if enabled := os.Getenv("GITLAB_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			cfg.Registry.GitLab.Enabled = val
+		}
+	}
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		cfg.Registry.GitLab.Token = token
+	}
+
 	// Registry timeout
 	if timeout := os.Getenv("REGISTRY_TIMEOUT"); timeout != "" {
 		if val, err := strconv.Atoi(timeout); err == nil && val > 0 {
@@ -111,6 +329,22 @@ func loadFromEnv(cfg *types.Config) {
 		}
 	}
 
+	if maxTags := os.Getenv("REGISTRY_MAX_TAGS"); maxTags != "" {
+		if val, err := strconv.Atoi(maxTags); err == nil && val >= 0 {
+			cfg.Registry.MaxTags = val
+		}
+	}
+
+	if trustedHosts := os.Getenv("REGISTRY_TRUSTED_HOSTS"); trustedHosts != "" {
+		cfg.Registry.TrustedHosts = splitList(trustedHosts)
+	}
+
+	if authFromEnv := os.Getenv("REGISTRY_AUTH_FROM_ENV"); authFromEnv != "" {
+		if val, err := strconv.ParseBool(authFromEnv); err == nil {
+			cfg.Registry.AuthFromEnv = val
+		}
+	}
+
 	// Scan configuration
 	if recursive := os.Getenv("SCAN_RECURSIVE"); recursive != "" {
 		if val, err := strconv.ParseBool(recursive); err == nil {
@@ -129,6 +363,54 @@ func loadFromEnv(cfg *types.Config) {
 			cfg.Scan.Timeout = val
 		}
 	}
+	if priority := os.Getenv("SCAN_PRIORITY"); priority != "" {
+		cfg.Scan.Priority = strings.Split(priority, ",")
+		for i, pattern := range cfg.Scan.Priority {
+			cfg.Scan.Priority[i] = strings.TrimSpace(pattern)
+		}
+	}
+	if rolling := os.Getenv("SCAN_ROLLING_IMAGES"); rolling != "" {
+		cfg.Scan.RollingImages = splitList(rolling)
+	}
+	if maxUpdateType := os.Getenv("SCAN_MAX_UPDATE_TYPE"); maxUpdateType != "" {
+		cfg.Scan.MaxUpdateType = strings.ToLower(strings.TrimSpace(maxUpdateType))
+	}
+	if suffixPolicy := os.Getenv("SCAN_SUFFIX_POLICY"); suffixPolicy != "" {
+		cfg.Scan.SuffixPolicy = strings.ToLower(strings.TrimSpace(suffixPolicy))
+	}
+	if targetOS := os.Getenv("SCAN_TARGET_OS"); targetOS != "" {
+		cfg.Scan.TargetOS = strings.ToLower(strings.TrimSpace(targetOS))
+	}
+	if resolveLatest := os.Getenv("SCAN_RESOLVE_LATEST"); resolveLatest != "" {
+		if val, err := strconv.ParseBool(resolveLatest); err == nil {
+			cfg.Scan.ResolveLatest = val
+		}
+	}
+	if strictSemver := os.Getenv("SCAN_STRICT_SEMVER"); strictSemver != "" {
+		if val, err := strconv.ParseBool(strictSemver); err == nil {
+			cfg.Scan.StrictSemver = val
+		}
+	}
+	if deepParse := os.Getenv("SCAN_DEEP_PARSE"); deepParse != "" {
+		if val, err := strconv.ParseBool(deepParse); err == nil {
+			cfg.Scan.DeepParse = val
+		}
+	}
+	if followSymlinks := os.Getenv("SCAN_FOLLOW_SYMLINKS"); followSymlinks != "" {
+		if val, err := strconv.ParseBool(followSymlinks); err == nil {
+			cfg.Scan.FollowSymlinks = val
+		}
+	}
+
+	// Report configuration
+	if maxUpToDate := os.Getenv("REPORT_MAX_UPTODATE"); maxUpToDate != "" {
+		if val, err := strconv.Atoi(maxUpToDate); err == nil && val >= 0 {
+			cfg.Report.MaxUpToDate = val
+		}
+	}
+	if defaultFormat := os.Getenv("REPORT_DEFAULT_FORMAT"); defaultFormat != "" {
+		cfg.Report.DefaultFormat = strings.ToLower(strings.TrimSpace(defaultFormat))
+	}
 }
 func validate(cfg *types.Config) error {
 	// Validar configuración de Telegram si está habilitada
@@ -141,22 +423,211 @@ func validate(cfg *types.Config) error {
 		}
 	}
 
+	// Validar canales de notificación
+	for channel, chatID := range cfg.Telegram.Channels {
+		if channel == "" || chatID == "" {
+			return errors.Newf("config.validate", "telegram channels entries must not be empty (%q -> %q)", channel, chatID)
+		}
+	}
+
+	// Validar umbral de notificación
+	if err := ValidateMinUpdateType(cfg.Telegram.MinUpdateType); err != nil {
+		return errors.Wrap("config.validate", err)
+	}
+
+	// Validar configuración de Slack
+	if cfg.Slack.Enabled && cfg.Slack.WebhookURL == "" {
+		return errors.New("config.validate", "slack webhook URL is required when slack is enabled")
+	}
+	if webhookURL := cfg.Slack.WebhookURL; webhookURL != "" && !strings.HasPrefix(webhookURL, "https://") && !strings.HasPrefix(webhookURL, "http://") {
+		return errors.Newf("config.validate", "invalid slack webhook URL %q (must start with https://)", webhookURL)
+	}
+
+	// Validar configuración de Discord
+	if cfg.Discord.Enabled && cfg.Discord.WebhookURL == "" {
+		return errors.New("config.validate", "discord webhook URL is required when discord is enabled")
+	}
+	if webhookURL := cfg.Discord.WebhookURL; webhookURL != "" && !strings.HasPrefix(webhookURL, "https://") && !strings.HasPrefix(webhookURL, "http://") {
+		return errors.Newf("config.validate", "invalid discord webhook URL %q (must start with https://)", webhookURL)
+	}
+
+	// Validar configuración de correo
+	if err := validateEmail(cfg.Email); err != nil {
+		return errors.Wrap("config.validate", err)
+	}
+
+	// Validar el webhook genérico
+	if cfg.Webhook.Enabled && cfg.Webhook.URL == "" {
+		return errors.New("config.validate", "webhook URL is required when webhook is enabled")
+	}
+	if url := cfg.Webhook.URL; url != "" && !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return errors.Newf("config.validate", "invalid webhook URL %q (must start with http:// or https://)", url)
+	}
+	for name := range cfg.Webhook.Headers {
+		if name == "" || strings.ContainsAny(name, " \t:\r\n") {
+			return errors.Newf("config.validate", "invalid webhook header name %q", name)
+		}
+	}
+
+	// Validar el notificador por comando
+	if cfg.Exec.Timeout < 0 {
+		return errors.New("config.validate", "exec timeout must not be negative")
+	}
+	if len(cfg.Exec.Command) > 0 && strings.TrimSpace(cfg.Exec.Command[0]) == "" {
+		return errors.New("config.validate", "exec command must start with the program to run")
+	}
+
+	// Validar credenciales de Docker Hub
+	if cfg.Registry.DockerHub.Secret() != "" && cfg.Registry.DockerHub.Username == "" {
+		return errors.New("config.validate", "registry dockerhub username is required when a password or token is set")
+	}
+	if mirror := cfg.Registry.DockerHub.Mirror; strings.Contains(mirror, "://") || strings.ContainsAny(mirror, " \t") {
+		return errors.Newf("config.validate", "invalid registry dockerhub mirror %q (use host[:port][/path] without scheme)", mirror)
+	}
+
 	// Validar timeouts
 	if cfg.Registry.Timeout <= 0 {
 		return errors.New("config.validate", "registry timeout must be positive")
 	}
+	if cfg.Registry.Quay.Timeout < 0 {
+		return errors.New("config.validate", "registry quay timeout cannot be negative")
+	}
+	if cfg.Registry.GitLab.Timeout < 0 {
+		return errors.New("config.validate", "registry gitlab timeout cannot be negative")
+	}
+	if host := cfg.Registry.GitLab.Host; strings.Contains(host, "/") || strings.ContainsAny(host, " \t") {
+		return errors.Newf("config.validate", "invalid registry gitlab host %q (use host[:port] without scheme)", host)
+	}
 	if cfg.Scan.Timeout <= 0 {
 		return errors.New("config.validate", "scan timeout must be positive")
 	}
 
+	// Validar límite de la versión recomendada
+	if err := ValidateMaxUpdateType(cfg.Scan.MaxUpdateType); err != nil {
+		return errors.Wrap("config.validate", err)
+	}
+
+	// Validar política de sufijos
+	if _, err := utils.ParseSuffixPolicy(cfg.Scan.SuffixPolicy); err != nil {
+		return errors.Wrap("config.validate", err)
+	}
+
+	// Validar sistema operativo objetivo
+	if _, err := utils.ParseTargetOS(cfg.Scan.TargetOS); err != nil {
+		return errors.Wrap("config.validate", err)
+	}
+
+	// Validar codenames de distribuciones
+	if err := utils.ValidateCodenames(cfg.Scan.Codenames); err != nil {
+		return errors.Wrap("config.validate", err)
+	}
+
 	// Validar patrones de escaneo
 	if len(cfg.Scan.Patterns) == 0 {
 		return errors.New("config.validate", "at least one scan pattern is required")
 	}
+	if err := ValidatePatterns(cfg.Scan.Patterns); err != nil {
+		return errors.Wrap("config.validate", err)
+	}
+
+	if cfg.Registry.MaxTags < 0 {
+		return errors.New("config.validate", "registry max_tags must not be negative")
+	}
+
+	// Validar timeouts por registro
+	for host, timeout := range cfg.Registry.Timeouts {
+		if host == "" || timeout <= 0 {
+			return errors.Newf("config.validate", "registry timeouts entries need a host and a positive timeout (%q: %d)", host, timeout)
+		}
+	}
+
+	// Validar concurrencia por registro
+	for host, limit := range cfg.Registry.Concurrency {
+		if host == "" || limit <= 0 {
+			return errors.Newf("config.validate", "registry concurrency entries need a host and a positive limit (%q: %d)", host, limit)
+		}
+	}
+
+	// Validar alias de repositorio
+	for prefix, upstream := range cfg.Registry.RepositoryAliases {
+		if prefix == "" || upstream == "" {
+			return errors.Newf("config.validate", "registry repository_aliases entries must not be empty (%q -> %q)", prefix, upstream)
+		}
+	}
+
+	if cfg.Report.MaxUpToDate < 0 {
+		return errors.New("config.validate", "report max_uptodate must not be negative")
+	}
+	if err := ValidateOutputFormat(cfg.Report.DefaultFormat); err != nil {
+		return errors.Wrap("config.validate", err)
+	}
 
 	return nil
 }
 
+// ValidateMinUpdateType verifica que el umbral sea vacío, patch, minor o major
+func ValidateMinUpdateType(value string) error {
+	if isUpdateThreshold(value) {
+		return nil
+	}
+	return errors.Newf("config.ValidateMinUpdateType", "invalid min_update_type %q (use patch, minor or major)", value)
+}
+
+// ValidatePatterns verifica que cada patrón de scan.patterns sea un glob válido
+// para filepath.Match; uno inválido nunca coincidiría con ningún archivo
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, "docker-compose.yml"); err != nil {
+			return errors.Wrapf("config.ValidatePatterns", err, "invalid scan pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// OutputFormats son los formatos de salida que admite scan --output
+var OutputFormats = []string{"console", "json", "html", "sarif", "influx", "markdown", "md", "jsonl", "csv"}
+
+// ValidateOutputFormat verifica que value sea vacío o una lista separada por
+// comas de OutputFormats (p. ej. "json,html")
+func ValidateOutputFormat(value string) error {
+	if value == "" {
+		return nil
+	}
+	for _, format := range SplitOutputFormats(value) {
+		if !slices.Contains(OutputFormats, format) {
+			return errors.Newf("config.ValidateOutputFormat", "invalid output format %q (use %s)", format, strings.Join(OutputFormats, ", "))
+		}
+	}
+	return nil
+}
+
+// SplitOutputFormats separa una lista de formatos separada por comas,
+// en minúsculas y sin espacios
+func SplitOutputFormats(value string) []string {
+	var formats []string
+	for _, format := range strings.Split(value, ",") {
+		if format = strings.ToLower(strings.TrimSpace(format)); format != "" {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// ValidateMaxUpdateType verifica que el límite de la recomendación sea vacío,
+// patch, minor o major
+func ValidateMaxUpdateType(value string) error {
+	if isUpdateThreshold(value) {
+		return nil
+	}
+	return errors.Newf("config.ValidateMaxUpdateType", "invalid max_update_type %q (use patch, minor or major)", value)
+}
+
+// isUpdateThreshold indica si value es vacío, patch, minor o major
+func isUpdateThreshold(value string) bool {
+	updateType := types.UpdateType(value)
+	return value == "" || updateType == types.UpdateTypePatch || updateType == types.UpdateTypeMinor || updateType == types.UpdateTypeMajor
+}
+
 // Save guarda la configuración en un archivo
 func Save(cfg *types.Config, configPath string) error {
 	if configPath == "" {
@@ -183,23 +654,41 @@ func Save(cfg *types.Config, configPath string) error {
 	return nil
 }
 
-// defaultTelegramTemplate devuelve el template por defecto para mensajes de Telegram
-func defaultTelegramTemplate() string {
-	return `🐳 <b>Docker Image Updates Available</b>
-
-📊 <b>Summary:</b> {{.Summary}}
-📅 <b>Scanned:</b> {{.ScanTimestamp.Format "2006-01-02 15:04:05"}}
-
-{{range .UpdatesAvailable}}
-🔄 <b>{{.ServiceName}}</b>
-   Current: <code>{{.CurrentImage}}</code>
-   Latest: <code>{{.LatestImage}}</code>
-   Type: {{.UpdateType}}
+// splitList separa una lista separada por comas, eliminando espacios y entradas vacías
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
 
-{{end}}
-{{if .Errors}}
-⚠️ <b>Errors:</b>
-{{range .Errors}}• {{.}}
-{{end}}
-{{end}}`
+// validateEmail comprueba la configuración de correo: con Enabled hacen falta
+// servidor, remitente y destinatarios, y las direcciones deben ser válidas
+func validateEmail(cfg types.EmailConfig) error {
+	if cfg.Enabled {
+		if cfg.Host == "" {
+			return errors.New("config.validateEmail", "email host is required when email is enabled")
+		}
+		if cfg.From == "" {
+			return errors.New("config.validateEmail", "email from address is required when email is enabled")
+		}
+		if len(cfg.To) == 0 {
+			return errors.New("config.validateEmail", "at least one email recipient is required when email is enabled")
+		}
+	}
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return errors.Newf("config.validateEmail", "invalid email port %d", cfg.Port)
+	}
+	for _, address := range append([]string{cfg.From}, cfg.To...) {
+		if address == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(address); err != nil {
+			return errors.Newf("config.validateEmail", "invalid email address %q", address)
+		}
+	}
+	return nil
 }
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/user/docker-image-reporter/pkg/types"
@@ -126,6 +128,162 @@ func TestValidate(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "negative max tags",
+			config: &types.Config{
+				Registry: types.RegistryConfig{Timeout: 30, MaxTags: -1},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid per-registry timeout",
+			config: &types.Config{
+				Registry: types.RegistryConfig{Timeout: 30, Timeouts: map[string]int{"registry.example.com": 0}},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid per-registry timeout",
+			config: &types.Config{
+				Registry: types.RegistryConfig{Timeout: 30, Timeouts: map[string]int{"registry.example.com": 120}},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid per-registry concurrency",
+			config: &types.Config{
+				Registry: types.RegistryConfig{Timeout: 30, Concurrency: map[string]int{"docker.io": 0}},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid per-registry concurrency",
+			config: &types.Config{
+				Registry: types.RegistryConfig{Timeout: 30, Concurrency: map[string]int{"docker.io": 2}},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid max update type",
+			config: &types.Config{
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}, MaxUpdateType: "digest"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid suffix policy",
+			config: &types.Config{
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}, SuffixPolicy: "loose"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "strict suffix policy",
+			config: &types.Config{
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}, SuffixPolicy: "strict"},
+			},
+			expectErr: false,
+		},
+		{
+			name: "empty repository alias target",
+			config: &types.Config{
+				Registry: types.RegistryConfig{
+					Timeout:           30,
+					RepositoryAliases: map[string]string{"internal.example.com/mirror/": ""},
+				},
+				Scan: types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "empty telegram channel chat id",
+			config: &types.Config{
+				Telegram: types.TelegramConfig{Channels: map[string]string{"team-a": ""}},
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "slack enabled without webhook URL",
+			config: &types.Config{
+				Slack:    types.SlackConfig{Enabled: true},
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "slack webhook URL without scheme",
+			config: &types.Config{
+				Slack:    types.SlackConfig{Enabled: true, WebhookURL: "hooks.slack.com/services/T0/B0/x"},
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "discord enabled without webhook URL",
+			config: &types.Config{
+				Discord:  types.DiscordConfig{Enabled: true},
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "email enabled without recipients",
+			config: &types.Config{
+				Email:    types.EmailConfig{Enabled: true, Host: "smtp.example.com", From: "reporter@example.com"},
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid email recipient",
+			config: &types.Config{
+				Email:    types.EmailConfig{Enabled: true, Host: "smtp.example.com", From: "reporter@example.com", To: []string{"ops"}},
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid email",
+			config: &types.Config{
+				Email:    types.EmailConfig{Enabled: true, Host: "smtp.example.com", From: "Reporter <reporter@example.com>", To: []string{"ops@example.com"}},
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: false,
+		},
+		{
+			name: "webhook enabled without URL",
+			config: &types.Config{
+				Webhook:  types.WebhookConfig{Enabled: true},
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid webhook header name",
+			config: &types.Config{
+				Webhook:  types.WebhookConfig{Enabled: true, URL: "https://automation.example.com/hooks/icr", Headers: map[string]string{"Bad Header": "x"}},
+				Registry: types.RegistryConfig{Timeout: 30},
+				Scan:     types.ScanConfig{Timeout: 300, Patterns: []string{"*.yml"}},
+			},
+			expectErr: true,
+		},
 		{
 			name: "no scan patterns",
 			config: &types.Config{
@@ -206,3 +364,194 @@ func TestSaveAndLoad(t *testing.T) {
 			originalConfig.Scan.Recursive, loadedConfig.Scan.Recursive)
 	}
 }
+
+func TestValidateMinUpdateType(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"", false},
+		{"patch", false},
+		{"minor", false},
+		{"major", false},
+		{"digest", true},
+		{"critical", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err := ValidateMinUpdateType(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMinUpdateType(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFromEnv_RollingImages(t *testing.T) {
+	t.Setenv("SCAN_ROLLING_IMAGES", " gcr.io/distroless/* ,, cgr.dev/chainguard/*")
+
+	cfg := DefaultConfig()
+	loadFromEnv(cfg)
+
+	want := []string{"gcr.io/distroless/*", "cgr.dev/chainguard/*"}
+	if strings.Join(cfg.Scan.RollingImages, "|") != strings.Join(want, "|") {
+		t.Errorf("RollingImages = %v, want %v", cfg.Scan.RollingImages, want)
+	}
+}
+
+func TestLoad_DockerHubEnvOverridesFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	configYAML := "registry:\n  dockerhub:\n    username: file-user\n    password: file-pass\n"
+	if err := os.WriteFile(configPath, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+
+	t.Setenv("DOCKER_USERNAME", "ci-user")
+	t.Setenv("DOCKER_PASSWORD", "")
+	t.Setenv("DOCKER_TOKEN", "ci-token")
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// Las variables de entorno tienen prioridad; lo no definido se mantiene del archivo
+	hub := cfg.Registry.DockerHub
+	if hub.Username != "ci-user" || hub.Password != "file-pass" || hub.Token != "ci-token" {
+		t.Errorf("DockerHub = %+v, want ci-user/file-pass/ci-token", hub)
+	}
+	if hub.Secret() != "ci-token" {
+		t.Errorf("Secret() = %q, want the token over the password", hub.Secret())
+	}
+}
+
+func TestValidate_DockerHubRequiresUsername(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Registry.DockerHub.Token = "token"
+	if err := validate(cfg); err == nil {
+		t.Error("expected error for a dockerhub token without username")
+	}
+
+	cfg.Registry.DockerHub.Username = "user"
+	if err := validate(cfg); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+}
+
+func TestValidatePatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErr  bool
+	}{
+		{name: "valid globs", patterns: []string{"docker-compose.yml", "docker-compose.*.yml", "compose.y?ml", "[dc]ompose.yml"}},
+		{name: "unclosed bracket", patterns: []string{"compose.yml", "[unclosed"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePatterns(tt.patterns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidatePatterns(%v) error = %v, wantErr %v", tt.patterns, err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if !strings.Contains(err.Error(), "[unclosed") {
+				t.Errorf("error %q does not name the pattern", err)
+			}
+			// La carga de la configuración también lo rechaza
+			cfg := DefaultConfig()
+			cfg.Scan.Patterns = tt.patterns
+			if validate(cfg) == nil {
+				t.Error("validate() accepted an invalid pattern")
+			}
+		})
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	configYAML := `registry:
+  timeout: 30
+  timeouts:
+    registry.example.com: 60
+scan:
+  patterns: [compose.yaml]
+telegram:
+  chat_id: "base-chat"
+profiles:
+  dev:
+    registry:
+      timeout: 5
+      timeouts:
+        localhost:5000: 2
+  prod:
+    registry:
+      timeout: 90
+`
+	if err := os.WriteFile(configPath, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+
+	cfg, err := LoadProfile(configPath, "dev")
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+
+	// El perfil sustituye los ajustes del registro y combina los mapas
+	if cfg.Registry.Timeout != 5 {
+		t.Errorf("Registry.Timeout = %d, want 5 from the dev profile", cfg.Registry.Timeout)
+	}
+	if cfg.Registry.Timeouts["localhost:5000"] != 2 || cfg.Registry.Timeouts["registry.example.com"] != 60 {
+		t.Errorf("Registry.Timeouts = %v, want dev's and the base entries", cfg.Registry.Timeouts)
+	}
+	// La base aporta lo que el perfil no define
+	if strings.Join(cfg.Scan.Patterns, ",") != "compose.yaml" || cfg.Telegram.ChatID != "base-chat" {
+		t.Errorf("expected base patterns and chat id, got %v and %q", cfg.Scan.Patterns, cfg.Telegram.ChatID)
+	}
+
+	base, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if base.Registry.Timeout != 30 {
+		t.Errorf("base Registry.Timeout = %d, want 30", base.Registry.Timeout)
+	}
+
+	if _, err := LoadProfile(configPath, "staging"); err == nil || !strings.Contains(err.Error(), "dev, prod") {
+		t.Errorf("expected an error listing the available profiles, got %v", err)
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Scan.Patterns = []string{"compose.yaml"}
+
+	current := DefaultConfig()
+	current.Scan.Patterns = []string{"compose.yaml"}
+	updated := DefaultConfig()
+	updated.Scan.Patterns = []string{"compose.yaml"}
+	updated.Registry.Timeout = 5
+
+	if err := UpdateProfile(cfg, "dev", current, updated); err != nil {
+		t.Fatalf("UpdateProfile() error = %v", err)
+	}
+
+	// Solo la clave cambiada pasa al perfil; la base no cambia
+	want := map[string]interface{}{"registry": map[string]interface{}{"timeout": 5}}
+	if !reflect.DeepEqual(cfg.Profiles["dev"], want) {
+		t.Errorf("profile dev = %v, want %v", cfg.Profiles["dev"], want)
+	}
+	if cfg.Registry.Timeout != DefaultConfig().Registry.Timeout {
+		t.Errorf("base Registry.Timeout changed to %d", cfg.Registry.Timeout)
+	}
+
+	if err := ApplyProfile(cfg, "dev"); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+	if cfg.Registry.Timeout != 5 || strings.Join(cfg.Scan.Patterns, ",") != "compose.yaml" {
+		t.Errorf("after ApplyProfile got timeout %d and patterns %v", cfg.Registry.Timeout, cfg.Scan.Patterns)
+	}
+}
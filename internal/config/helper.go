@@ -8,23 +8,39 @@ import (
 
 	"github.com/user/docker-image-reporter/pkg/errors"
 	"github.com/user/docker-image-reporter/pkg/types"
+	"github.com/user/docker-image-reporter/pkg/utils"
 )
 
 // Configuration keys and values constants
 const (
 	// Configuration section keys
-	keyTelegram  = "telegram"
-	keyRegistry  = "registry"
-	keyScan      = "scan"
-	keyEnabled   = "enabled"
-	keyTimeout   = "timeout"
-	keyBotToken  = "bot_token"
-	keyChatID    = "chat_id"
-	keyTemplate  = "template"
-	keyGHCR  = "ghcr"
-	keyToken = "token"
-	keyRecursive = "recursive"
-	keyPatterns  = "patterns"
+	keyTelegram      = "telegram"
+	keyRegistry      = "registry"
+	keyScan          = "scan"
+	keyReport        = "report"
+	keyEnabled       = "enabled"
+	keyTimeout       = "timeout"
+	keyBotToken      = "bot_token"
+	keyChatID        = "chat_id"
+	keyTemplate      = "template"
+	keyMinUpdate     = "min_update_type"
+	keyGHCR          = "ghcr"
+	keyToken         = "token"
+	keyTrustedHosts  = "trusted_hosts"
+	keyMaxTags       = "max_tags"
+	keyDockerHub     = "dockerhub"
+	keyUsername      = "username"
+	keyPassword      = "password"
+	keyMirror        = "mirror"
+	keyRecursive     = "recursive"
+	keyPatterns      = "patterns"
+	keyPriority      = "priority"
+	keyRollingImages = "rolling_images"
+	keySuffixPolicy  = "suffix_policy"
+	keyTargetOS      = "target_os"
+	keyMaxUpdate     = "max_update_type"
+	keyMaxUpToDate   = "max_uptodate"
+	keyDefaultFormat = "default_format"
 
 	// Configuration values
 	valueTrue = "true"
@@ -68,6 +84,8 @@ func SetValue(cfg *types.Config, key, value string) error {
 		return setRegistryValue(cfg, parts[1:], value)
 	case keyScan:
 		return setScanValue(cfg, parts[1:], value)
+	case keyReport:
+		return setReportValue(cfg, parts[1:], value)
 	default:
 		return errors.Newf("config.SetValue", "unknown config section: %s", parts[0])
 	}
@@ -87,6 +105,8 @@ func GetValue(cfg *types.Config, key string) (string, error) {
 		return getRegistryValue(cfg, parts[1:])
 	case keyScan:
 		return getScanValue(cfg, parts[1:])
+	case keyReport:
+		return getReportValue(cfg, parts[1:])
 	default:
 		return "", errors.Newf("config.GetValue", "unknown config section: %s", parts[0])
 	}
@@ -107,6 +127,12 @@ func setTelegramValue(cfg *types.Config, parts []string, value string) error {
 		cfg.Telegram.Enabled = enabled
 	case keyTemplate:
 		cfg.Telegram.Template = value
+	case keyMinUpdate:
+		minUpdateType := strings.ToLower(strings.TrimSpace(value))
+		if err := ValidateMinUpdateType(minUpdateType); err != nil {
+			return errors.Wrap("config.setTelegramValue", err)
+		}
+		cfg.Telegram.MinUpdateType = minUpdateType
 	default:
 		return errors.Newf("config.setTelegramValue", "unknown telegram field: %s", parts[0])
 	}
@@ -128,6 +154,8 @@ func getTelegramValue(cfg *types.Config, parts []string) (string, error) {
 		return fmt.Sprintf("%t", cfg.Telegram.Enabled), nil
 	case keyTemplate:
 		return cfg.Telegram.Template, nil
+	case keyMinUpdate:
+		return cfg.Telegram.MinUpdateType, nil
 	default:
 		return "", errors.Newf("config.getTelegramValue", "unknown telegram field: %s", parts[0])
 	}
@@ -146,6 +174,14 @@ func setRegistryValue(cfg *types.Config, parts []string, value string) error {
 			return errors.Wrapf("config.setRegistryValue", err, "invalid timeout value: %s", value)
 		}
 		cfg.Registry.Timeout = timeout
+	case keyTrustedHosts:
+		cfg.Registry.TrustedHosts = splitList(value)
+	case keyMaxTags:
+		var maxTags int
+		if _, err := fmt.Sscanf(value, "%d", &maxTags); err != nil || maxTags < 0 {
+			return errors.Newf("config.setRegistryValue", "invalid max_tags value: %s", value)
+		}
+		cfg.Registry.MaxTags = maxTags
 	case keyGHCR:
 		if len(parts) < 2 {
 			return errors.New("config.setRegistryValue", "missing ghcr field")
@@ -156,6 +192,22 @@ func setRegistryValue(cfg *types.Config, parts []string, value string) error {
 		default:
 			return errors.Newf("config.setRegistryValue", "unknown ghcr field: %s", parts[1])
 		}
+	case keyDockerHub:
+		if len(parts) < 2 {
+			return errors.New("config.setRegistryValue", "missing dockerhub field")
+		}
+		switch parts[1] {
+		case keyUsername:
+			cfg.Registry.DockerHub.Username = value
+		case keyPassword:
+			cfg.Registry.DockerHub.Password = value
+		case keyToken:
+			cfg.Registry.DockerHub.Token = value
+		case keyMirror:
+			cfg.Registry.DockerHub.Mirror = value
+		default:
+			return errors.Newf("config.setRegistryValue", "unknown dockerhub field: %s", parts[1])
+		}
 	default:
 		return errors.Newf("config.setRegistryValue", "unknown registry field: %s", parts[0])
 	}
@@ -171,6 +223,10 @@ func getRegistryValue(cfg *types.Config, parts []string) (string, error) {
 	switch parts[0] {
 	case keyTimeout:
 		return fmt.Sprintf("%d", cfg.Registry.Timeout), nil
+	case keyTrustedHosts:
+		return strings.Join(cfg.Registry.TrustedHosts, ", "), nil
+	case keyMaxTags:
+		return fmt.Sprintf("%d", cfg.Registry.MaxTags), nil
 	case keyGHCR:
 		if len(parts) < 2 {
 			return "", errors.New("config.getRegistryValue", "missing ghcr field")
@@ -185,6 +241,28 @@ func getRegistryValue(cfg *types.Config, parts []string) (string, error) {
 		default:
 			return "", errors.Newf("config.getRegistryValue", "unknown ghcr field: %s", parts[1])
 		}
+	case keyDockerHub:
+		if len(parts) < 2 {
+			return "", errors.New("config.getRegistryValue", "missing dockerhub field")
+		}
+		var secret string
+		switch parts[1] {
+		case keyUsername:
+			return cfg.Registry.DockerHub.Username, nil
+		case keyMirror:
+			return cfg.Registry.DockerHub.Mirror, nil
+		case keyPassword:
+			secret = cfg.Registry.DockerHub.Password
+		case keyToken:
+			secret = cfg.Registry.DockerHub.Token
+		default:
+			return "", errors.Newf("config.getRegistryValue", "unknown dockerhub field: %s", parts[1])
+		}
+		// No mostrar los secretos por seguridad
+		if secret == "" {
+			return "", nil
+		}
+		return "[REDACTED]", nil
 	default:
 		return "", errors.Newf("config.getRegistryValue", "unknown registry field: %s", parts[0])
 	}
@@ -205,7 +283,39 @@ func setScanValue(cfg *types.Config, parts []string, value string) error {
 		for i, pattern := range patterns {
 			patterns[i] = strings.TrimSpace(pattern)
 		}
+		if err := ValidatePatterns(patterns); err != nil {
+			return errors.Wrap("config.setScanValue", err)
+		}
 		cfg.Scan.Patterns = patterns
+	case keyPriority:
+		// Split comma-separated repository globs
+		var priority []string
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				priority = append(priority, pattern)
+			}
+		}
+		cfg.Scan.Priority = priority
+	case keyRollingImages:
+		cfg.Scan.RollingImages = splitList(value)
+	case keyMaxUpdate:
+		maxUpdateType := strings.ToLower(strings.TrimSpace(value))
+		if err := ValidateMaxUpdateType(maxUpdateType); err != nil {
+			return errors.Wrap("config.setScanValue", err)
+		}
+		cfg.Scan.MaxUpdateType = maxUpdateType
+	case keySuffixPolicy:
+		policy, err := utils.ParseSuffixPolicy(value)
+		if err != nil {
+			return errors.Wrap("config.setScanValue", err)
+		}
+		cfg.Scan.SuffixPolicy = string(policy)
+	case keyTargetOS:
+		target, err := utils.ParseTargetOS(value)
+		if err != nil {
+			return errors.Wrap("config.setScanValue", err)
+		}
+		cfg.Scan.TargetOS = string(target)
 	case keyTimeout:
 		var timeout int
 		if _, err := fmt.Sscanf(value, "%d", &timeout); err != nil {
@@ -229,9 +339,62 @@ func getScanValue(cfg *types.Config, parts []string) (string, error) {
 		return fmt.Sprintf("%t", cfg.Scan.Recursive), nil
 	case keyPatterns:
 		return strings.Join(cfg.Scan.Patterns, ", "), nil
+	case keyPriority:
+		return strings.Join(cfg.Scan.Priority, ", "), nil
+	case keyRollingImages:
+		return strings.Join(cfg.Scan.RollingImages, ", "), nil
+	case keyMaxUpdate:
+		return cfg.Scan.MaxUpdateType, nil
+	case keySuffixPolicy:
+		return cfg.Scan.SuffixPolicy, nil
+	case keyTargetOS:
+		return cfg.Scan.TargetOS, nil
 	case keyTimeout:
 		return fmt.Sprintf("%d", cfg.Scan.Timeout), nil
 	default:
 		return "", errors.Newf("config.getScanValue", "unknown scan field: %s", parts[0])
 	}
 }
+
+func setReportValue(cfg *types.Config, parts []string, value string) error {
+	if len(parts) == 0 {
+		return errors.New("config.setReportValue", "missing report field")
+	}
+
+	switch parts[0] {
+	case keyMaxUpToDate:
+		var maxUpToDate int
+		if _, err := fmt.Sscanf(value, "%d", &maxUpToDate); err != nil {
+			return errors.Wrapf("config.setReportValue", err, "invalid max_uptodate value: %s", value)
+		}
+		if maxUpToDate < 0 {
+			return errors.Newf("config.setReportValue", "max_uptodate must not be negative: %d", maxUpToDate)
+		}
+		cfg.Report.MaxUpToDate = maxUpToDate
+	case keyDefaultFormat:
+		format := strings.ToLower(strings.TrimSpace(value))
+		if err := ValidateOutputFormat(format); err != nil {
+			return errors.Wrap("config.setReportValue", err)
+		}
+		cfg.Report.DefaultFormat = format
+	default:
+		return errors.Newf("config.setReportValue", "unknown report field: %s", parts[0])
+	}
+
+	return nil
+}
+
+func getReportValue(cfg *types.Config, parts []string) (string, error) {
+	if len(parts) == 0 {
+		return "", errors.New("config.getReportValue", "missing report field")
+	}
+
+	switch parts[0] {
+	case keyMaxUpToDate:
+		return fmt.Sprintf("%d", cfg.Report.MaxUpToDate), nil
+	case keyDefaultFormat:
+		return cfg.Report.DefaultFormat, nil
+	default:
+		return "", errors.Newf("config.getReportValue", "unknown report field: %s", parts[0])
+	}
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+	"github.com/user/docker-image-reporter/pkg/types"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// CurrentSchemaVersion es la versión del esquema que escribe esta versión de icr.
+// Los archivos sin schema_version se consideran versión 0.
+const CurrentSchemaVersion = 1
+
+// migration transforma el documento YAML genérico de la versión from a from+1
+// y devuelve una descripción de cada cambio aplicado
+type migration struct {
+	from  int
+	apply func(doc map[string]interface{}) []string
+}
+
+// migrations contiene las migraciones conocidas, en orden
+var migrations = []migration{
+	{from: 0, apply: migrateFlattenRegistry},
+}
+
+// MigrationResult describe el resultado de migrar un archivo de configuración
+type MigrationResult struct {
+	Config      *types.Config
+	FromVersion int
+	Changes     []string
+	Warnings    []string
+}
+
+// Changed indica si la migración modificó la configuración
+func (r *MigrationResult) Changed() bool {
+	return len(r.Changes) > 0
+}
+
+// Migrate aplica las migraciones conocidas a un archivo de configuración en
+// bruto: renombra claves obsoletas, completa los valores por defecto nuevos y
+// avisa de las claves desconocidas. No aplica variables de entorno para no
+// volcar secretos al archivo.
+func Migrate(data []byte) (*MigrationResult, error) {
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap("config.Migrate", err)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+
+	version, err := schemaVersion(doc)
+	if err != nil {
+		return nil, errors.Wrap("config.Migrate", err)
+	}
+	if version > CurrentSchemaVersion {
+		return nil, errors.Newf("config.Migrate", "schema_version %d is newer than supported version %d", version, CurrentSchemaVersion)
+	}
+
+	result := &MigrationResult{FromVersion: version}
+	for _, m := range migrations {
+		if m.from < version {
+			continue
+		}
+		result.Changes = append(result.Changes, m.apply(doc)...)
+	}
+	if version != CurrentSchemaVersion {
+		result.Changes = append(result.Changes, fmt.Sprintf("set schema_version %d -> %d", version, CurrentSchemaVersion))
+	}
+	doc["schema_version"] = CurrentSchemaVersion
+
+	for _, key := range unknownKeys(doc, reflect.TypeOf(types.Config{}), "") {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("unknown key %q is ignored and will be dropped", key))
+	}
+
+	// Decodificar sobre los valores por defecto completa las claves nuevas
+	migrated, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, errors.Wrap("config.Migrate", err)
+	}
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal(migrated, cfg); err != nil {
+		return nil, errors.Wrap("config.Migrate", err)
+	}
+	result.Changes = append(result.Changes, missingDefaults(doc, cfg)...)
+
+	if err := validate(cfg); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+
+	result.Config = cfg
+	return result, nil
+}
+
+// schemaVersion lee schema_version del documento (0 si no existe)
+func schemaVersion(doc map[string]interface{}) (int, error) {
+	raw, ok := doc["schema_version"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	version, ok := raw.(int)
+	if !ok || version < 0 {
+		return 0, fmt.Errorf("invalid schema_version %v", raw)
+	}
+	return version, nil
+}
+
+// migrateFlattenRegistry (v0 -> v1) aplana la configuración por registro
+// de las versiones antiguas: el token de GHCR pasa a registry.ghcr_token y los
+// timeouts por registro se reducen a registry.timeout. La sección
+// registry.dockerhub se conserva (credenciales y mirror siguen vigentes); solo
+// se le quitan las claves antiguas timeout y enabled.
+func migrateFlattenRegistry(doc map[string]interface{}) []string {
+	registry, ok := doc["registry"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var changes []string
+	_, hasTimeout := registry["timeout"]
+	legacyTimeout := 0
+
+	if section, ok := registry["ghcr"].(map[string]interface{}); ok {
+		if token, ok := section["token"].(string); ok && token != "" {
+			if _, exists := registry["ghcr_token"]; !exists {
+				registry["ghcr_token"] = token
+				changes = append(changes, "renamed registry.ghcr.token -> registry.ghcr_token")
+			}
+		}
+		if timeout, ok := section["timeout"].(int); ok && timeout > legacyTimeout {
+			legacyTimeout = timeout
+		}
+		delete(registry, "ghcr")
+		changes = append(changes, "removed deprecated registry.ghcr section")
+	}
+
+	if section, ok := registry["dockerhub"].(map[string]interface{}); ok {
+		if timeout, ok := section["timeout"].(int); ok && timeout > legacyTimeout {
+			legacyTimeout = timeout
+		}
+		for _, key := range []string{"timeout", "enabled"} {
+			if _, exists := section[key]; exists {
+				delete(section, key)
+				changes = append(changes, fmt.Sprintf("removed deprecated registry.dockerhub.%s", key))
+			}
+		}
+		if len(section) == 0 {
+			delete(registry, "dockerhub")
+		}
+	}
+
+	if !hasTimeout && legacyTimeout > 0 {
+		registry["timeout"] = legacyTimeout
+		changes = append(changes, fmt.Sprintf("moved per-registry timeout to registry.timeout = %d", legacyTimeout))
+	}
+
+	return changes
+}
+
+// missingDefaults describe las secciones y claves que no estaban en el
+// documento y se completaron con su valor por defecto
+func missingDefaults(doc map[string]interface{}, cfg *types.Config) []string {
+	var changes []string
+	value := reflect.ValueOf(*cfg)
+	for i := 0; i < value.NumField(); i++ {
+		name := yamlKey(value.Type().Field(i))
+		if name == "" || name == "schema_version" {
+			continue
+		}
+
+		field := value.Field(i)
+		if field.Kind() != reflect.Struct {
+			continue
+		}
+		section, _ := doc[name].(map[string]interface{})
+		for j := 0; j < field.NumField(); j++ {
+			key := yamlKey(field.Type().Field(j))
+			if key == "" || field.Field(j).IsZero() {
+				continue
+			}
+			if _, exists := section[key]; exists {
+				continue
+			}
+			changes = append(changes, fmt.Sprintf("added default %s.%s", name, key))
+		}
+	}
+	return changes
+}
+
+// unknownKeys devuelve, ordenadas, las claves del documento que no
+// corresponden a ningún campo de t
+func unknownKeys(doc map[string]interface{}, t reflect.Type, prefix string) []string {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		if key := yamlKey(t.Field(i)); key != "" {
+			fields[key] = t.Field(i).Type
+		}
+	}
+
+	var unknown []string
+	for key, value := range doc {
+		fieldType, ok := fields[key]
+		if !ok {
+			unknown = append(unknown, prefix+key)
+			continue
+		}
+		if nested, isMap := value.(map[string]interface{}); isMap && fieldType.Kind() == reflect.Struct {
+			unknown = append(unknown, unknownKeys(nested, fieldType, prefix+key+".")...)
+		}
+	}
+
+	sort.Strings(unknown)
+	return unknown
+}
+
+// yamlKey devuelve el nombre YAML de un campo ("" si se ignora)
+func yamlKey(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestMigrate_LegacyFixture(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "legacy-config.yml"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	result, err := Migrate(data)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	if result.FromVersion != 0 {
+		t.Errorf("FromVersion = %d, want 0", result.FromVersion)
+	}
+	if !result.Changed() {
+		t.Fatal("expected legacy config to change")
+	}
+
+	cfg := result.Config
+	if cfg.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", cfg.SchemaVersion, CurrentSchemaVersion)
+	}
+	if cfg.Registry.GHCRToken != "ghp_legacy_token" {
+		t.Errorf("GHCRToken = %q, want legacy ghcr.token", cfg.Registry.GHCRToken)
+	}
+	if cfg.Registry.Timeout != 45 {
+		t.Errorf("Registry.Timeout = %d, want largest per-registry timeout 45", cfg.Registry.Timeout)
+	}
+
+	// Valores existentes se conservan y los nuevos toman su valor por defecto
+	if cfg.Telegram.BotToken != "123456:ABC" || !cfg.Telegram.Enabled {
+		t.Errorf("telegram settings not preserved: %+v", cfg.Telegram)
+	}
+	if cfg.Scan.Recursive {
+		t.Error("expected scan.recursive=false to be preserved")
+	}
+	if cfg.Scan.Timeout != DefaultConfig().Scan.Timeout {
+		t.Errorf("Scan.Timeout = %d, want default %d", cfg.Scan.Timeout, DefaultConfig().Scan.Timeout)
+	}
+	if cfg.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", cfg.SchemaVersion, CurrentSchemaVersion)
+	}
+
+	changes := strings.Join(result.Changes, "\n")
+	for _, expected := range []string{
+		"renamed registry.ghcr.token -> registry.ghcr_token",
+		"removed deprecated registry.ghcr section",
+		"removed deprecated registry.dockerhub.enabled",
+		"added default scan.timeout",
+		"set schema_version 0 -> 1",
+	} {
+		if !strings.Contains(changes, expected) {
+			t.Errorf("expected change %q, got:\n%s", expected, changes)
+		}
+	}
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "scan.notify_on_errors") {
+		t.Errorf("expected a single unknown-key warning for scan.notify_on_errors, got %v", result.Warnings)
+	}
+
+	// El resultado es estable: migrarlo otra vez no cambia nada
+	migrated, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal migrated config: %v", err)
+	}
+	again, err := Migrate(migrated)
+	if err != nil {
+		t.Fatalf("second Migrate failed: %v", err)
+	}
+	if again.Changed() {
+		t.Errorf("expected migrated config to be current, got changes %v", again.Changes)
+	}
+}
+
+func TestMigrate_KeepsExplicitRegistryTimeout(t *testing.T) {
+	data := []byte("registry:\n  timeout: 10\n  dockerhub:\n    timeout: 60\n")
+
+	result, err := Migrate(data)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if result.Config.Registry.Timeout != 10 {
+		t.Errorf("Registry.Timeout = %d, want explicit 10", result.Config.Registry.Timeout)
+	}
+}
+
+func TestMigrate_KeepsDockerHubCredentials(t *testing.T) {
+	data := []byte("registry:\n  dockerhub:\n    username: alice\n    token: dckr_pat_abc\n    mirror: mirror.example.com\n    timeout: 30\n")
+
+	result, err := Migrate(data)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	// Las credenciales y el mirror sobreviven; solo el timeout antiguo se mueve
+	hub := result.Config.Registry.DockerHub
+	if hub.Username != "alice" || hub.Token != "dckr_pat_abc" || hub.Mirror != "mirror.example.com" {
+		t.Errorf("registry.dockerhub not preserved: %+v", hub)
+	}
+	if result.Config.Registry.Timeout != 30 {
+		t.Errorf("Registry.Timeout = %d, want legacy dockerhub timeout 30", result.Config.Registry.Timeout)
+	}
+	changes := strings.Join(result.Changes, "\n")
+	if strings.Contains(changes, "registry.dockerhub section") {
+		t.Errorf("registry.dockerhub should not be removed, got changes:\n%s", changes)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+
+	// Migrar el resultado otra vez no cambia nada
+	migrated, err := yaml.Marshal(result.Config)
+	if err != nil {
+		t.Fatalf("marshal migrated config: %v", err)
+	}
+	again, err := Migrate(migrated)
+	if err != nil {
+		t.Fatalf("second Migrate failed: %v", err)
+	}
+	if again.Changed() || again.Config.Registry.DockerHub != hub {
+		t.Errorf("expected a stable migration, got changes %v and %+v", again.Changes, again.Config.Registry.DockerHub)
+	}
+}
+
+func TestMigrate_RejectsNewerSchema(t *testing.T) {
+	if _, err := Migrate([]byte("schema_version: 99\n")); err == nil {
+		t.Error("expected error for a schema_version newer than supported")
+	}
+}
@@ -6,16 +6,29 @@ import (
 	"log/slog"
 	"strings"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/system"
 	"github.com/docker/docker/client"
 
 	dockerTypes "github.com/user/docker-image-reporter/pkg/types"
 )
 
+// dockerAPI is the subset of the Docker SDK client used by Client. It exists
+// so tests can substitute a fake runtime.
+type dockerAPI interface {
+	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
+	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ImageInspect(ctx context.Context, imageID string, opts ...client.ImageInspectOption) (image.InspectResponse, error)
+	Ping(ctx context.Context) (types.Ping, error)
+	Info(ctx context.Context) (system.Info, error)
+	Close() error
+}
+
 // Client wraps Docker daemon client functionality
 type Client struct {
-	client *client.Client
+	client dockerAPI
 	logger *slog.Logger
 }
 
@@ -85,15 +98,25 @@ func (d *Client) extractImageFromContainer(ctx context.Context, cont container.S
 	if err != nil {
 		return dockerTypes.DockerImage{}, fmt.Errorf("parsing image string %s: %w", imageStr, err)
 	}
+	// A reference such as "nginx@sha256:..." carries no tag; the parser fills
+	// in "latest", which the scanner replaces with the tag the digest matches.
+	image.DigestOnly = image.Digest != "" && !hasExplicitTag(imageStr)
 
 	// Extract service name from labels or container name
 	serviceName := d.extractServiceName(cont, inspect.Config.Labels)
 	image.ServiceName = serviceName
+	image.NotifyChannel = inspect.Config.Labels[dockerTypes.NotifyChannelLabel]
 
 	// Add container context
 	image.ContainerID = cont.ID[:12]
 	image.ContainerName = d.getContainerName(cont)
 
+	// Record the digest of the local image so rolling tags such as "latest"
+	// can be compared against the digest the registry currently serves.
+	if image.Digest == "" {
+		image.Digest = d.localImageDigest(ctx, inspect.Image, image)
+	}
+
 	d.logger.Debug("Extracted image from container",
 		"container", image.ContainerName,
 		"service", serviceName,
@@ -102,6 +125,41 @@ func (d *Client) extractImageFromContainer(ctx context.Context, cont container.S
 	return image, nil
 }
 
+// hasExplicitTag reports whether the image reference names a tag, ignoring
+// any registry port and digest
+func hasExplicitTag(imageStr string) bool {
+	name, _, _ := strings.Cut(imageStr, "@")
+	return strings.LastIndex(name, ":") > strings.LastIndex(name, "/")
+}
+
+// localImageDigest returns the repo digest of the local image that matches the
+// container's registry and repository, or "" when none is recorded (e.g. the
+// image was built locally and never pulled).
+func (d *Client) localImageDigest(ctx context.Context, imageID string, ref dockerTypes.DockerImage) string {
+	if imageID == "" {
+		return ""
+	}
+
+	inspect, err := d.client.ImageInspect(ctx, imageID)
+	if err != nil {
+		d.logger.Debug("Failed to inspect local image", "image", ref.String(), "error", err)
+		return ""
+	}
+
+	for _, repoDigest := range inspect.RepoDigests {
+		name, digest, ok := strings.Cut(repoDigest, "@")
+		if !ok {
+			continue
+		}
+		registry, repository := d.parseRegistryAndRepository(name)
+		if registry == ref.Registry && repository == ref.Repository {
+			return digest
+		}
+	}
+
+	return ""
+}
+
 // extractServiceName extracts service name from container labels or name
 func (d *Client) extractServiceName(cont container.Summary, labels map[string]string) string {
 	// Try compose service label first
@@ -141,94 +199,12 @@ func (d *Client) getContainerName(cont container.Summary) string {
 
 // parseImageString parses Docker image string into components
 func (d *Client) parseImageString(imageStr string) (dockerTypes.DockerImage, error) {
-	if imageStr == "" {
-		return dockerTypes.DockerImage{}, fmt.Errorf("empty image string")
-	}
-
-	// Remove whitespace
-	imageStr = strings.TrimSpace(imageStr)
-
-	// Handle digest (@sha256:...)
-	var tag, digest string
-	if strings.Contains(imageStr, "@") {
-		parts := strings.Split(imageStr, "@")
-		if len(parts) != 2 {
-			return dockerTypes.DockerImage{}, fmt.Errorf("invalid image format with digest: %s", imageStr)
-		}
-		imageStr = parts[0]
-		digest = parts[1]
-	}
-
-	// Handle tag (:tag)
-	if strings.Contains(imageStr, "/") {
-		// Has slash, look for : after last /
-		lastSlashIndex := strings.LastIndex(imageStr, "/")
-		afterSlash := imageStr[lastSlashIndex:]
-
-		if strings.Contains(afterSlash, ":") {
-			colonIndex := strings.LastIndex(imageStr, ":")
-			tag = imageStr[colonIndex+1:]
-			imageStr = imageStr[:colonIndex]
-		} else {
-			tag = "latest"
-		}
-	} else {
-		// No slash, normal parsing
-		parts := strings.Split(imageStr, ":")
-		switch len(parts) {
-		case 1:
-			tag = "latest"
-		case 2:
-			tag = parts[1]
-			imageStr = parts[0]
-		default:
-			// Multiple :, use last as tag
-			lastColonIndex := strings.LastIndex(imageStr, ":")
-			tag = imageStr[lastColonIndex+1:]
-			imageStr = imageStr[:lastColonIndex]
-		}
-	}
-
-	// Parse registry and repository
-	registry, repository := d.parseRegistryAndRepository(imageStr)
-
-	return dockerTypes.DockerImage{
-		Registry:   registry,
-		Repository: repository,
-		Tag:        tag,
-		Digest:     digest,
-	}, nil
+	return dockerTypes.ParseImageReference(imageStr)
 }
 
 // parseRegistryAndRepository separates registry from repository
 func (d *Client) parseRegistryAndRepository(imageStr string) (string, string) {
-	parts := strings.Split(imageStr, "/")
-
-	switch len(parts) {
-	case 1:
-		// Just image name (e.g., "nginx")
-		// Assume Docker Hub with library/
-		return "docker.io", "library/" + parts[0]
-
-	case 2:
-		// Can be:
-		// - user/image on Docker Hub (e.g., "user/nginx")
-		// - registry/image (e.g., "localhost:5000/nginx")
-
-		// If first part contains dot, colon, or is localhost, it's a registry
-		if strings.Contains(parts[0], ".") || strings.Contains(parts[0], ":") || parts[0] == "localhost" {
-			return parts[0], parts[1]
-		}
-
-		// Otherwise, it's user/image on Docker Hub
-		return "docker.io", imageStr
-
-	default:
-		// 3+ parts: registry/namespace/image or registry/user/image
-		registry := parts[0]
-		repository := strings.Join(parts[1:], "/")
-		return registry, repository
-	}
+	return dockerTypes.SplitRegistryRepository(imageStr)
 }
 
 // Ping tests connection to Docker daemon
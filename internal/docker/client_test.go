@@ -1,13 +1,78 @@
 package docker
 
 import (
+	"context"
 	"log/slog"
 	"testing"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/client"
+
+	"github.com/user/docker-image-reporter/internal/scanner"
 	dockerTypes "github.com/user/docker-image-reporter/pkg/types"
 )
 
+// fakeRuntime implements dockerAPI with canned containers and images.
+type fakeRuntime struct {
+	containers []container.Summary
+	inspects   map[string]container.InspectResponse
+	images     map[string]image.InspectResponse
+}
+
+func (f *fakeRuntime) ContainerList(_ context.Context, _ container.ListOptions) ([]container.Summary, error) {
+	return f.containers, nil
+}
+
+func (f *fakeRuntime) ContainerInspect(_ context.Context, containerID string) (container.InspectResponse, error) {
+	return f.inspects[containerID], nil
+}
+
+func (f *fakeRuntime) ImageInspect(_ context.Context, imageID string, _ ...client.ImageInspectOption) (image.InspectResponse, error) {
+	return f.images[imageID], nil
+}
+
+func (f *fakeRuntime) Ping(_ context.Context) (types.Ping, error) { return types.Ping{}, nil }
+
+func (f *fakeRuntime) Info(_ context.Context) (system.Info, error) { return system.Info{}, nil }
+
+func (f *fakeRuntime) Close() error { return nil }
+
+// digestRegistry is a registry mock that serves a fixed digest for every tag,
+// unless digests maps the tag to its own digest.
+type digestRegistry struct {
+	tags    []string
+	digest  string
+	digests map[string]string
+}
+
+func (r *digestRegistry) GetLatestTags(_ context.Context, _ dockerTypes.DockerImage) ([]string, error) {
+	return r.tags, nil
+}
+
+func (r *digestRegistry) GetTags(_ context.Context, _ dockerTypes.DockerImage) ([]dockerTypes.TagInfo, error) {
+	tags := make([]dockerTypes.TagInfo, 0, len(r.tags))
+	for _, tag := range r.tags {
+		tags = append(tags, dockerTypes.TagInfo{Name: tag})
+	}
+	return tags, nil
+}
+
+func (r *digestRegistry) GetImageInfo(_ context.Context, _ dockerTypes.DockerImage) (*dockerTypes.ImageInfo, error) {
+	return nil, nil
+}
+
+func (r *digestRegistry) GetDigest(_ context.Context, image dockerTypes.DockerImage) (string, error) {
+	if digest, ok := r.digests[image.Tag]; ok {
+		return digest, nil
+	}
+	return r.digest, nil
+}
+
+func (r *digestRegistry) Name() string { return "generic" }
+
 func TestParseImageString(t *testing.T) {
 	logger := slog.Default()
 	client := &Client{logger: logger}
@@ -73,6 +138,15 @@ func TestParseImageString(t *testing.T) {
 				Digest:     "sha256:abc123",
 			},
 		},
+		{
+			name:     "registry with port and nested path",
+			imageStr: "localhost:5000/team/app/sub",
+			want: dockerTypes.DockerImage{
+				Registry:   "localhost:5000",
+				Repository: "team/app/sub",
+				Tag:        "latest",
+			},
+		},
 		{
 			name:     "empty string",
 			imageStr: "",
@@ -134,6 +208,30 @@ func TestParseRegistryAndRepository(t *testing.T) {
 			wantRegistry:   "ghcr.io",
 			wantRepository: "user/app",
 		},
+		{
+			name:           "docker hub nested path",
+			imageStr:       "team/app/sub",
+			wantRegistry:   "docker.io",
+			wantRepository: "team/app/sub",
+		},
+		{
+			name:           "explicit docker.io/library",
+			imageStr:       "docker.io/library/nginx",
+			wantRegistry:   "docker.io",
+			wantRepository: "library/nginx",
+		},
+		{
+			name:           "index.docker.io/library",
+			imageStr:       "index.docker.io/library/nginx",
+			wantRegistry:   "docker.io",
+			wantRepository: "library/nginx",
+		},
+		{
+			name:           "index.docker.io without library",
+			imageStr:       "index.docker.io/nginx",
+			wantRegistry:   "docker.io",
+			wantRepository: "library/nginx",
+		},
 	}
 
 	for _, tt := range tests {
@@ -242,3 +340,213 @@ func TestGetContainerName(t *testing.T) {
 		})
 	}
 }
+
+func TestScanRunningContainers_LatestDigestDrift(t *testing.T) {
+	const (
+		containerID  = "0123456789abcdef0123"
+		imageID      = "sha256:feedface"
+		localDigest  = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		remoteDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	runtime := &fakeRuntime{
+		containers: []container.Summary{{ID: containerID, Names: []string{"/web"}}},
+		inspects: map[string]container.InspectResponse{
+			containerID: {
+				ContainerJSONBase: &container.ContainerJSONBase{Image: imageID},
+				Config: &container.Config{
+					Image:  "nginx:latest",
+					Labels: map[string]string{"com.docker.compose.service": "web"},
+				},
+			},
+		},
+		images: map[string]image.InspectResponse{
+			imageID: {RepoDigests: []string{
+				"ghcr.io/other/nginx@sha256:3333333333333333333333333333333333333333333333333333333333333333",
+				"nginx@" + localDigest,
+			}},
+		},
+	}
+
+	c := &Client{client: runtime, logger: slog.Default()}
+	images, err := c.ScanRunningContainers(context.Background())
+	if err != nil {
+		t.Fatalf("ScanRunningContainers() error = %v", err)
+	}
+	if len(images) != 1 {
+		t.Fatalf("expected 1 image, got %d", len(images))
+	}
+	if images[0].Digest != localDigest {
+		t.Fatalf("Digest = %q, want %q", images[0].Digest, localDigest)
+	}
+
+	registry := &digestRegistry{tags: []string{"latest", "1.25", "alpine"}, digest: remoteDigest}
+	result, err := scanner.NewService(nil, []dockerTypes.RegistryClient{registry}, slog.Default()).
+		ScanImages(context.Background(), images, "docker-daemon")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+
+	if len(result.UpdatesAvailable) != 1 {
+		t.Fatalf("expected 1 update, got %d (errors: %v)", len(result.UpdatesAvailable), result.Errors)
+	}
+	update := result.UpdatesAvailable[0]
+	if update.UpdateType != dockerTypes.UpdateTypeDigest {
+		t.Errorf("UpdateType = %s, want %s", update.UpdateType, dockerTypes.UpdateTypeDigest)
+	}
+	if update.Reason != dockerTypes.UpdateReasonDigest {
+		t.Errorf("Reason = %s, want %s", update.Reason, dockerTypes.UpdateReasonDigest)
+	}
+	if update.LatestImage.Digest != remoteDigest {
+		t.Errorf("LatestImage.Digest = %q, want %q", update.LatestImage.Digest, remoteDigest)
+	}
+}
+
+func TestScanRunningContainers_DockerHubHostForms(t *testing.T) {
+	const (
+		containerID = "0123456789abcdef0123"
+		imageID     = "sha256:feedface"
+		localDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	)
+
+	tests := []struct {
+		name       string
+		image      string
+		repoDigest string
+	}{
+		{name: "bare", image: "nginx:1.25", repoDigest: "docker.io/library/nginx@" + localDigest},
+		{name: "docker.io/library", image: "docker.io/library/nginx:1.25", repoDigest: "nginx@" + localDigest},
+		{name: "index.docker.io/library", image: "index.docker.io/library/nginx:1.25", repoDigest: "index.docker.io/library/nginx@" + localDigest},
+		{name: "registry-1.docker.io", image: "registry-1.docker.io/library/nginx:1.25", repoDigest: "docker.io/nginx@" + localDigest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtime := &fakeRuntime{
+				containers: []container.Summary{{ID: containerID, Names: []string{"/web"}}},
+				inspects: map[string]container.InspectResponse{
+					containerID: {
+						ContainerJSONBase: &container.ContainerJSONBase{Image: imageID},
+						Config:            &container.Config{Image: tt.image},
+					},
+				},
+				images: map[string]image.InspectResponse{imageID: {RepoDigests: []string{tt.repoDigest}}},
+			}
+
+			c := &Client{client: runtime, logger: slog.Default()}
+			images, err := c.ScanRunningContainers(context.Background())
+			if err != nil {
+				t.Fatalf("ScanRunningContainers() error = %v", err)
+			}
+			if len(images) != 1 {
+				t.Fatalf("expected 1 image, got %d", len(images))
+			}
+			got := images[0]
+			if got.Registry != "docker.io" || got.Repository != "library/nginx" {
+				t.Errorf("image = %s/%s, want docker.io/library/nginx", got.Registry, got.Repository)
+			}
+			if got.Digest != localDigest {
+				t.Errorf("Digest = %q, want %q", got.Digest, localDigest)
+			}
+
+			// A client registered only for Docker Hub resolves the image
+			registry := &dockerHubRegistry{digestRegistry{tags: []string{"1.25", "1.26"}}}
+			result, err := scanner.NewService(nil, []dockerTypes.RegistryClient{registry}, slog.Default()).
+				ScanImages(context.Background(), images, "docker-daemon")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+			if len(result.Errors) != 0 || len(result.UpdatesAvailable) != 1 {
+				t.Errorf("updates = %v, errors = %v, want one update", result.UpdatesAvailable, result.Errors)
+			}
+		})
+	}
+}
+
+// dockerHubRegistry is a digestRegistry that only handles Docker Hub
+type dockerHubRegistry struct {
+	digestRegistry
+}
+
+func (r *dockerHubRegistry) Name() string { return "docker.io" }
+
+func TestScanRunningContainers_DigestOnlyReference(t *testing.T) {
+	const (
+		containerID = "0123456789abcdef0123"
+		imageID     = "sha256:feedface"
+		pinned      = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		newer       = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		older       = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+	)
+
+	runtime := &fakeRuntime{
+		containers: []container.Summary{{ID: containerID, Names: []string{"/web"}}},
+		inspects: map[string]container.InspectResponse{
+			containerID: {
+				ContainerJSONBase: &container.ContainerJSONBase{Image: imageID},
+				Config: &container.Config{
+					Image:  "nginx@" + pinned,
+					Labels: map[string]string{"com.docker.compose.service": "web"},
+				},
+			},
+		},
+	}
+
+	c := &Client{client: runtime, logger: slog.Default()}
+	images, err := c.ScanRunningContainers(context.Background())
+	if err != nil {
+		t.Fatalf("ScanRunningContainers() error = %v", err)
+	}
+	if len(images) != 1 || !images[0].DigestOnly || images[0].Digest != pinned {
+		t.Fatalf("expected one digest-only image pinned to %s, got %+v", pinned, images)
+	}
+
+	tests := []struct {
+		name        string
+		digests     map[string]string
+		wantUpdate  bool
+		wantCurrent string
+	}{
+		{
+			name:        "digest matches 1.20",
+			digests:     map[string]string{"1": newer, "1.21": newer, "1.20": pinned, "1.19": older},
+			wantUpdate:  true,
+			wantCurrent: "1.20",
+		},
+		{
+			name:    "digest not found upstream",
+			digests: map[string]string{"1": newer, "1.21": newer, "1.20": older, "1.19": older, "latest": pinned},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &digestRegistry{tags: []string{"latest", "1", "1.19", "1.20", "1.21"}, digests: tt.digests}
+			result, err := scanner.NewService(nil, []dockerTypes.RegistryClient{registry}, slog.Default()).
+				ScanImages(context.Background(), images, "docker-daemon")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+			if len(result.Errors) != 0 {
+				t.Fatalf("unexpected errors: %v", result.Errors)
+			}
+
+			if !tt.wantUpdate {
+				if len(result.UpdatesAvailable) != 0 {
+					t.Fatalf("expected no updates, got %+v", result.UpdatesAvailable)
+				}
+				return
+			}
+			if len(result.UpdatesAvailable) != 1 {
+				t.Fatalf("expected 1 update, got %d", len(result.UpdatesAvailable))
+			}
+			update := result.UpdatesAvailable[0]
+			if update.CurrentImage.Tag != tt.wantCurrent {
+				t.Errorf("CurrentImage.Tag = %q, want %q", update.CurrentImage.Tag, tt.wantCurrent)
+			}
+			if update.LatestImage.Tag != "1.21" {
+				t.Errorf("LatestImage.Tag = %q, want 1.21", update.LatestImage.Tag)
+			}
+		})
+	}
+}
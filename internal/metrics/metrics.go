@@ -0,0 +1,169 @@
+// Package metrics exposes scan telemetry and cache statistics in the
+// Prometheus text exposition format for long-running (watch) mode.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/user/docker-image-reporter/internal/cache"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// shutdownTimeout bounds how long in-flight scrapes may take on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+// Metrics holds the latest scan telemetry. It is safe for concurrent use.
+type Metrics struct {
+	mu           sync.RWMutex
+	scans        int64
+	failures     int64
+	lastScan     time.Time
+	lastDuration time.Duration
+	services     int
+	upToDate     int
+	files        int
+	errors       int
+	updates      map[types.UpdateType]int
+	rateLimits   []types.RateLimit
+	cacheStats   *cache.CacheStats
+}
+
+// New creates an empty metrics registry.
+func New() *Metrics {
+	return &Metrics{updates: make(map[types.UpdateType]int)}
+}
+
+// RecordScan stores the outcome of a completed scan cycle.
+func (m *Metrics) RecordScan(result types.ScanResult, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.scans++
+	m.lastScan = result.ScanTimestamp
+	m.lastDuration = duration
+	m.services = result.TotalServicesFound
+	m.upToDate = len(result.UpToDateServices)
+	m.files = len(result.FilesScanned)
+	m.errors = len(result.Errors)
+
+	m.updates = make(map[types.UpdateType]int)
+	for _, update := range result.UpdatesAvailable {
+		m.updates[update.UpdateType]++
+	}
+	m.rateLimits = result.RateLimits
+}
+
+// RecordFailure counts a scan cycle that could not complete.
+func (m *Metrics) RecordFailure() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures++
+}
+
+// RecordCache stores a snapshot of registry cache statistics.
+func (m *Metrics) RecordCache(stats cache.CacheStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cacheStats = &stats
+}
+
+// ServeHTTP writes all metrics in Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.Write(w)
+}
+
+// Write writes all metrics in Prometheus text format to w.
+func (m *Metrics) Write(w io.Writer) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	writeMetric(w, "icr_scans_total", "counter", "Completed scan cycles.", float64(m.scans))
+	writeMetric(w, "icr_scan_failures_total", "counter", "Scan cycles that failed to complete.", float64(m.failures))
+
+	var lastScan float64
+	if !m.lastScan.IsZero() {
+		lastScan = float64(m.lastScan.Unix())
+	}
+	writeMetric(w, "icr_last_scan_timestamp_seconds", "gauge", "Unix time of the last completed scan.", lastScan)
+	writeMetric(w, "icr_scan_duration_seconds", "gauge", "Duration of the last completed scan.", m.lastDuration.Seconds())
+	writeMetric(w, "icr_services", "gauge", "Services found in the last scan.", float64(m.services))
+	writeMetric(w, "icr_services_up_to_date", "gauge", "Services up to date in the last scan.", float64(m.upToDate))
+	writeMetric(w, "icr_files_scanned", "gauge", "Compose files scanned in the last scan.", float64(m.files))
+	writeMetric(w, "icr_scan_errors", "gauge", "Errors reported by the last scan.", float64(m.errors))
+
+	fmt.Fprintln(w, "# HELP icr_updates_available Updates available in the last scan by update type.")
+	fmt.Fprintln(w, "# TYPE icr_updates_available gauge")
+	for _, updateType := range []types.UpdateType{
+		types.UpdateTypeMajor, types.UpdateTypeMinor, types.UpdateTypePatch, types.UpdateTypeUnknown, types.UpdateTypeDigest,
+	} {
+		fmt.Fprintf(w, "icr_updates_available{type=%q} %d\n", string(updateType), m.updates[updateType])
+	}
+
+	if len(m.rateLimits) > 0 {
+		fmt.Fprintln(w, "# HELP icr_registry_rate_limit Request budget advertised by the registry (RateLimit-Limit).")
+		fmt.Fprintln(w, "# TYPE icr_registry_rate_limit gauge")
+		for _, limit := range m.rateLimits {
+			fmt.Fprintf(w, "icr_registry_rate_limit{registry=%q} %d\n", limit.Registry, limit.Limit)
+		}
+		fmt.Fprintln(w, "# HELP icr_registry_rate_limit_remaining Requests left in the registry budget (RateLimit-Remaining).")
+		fmt.Fprintln(w, "# TYPE icr_registry_rate_limit_remaining gauge")
+		for _, limit := range m.rateLimits {
+			fmt.Fprintf(w, "icr_registry_rate_limit_remaining{registry=%q} %d\n", limit.Registry, limit.Remaining)
+		}
+	}
+
+	if m.cacheStats != nil {
+		writeMetric(w, "icr_cache_hits_total", "counter", "Registry cache hits.", float64(m.cacheStats.Hits))
+		writeMetric(w, "icr_cache_misses_total", "counter", "Registry cache misses.", float64(m.cacheStats.Misses))
+		writeMetric(w, "icr_cache_evictions_total", "counter", "Registry cache evictions.", float64(m.cacheStats.Evicted))
+		writeMetric(w, "icr_cache_entries", "gauge", "Registry cache entries.", float64(m.cacheStats.Size))
+	}
+}
+
+// Handler returns an HTTP handler serving the metrics at /metrics.
+func (m *Metrics) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	return mux
+}
+
+// Serve serves handler on ln until ctx is cancelled, then shuts down gracefully.
+func Serve(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+func writeMetric(w io.Writer, name, kind, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, strconv.FormatFloat(value, 'f', -1, 64))
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/docker-image-reporter/internal/cache"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+func TestMetrics_EndpointAfterScan(t *testing.T) {
+	m := New()
+	m.RecordScan(types.ScanResult{
+		ScanTimestamp:      time.Unix(1700000000, 0),
+		TotalServicesFound: 3,
+		UpToDateServices:   []string{"db"},
+		FilesScanned:       []string{"docker-compose.yml"},
+		UpdatesAvailable: []types.ImageUpdate{
+			{ServiceName: "web", UpdateType: types.UpdateTypeMinor},
+			{ServiceName: "api", UpdateType: types.UpdateTypeMinor},
+		},
+	}, 1500*time.Millisecond)
+	m.RecordCache(cache.CacheStats{Hits: 4, Misses: 2, Size: 2})
+
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	output := string(body)
+
+	expected := []string{
+		"icr_scans_total 1",
+		"icr_scan_failures_total 0",
+		"icr_last_scan_timestamp_seconds 1700000000",
+		"icr_scan_duration_seconds 1.5",
+		"icr_services 3",
+		"icr_services_up_to_date 1",
+		"icr_files_scanned 1",
+		"icr_scan_errors 0",
+		`icr_updates_available{type="minor"} 2`,
+		`icr_updates_available{type="major"} 0`,
+		"icr_cache_hits_total 4",
+		"icr_cache_misses_total 2",
+		"icr_cache_evictions_total 0",
+		"icr_cache_entries 2",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("metrics output missing %q\n%s", line, output)
+		}
+	}
+}
+
+func TestMetrics_NoCacheStats(t *testing.T) {
+	var sb strings.Builder
+	New().Write(&sb)
+
+	if strings.Contains(sb.String(), "icr_cache_") {
+		t.Errorf("cache metrics should be omitted before RecordCache\n%s", sb.String())
+	}
+}
+
+func TestServe_ShutsDownOnCancel(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, ln, New().Handler())
+	}()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics error = %v", err)
+	}
+	resp.Body.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() error = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve() did not return after context cancellation")
+	}
+}
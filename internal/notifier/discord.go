@@ -0,0 +1,326 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// Límites de Discord para mensajes y embeds
+const (
+	maxDiscordContent    = 2000
+	maxDiscordTitle      = 256
+	maxDiscordFieldName  = 256
+	maxDiscordFieldValue = 1024
+	maxDiscordFields     = 25
+	maxDiscordEmbedChars = 6000
+)
+
+// discordColors es el color del embed según la actualización más grave
+var discordColors = map[types.UpdateType]int{
+	types.UpdateTypeMajor:  0xE74C3C,
+	types.UpdateTypeMinor:  0xF1C40F,
+	types.UpdateTypePatch:  0x2ECC71,
+	types.UpdateTypeDigest: 0x3498DB,
+}
+
+// discordErrorColor es el color de un embed que solo contiene errores
+const discordErrorColor = 0xE67E22
+
+// discordTagReplacer traduce las etiquetas HTML de los mensajes (pensados para
+// Telegram) a markdown de Discord
+var discordTagReplacer = strings.NewReplacer(
+	"<b>", "**", "</b>", "**",
+	"<i>", "*", "</i>", "*",
+	"<code>", "`", "</code>", "`",
+	"<pre>", "```", "</pre>", "```",
+)
+
+// DiscordClient implementa NotificationClient publicando en un webhook de
+// Discord. Implementa también types.ScanResultNotifier: los resultados de
+// escaneo se envían como un embed con un campo por actualización.
+type DiscordClient struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// discordPayload es el cuerpo que se envía al webhook
+type discordPayload struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []discordEmbed `json:"embeds,omitempty"`
+}
+
+// discordEmbed es un embed de Discord
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color,omitempty"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+}
+
+// discordField es un campo de un embed
+type discordField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// size devuelve los caracteres del campo que cuentan para el límite del embed
+func (f discordField) size() int {
+	return utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+}
+
+// NewDiscordClient crea un cliente para el webhook webhookURL
+func NewDiscordClient(webhookURL string) *DiscordClient {
+	return &DiscordClient{
+		webhookURL: webhookURL,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// SendNotification publica el mensaje como texto, dividido en partes de
+// como mucho maxDiscordContent caracteres
+func (d *DiscordClient) SendNotification(ctx context.Context, message string) error {
+	if d.webhookURL == "" {
+		return errors.New("discord.SendNotification", "webhook URL is required")
+	}
+
+	for i, part := range splitMessage(toDiscordMarkdown(message), maxDiscordContent) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		if err := d.postJSON(ctx, discordPayload{Content: part}); err != nil {
+			return errors.Wrapf("discord.SendNotification", err, "failed to send message part %d", i+1)
+		}
+	}
+	return nil
+}
+
+// SendScanResult publica el resultado como un embed
+func (d *DiscordClient) SendScanResult(ctx context.Context, result types.ScanResult) error {
+	if d.webhookURL == "" {
+		return errors.New("discord.SendScanResult", "webhook URL is required")
+	}
+
+	if err := d.postJSON(ctx, discordPayload{Embeds: []discordEmbed{scanResultEmbed(result)}}); err != nil {
+		return errors.Wrap("discord.SendScanResult", err)
+	}
+	return nil
+}
+
+// SendFile sube el archivo como adjunto del webhook, con caption como texto
+// del mensaje
+func (d *DiscordClient) SendFile(ctx context.Context, filePath, fileName, caption string) error {
+	if d.webhookURL == "" {
+		return errors.New("discord.SendFile", "webhook URL is required")
+	}
+
+	fileData, err := os.ReadFile(filePath) //nolint:gosec
+	if err != nil {
+		return errors.Wrap("discord.SendFile", err)
+	}
+
+	payload, err := json.Marshal(discordPayload{Content: truncateRunes(toDiscordMarkdown(caption), maxDiscordContent)})
+	if err != nil {
+		return errors.Wrap("discord.SendFile", err)
+	}
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	if err := w.WriteField("payload_json", string(payload)); err != nil {
+		return errors.Wrap("discord.SendFile", err)
+	}
+	fw, err := w.CreateFormFile("files[0]", fileName)
+	if err != nil {
+		return errors.Wrap("discord.SendFile", err)
+	}
+	if _, err := fw.Write(fileData); err != nil {
+		return errors.Wrap("discord.SendFile", err)
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap("discord.SendFile", err)
+	}
+
+	if err := d.post(ctx, w.FormDataContentType(), &b); err != nil {
+		return errors.Wrap("discord.SendFile", err)
+	}
+	return nil
+}
+
+// Name devuelve el nombre del cliente de notificación
+func (d *DiscordClient) Name() string {
+	return "discord"
+}
+
+// postJSON envía un payload JSON al webhook
+func (d *DiscordClient) postJSON(ctx context.Context, payload discordPayload) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap("discord.postJSON", err)
+	}
+	return d.post(ctx, "application/json", bytes.NewReader(jsonData))
+}
+
+// post envía un cuerpo al webhook. Discord responde 204 sin cuerpo, o 200 con
+// el mensaje creado si se pide con ?wait=true
+func (d *DiscordClient) post(ctx context.Context, contentType string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, body)
+	if err != nil {
+		return errors.Wrap("discord.post", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return errors.Wrap("discord.post", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Newf("discord.post", "discord webhook error: %s (status: %d)", strings.TrimSpace(string(respBody)), resp.StatusCode)
+	}
+	return nil
+}
+
+// scanResultEmbed construye el embed de un resultado: título con el proyecto,
+// el resumen como descripción y un campo por actualización, de mayor a menor
+// severidad. Las actualizaciones que no caben en los límites de Discord se
+// resumen en un último campo; los errores van en un campo propio.
+func scanResultEmbed(result types.ScanResult) discordEmbed {
+	embed := discordEmbed{
+		Title:       truncateRunes("🐳 Docker Image Updates — "+result.ProjectName, maxDiscordTitle),
+		Description: result.Summary(),
+		Color:       discordErrorColor,
+	}
+	if !result.ScanTimestamp.IsZero() {
+		embed.Timestamp = result.ScanTimestamp.UTC().Format(time.RFC3339)
+	}
+
+	var updates []types.ImageUpdate
+	for i, group := range result.GroupUpdates(types.GroupBySeverity) {
+		if i == 0 {
+			if color, ok := discordColors[types.UpdateType(group.Name)]; ok {
+				embed.Color = color
+			}
+		}
+		updates = append(updates, group.Updates...)
+	}
+
+	// Reservar sitio para el campo de errores y el de actualizaciones omitidas
+	var trailer []discordField
+	if result.HasErrors() {
+		trailer = append(trailer, discordErrorsField(result.Errors))
+	}
+	fields := maxDiscordFields - len(trailer)
+	chars := maxDiscordEmbedChars - utf8.RuneCountInString(embed.Title) - utf8.RuneCountInString(embed.Description)
+	for _, field := range trailer {
+		chars -= field.size()
+	}
+
+	for i, update := range updates {
+		field := discordUpdateField(update)
+		remaining := len(updates) - i - 1
+		needed := field.size()
+		if remaining > 0 {
+			// El campo de omitidas tiene que seguir cabiendo detrás de este
+			needed += discordOmittedField(remaining).size()
+		}
+		if len(embed.Fields)+1 > fields-min(remaining, 1) || needed > chars {
+			omitted := discordOmittedField(len(updates) - i)
+			embed.Fields = append(embed.Fields, omitted)
+			break
+		}
+		embed.Fields = append(embed.Fields, field)
+		chars -= field.size()
+	}
+
+	embed.Fields = append(embed.Fields, trailer...)
+	return embed
+}
+
+// discordUpdateField devuelve el campo de una actualización
+func discordUpdateField(update types.ImageUpdate) discordField {
+	header, ok := severityHeaders[update.UpdateType]
+	if !ok {
+		header = severityHeaders[types.UpdateTypeUnknown]
+	}
+	emoji, _, _ := strings.Cut(header, " ")
+	name := truncateRunes(emoji+" "+update.ServiceName, maxDiscordFieldName)
+
+	if update.UpdateType == types.UpdateTypeDigest {
+		return discordField{Name: name, Value: truncateRunes(fmt.Sprintf("`%s` rebuilt", update.CurrentImage.String()), maxDiscordFieldValue)}
+	}
+
+	target := fmt.Sprintf("`%s`", update.LatestImage.Tag)
+	if update.RecommendedImage != nil && update.RecommendedImage.Tag != update.LatestImage.Tag {
+		target = fmt.Sprintf("recommended `%s`, latest %s", update.RecommendedImage.Tag, target)
+	}
+	var notes []string
+	if behind := update.BehindText(); behind != "" {
+		notes = append(notes, behind)
+	}
+	if size := update.SizeDeltaText(); size != "" {
+		notes = append(notes, size)
+	}
+	if len(notes) > 0 {
+		target += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return discordField{Name: name, Value: truncateRunes(fmt.Sprintf("`%s` → %s", update.CurrentImage.String(), target), maxDiscordFieldValue)}
+}
+
+// discordErrorsField devuelve el campo con los errores del escaneo, una línea
+// por error
+func discordErrorsField(scanErrors []string) discordField {
+	lines := make([]string, len(scanErrors))
+	for i, scanErr := range scanErrors {
+		lines[i] = "• " + scanErr
+	}
+	return discordField{
+		Name:  fmt.Sprintf("⚠️ Errors (%d)", len(scanErrors)),
+		Value: truncateRunes(strings.Join(lines, "\n"), maxDiscordFieldValue),
+	}
+}
+
+// discordOmittedField devuelve el campo que resume las actualizaciones que no
+// caben en el embed
+func discordOmittedField(count int) discordField {
+	return discordField{Name: "…", Value: fmt.Sprintf("%d more updates not shown", count)}
+}
+
+// toDiscordMarkdown convierte un mensaje en HTML de Telegram a markdown de
+// Discord: negrita, cursiva y código cambian de sintaxis, el resto de
+// etiquetas se eliminan y las entidades se decodifican
+func toDiscordMarkdown(message string) string {
+	text := htmlTagRegex.ReplaceAllStringFunc(message, func(tag string) string {
+		if replaced := discordTagReplacer.Replace(tag); replaced != tag {
+			return replaced
+		}
+		return ""
+	})
+	return html.UnescapeString(text)
+}
+
+// truncateRunes recorta s a como mucho n caracteres, terminando en "…" si se
+// recortó
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
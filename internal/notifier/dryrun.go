@@ -0,0 +1,42 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DryRunClient implementa NotificationClient escribiendo en w lo que se
+// enviaría, sin contactar con ningún servicio externo
+type DryRunClient struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+// NewDryRunClient crea un cliente de prueba que se identifica como name
+func NewDryRunClient(name string, w io.Writer) *DryRunClient {
+	return &DryRunClient{name: name, w: w}
+}
+
+// SendNotification escribe el mensaje en lugar de enviarlo
+func (d *DryRunClient) SendNotification(ctx context.Context, message string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, err := fmt.Fprintf(d.w, "--- dry-run notification to %s ---\n%s\n", d.name, message)
+	return err
+}
+
+// SendFile escribe el nombre y el caption del adjunto en lugar de enviarlo
+func (d *DryRunClient) SendFile(ctx context.Context, filePath, fileName, caption string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, err := fmt.Fprintf(d.w, "--- dry-run file %s to %s ---\n%s\n", fileName, d.name, caption)
+	return err
+}
+
+// Name devuelve el nombre del destino simulado
+func (d *DryRunClient) Name() string {
+	return d.name
+}
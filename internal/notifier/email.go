@@ -0,0 +1,278 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+const (
+	// emailTimeout limita cada envío cuando el contexto no tiene plazo
+	emailTimeout = 30 * time.Second
+	// emailDefaultSubject es el asunto de los mensajes sin primera línea
+	emailDefaultSubject = "Docker Image Reporter"
+	// maxEmailSubject es la longitud máxima del asunto en caracteres
+	maxEmailSubject = 120
+)
+
+// EmailClient implementa NotificationClient enviando correos por SMTP. Si el
+// servidor anuncia STARTTLS la conexión se cifra antes de autenticarse; sin
+// él, net/smtp solo envía credenciales a localhost.
+type EmailClient struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	to       []string
+	// tlsConfig se usa en STARTTLS; nil = verificar el certificado de host
+	tlsConfig *tls.Config
+}
+
+// NewEmailClient crea un cliente SMTP a partir de la configuración de correo
+func NewEmailClient(cfg types.EmailConfig) *EmailClient {
+	return &EmailClient{
+		host:     cfg.Host,
+		port:     cfg.SMTPPort(),
+		username: cfg.Username,
+		password: cfg.Password,
+		from:     cfg.From,
+		to:       cfg.To,
+	}
+}
+
+// SendNotification envía el mensaje como correo HTML, con una alternativa en
+// texto plano. El asunto es la primera línea del mensaje.
+func (e *EmailClient) SendNotification(ctx context.Context, message string) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := writeTextPart(w, "text/plain", emailPlainText(message)); err != nil {
+		return errors.Wrap("email.SendNotification", err)
+	}
+	if err := writeTextPart(w, "text/html", emailHTML(message)); err != nil {
+		return errors.Wrap("email.SendNotification", err)
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap("email.SendNotification", err)
+	}
+
+	if err := e.send(ctx, emailSubject(message), "multipart/alternative; boundary="+w.Boundary(), body.Bytes()); err != nil {
+		return errors.Wrap("email.SendNotification", err)
+	}
+	return nil
+}
+
+// SendFile envía un correo con caption como cuerpo HTML y el archivo adjunto
+func (e *EmailClient) SendFile(ctx context.Context, filePath, fileName, caption string) error {
+	fileData, err := os.ReadFile(filePath) //nolint:gosec
+	if err != nil {
+		return errors.Wrap("email.SendFile", err)
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := writeTextPart(w, "text/html", emailHTML(caption)); err != nil {
+		return errors.Wrap("email.SendFile", err)
+	}
+	if err := writeAttachment(w, fileName, fileData); err != nil {
+		return errors.Wrap("email.SendFile", err)
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap("email.SendFile", err)
+	}
+
+	if err := e.send(ctx, emailSubject(caption), "multipart/mixed; boundary="+w.Boundary(), body.Bytes()); err != nil {
+		return errors.Wrap("email.SendFile", err)
+	}
+	return nil
+}
+
+// Name devuelve el nombre del cliente de notificación
+func (e *EmailClient) Name() string {
+	return "email"
+}
+
+// send entrega un correo con el cuerpo MIME body a todos los destinatarios
+func (e *EmailClient) send(ctx context.Context, subject, contentType string, body []byte) error {
+	if e.host == "" {
+		return errors.New("email.send", "SMTP host is required")
+	}
+	from, err := mail.ParseAddress(e.from)
+	if err != nil {
+		return errors.Wrapf("email.send", err, "invalid sender %q", e.from)
+	}
+	if len(e.to) == 0 {
+		return errors.New("email.send", "at least one recipient is required")
+	}
+	recipients := make([]*mail.Address, 0, len(e.to))
+	for _, to := range e.to {
+		address, err := mail.ParseAddress(to)
+		if err != nil {
+			return errors.Wrapf("email.send", err, "invalid recipient %q", to)
+		}
+		recipients = append(recipients, address)
+	}
+
+	msg := buildEmail(from, recipients, subject, contentType, body, time.Now())
+	return e.deliver(ctx, from.Address, recipients, msg)
+}
+
+// deliver mantiene la conversación SMTP: STARTTLS si el servidor lo ofrece,
+// autenticación si hay usuario, un RCPT por destinatario y el mensaje
+func (e *EmailClient) deliver(ctx context.Context, from string, recipients []*mail.Address, msg []byte) error {
+	addr := net.JoinHostPort(e.host, strconv.Itoa(e.port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(emailTimeout)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("starting SMTP session with %s: %w", addr, err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		tlsConfig := e.tlsConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: e.host, MinVersion: tls.VersionTLS12}
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS: %w", err)
+		}
+	}
+	if e.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return fmt.Errorf("authenticating as %s: %w", e.username, err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("sender %s rejected: %w", from, err)
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient.Address); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", recipient.Address, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("starting message data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildEmail monta el correo completo: cabeceras y cuerpo MIME
+func buildEmail(from *mail.Address, recipients []*mail.Address, subject, contentType string, body []byte, date time.Time) []byte {
+	to := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		to[i] = recipient.String()
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n\r\n", contentType)
+	msg.Write(body)
+	return msg.Bytes()
+}
+
+// writeTextPart añade una parte de texto en UTF-8 codificada quoted-printable
+func writeTextPart(w *multipart.Writer, mediaType, text string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mediaType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeAttachment añade un adjunto codificado en base64 en líneas de 76 caracteres
+func writeAttachment(w *multipart.Writer, fileName string, data []byte) error {
+	mediaType := mime.TypeByExtension(filepath.Ext(fileName))
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(mediaType, map[string]string{"name": fileName})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": fileName})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := fmt.Fprintf(part, "%s\r\n", encoded[:76]); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = fmt.Fprintf(part, "%s\r\n", encoded)
+	return err
+}
+
+// emailHTML convierte un mensaje en HTML de Telegram (etiquetas en línea y
+// saltos de línea) en un documento HTML
+func emailHTML(message string) string {
+	return "<!DOCTYPE html>\n<html><body style=\"font-family: sans-serif\">\n" +
+		strings.ReplaceAll(message, "\n", "<br>\n") +
+		"\n</body></html>\n"
+}
+
+// emailPlainText devuelve el mensaje sin etiquetas HTML
+func emailPlainText(message string) string {
+	return html.UnescapeString(htmlTagRegex.ReplaceAllString(message, ""))
+}
+
+// emailSubject devuelve la primera línea no vacía del mensaje como texto plano
+func emailSubject(message string) string {
+	for _, line := range strings.Split(emailPlainText(message), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return truncateRunes(line, maxEmailSubject)
+		}
+	}
+	return emailDefaultSubject
+}
@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+)
+
+// DefaultExecTimeout es el tiempo máximo de cada ejecución si no se configura otro
+const DefaultExecTimeout = 30 * time.Second
+
+// ExecClient implementa NotificationClient ejecutando un comando con el
+// mensaje en su stdin, para integrarse con cualquier sistema. El comando se
+// ejecuta sin shell y la notificación falla si termina con código distinto de 0.
+type ExecClient struct {
+	command []string
+	timeout time.Duration
+}
+
+// NewExecClient crea un cliente que ejecuta command (programa y argumentos).
+// Un timeout <= 0 usa DefaultExecTimeout.
+func NewExecClient(command []string, timeout time.Duration) *ExecClient {
+	if timeout <= 0 {
+		timeout = DefaultExecTimeout
+	}
+	return &ExecClient{command: command, timeout: timeout}
+}
+
+// SendNotification ejecuta el comando con el mensaje en stdin.
+// ICR_NOTIFICATION_KIND vale "message".
+func (e *ExecClient) SendNotification(ctx context.Context, message string) error {
+	return e.run(ctx, "exec.SendNotification", strings.NewReader(message), "ICR_NOTIFICATION_KIND=message")
+}
+
+// SendFile ejecuta el comando con el contenido del archivo en stdin.
+// ICR_NOTIFICATION_KIND vale "file" e ICR_FILE_NAME e ICR_CAPTION describen el adjunto.
+func (e *ExecClient) SendFile(ctx context.Context, filePath, fileName, caption string) error {
+	file, err := os.Open(filePath) //nolint:gosec
+	if err != nil {
+		return errors.Wrapf("exec.SendFile", err, "opening %s", filePath)
+	}
+	defer file.Close()
+
+	return e.run(ctx, "exec.SendFile", file,
+		"ICR_NOTIFICATION_KIND=file", "ICR_FILE_NAME="+fileName, "ICR_CAPTION="+caption)
+}
+
+// Name devuelve el nombre del cliente
+func (e *ExecClient) Name() string {
+	return "exec"
+}
+
+// run ejecuta el comando con stdin y las variables de entorno extra,
+// cancelándolo si vence el timeout o se cancela ctx
+func (e *ExecClient) run(ctx context.Context, op string, stdin io.Reader, env ...string) error {
+	if len(e.command) == 0 {
+		return errors.New(op, "command is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.command[0], e.command[1:]...) //nolint:gosec
+	cmd.Stdin = stdin
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrapf(op, ctx.Err(), "running %s", e.command[0])
+		}
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return errors.Wrapf(op, err, "running %s: %s", e.command[0], detail)
+		}
+		return errors.Wrapf(op, err, "running %s", e.command[0])
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// severityHeaders asocia cada tipo de actualización con la cabecera de su sección
+var severityHeaders = map[types.UpdateType]string{
+	types.UpdateTypeMajor:   "🔴 <b>Major</b>",
+	types.UpdateTypeMinor:   "🟡 <b>Minor</b>",
+	types.UpdateTypePatch:   "🟢 <b>Patch</b>",
+	types.UpdateTypeDigest:  "🔵 <b>Rebuilt (digest)</b>",
+	types.UpdateTypeUnknown: "⚪ <b>Other</b>",
+}
+
+// TelegramFormatter implementa ReportFormatter generando un mensaje de Telegram
+// en modo HTML. Si Template no está vacío se usa como text/template sobre el
+// ScanResult; si no, se genera un mensaje agrupado por severidad.
+type TelegramFormatter struct {
+	Template string
+}
+
+// Format convierte un ScanResult en el texto del mensaje. Los mensajes largos
+// no se recortan aquí: TelegramClient los divide por líneas al enviarlos.
+func (f TelegramFormatter) Format(result types.ScanResult) (string, error) {
+	if strings.TrimSpace(f.Template) != "" {
+		return f.formatTemplate(result)
+	}
+	return formatBySeverity(result), nil
+}
+
+// FormatName devuelve el nombre del formato
+func (f TelegramFormatter) FormatName() string {
+	return "telegram"
+}
+
+// formatTemplate ejecuta el template configurado por el usuario
+func (f TelegramFormatter) formatTemplate(result types.ScanResult) (string, error) {
+	tmpl, err := template.New("telegram").Parse(f.Template)
+	if err != nil {
+		return "", errors.Wrap("notifier.formatTemplate", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, result); err != nil {
+		return "", errors.Wrap("notifier.formatTemplate", err)
+	}
+	return buf.String(), nil
+}
+
+// priorityHeader es la cabecera de la sección de actualizaciones prioritarias
+// (scan.priority), que va antes de las secciones por severidad
+const priorityHeader = "🚨 <b>Priority</b>"
+
+// formatBySeverity genera el mensaje por defecto: cabecera con recuentos, las
+// actualizaciones prioritarias, una sección por severidad con una línea por
+// actualización, errores y pie con la fecha del escaneo. Cada línea es HTML autocontenido para poder dividir el
+// mensaje en cualquier salto de línea.
+func formatBySeverity(result types.ScanResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "🐳 <b>Docker Image Updates</b> — %s\n", html.EscapeString(result.ProjectName))
+	fmt.Fprintf(&b, "📊 %s\n", html.EscapeString(result.Summary()))
+
+	// Las prioritarias van en su propia sección, con la severidad en cada línea
+	var priority, rest []types.ImageUpdate
+	for _, update := range result.UpdatesAvailable {
+		if update.Priority {
+			priority = append(priority, update)
+		} else {
+			rest = append(rest, update)
+		}
+	}
+	if len(priority) > 0 {
+		fmt.Fprintf(&b, "\n%s (%d)\n", priorityHeader, len(priority))
+		for _, update := range priority {
+			line := strings.TrimSuffix(formatUpdateLine(update), "\n")
+			fmt.Fprintf(&b, "%s [%s]\n", line, html.EscapeString(update.UpdateType.String()))
+		}
+	}
+
+	others := result
+	others.UpdatesAvailable = rest
+	for _, group := range others.GroupUpdates(types.GroupBySeverity) {
+		header, ok := severityHeaders[types.UpdateType(group.Name)]
+		if !ok {
+			header = severityHeaders[types.UpdateTypeUnknown]
+		}
+		fmt.Fprintf(&b, "\n%s (%d)\n", header, len(group.Updates))
+		for _, update := range group.Updates {
+			b.WriteString(formatUpdateLine(update))
+		}
+	}
+
+	if result.HasErrors() {
+		fmt.Fprintf(&b, "\n⚠️ <b>Errors</b> (%d)\n", len(result.Errors))
+		for _, scanErr := range result.Errors {
+			fmt.Fprintf(&b, "• %s\n", html.EscapeString(scanErr))
+		}
+	}
+
+	fmt.Fprintf(&b, "\n📅 Scanned: %s", result.ScanTimestamp.Format("2006-01-02 15:04:05"))
+	return b.String()
+}
+
+// formatUpdateLine devuelve la línea de una actualización
+func formatUpdateLine(update types.ImageUpdate) string {
+	if update.UpdateType == types.UpdateTypeDigest {
+		return fmt.Sprintf("• <b>%s</b>: <code>%s</code> rebuilt\n",
+			html.EscapeString(update.ServiceName), html.EscapeString(update.CurrentImage.String()))
+	}
+
+	target := fmt.Sprintf("<code>%s</code>", html.EscapeString(update.LatestImage.Tag))
+	if update.RecommendedImage != nil && update.RecommendedImage.Tag != update.LatestImage.Tag {
+		target = fmt.Sprintf("recommended <code>%s</code>, latest %s", html.EscapeString(update.RecommendedImage.Tag), target)
+	}
+	var notes []string
+	if behind := update.BehindText(); behind != "" {
+		notes = append(notes, behind)
+	}
+	if size := update.SizeDeltaText(); size != "" {
+		notes = append(notes, size)
+	}
+	if len(notes) > 0 {
+		target += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return fmt.Sprintf("• <b>%s</b>: <code>%s</code> → %s\n",
+		html.EscapeString(update.ServiceName),
+		html.EscapeString(update.CurrentImage.String()),
+		target)
+}
@@ -0,0 +1,186 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+func newUpdate(service, repo, current, latest string, updateType types.UpdateType) types.ImageUpdate {
+	return types.ImageUpdate{
+		ServiceName:  service,
+		CurrentImage: types.DockerImage{Registry: "docker.io", Repository: repo, Tag: current},
+		LatestImage:  types.DockerImage{Registry: "docker.io", Repository: repo, Tag: latest},
+		UpdateType:   updateType,
+	}
+}
+
+func TestTelegramFormatter_GroupsBySeverity(t *testing.T) {
+	result := types.ScanResult{
+		ProjectName:   "home<lab>",
+		ScanTimestamp: time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC),
+		UpdatesAvailable: []types.ImageUpdate{
+			newUpdate("cache", "library/redis", "7.2.3", "7.2.4", types.UpdateTypePatch),
+			newUpdate("db", "library/postgres", "15.4", "16.1", types.UpdateTypeMajor),
+			newUpdate("web", "library/nginx", "1.24.0", "1.25.3", types.UpdateTypeMinor),
+			newUpdate("proxy", "library/traefik", "2.10.4", "2.10.7", types.UpdateTypePatch),
+		},
+		UpToDateServices: []string{"app"},
+		Errors:           []string{"getting tags for x: <timeout>"},
+	}
+
+	message, err := TelegramFormatter{}.Format(result)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	// Cabecera con recuentos, secciones en orden de severidad y pie con la fecha
+	order := []string{
+		"🐳 <b>Docker Image Updates</b> — home&lt;lab&gt;",
+		"📊 4 updates (1 major, 1 minor, 2 patch), 1 up to date, 1 error",
+		"🔴 <b>Major</b> (1)",
+		"• <b>db</b>: <code>library/postgres:15.4</code> → <code>16.1</code>",
+		"🟡 <b>Minor</b> (1)",
+		"• <b>web</b>:",
+		"🟢 <b>Patch</b> (2)",
+		"• <b>cache</b>:",
+		"• <b>proxy</b>:",
+		"⚠️ <b>Errors</b> (1)",
+		"• getting tags for x: &lt;timeout&gt;",
+		"📅 Scanned: 2024-03-15 10:30:00",
+	}
+	pos := 0
+	for _, want := range order {
+		i := strings.Index(message[pos:], want)
+		if i < 0 {
+			t.Fatalf("expected %q after position %d in message:\n%s", want, pos, message)
+		}
+		pos += i + len(want)
+	}
+}
+
+func TestTelegramFormatter_PriorityFirst(t *testing.T) {
+	openssl := newUpdate("tls", "library/openssl", "3.1.4", "3.1.5", types.UpdateTypePatch)
+	openssl.Priority = true
+	result := types.ScanResult{
+		ProjectName: "homelab",
+		UpdatesAvailable: []types.ImageUpdate{
+			newUpdate("db", "library/postgres", "15.4", "16.1", types.UpdateTypeMajor),
+			openssl,
+			newUpdate("cache", "library/redis", "7.2.3", "7.2.4", types.UpdateTypePatch),
+		},
+	}
+
+	message, err := TelegramFormatter{}.Format(result)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	// La actualización prioritaria va antes que las major y no se repite en su severidad
+	order := []string{
+		"🚨 <b>Priority</b> (1)",
+		"• <b>tls</b>: <code>library/openssl:3.1.4</code> → <code>3.1.5</code> [patch]",
+		"🔴 <b>Major</b> (1)",
+		"• <b>db</b>:",
+		"🟢 <b>Patch</b> (1)",
+		"• <b>cache</b>:",
+	}
+	pos := 0
+	for _, want := range order {
+		i := strings.Index(message[pos:], want)
+		if i < 0 {
+			t.Fatalf("expected %q after position %d in message:\n%s", want, pos, message)
+		}
+		pos += i + len(want)
+	}
+	if strings.Count(message, "<b>tls</b>") != 1 {
+		t.Errorf("priority update listed more than once:\n%s", message)
+	}
+}
+
+func TestTelegramFormatter_UsesTemplate(t *testing.T) {
+	result := types.ScanResult{
+		UpdatesAvailable: []types.ImageUpdate{newUpdate("web", "library/nginx", "1.24", "1.25", types.UpdateTypeMinor)},
+	}
+
+	message, err := TelegramFormatter{Template: "{{range .UpdatesAvailable}}{{.ServiceName}}={{.LatestImage.Tag}}{{end}}"}.Format(result)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if message != "web=1.25" {
+		t.Errorf("Format() = %q, want %q", message, "web=1.25")
+	}
+
+	if _, err := (TelegramFormatter{Template: "{{.Missing"}).Format(result); err == nil {
+		t.Error("expected error for invalid template")
+	}
+}
+
+func TestTelegramFormatter_LongMessageSplitsOnLines(t *testing.T) {
+	var updates []types.ImageUpdate
+	for i := 0; i < 200; i++ {
+		updates = append(updates, newUpdate(fmt.Sprintf("service-%03d", i), fmt.Sprintf("org/image-%03d", i), "1.0.0", "2.0.0", types.UpdateTypeMajor))
+	}
+
+	message, err := TelegramFormatter{}.Format(types.ScanResult{UpdatesAvailable: updates})
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	const maxLength = 4096
+	parts := splitMessage(message, maxLength)
+	if len(parts) < 2 {
+		t.Fatalf("expected the message to be split, got %d part(s) for %d runes", len(parts), utf8.RuneCountInString(message))
+	}
+
+	for i, part := range parts {
+		if n := utf8.RuneCountInString(part); n > maxLength {
+			t.Errorf("part %d has %d runes, want <= %d", i, n, maxLength)
+		}
+		// Cada parte termina en un salto de línea, así no se corta ninguna etiqueta HTML
+		if i < len(parts)-1 && !strings.HasSuffix(part, "\n") {
+			t.Errorf("part %d does not end on a line boundary: %q", i, part[len(part)-40:])
+		}
+	}
+	if strings.Join(parts, "") != message {
+		t.Error("joined parts differ from the original message")
+	}
+}
+
+func TestFormatUpdateLine_Recommended(t *testing.T) {
+	update := newUpdate("db", "library/postgres", "15.4", "17.1", types.UpdateTypeMajor)
+	update.RecommendedImage = &types.DockerImage{Registry: "docker.io", Repository: "library/postgres", Tag: "15.6"}
+
+	want := "• <b>db</b>: <code>library/postgres:15.4</code> → recommended <code>15.6</code>, latest <code>17.1</code>\n"
+	if got := formatUpdateLine(update); got != want {
+		t.Errorf("formatUpdateLine() = %q, want %q", got, want)
+	}
+
+	// Si la recomendación coincide con la última versión se muestra una sola
+	update.RecommendedImage.Tag = "17.1"
+	if got := formatUpdateLine(update); strings.Contains(got, "recommended") {
+		t.Errorf("formatUpdateLine() = %q, want no recommendation", got)
+	}
+}
+
+func TestFormatUpdateLine_SizeDelta(t *testing.T) {
+	update := newUpdate("web", "library/nginx", "1.24", "1.25", types.UpdateTypeMinor)
+	update.VersionsBehind = 1
+	update.CurrentSize = 60_000_000
+	update.LatestSize = 102_000_000
+
+	want := "• <b>web</b>: <code>library/nginx:1.24</code> → <code>1.25</code> (1 release behind, +42 MB)\n"
+	if got := formatUpdateLine(update); got != want {
+		t.Errorf("formatUpdateLine() = %q, want %q", got, want)
+	}
+
+	// Si falta un tamaño no se muestra la diferencia
+	update.CurrentSize = 0
+	if got := formatUpdateLine(update); strings.Contains(got, "MB") {
+		t.Errorf("formatUpdateLine() = %q, want no size delta", got)
+	}
+}
@@ -1,11 +1,33 @@
 package notifier
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	"github.com/user/docker-image-reporter/internal/clock"
 	"github.com/user/docker-image-reporter/pkg/types"
 )
 
@@ -51,6 +73,109 @@ func TestTelegramClient_SendNotification_APIError(t *testing.T) {
 	// Las pruebas de error de API requerirían un servidor mock
 }
 
+// newTelegramMock crea un servidor que responde a la API de Telegram con
+// respond y devuelve el cliente apuntando a él y los instantes de cada petición
+// según el reloj del cliente
+func newTelegramMock(t *testing.T, respond func(call int, w http.ResponseWriter)) (*TelegramClient, *[]time.Time) {
+	t.Helper()
+
+	var client *TelegramClient
+	var mu sync.Mutex
+	var calls []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, client.clock.Now())
+		call := len(calls)
+		mu.Unlock()
+		respond(call, w)
+	}))
+	t.Cleanup(srv.Close)
+
+	client = NewTelegramClient("token", "chat")
+	client.baseURL = srv.URL + "/bot%s/%s"
+	client.minInterval = 0
+	return client, &calls
+}
+
+func TestTelegramClient_SendNotification_RetryAfter(t *testing.T) {
+	client, calls := newTelegramMock(t, func(call int, w http.ResponseWriter) {
+		if call == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	})
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	client.WithClock(clk)
+
+	done := make(chan error, 1)
+	go func() { done <- client.SendNotification(context.Background(), "test message") }()
+
+	// El reintento espera lo que indica retry_after, no el retraso fijo
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+	if len(*calls) != 2 {
+		t.Fatalf("requests = %d, want 2", len(*calls))
+	}
+	if gap := (*calls)[1].Sub((*calls)[0]); gap != time.Second {
+		t.Errorf("retry waited %v, want 1s", gap)
+	}
+}
+
+func TestTelegramClient_SendNotification_RetryAfterTooLong(t *testing.T) {
+	client, calls := newTelegramMock(t, func(call int, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":3600}}`))
+	})
+
+	if err := client.SendNotification(context.Background(), "test message"); err == nil {
+		t.Fatal("SendNotification() error = nil, want rate limit error")
+	}
+	if len(*calls) != 1 {
+		t.Errorf("requests = %d, want 1 (no retry beyond maxRetryAfter)", len(*calls))
+	}
+}
+
+func TestTelegramClient_MinInterval(t *testing.T) {
+	client, calls := newTelegramMock(t, func(call int, w http.ResponseWriter) {
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	})
+	client.minInterval = 200 * time.Millisecond
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	client.WithClock(clk)
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 2; i++ {
+			if err := client.SendNotification(context.Background(), "test message"); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	// El segundo envío espera a que pase minInterval desde el primero
+	clk.BlockUntil(1)
+	if len(*calls) != 1 {
+		t.Fatalf("requests before minInterval = %d, want 1", len(*calls))
+	}
+	clk.Advance(client.minInterval)
+	if err := <-done; err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+	if len(*calls) != 2 {
+		t.Fatalf("requests = %d, want 2", len(*calls))
+	}
+	if gap := (*calls)[1].Sub((*calls)[0]); gap != client.minInterval {
+		t.Errorf("messages sent %v apart, want %v", gap, client.minInterval)
+	}
+}
+
 func TestNotificationService_AddClient(t *testing.T) {
 	service := NewNotificationService()
 	if service.HasClients() {
@@ -139,3 +264,1072 @@ func (m *MockReportFormatter) Format(result types.ScanResult) (string, error) {
 func (m *MockReportFormatter) FormatName() string {
 	return "mock"
 }
+
+// recordingClient es un cliente de notificación que guarda lo recibido
+type recordingClient struct {
+	name     string
+	messages []string
+	files    []string
+	err      error // error devuelto por SendNotification
+}
+
+func (c *recordingClient) SendNotification(ctx context.Context, message string) error {
+	c.messages = append(c.messages, message)
+	return c.err
+}
+
+func (c *recordingClient) SendFile(ctx context.Context, filePath, fileName, caption string) error {
+	c.files = append(c.files, caption)
+	return nil
+}
+
+func (c *recordingClient) Name() string {
+	return c.name
+}
+
+// servicesFormatter formatea el resultado como la lista de servicios con update
+type servicesFormatter struct{}
+
+func (servicesFormatter) Format(result types.ScanResult) (string, error) {
+	names := make([]string, 0, len(result.UpdatesAvailable))
+	for _, update := range result.UpdatesAvailable {
+		names = append(names, update.ServiceName)
+	}
+	return strings.Join(names, ","), nil
+}
+
+func (servicesFormatter) FormatName() string {
+	return "services"
+}
+
+func filterTestResult() types.ScanResult {
+	return types.ScanResult{
+		ProjectName:   "test",
+		ScanTimestamp: time.Now(),
+		UpdatesAvailable: []types.ImageUpdate{
+			{ServiceName: "web", UpdateType: types.UpdateTypeMajor},
+			{ServiceName: "db", UpdateType: types.UpdateTypeMinor},
+			{ServiceName: "cache", UpdateType: types.UpdateTypePatch},
+		},
+	}
+}
+
+func TestNotificationService_NotifyScanResult_PerClientFilter(t *testing.T) {
+	onCall := &recordingClient{name: "oncall"}
+	logChannel := &recordingClient{name: "log"}
+
+	service := NewNotificationService()
+	service.AddClientWithFilter(onCall, types.UpdateTypeMajor)
+	service.AddClient(logChannel)
+
+	if err := service.NotifyScanResult(context.Background(), filterTestResult(), servicesFormatter{}); err != nil {
+		t.Fatalf("NotifyScanResult() error = %v", err)
+	}
+
+	tests := []struct {
+		client   *recordingClient
+		expected string
+	}{
+		{onCall, "web"},
+		{logChannel, "web,db,cache"},
+	}
+
+	for _, tt := range tests {
+		if len(tt.client.messages) != 1 || tt.client.messages[0] != tt.expected {
+			t.Errorf("%s received %v, want [%s]", tt.client.name, tt.client.messages, tt.expected)
+		}
+	}
+}
+
+func TestNotificationService_NotifyScanResult_SkipsClientWithNothingRelevant(t *testing.T) {
+	onCall := &recordingClient{name: "oncall"}
+	service := NewNotificationService()
+	service.AddClientWithFilter(onCall, types.UpdateTypeMajor)
+
+	result := filterTestResult()
+	result.UpdatesAvailable = result.UpdatesAvailable[1:] // solo minor y patch
+
+	if err := service.NotifyScanResult(context.Background(), result, servicesFormatter{}); err != nil {
+		t.Fatalf("NotifyScanResult() error = %v", err)
+	}
+
+	if len(onCall.messages) != 0 {
+		t.Errorf("Expected no notification below threshold, got %v", onCall.messages)
+	}
+}
+
+func TestNotificationService_SendScanReport_PerClientFilter(t *testing.T) {
+	onCall := &recordingClient{name: "oncall"}
+	team := &recordingClient{name: "team"}
+	logChannel := &recordingClient{name: "log"}
+
+	service := NewNotificationService()
+	service.AddClientWithFilter(onCall, types.UpdateTypeMajor)
+	service.AddClientWithFilter(team, types.UpdateTypeMinor)
+	service.AddClient(logChannel)
+
+	renders := 0
+	render := func(view types.ScanResult) (string, string, error) {
+		renders++
+		caption, _ := servicesFormatter{}.Format(view)
+		return "/tmp/report.html", caption, nil
+	}
+
+	if err := service.SendScanReport(context.Background(), filterTestResult(), "report.html", render); err != nil {
+		t.Fatalf("SendScanReport() error = %v", err)
+	}
+
+	if renders != 3 {
+		t.Errorf("Expected one render per threshold (3), got %d", renders)
+	}
+
+	tests := []struct {
+		client   *recordingClient
+		expected string
+	}{
+		{onCall, "web"},
+		{team, "web,db"},
+		{logChannel, "web,db,cache"},
+	}
+
+	for _, tt := range tests {
+		if len(tt.client.files) != 1 || tt.client.files[0] != tt.expected {
+			t.Errorf("%s received %v, want [%s]", tt.client.name, tt.client.files, tt.expected)
+		}
+	}
+}
+
+func TestNotificationService_NotifyScanResult_RoutesByChannel(t *testing.T) {
+	defaultClient := &recordingClient{name: "default"}
+	teamA := &recordingClient{name: "team-a"}
+	teamB := &recordingClient{name: "team-b"}
+
+	service := NewNotificationService()
+	service.AddClient(defaultClient)
+	service.AddRoutedClient("team-a", teamA, "")
+	service.AddRoutedClient("team-b", teamB, "")
+
+	result := filterTestResult()
+	result.UpdatesAvailable[0].CurrentImage.NotifyChannel = "team-a"
+	result.UpdatesAvailable[1].CurrentImage.NotifyChannel = "team-b"
+	// Un canal sin cliente asignado cae en el destino por defecto
+	result.UpdatesAvailable[2].CurrentImage.NotifyChannel = "team-c"
+	result.Errors = []string{"registry unreachable"}
+
+	if err := service.NotifyScanResult(context.Background(), result, servicesFormatter{}); err != nil {
+		t.Fatalf("NotifyScanResult() error = %v", err)
+	}
+
+	tests := []struct {
+		client   *recordingClient
+		expected string
+	}{
+		{teamA, "web"},
+		{teamB, "db"},
+		{defaultClient, "cache"},
+	}
+
+	for _, tt := range tests {
+		if len(tt.client.messages) != 1 || tt.client.messages[0] != tt.expected {
+			t.Errorf("%s received %v, want [%s]", tt.client.name, tt.client.messages, tt.expected)
+		}
+	}
+}
+
+func TestNotificationService_NotifyScanResult_ErrorsOnlyToDefault(t *testing.T) {
+	defaultClient := &recordingClient{name: "default"}
+	teamA := &recordingClient{name: "team-a"}
+
+	service := NewNotificationService()
+	service.AddClient(defaultClient)
+	service.AddRoutedClient("team-a", teamA, "")
+
+	result := types.ScanResult{ProjectName: "test", Errors: []string{"registry unreachable"}}
+	if err := service.NotifyScanResult(context.Background(), result, servicesFormatter{}); err != nil {
+		t.Fatalf("NotifyScanResult() error = %v", err)
+	}
+
+	if len(defaultClient.messages) != 1 {
+		t.Errorf("default client received %d messages, want 1", len(defaultClient.messages))
+	}
+	if len(teamA.messages) != 0 {
+		t.Errorf("routed client received %v, want nothing", teamA.messages)
+	}
+}
+
+func TestDryRunClient_WritesInsteadOfSending(t *testing.T) {
+	var buf strings.Builder
+	client := NewDryRunClient("telegram:team-a", &buf)
+
+	if err := client.SendNotification(context.Background(), "hello"); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+	if err := client.SendFile(context.Background(), "/tmp/report.html", "report.html", "caption"); err != nil {
+		t.Fatalf("SendFile() error = %v", err)
+	}
+
+	want := "--- dry-run notification to telegram:team-a ---\nhello\n" +
+		"--- dry-run file report.html to telegram:team-a ---\ncaption\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestExecClient_SendNotification(t *testing.T) {
+	// El comando copia su stdin a un archivo, como haría cat
+	out := filepath.Join(t.TempDir(), "received.txt")
+	client := NewExecClient([]string{"sh", "-c", `cat > "$1"`, "sh", out}, time.Second)
+
+	if err := client.SendNotification(context.Background(), "3 updates available"); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("reading command output: %v", err)
+	}
+	if string(data) != "3 updates available" {
+		t.Errorf("command received %q, want %q", data, "3 updates available")
+	}
+}
+
+func TestExecClient_SendFile(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "report.html")
+	if err := os.WriteFile(report, []byte("<html></html>"), 0600); err != nil {
+		t.Fatalf("writing report: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "received.txt")
+	client := NewExecClient([]string{"sh", "-c", `{ echo "$ICR_NOTIFICATION_KIND $ICR_FILE_NAME"; cat; } > "$1"`, "sh", out}, time.Second)
+
+	if err := client.SendFile(context.Background(), report, "docker-updates-report.html", "Report"); err != nil {
+		t.Fatalf("SendFile() error = %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if want := "file docker-updates-report.html\n<html></html>"; string(data) != want {
+		t.Errorf("command received %q, want %q", data, want)
+	}
+}
+
+func TestExecClient_Failures(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		timeout time.Duration
+		wantErr string
+	}{
+		{name: "no command", wantErr: "command is required"},
+		{name: "non-zero exit", command: []string{"sh", "-c", "echo boom >&2; exit 3"}, wantErr: "boom"},
+		{name: "timeout", command: []string{"sleep", "5"}, timeout: 50 * time.Millisecond, wantErr: "deadline exceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewExecClient(tt.command, tt.timeout).SendNotification(context.Background(), "message")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SendNotification() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotificationService_TestClients(t *testing.T) {
+	failing := &recordingClient{name: "telegram", err: errors.New("chat not found")}
+	working := &recordingClient{name: "exec"}
+	service := NewNotificationService(failing, working)
+
+	results := service.TestClients(context.Background(), "ping")
+
+	// Ambos clientes reciben el mensaje aunque el primero falle
+	if len(failing.messages) != 1 || len(working.messages) != 1 {
+		t.Fatalf("expected one message per client, got %d and %d", len(failing.messages), len(working.messages))
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Name != "telegram" || results[0].Err == nil {
+		t.Errorf("results[0] = %+v, want a telegram failure", results[0])
+	}
+	if results[1].Name != "exec" || results[1].Err != nil {
+		t.Errorf("results[1] = %+v, want exec success", results[1])
+	}
+}
+
+// newSlackMock crea un webhook de Slack que responde con status y devuelve el
+// cliente apuntando a él y los payloads recibidos
+func newSlackMock(t *testing.T, status int) (*SlackClient, *[]slackPayload) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var payloads []slackPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload slackPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding webhook payload: %v", err)
+		}
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_, _ = w.Write([]byte("ok"))
+			return
+		}
+		_, _ = w.Write([]byte("invalid_payload"))
+	}))
+	t.Cleanup(srv.Close)
+
+	return NewSlackClient(srv.URL, "#ops"), &payloads
+}
+
+func TestSlackClient_SendNotification(t *testing.T) {
+	client, payloads := newSlackMock(t, http.StatusOK)
+
+	message := "<b>🐳 Docker Image Updates</b>\n\n<code>web</code>: nginx 1.25 → 1.27 &amp; more"
+	if err := client.SendNotification(context.Background(), message); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+	if len(*payloads) != 1 {
+		t.Fatalf("requests = %d, want 1", len(*payloads))
+	}
+
+	payload := (*payloads)[0]
+	if payload.Channel != "#ops" {
+		t.Errorf("channel = %q, want #ops", payload.Channel)
+	}
+	// Un bloque por párrafo, con el HTML de Telegram traducido a mrkdwn
+	want := []string{"*🐳 Docker Image Updates*", "`web`: nginx 1.25 → 1.27 &amp; more"}
+	if len(payload.Blocks) != len(want) {
+		t.Fatalf("blocks = %+v, want %d", payload.Blocks, len(want))
+	}
+	for i, block := range payload.Blocks {
+		if block.Type != "section" || block.Text.Type != "mrkdwn" || block.Text.Text != want[i] {
+			t.Errorf("blocks[%d] = %+v, want mrkdwn section %q", i, block, want[i])
+		}
+	}
+	if payload.Text != want[0] {
+		t.Errorf("fallback text = %q, want %q", payload.Text, want[0])
+	}
+}
+
+func TestSlackClient_SendNotification_SplitsLongMessages(t *testing.T) {
+	client, payloads := newSlackMock(t, http.StatusOK)
+
+	// Un párrafo que supera el límite de un bloque y más párrafos de los que
+	// caben en un mensaje
+	line := strings.Repeat("x", 99) + "\n"
+	paragraphs := []string{strings.Repeat(line, 40)}
+	for i := 0; i < maxSlackBlocks; i++ {
+		paragraphs = append(paragraphs, "update")
+	}
+
+	if err := client.SendNotification(context.Background(), strings.Join(paragraphs, "\n\n")); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+	if len(*payloads) != 2 {
+		t.Fatalf("requests = %d, want 2", len(*payloads))
+	}
+
+	var blocks []slackBlock
+	for _, payload := range *payloads {
+		if len(payload.Blocks) > maxSlackBlocks {
+			t.Errorf("payload has %d blocks, want at most %d", len(payload.Blocks), maxSlackBlocks)
+		}
+		blocks = append(blocks, payload.Blocks...)
+	}
+	if len(blocks) != maxSlackBlocks+2 {
+		t.Errorf("blocks = %d, want %d", len(blocks), maxSlackBlocks+2)
+	}
+	for i, block := range blocks {
+		if len(block.Text.Text) > maxSlackBlockText {
+			t.Errorf("blocks[%d] has %d characters, want at most %d", i, len(block.Text.Text), maxSlackBlockText)
+		}
+	}
+}
+
+func TestSlackClient_SendNotification_Errors(t *testing.T) {
+	if err := NewSlackClient("", "").SendNotification(context.Background(), "message"); err == nil || !strings.Contains(err.Error(), "webhook URL is required") {
+		t.Errorf("SendNotification() error = %v, want missing webhook URL", err)
+	}
+
+	client, _ := newSlackMock(t, http.StatusBadRequest)
+	err := client.SendNotification(context.Background(), "message")
+	if err == nil || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Errorf("SendNotification() error = %v, want the webhook's error", err)
+	}
+}
+
+func TestToSlackMrkdwn(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "bold and code", message: "<b>api</b> <code>1.0</code>", want: "*api* `1.0`"},
+		{name: "unknown tags dropped", message: `<a href="https://example.com">link</a>`, want: "link"},
+		{name: "entities escaped for slack", message: "a &lt;b&gt; &amp; c", want: "a &lt;b&gt; &amp; c"},
+		{name: "plain text", message: "3 updates", want: "3 updates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toSlackMrkdwn(tt.message); got != tt.want {
+				t.Errorf("toSlackMrkdwn(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+// discordRequest es una petición recibida por el webhook de Discord simulado
+type discordRequest struct {
+	payload  map[string]interface{} // cuerpo JSON, o payload_json si es multipart
+	fileName string
+	file     string
+}
+
+// newDiscordMock crea un webhook de Discord que responde con status y devuelve
+// el cliente apuntando a él y las peticiones recibidas
+func newDiscordMock(t *testing.T, status int) (*DiscordClient, *[]discordRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []discordRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req discordRequest
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("parsing multipart body: %v", err)
+			}
+			if err := json.Unmarshal([]byte(r.FormValue("payload_json")), &req.payload); err != nil {
+				t.Errorf("decoding payload_json: %v", err)
+			}
+			if file, header, err := r.FormFile("files[0]"); err == nil {
+				data, _ := io.ReadAll(file)
+				req.fileName, req.file = header.Filename, string(data)
+			}
+		} else if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
+			t.Errorf("decoding webhook payload: %v", err)
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		if status != http.StatusNoContent {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"message": "Invalid Webhook Token", "code": 50027}`))
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return NewDiscordClient(srv.URL), &requests
+}
+
+func TestDiscordClient_SendScanResult(t *testing.T) {
+	client, requests := newDiscordMock(t, http.StatusNoContent)
+	result := types.ScanResult{
+		ProjectName:   "homelab",
+		ScanTimestamp: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+		UpdatesAvailable: []types.ImageUpdate{
+			{
+				ServiceName:  "db",
+				CurrentImage: types.DockerImage{Registry: "docker.io", Repository: "postgres", Tag: "16.1"},
+				LatestImage:  types.DockerImage{Registry: "docker.io", Repository: "postgres", Tag: "16.2"},
+				UpdateType:   types.UpdateTypeMinor,
+			},
+			{
+				ServiceName:  "web",
+				CurrentImage: types.DockerImage{Registry: "docker.io", Repository: "nginx", Tag: "1.25"},
+				LatestImage:  types.DockerImage{Registry: "docker.io", Repository: "nginx", Tag: "2.0"},
+				UpdateType:   types.UpdateTypeMajor,
+			},
+		},
+		Errors: []string{"cache: registry unreachable"},
+	}
+
+	if err := client.SendScanResult(context.Background(), result); err != nil {
+		t.Fatalf("SendScanResult() error = %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(*requests))
+	}
+
+	embeds, _ := (*requests)[0].payload["embeds"].([]interface{})
+	if len(embeds) != 1 {
+		t.Fatalf("payload = %v, want one embed", (*requests)[0].payload)
+	}
+	embed := embeds[0].(map[string]interface{})
+	if embed["title"] != "🐳 Docker Image Updates — homelab" {
+		t.Errorf("title = %v", embed["title"])
+	}
+	if embed["description"] != result.Summary() {
+		t.Errorf("description = %v, want %q", embed["description"], result.Summary())
+	}
+	// El color es el de la actualización más grave
+	if embed["color"] != float64(discordColors[types.UpdateTypeMajor]) {
+		t.Errorf("color = %v, want the major color", embed["color"])
+	}
+	if embed["timestamp"] != "2025-03-01T10:00:00Z" {
+		t.Errorf("timestamp = %v", embed["timestamp"])
+	}
+
+	// Un campo por actualización, de mayor a menor severidad, y los errores al final
+	want := []struct{ name, value string }{
+		{"🔴 web", "`nginx:1.25` → `2.0`"},
+		{"🟡 db", "`postgres:16.1` → `16.2`"},
+		{"⚠️ Errors (1)", "• cache: registry unreachable"},
+	}
+	fields, _ := embed["fields"].([]interface{})
+	if len(fields) != len(want) {
+		t.Fatalf("fields = %v, want %d", fields, len(want))
+	}
+	for i, raw := range fields {
+		field := raw.(map[string]interface{})
+		if field["name"] != want[i].name || field["value"] != want[i].value {
+			t.Errorf("fields[%d] = %v, want %s: %s", i, field, want[i].name, want[i].value)
+		}
+	}
+}
+
+func TestScanResultEmbed_Limits(t *testing.T) {
+	manyUpdates := func(count, tagLength int) types.ScanResult {
+		result := types.ScanResult{ProjectName: "big", Errors: []string{"boom"}}
+		for i := 0; i < count; i++ {
+			result.UpdatesAvailable = append(result.UpdatesAvailable, types.ImageUpdate{
+				ServiceName:  fmt.Sprintf("svc-%02d", i),
+				CurrentImage: types.DockerImage{Repository: "app", Tag: "1.0"},
+				LatestImage:  types.DockerImage{Repository: "app", Tag: strings.Repeat("9", tagLength)},
+				UpdateType:   types.UpdateTypePatch,
+			})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name       string
+		result     types.ScanResult
+		wantShown  int
+		wantHidden string
+	}{
+		{name: "fits", result: manyUpdates(10, 3), wantShown: 10},
+		{name: "too many fields", result: manyUpdates(40, 3), wantShown: 23, wantHidden: "17 more updates not shown"},
+		{name: "too many characters", result: manyUpdates(20, 900), wantShown: 6, wantHidden: "14 more updates not shown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embed := scanResultEmbed(tt.result)
+
+			if len(embed.Fields) > maxDiscordFields {
+				t.Errorf("fields = %d, want at most %d", len(embed.Fields), maxDiscordFields)
+			}
+			size := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+			for _, field := range embed.Fields {
+				size += field.size()
+				if utf8.RuneCountInString(field.Value) > maxDiscordFieldValue {
+					t.Errorf("field %q has a value longer than %d characters", field.Name, maxDiscordFieldValue)
+				}
+			}
+			if size > maxDiscordEmbedChars {
+				t.Errorf("embed has %d characters, want at most %d", size, maxDiscordEmbedChars)
+			}
+
+			// Las actualizaciones mostradas, el campo de omitidas si lo hay y los errores
+			shown := len(embed.Fields) - 1
+			if tt.wantHidden != "" {
+				shown--
+				if hidden := embed.Fields[len(embed.Fields)-2]; hidden.Value != tt.wantHidden {
+					t.Errorf("omitted field = %+v, want %q", hidden, tt.wantHidden)
+				}
+			}
+			if shown != tt.wantShown {
+				t.Errorf("updates shown = %d, want %d", shown, tt.wantShown)
+			}
+			if last := embed.Fields[len(embed.Fields)-1]; last.Name != "⚠️ Errors (1)" {
+				t.Errorf("last field = %+v, want the errors field", last)
+			}
+		})
+	}
+}
+
+func TestDiscordClient_SendFile(t *testing.T) {
+	client, requests := newDiscordMock(t, http.StatusNoContent)
+	report := filepath.Join(t.TempDir(), "report.html")
+	if err := os.WriteFile(report, []byte("<html></html>"), 0600); err != nil {
+		t.Fatalf("writing report: %v", err)
+	}
+
+	if err := client.SendFile(context.Background(), report, "docker-updates-report.html", "📊 <b>Summary:</b> 2 updates"); err != nil {
+		t.Fatalf("SendFile() error = %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(*requests))
+	}
+
+	req := (*requests)[0]
+	if req.fileName != "docker-updates-report.html" || req.file != "<html></html>" {
+		t.Errorf("uploaded file %q = %q", req.fileName, req.file)
+	}
+	// El caption en HTML llega como markdown de Discord
+	if req.payload["content"] != "📊 **Summary:** 2 updates" {
+		t.Errorf("content = %v", req.payload["content"])
+	}
+}
+
+func TestDiscordClient_SendNotification(t *testing.T) {
+	client, requests := newDiscordMock(t, http.StatusNoContent)
+
+	message := strings.Repeat(strings.Repeat("x", 99)+"\n", 30)
+	if err := client.SendNotification(context.Background(), message); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("requests = %d, want 2 (message split at %d characters)", len(*requests), maxDiscordContent)
+	}
+	for i, req := range *requests {
+		if content, _ := req.payload["content"].(string); len(content) > maxDiscordContent {
+			t.Errorf("requests[%d] content has %d characters", i, len(content))
+		}
+	}
+}
+
+func TestDiscordClient_Errors(t *testing.T) {
+	if err := NewDiscordClient("").SendNotification(context.Background(), "message"); err == nil || !strings.Contains(err.Error(), "webhook URL is required") {
+		t.Errorf("SendNotification() error = %v, want missing webhook URL", err)
+	}
+
+	client, _ := newDiscordMock(t, http.StatusUnauthorized)
+	err := client.SendScanResult(context.Background(), types.ScanResult{Errors: []string{"boom"}})
+	if err == nil || !strings.Contains(err.Error(), "Invalid Webhook Token") {
+		t.Errorf("SendScanResult() error = %v, want the webhook's error", err)
+	}
+}
+
+// resultClient es un recordingClient que además recibe el resultado del escaneo
+type resultClient struct {
+	recordingClient
+	results []types.ScanResult
+}
+
+func (c *resultClient) SendScanResult(ctx context.Context, result types.ScanResult) error {
+	c.results = append(c.results, result)
+	return nil
+}
+
+func TestNotificationService_NotifyScanResult_ScanResultNotifier(t *testing.T) {
+	text := &recordingClient{name: "telegram"}
+	embeds := &resultClient{recordingClient: recordingClient{name: "discord"}}
+	service := NewNotificationService(text)
+	service.AddClientWithFilter(embeds, types.UpdateTypeMajor)
+
+	result := types.ScanResult{UpdatesAvailable: []types.ImageUpdate{
+		{ServiceName: "web", UpdateType: types.UpdateTypeMajor},
+		{ServiceName: "db", UpdateType: types.UpdateTypePatch},
+	}}
+	if err := service.NotifyScanResult(context.Background(), result, servicesFormatter{}); err != nil {
+		t.Fatalf("NotifyScanResult() error = %v", err)
+	}
+
+	if len(text.messages) != 1 {
+		t.Errorf("text client messages = %v, want 1", text.messages)
+	}
+	// El cliente con resultado recibe su vista filtrada y no el texto formateado
+	if len(embeds.messages) != 0 {
+		t.Errorf("result client got text messages %v", embeds.messages)
+	}
+	if len(embeds.results) != 1 || len(embeds.results[0].UpdatesAvailable) != 1 || embeds.results[0].UpdatesAvailable[0].ServiceName != "web" {
+		t.Errorf("result client results = %+v, want only the major update", embeds.results)
+	}
+}
+
+// smtpMessage es un correo recibido por el servidor SMTP simulado
+type smtpMessage struct {
+	auth string // credenciales de AUTH PLAIN decodificadas
+	from string
+	to   []string
+	data []byte
+}
+
+// newSMTPMock arranca un servidor SMTP mínimo en localhost que acepta AUTH
+// PLAIN y rechaza los destinatarios de reject. Devuelve la configuración de
+// correo apuntando a él y los mensajes completados
+func newSMTPMock(t *testing.T, reject ...string) (types.EmailConfig, <-chan smtpMessage) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	messages := make(chan smtpMessage, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			serveSMTP(textproto.NewConn(conn), reject, messages)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return types.EmailConfig{
+		Enabled:  true,
+		Host:     "127.0.0.1",
+		Port:     addr.Port,
+		Username: "reporter",
+		Password: "secret",
+		From:     "Image Reporter <reporter@example.com>",
+		To:       []string{"Ops Team <ops@example.com>", "dev@example.com"},
+	}, messages
+}
+
+// serveSMTP atiende una sesión SMTP hasta QUIT
+func serveSMTP(conn *textproto.Conn, reject []string, messages chan<- smtpMessage) {
+	defer func() { _ = conn.Close() }()
+
+	var msg smtpMessage
+	_ = conn.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			_ = conn.PrintfLine("250-localhost")
+			_ = conn.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			_, encoded, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(encoded)
+			msg.auth = string(decoded)
+			_ = conn.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			_ = conn.PrintfLine("250 2.1.0 OK")
+		case "RCPT":
+			to := strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			if slices.Contains(reject, to) {
+				_ = conn.PrintfLine("550 5.1.1 %s: mailbox unavailable", to)
+				continue
+			}
+			msg.to = append(msg.to, to)
+			_ = conn.PrintfLine("250 2.1.5 OK")
+		case "DATA":
+			_ = conn.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			msg.data, _ = conn.ReadDotBytes()
+			messages <- msg
+			msg = smtpMessage{auth: msg.auth}
+			_ = conn.PrintfLine("250 2.0.0 Queued")
+		case "QUIT":
+			_ = conn.PrintfLine("221 2.0.0 Bye")
+			return
+		default:
+			_ = conn.PrintfLine("250 OK")
+		}
+	}
+}
+
+// receiveEmail espera el siguiente correo del servidor simulado y lo parsea
+func receiveEmail(t *testing.T, messages <-chan smtpMessage) (smtpMessage, *mail.Message) {
+	t.Helper()
+
+	select {
+	case msg := <-messages:
+		parsed, err := mail.ReadMessage(bytes.NewReader(msg.data))
+		if err != nil {
+			t.Fatalf("parsing received email: %v", err)
+		}
+		return msg, parsed
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email received")
+		return smtpMessage{}, nil
+	}
+}
+
+// emailPart es una parte MIME leída de un correo
+type emailPart struct {
+	header   textproto.MIMEHeader
+	fileName string
+	body     string
+}
+
+// emailParts devuelve las partes MIME de un correo multipart del tipo
+// mediaType. Las partes quoted-printable llegan ya decodificadas
+func emailParts(t *testing.T, msg *mail.Message, mediaType string) []emailPart {
+	t.Helper()
+
+	got, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || got != mediaType {
+		t.Fatalf("Content-Type = %q, want %s", msg.Header.Get("Content-Type"), mediaType)
+	}
+	var parts []emailPart
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatalf("reading MIME part: %v", err)
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("reading MIME part body: %v", err)
+		}
+		parts = append(parts, emailPart{header: part.Header, fileName: part.FileName(), body: string(body)})
+	}
+}
+
+func TestEmailClient_SendNotification(t *testing.T) {
+	cfg, messages := newSMTPMock(t)
+	client := NewEmailClient(cfg)
+
+	message := "🐳 <b>Docker Image Updates</b> — homelab\n• <b>web</b>: <code>nginx:1.25</code> → <code>1.27</code>"
+	if err := client.SendNotification(context.Background(), message); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+	received, msg := receiveEmail(t, messages)
+
+	// Sobre: remitente y destinatarios sin nombre, y credenciales de AUTH PLAIN
+	if received.from != "reporter@example.com" {
+		t.Errorf("MAIL FROM = %q", received.from)
+	}
+	if !slices.Equal(received.to, []string{"ops@example.com", "dev@example.com"}) {
+		t.Errorf("RCPT TO = %v, want both recipients", received.to)
+	}
+	if received.auth != "\x00reporter\x00secret" {
+		t.Errorf("AUTH PLAIN = %q", received.auth)
+	}
+
+	// Cabeceras: destinatarios con nombre y asunto de la primera línea
+	if to := msg.Header.Get("To"); to != `"Ops Team" <ops@example.com>, <dev@example.com>` {
+		t.Errorf("To = %q", to)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "🐳 Docker Image Updates — homelab" {
+		t.Errorf("Subject = %q (%v)", subject, err)
+	}
+
+	parts := emailParts(t, msg, "multipart/alternative")
+	if len(parts) != 2 {
+		t.Fatalf("parts = %d, want text and HTML", len(parts))
+	}
+	plain, body := parts[0], parts[1]
+	if !strings.HasPrefix(plain.header.Get("Content-Type"), "text/plain") || strings.Contains(plain.body, "<b>") || !strings.Contains(plain.body, "web: nginx:1.25 → 1.27") {
+		t.Errorf("text part %q = %q", plain.header.Get("Content-Type"), plain.body)
+	}
+	if !strings.HasPrefix(body.header.Get("Content-Type"), "text/html") || !strings.Contains(body.body, "<b>Docker Image Updates</b> — homelab<br>") {
+		t.Errorf("HTML part %q = %q", body.header.Get("Content-Type"), body.body)
+	}
+}
+
+func TestEmailClient_SendFile(t *testing.T) {
+	cfg, messages := newSMTPMock(t)
+	client := NewEmailClient(cfg)
+
+	report := filepath.Join(t.TempDir(), "report.html")
+	content := "<html>" + strings.Repeat("<p>update</p>", 20) + "</html>"
+	if err := os.WriteFile(report, []byte(content), 0600); err != nil {
+		t.Fatalf("writing report: %v", err)
+	}
+
+	if err := client.SendFile(context.Background(), report, "docker-updates-report.html", "🐳 <b>Docker Image Updates Report</b> — homelab"); err != nil {
+		t.Fatalf("SendFile() error = %v", err)
+	}
+	_, msg := receiveEmail(t, messages)
+
+	parts := emailParts(t, msg, "multipart/mixed")
+	if len(parts) != 2 {
+		t.Fatalf("parts = %d, want body and attachment", len(parts))
+	}
+	if !strings.Contains(parts[0].body, "<b>Docker Image Updates Report</b>") {
+		t.Errorf("body = %q", parts[0].body)
+	}
+
+	attachment := parts[1]
+	if attachment.fileName != "docker-updates-report.html" || attachment.header.Get("Content-Transfer-Encoding") != "base64" {
+		t.Errorf("attachment headers = %v", attachment.header)
+	}
+	encoded := attachment.body
+	for _, line := range strings.Fields(encoded) {
+		if len(line) > 76 {
+			t.Errorf("base64 line has %d characters, want at most 76", len(line))
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil || string(decoded) != content {
+		t.Errorf("attachment = %q (%v), want the report", decoded, err)
+	}
+}
+
+func TestEmailClient_Errors(t *testing.T) {
+	cfg, messages := newSMTPMock(t, "dev@example.com")
+
+	tests := []struct {
+		name    string
+		edit    func(*types.EmailConfig)
+		wantErr string
+	}{
+		{name: "rejected recipient", edit: func(*types.EmailConfig) {}, wantErr: "recipient dev@example.com rejected"},
+		{name: "no recipients", edit: func(c *types.EmailConfig) { c.To = nil }, wantErr: "at least one recipient"},
+		{name: "invalid recipient", edit: func(c *types.EmailConfig) { c.To = []string{"not an address"} }, wantErr: `invalid recipient "not an address"`},
+		{name: "invalid sender", edit: func(c *types.EmailConfig) { c.From = "" }, wantErr: "invalid sender"},
+		{name: "no host", edit: func(c *types.EmailConfig) { c.Host = "" }, wantErr: "SMTP host is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := cfg
+			tt.edit(&email)
+			err := NewEmailClient(email).SendNotification(context.Background(), "message")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SendNotification() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Un destinatario rechazado cancela el envío a todos
+	select {
+	case msg := <-messages:
+		t.Errorf("email delivered to %v despite errors", msg.to)
+	default:
+	}
+}
+
+// webhookRequest es una petición recibida por el webhook simulado
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newWebhookMock crea un receptor que responde con status y devuelve las
+// peticiones recibidas
+func newWebhookMock(t *testing.T, status int) (string, *[]webhookRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []webhookRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, webhookRequest{header: r.Header.Clone(), body: body})
+		mu.Unlock()
+		w.WriteHeader(status)
+		if status >= 300 {
+			_, _ = w.Write([]byte("forbidden"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &requests
+}
+
+// verifyWebhookSignature comprueba la firma como lo haría el receptor
+func verifyWebhookSignature(secret string, body []byte, signature string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func TestWebhookClient_SendScanResult(t *testing.T) {
+	url, requests := newWebhookMock(t, http.StatusAccepted)
+	headers := map[string]string{"Authorization": "Bearer automation-token", "X-Source": "icr"}
+	client := NewWebhookClient(url, headers, "s3cret")
+
+	result := types.ScanResult{
+		ProjectName: "homelab",
+		UpdatesAvailable: []types.ImageUpdate{{
+			ServiceName:  "web",
+			CurrentImage: types.DockerImage{Repository: "nginx", Tag: "1.25"},
+			LatestImage:  types.DockerImage{Repository: "nginx", Tag: "1.27"},
+			UpdateType:   types.UpdateTypeMinor,
+		}},
+	}
+	if err := client.SendScanResult(context.Background(), result); err != nil {
+		t.Fatalf("SendScanResult() error = %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(*requests))
+	}
+	req := (*requests)[0]
+
+	// El cuerpo es el ScanResult completo en JSON
+	var received types.ScanResult
+	if err := json.Unmarshal(req.body, &received); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if received.ProjectName != "homelab" || len(received.UpdatesAvailable) != 1 || received.UpdatesAvailable[0].LatestImage.Tag != "1.27" {
+		t.Errorf("received result = %+v", received)
+	}
+
+	for name, value := range headers {
+		if got := req.header.Get(name); got != value {
+			t.Errorf("header %s = %q, want %q", name, got, value)
+		}
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	signature := req.header.Get(WebhookSignatureHeader)
+	if !verifyWebhookSignature("s3cret", req.body, signature) {
+		t.Errorf("signature %q does not match the body", signature)
+	}
+	if verifyWebhookSignature("other", req.body, signature) {
+		t.Error("signature verified with the wrong secret")
+	}
+}
+
+func TestWebhookClient_SendNotification(t *testing.T) {
+	tests := []struct {
+		name          string
+		secret        string
+		wantSignature bool
+	}{
+		{name: "signed", secret: "s3cret", wantSignature: true},
+		{name: "unsigned"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, requests := newWebhookMock(t, http.StatusOK)
+			if err := NewWebhookClient(url, nil, tt.secret).SendNotification(context.Background(), "ping"); err != nil {
+				t.Fatalf("SendNotification() error = %v", err)
+			}
+
+			req := (*requests)[0]
+			if string(req.body) != `{"text":"ping"}` {
+				t.Errorf("body = %s", req.body)
+			}
+			signature := req.header.Get(WebhookSignatureHeader)
+			if tt.wantSignature != (signature != "") {
+				t.Errorf("%s = %q, want signature %v", WebhookSignatureHeader, signature, tt.wantSignature)
+			}
+			if tt.wantSignature && !verifyWebhookSignature(tt.secret, req.body, signature) {
+				t.Errorf("signature %q does not match the body", signature)
+			}
+		})
+	}
+}
+
+func TestWebhookClient_Errors(t *testing.T) {
+	url, _ := newWebhookMock(t, http.StatusForbidden)
+	err := NewWebhookClient(url, nil, "").SendScanResult(context.Background(), types.ScanResult{})
+	if err == nil || !strings.Contains(err.Error(), "forbidden") || !strings.Contains(err.Error(), "403") {
+		t.Errorf("SendScanResult() error = %v, want the receiver's error", err)
+	}
+
+	if err := NewWebhookClient("", nil, "").SendNotification(context.Background(), "ping"); err == nil || !strings.Contains(err.Error(), "webhook URL is required") {
+		t.Errorf("SendNotification() error = %v, want missing URL", err)
+	}
+}
+
+func TestSignWebhookBody(t *testing.T) {
+	// Vector de ejemplo de la documentación de webhooks de GitHub
+	got := SignWebhookBody("It's a Secret to Everybody", []byte("Hello, World!"))
+	want := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	if got != want {
+		t.Errorf("SignWebhookBody() = %q, want %q", got, want)
+	}
+}
@@ -7,52 +7,201 @@ import (
 
 	"github.com/user/docker-image-reporter/pkg/errors"
 	"github.com/user/docker-image-reporter/pkg/types"
+	"github.com/user/docker-image-reporter/pkg/utils"
 )
 
 // NotificationService coordina el envío de notificaciones a múltiples clientes
 type NotificationService struct {
-	clients []types.NotificationClient
+	clients []filteredClient
 }
 
+// filteredClient asocia un cliente con el tipo mínimo de actualización que le
+// interesa y, opcionalmente, con el canal de notificación que atiende
+type filteredClient struct {
+	client        types.NotificationClient
+	minUpdateType types.UpdateType // vacío = todas las actualizaciones
+	channel       string           // vacío = destino por defecto
+}
+
+// viewKey identifica una vista filtrada del resultado, para formatearla una sola vez
+type viewKey struct {
+	minUpdateType types.UpdateType
+	channel       string
+}
+
+// ReportRenderer genera el archivo a adjuntar para una vista del resultado.
+// Devuelve la ruta del archivo y el caption del mensaje.
+type ReportRenderer func(result types.ScanResult) (filePath, caption string, err error)
+
 // NewNotificationService crea un nuevo servicio de notificaciones
 func NewNotificationService(clients ...types.NotificationClient) *NotificationService {
-	return &NotificationService{
-		clients: clients,
+	s := &NotificationService{}
+	for _, client := range clients {
+		s.AddClient(client)
 	}
+	return s
 }
 
 // AddClient agrega un cliente de notificación al servicio
 func (s *NotificationService) AddClient(client types.NotificationClient) {
-	s.clients = append(s.clients, client)
+	s.AddClientWithFilter(client, "")
+}
+
+// AddClientWithFilter agrega un cliente que solo recibe actualizaciones de tipo
+// minUpdateType o superior (patch < minor < major). Un valor vacío no filtra.
+func (s *NotificationService) AddClientWithFilter(client types.NotificationClient, minUpdateType types.UpdateType) {
+	s.clients = append(s.clients, filteredClient{client: client, minUpdateType: minUpdateType})
+}
+
+// AddRoutedClient agrega un cliente que solo recibe las actualizaciones de los
+// servicios cuyo label image-reporter.notify-channel es channel. Esas
+// actualizaciones dejan de enviarse a los clientes por defecto.
+func (s *NotificationService) AddRoutedClient(channel string, client types.NotificationClient, minUpdateType types.UpdateType) {
+	s.clients = append(s.clients, filteredClient{client: client, minUpdateType: minUpdateType, channel: channel})
+}
+
+// routedChannels devuelve los canales que tienen un cliente asignado
+func (s *NotificationService) routedChannels() map[string]bool {
+	channels := make(map[string]bool)
+	for _, fc := range s.clients {
+		if fc.channel != "" {
+			channels[fc.channel] = true
+		}
+	}
+	return channels
 }
 
-// NotifyScanResult envía notificaciones basadas en el resultado del escaneo
+// view devuelve la vista del resultado que corresponde a un cliente: sus
+// actualizaciones enrutadas (o las no enrutadas si es un cliente por defecto)
+// filtradas por minUpdateType. Los errores solo llegan a los clientes por
+// defecto. Un canal sin cliente asignado cae en el destino por defecto.
+func (fc filteredClient) view(result types.ScanResult, routed map[string]bool) types.ScanResult {
+	if len(routed) > 0 {
+		updates := make([]types.ImageUpdate, 0, len(result.UpdatesAvailable))
+		for _, update := range result.UpdatesAvailable {
+			channel := update.CurrentImage.NotifyChannel
+			if !routed[channel] {
+				channel = ""
+			}
+			if channel == fc.channel {
+				updates = append(updates, update)
+			}
+		}
+		result.UpdatesAvailable = updates
+		if fc.channel != "" {
+			result.Errors = nil
+		}
+	}
+	return filterResult(result, fc.minUpdateType)
+}
+
+// filterResult devuelve una vista del resultado con solo las actualizaciones
+// que cumplen minUpdateType
+func filterResult(result types.ScanResult, minUpdateType types.UpdateType) types.ScanResult {
+	if minUpdateType == "" {
+		return result
+	}
+
+	filtered := make([]types.ImageUpdate, 0, len(result.UpdatesAvailable))
+	for _, update := range result.UpdatesAvailable {
+		if utils.IsUpdateTypeAcceptable(update.UpdateType, minUpdateType) {
+			filtered = append(filtered, update)
+		}
+	}
+	result.UpdatesAvailable = filtered
+	return result
+}
+
+// NotifyScanResult envía notificaciones basadas en el resultado del escaneo.
+// Los clientes que implementan types.ScanResultNotifier reciben su vista del
+// resultado en lugar del mensaje de formatter.
 func (s *NotificationService) NotifyScanResult(ctx context.Context, result types.ScanResult, formatter types.ReportFormatter) error {
 	if len(s.clients) == 0 {
 		return nil // No hay clientes configurados, no es un error
 	}
 
 	// Solo enviar notificaciones si hay updates o errores
-	if !result.HasUpdates() && !result.HasErrors() {
+	if !result.HasActionable(true) {
 		return nil // Nada que notificar
 	}
 
-	// Formatear el mensaje usando el formatter proporcionado
-	message, err := formatter.Format(result)
-	if err != nil {
-		return errors.Wrap("notification.NotifyScanResult", err)
+	// Formatear una vez por vista y enviar a cada cliente la suya
+	routed := s.routedChannels()
+	messages := make(map[viewKey]string)
+	var errs []string
+	for _, fc := range s.clients {
+		view := fc.view(result, routed)
+		if !view.HasActionable(true) {
+			continue // Nada relevante para este cliente
+		}
+
+		if notifier, ok := fc.client.(types.ScanResultNotifier); ok {
+			if err := notifier.SendScanResult(ctx, view); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", fc.client.Name(), err))
+			}
+			continue
+		}
+
+		key := viewKey{minUpdateType: fc.minUpdateType, channel: fc.channel}
+		message, ok := messages[key]
+		if !ok {
+			var err error
+			message, err = formatter.Format(view)
+			if err != nil {
+				return errors.Wrap("notification.NotifyScanResult", err)
+			}
+			messages[key] = message
+		}
+
+		if err := fc.client.SendNotification(ctx, message); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", fc.client.Name(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Newf("notification.NotifyScanResult", "failed to send notifications: %s", strings.Join(errs, "; "))
 	}
 
-	// Enviar a todos los clientes
+	return nil
+}
+
+// SendScanReport envía a cada cliente un archivo generado por render a partir de
+// su vista filtrada del resultado. Los clientes con filtro o canal que no tienen
+// actualizaciones ni errores relevantes se omiten; los clientes por defecto sin
+// filtro siempre reciben el informe. render se invoca una vez por vista distinta.
+func (s *NotificationService) SendScanReport(ctx context.Context, result types.ScanResult, fileName string, render ReportRenderer) error {
+	type rendered struct {
+		filePath string
+		caption  string
+	}
+
+	routed := s.routedChannels()
+	reports := make(map[viewKey]rendered)
 	var errs []string
-	for _, client := range s.clients {
-		if err := client.SendNotification(ctx, message); err != nil {
-			errs = append(errs, fmt.Sprintf("%s: %v", client.Name(), err))
+	for _, fc := range s.clients {
+		view := fc.view(result, routed)
+		if (fc.minUpdateType != "" || fc.channel != "") && !view.HasActionable(true) {
+			continue
+		}
+
+		key := viewKey{minUpdateType: fc.minUpdateType, channel: fc.channel}
+		report, ok := reports[key]
+		if !ok {
+			filePath, caption, err := render(view)
+			if err != nil {
+				return errors.Wrap("notification.SendScanReport", err)
+			}
+			report = rendered{filePath: filePath, caption: caption}
+			reports[key] = report
+		}
+
+		if err := fc.client.SendFile(ctx, report.filePath, fileName, report.caption); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", fc.client.Name(), err))
 		}
 	}
 
 	if len(errs) > 0 {
-		return errors.Newf("notification.NotifyScanResult", "failed to send notifications: %s", strings.Join(errs, "; "))
+		return errors.Newf("notification.SendScanReport", "failed to send report: %s", strings.Join(errs, "; "))
 	}
 
 	return nil
@@ -66,9 +215,9 @@ func (s *NotificationService) NotifyCustomMessage(ctx context.Context, message s
 
 	// Enviar a todos los clientes
 	var errs []string
-	for _, client := range s.clients {
-		if err := client.SendNotification(ctx, message); err != nil {
-			errs = append(errs, fmt.Sprintf("%s: %v", client.Name(), err))
+	for _, fc := range s.clients {
+		if err := fc.client.SendNotification(ctx, message); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", fc.client.Name(), err))
 		}
 	}
 
@@ -87,9 +236,9 @@ func (s *NotificationService) SendFile(ctx context.Context, filePath, fileName,
 
 	// Enviar a todos los clientes
 	var errs []string
-	for _, client := range s.clients {
-		if err := client.SendFile(ctx, filePath, fileName, caption); err != nil {
-			errs = append(errs, fmt.Sprintf("%s: %v", client.Name(), err))
+	for _, fc := range s.clients {
+		if err := fc.client.SendFile(ctx, filePath, fileName, caption); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", fc.client.Name(), err))
 		}
 	}
 
@@ -100,6 +249,23 @@ func (s *NotificationService) SendFile(ctx context.Context, filePath, fileName,
 	return nil
 }
 
+// ClientResult es el resultado de enviar un mensaje a un cliente concreto
+type ClientResult struct {
+	Name string
+	Err  error
+}
+
+// TestClients envía message a cada cliente por separado y devuelve el
+// resultado de cada uno en el orden en que se registraron: el fallo de un
+// cliente no impide probar los siguientes
+func (s *NotificationService) TestClients(ctx context.Context, message string) []ClientResult {
+	results := make([]ClientResult, 0, len(s.clients))
+	for _, fc := range s.clients {
+		results = append(results, ClientResult{Name: fc.client.Name(), Err: fc.client.SendNotification(ctx, message)})
+	}
+	return results
+}
+
 // HasClients verifica si hay clientes de notificación configurados
 func (s *NotificationService) HasClients() bool {
 	return len(s.clients) > 0
@@ -108,8 +274,8 @@ func (s *NotificationService) HasClients() bool {
 // GetClientNames devuelve los nombres de todos los clientes configurados
 func (s *NotificationService) GetClientNames() []string {
 	names := make([]string, len(s.clients))
-	for i, client := range s.clients {
-		names[i] = client.Name()
+	for i, fc := range s.clients {
+		names[i] = fc.client.Name()
 	}
 	return names
 }
@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+)
+
+const (
+	// maxSlackBlockText es el límite de caracteres del texto de un bloque section
+	maxSlackBlockText = 3000
+	// maxSlackBlocks es el número máximo de bloques por mensaje
+	maxSlackBlocks = 50
+)
+
+// slackTagReplacer traduce las etiquetas HTML de los mensajes (pensados para
+// Telegram) a mrkdwn de Slack
+var slackTagReplacer = strings.NewReplacer(
+	"<b>", "*", "</b>", "*",
+	"<i>", "_", "</i>", "_",
+	"<code>", "`", "</code>", "`",
+	"<pre>", "```", "</pre>", "```",
+)
+
+// htmlTagRegex encuentra las etiquetas HTML que mrkdwn no tiene
+var htmlTagRegex = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+
+// SlackClient implementa NotificationClient publicando en un incoming
+// webhook de Slack. Los mensajes se envían como bloques de Block Kit: una
+// sección por párrafo (cabecera, cada grupo de actualizaciones, errores y
+// pie), divididos si superan el límite de Slack.
+type SlackClient struct {
+	webhookURL string
+	channel    string
+	client     *http.Client
+}
+
+// slackBlock es un bloque section de Block Kit con texto mrkdwn
+type slackBlock struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+}
+
+// slackText es un objeto de texto de Block Kit
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackPayload es el cuerpo que se envía al webhook. Text es el resumen que
+// Slack muestra en las notificaciones del móvil
+type slackPayload struct {
+	Channel string       `json:"channel,omitempty"`
+	Text    string       `json:"text"`
+	Blocks  []slackBlock `json:"blocks"`
+}
+
+// NewSlackClient crea un cliente para el webhook webhookURL. channel es
+// opcional y solo lo respetan los webhooks antiguos; los actuales publican
+// siempre en el canal para el que se crearon.
+func NewSlackClient(webhookURL, channel string) *SlackClient {
+	return &SlackClient{
+		webhookURL: webhookURL,
+		channel:    channel,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// SendNotification publica el mensaje en Slack. Los mensajes con más de
+// maxSlackBlocks bloques se envían en varias partes.
+func (s *SlackClient) SendNotification(ctx context.Context, message string) error {
+	if s.webhookURL == "" {
+		return errors.New("slack.SendNotification", "webhook URL is required")
+	}
+
+	blocks := slackBlocks(toSlackMrkdwn(message))
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	for start := 0; start < len(blocks); start += maxSlackBlocks {
+		end := min(start+maxSlackBlocks, len(blocks))
+		payload := slackPayload{Channel: s.channel, Text: blocks[start].Text.Text, Blocks: blocks[start:end]}
+		if err := s.post(ctx, payload); err != nil {
+			return errors.Wrapf("slack.SendNotification", err, "failed to send message part starting at block %d", start+1)
+		}
+	}
+	return nil
+}
+
+// SendFile no adjunta nada: los incoming webhooks de Slack no aceptan
+// archivos, y el mensaje ya lleva el contenido del informe
+func (s *SlackClient) SendFile(ctx context.Context, filePath, fileName, caption string) error {
+	return nil
+}
+
+// Name devuelve el nombre del cliente de notificación
+func (s *SlackClient) Name() string {
+	return "slack"
+}
+
+// post envía un payload al webhook. Slack responde "ok" con un 200
+func (s *SlackClient) post(ctx context.Context, payload slackPayload) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap("slack.post", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return errors.Wrap("slack.post", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap("slack.post", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Newf("slack.post", "slack webhook error: %s (status: %d)", strings.TrimSpace(string(body)), resp.StatusCode)
+	}
+	return nil
+}
+
+// toSlackMrkdwn convierte un mensaje en HTML de Telegram a mrkdwn de Slack:
+// negrita, cursiva y código cambian de sintaxis, el resto de etiquetas se
+// eliminan y las entidades se decodifican. &, < y > se vuelven a escapar
+// como pide Slack.
+func toSlackMrkdwn(message string) string {
+	text := htmlTagRegex.ReplaceAllStringFunc(message, func(tag string) string {
+		if replaced := slackTagReplacer.Replace(tag); replaced != tag {
+			return replaced
+		}
+		return ""
+	})
+	text = html.UnescapeString(text)
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// slackBlocks divide el texto en bloques section: uno por párrafo, y los
+// párrafos largos por líneas como splitMessage hace para Telegram
+func slackBlocks(text string) []slackBlock {
+	var blocks []slackBlock
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		for _, part := range splitMessage(paragraph, maxSlackBlockText) {
+			blocks = append(blocks, slackBlock{Type: "section", Text: slackText{Type: "mrkdwn", Text: part}})
+		}
+	}
+	return blocks
+}
@@ -9,8 +9,10 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/user/docker-image-reporter/internal/clock"
 	"github.com/user/docker-image-reporter/pkg/errors"
 )
 
@@ -18,13 +20,25 @@ const (
 	telegramBaseURL = "https://api.telegram.org/bot%s/%s"
 	maxRetries      = 3
 	retryDelay      = 2 * time.Second
+	// minMessageInterval separa los envíos a un mismo chat; Telegram limita
+	// cada chat a alrededor de un mensaje por segundo
+	minMessageInterval = time.Second
+	// maxRetryAfter es la espera más larga que se acepta de un 429; si Telegram
+	// pide más, el envío falla en lugar de bloquear el escaneo
+	maxRetryAfter = time.Minute
 )
 
 // TelegramClient implementa NotificationClient para enviar notificaciones via Telegram
 type TelegramClient struct {
-	botToken string
-	chatID   string
-	client   *http.Client
+	botToken    string
+	chatID      string
+	client      *http.Client
+	baseURL     string        // formato de la URL de la API (token, método)
+	minInterval time.Duration // separación mínima entre envíos
+	clock       clock.Clock   // reloj de las esperas entre envíos y reintentos
+
+	mu       sync.Mutex
+	nextSend time.Time // primer instante en el que se puede enviar otra petición
 }
 
 // NewTelegramClient crea un nuevo cliente de Telegram
@@ -35,9 +49,38 @@ func NewTelegramClient(botToken, chatID string) *TelegramClient {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		baseURL:     telegramBaseURL,
+		minInterval: minMessageInterval,
+		clock:       clock.New(),
 	}
 }
 
+// WithClock reemplaza el reloj con el que se espacian los envíos y se esperan
+// los reintentos. Pensado para tests.
+func (t *TelegramClient) WithClock(c clock.Clock) *TelegramClient {
+	t.clock = c
+	return t
+}
+
+// retryAfterError es un 429 de Telegram con la espera que indica parameters.retry_after
+type retryAfterError struct {
+	retryAfter time.Duration
+	message    string
+}
+
+func (e *retryAfterError) Error() string {
+	return fmt.Sprintf("telegram API rate limit: %s (retry after %s)", e.message, e.retryAfter)
+}
+
+// telegramResponse es el cuerpo que devuelve la API de Telegram
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description,omitempty"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after,omitempty"`
+	} `json:"parameters,omitempty"`
+}
+
 // SendNotification envía una notificación a Telegram con reintentos
 func (t *TelegramClient) SendNotification(ctx context.Context, message string) error {
 	if t.botToken == "" {
@@ -56,19 +99,10 @@ func (t *TelegramClient) SendNotification(ctx context.Context, message string) e
 	}
 
 	// Dividir el mensaje en partes más pequeñas
-	messages := t.splitMessage(message, maxMessageLength)
+	messages := splitMessage(message, maxMessageLength)
 
-	// Enviar cada parte
+	// Enviar cada parte; throttle separa los envíos
 	for i, msg := range messages {
-		if i > 0 {
-			// Pequeña pausa entre mensajes para evitar rate limits
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(100 * time.Millisecond):
-			}
-		}
-
 		if err := t.sendSingleMessage(ctx, msg); err != nil {
 			return errors.Wrapf("telegram.SendNotification", err, "failed to send message part %d/%d", i+1, len(messages))
 		}
@@ -91,34 +125,108 @@ func (t *TelegramClient) sendSingleMessage(ctx context.Context, message string)
 		return errors.Wrap("telegram.sendSingleMessage", err)
 	}
 
-	url := fmt.Sprintf(telegramBaseURL, t.botToken, "sendMessage")
+	url := fmt.Sprintf(t.baseURL, t.botToken, "sendMessage")
+
+	return t.withRetries(ctx, "telegram.sendSingleMessage", func() error {
+		return t.sendRequest(ctx, url, jsonData)
+	})
+}
 
-	// Intentar enviar con reintentos
+// withRetries ejecuta send hasta maxRetries veces respetando la separación
+// mínima entre envíos. Tras un 429 espera lo que indica retry_after; tras
+// cualquier otro error, retryDelay.
+func (t *TelegramClient) withRetries(ctx context.Context, op string, send func() error) error {
 	var lastErr error
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		err := t.sendRequest(ctx, url, jsonData)
+		if err := t.throttle(ctx); err != nil {
+			return err
+		}
+
+		err := send()
 		if err == nil {
 			return nil // Éxito
 		}
-
 		lastErr = err
 
+		delay := retryDelay
+		var rateLimited *retryAfterError
+		if errors.AsType(err, &rateLimited) {
+			if rateLimited.retryAfter > maxRetryAfter {
+				return errors.Wrap(op, err)
+			}
+			delay = rateLimited.retryAfter
+		}
+
 		// Si no es el último intento, esperar antes de reintentar
 		if attempt < maxRetries {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-time.After(retryDelay):
+			case <-t.clock.After(delay):
 				// Continuar con el siguiente intento
 			}
 		}
 	}
 
-	return errors.Wrapf("telegram.sendSingleMessage", lastErr, "failed after %d attempts", maxRetries)
+	return errors.Wrapf(op, lastErr, "failed after %d attempts", maxRetries)
+}
+
+// throttle espera hasta que haya pasado minInterval desde el envío anterior.
+// Reserva el turno antes de esperar, así que los envíos concurrentes también
+// quedan separados.
+func (t *TelegramClient) throttle(ctx context.Context) error {
+	t.mu.Lock()
+	now := t.clock.Now()
+	sendAt := now
+	if t.nextSend.After(now) {
+		sendAt = t.nextSend
+	}
+	t.nextSend = sendAt.Add(t.minInterval)
+	t.mu.Unlock()
+
+	if wait := sendAt.Sub(now); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.clock.After(wait):
+		}
+	}
+	return nil
+}
+
+// parseTelegramResponse interpreta el cuerpo de la respuesta de la API. Un 429
+// se devuelve como *retryAfterError con la espera de parameters.retry_after.
+func parseTelegramResponse(op string, statusCode int, body []byte) error {
+	var telegramResp telegramResponse
+	parseErr := json.Unmarshal(body, &telegramResp)
+
+	if statusCode == http.StatusTooManyRequests {
+		retryAfter := retryDelay
+		if parseErr == nil && telegramResp.Parameters.RetryAfter > 0 {
+			retryAfter = time.Duration(telegramResp.Parameters.RetryAfter) * time.Second
+		}
+		return &retryAfterError{retryAfter: retryAfter, message: telegramResp.Description}
+	}
+
+	// Verificar el código de estado
+	if statusCode != http.StatusOK {
+		return errors.Newf(op, "telegram API error: %s (status: %d)", string(body), statusCode)
+	}
+
+	if parseErr != nil {
+		return errors.Wrap(op, parseErr)
+	}
+
+	if !telegramResp.OK {
+		return errors.Newf(op, "telegram API error: %s", telegramResp.Description)
+	}
+
+	return nil
 }
 
-// splitMessage divide un mensaje largo en partes más pequeñas
-func (t *TelegramClient) splitMessage(message string, maxLength int) []string {
+// splitMessage divide un mensaje largo en partes de como mucho maxLength
+// caracteres, cortando preferiblemente en saltos de línea o espacios
+func splitMessage(message string, maxLength int) []string {
 	if len(message) <= maxLength {
 		return []string{message}
 	}
@@ -183,26 +291,7 @@ func (t *TelegramClient) sendRequest(ctx context.Context, url string, jsonData [
 		return errors.Wrap("telegram.sendRequest", err)
 	}
 
-	// Verificar el código de estado
-	if resp.StatusCode != http.StatusOK {
-		return errors.Newf("telegram.sendRequest", "telegram API error: %s (status: %d)", string(body), resp.StatusCode)
-	}
-
-	// Parsear la respuesta JSON
-	var telegramResp struct {
-		OK          bool   `json:"ok"`
-		Description string `json:"description,omitempty"`
-	}
-
-	if err := json.Unmarshal(body, &telegramResp); err != nil {
-		return errors.Wrap("telegram.sendRequest", err)
-	}
-
-	if !telegramResp.OK {
-		return errors.Newf("telegram.sendRequest", "telegram API error: %s", telegramResp.Description)
-	}
-
-	return nil
+	return parseTelegramResponse("telegram.sendRequest", resp.StatusCode, body)
 }
 
 // sendMultipartRequest envía una solicitud HTTP multipart a la API de Telegram
@@ -226,26 +315,7 @@ func (t *TelegramClient) sendMultipartRequest(ctx context.Context, url string, b
 		return errors.Wrap("telegram.sendMultipartRequest", err)
 	}
 
-	// Verificar el código de estado
-	if resp.StatusCode != http.StatusOK {
-		return errors.Newf("telegram.sendMultipartRequest", "telegram API error: %s (status: %d)", string(respBody), resp.StatusCode)
-	}
-
-	// Parsear la respuesta JSON
-	var telegramResp struct {
-		OK          bool   `json:"ok"`
-		Description string `json:"description,omitempty"`
-	}
-
-	if err := json.Unmarshal(respBody, &telegramResp); err != nil {
-		return errors.Wrap("telegram.sendMultipartRequest", err)
-	}
-
-	if !telegramResp.OK {
-		return errors.Newf("telegram.sendMultipartRequest", "telegram API error: %s", telegramResp.Description)
-	}
-
-	return nil
+	return parseTelegramResponse("telegram.sendMultipartRequest", resp.StatusCode, respBody)
 }
 
 // Name devuelve el nombre del cliente de notificación
@@ -296,28 +366,11 @@ func (t *TelegramClient) SendFile(ctx context.Context, filePath, fileName, capti
 
 	w.Close()
 
-	url := fmt.Sprintf(telegramBaseURL, t.botToken, "sendDocument")
-
-	// Intentar enviar con reintentos
-	var lastErr error
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		err := t.sendMultipartRequest(ctx, url, &b, w.Boundary())
-		if err == nil {
-			return nil // Éxito
-		}
-
-		lastErr = err
-
-		// Si no es el último intento, esperar antes de reintentar
-		if attempt < maxRetries {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(retryDelay):
-				// Continuar con el siguiente intento
-			}
-		}
-	}
+	url := fmt.Sprintf(t.baseURL, t.botToken, "sendDocument")
 
-	return errors.Wrapf("telegram.SendFile", lastErr, "failed after %d attempts", maxRetries)
+	// Cada intento necesita el cuerpo completo
+	payload := b.Bytes()
+	return t.withRetries(ctx, "telegram.SendFile", func() error {
+		return t.sendMultipartRequest(ctx, url, bytes.NewBuffer(payload), w.Boundary())
+	})
 }
@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/user/docker-image-reporter/internal/report"
+	"github.com/user/docker-image-reporter/pkg/errors"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// WebhookSignatureHeader es la cabecera con la firma HMAC-SHA256 del cuerpo
+const WebhookSignatureHeader = "X-Signature-256"
+
+// WebhookClient implementa NotificationClient enviando a una URL el resultado
+// del escaneo completo en JSON, el mismo que genera scan --output json. Con
+// secreto, cada petición lleva en X-Signature-256 la firma HMAC-SHA256 del
+// cuerpo ("sha256=<hex>") para que el receptor pueda verificarla.
+type WebhookClient struct {
+	url       string
+	headers   map[string]string
+	secret    string
+	client    *http.Client
+	formatter report.JSONFormatter
+}
+
+// webhookMessage es el cuerpo de los mensajes de texto (pruebas y mensajes
+// personalizados), que no son resultados de escaneo
+type webhookMessage struct {
+	Text string `json:"text"`
+}
+
+// NewWebhookClient crea un cliente que publica en url. headers se añaden a
+// cada petición (p. ej. Authorization); secret vacío = sin firma.
+func NewWebhookClient(url string, headers map[string]string, secret string) *WebhookClient {
+	return &WebhookClient{
+		url:     url,
+		headers: headers,
+		secret:  secret,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		formatter: report.JSONFormatter{Compact: true},
+	}
+}
+
+// SendScanResult publica el resultado del escaneo como JSON
+func (w *WebhookClient) SendScanResult(ctx context.Context, result types.ScanResult) error {
+	body, err := w.formatter.Format(result)
+	if err != nil {
+		return errors.Wrap("webhook.SendScanResult", err)
+	}
+	if err := w.post(ctx, []byte(body)); err != nil {
+		return errors.Wrap("webhook.SendScanResult", err)
+	}
+	return nil
+}
+
+// SendNotification publica un mensaje de texto como {"text": message}
+func (w *WebhookClient) SendNotification(ctx context.Context, message string) error {
+	body, err := json.Marshal(webhookMessage{Text: message})
+	if err != nil {
+		return errors.Wrap("webhook.SendNotification", err)
+	}
+	if err := w.post(ctx, body); err != nil {
+		return errors.Wrap("webhook.SendNotification", err)
+	}
+	return nil
+}
+
+// SendFile no envía nada: el receptor ya tiene el resultado completo en JSON
+func (w *WebhookClient) SendFile(ctx context.Context, filePath, fileName, caption string) error {
+	return nil
+}
+
+// Name devuelve el nombre del cliente de notificación
+func (w *WebhookClient) Name() string {
+	return "webhook"
+}
+
+// post envía body a la URL con las cabeceras configuradas y la firma
+func (w *WebhookClient) post(ctx context.Context, body []byte) error {
+	if w.url == "" {
+		return errors.New("webhook.post", "webhook URL is required")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap("webhook.post", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+	if w.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookBody(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return errors.Wrap("webhook.post", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Newf("webhook.post", "webhook error: %s (status: %d)", strings.TrimSpace(string(respBody)), resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookBody devuelve la firma de body con secret tal como se envía en
+// X-Signature-256: "sha256=" seguido del HMAC-SHA256 en hexadecimal
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
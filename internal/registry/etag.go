@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/user/docker-image-reporter/internal/cache"
+	"github.com/user/docker-image-reporter/pkg/errors"
+)
+
+// errNotModified is returned by etagTransport when the registry answers a
+// conditional tag-list request with 304 Not Modified. listTags turns it into
+// the tag list cached for that ETag, so no body is rebuilt or parsed again.
+var errNotModified = errors.New("etag.RoundTrip", "tag list not modified")
+
+// etagProbeKey is the context key under which listTags passes its etagProbe
+type etagProbeKey struct{}
+
+// etagProbe records what etagTransport saw for the first page of one tag
+// listing
+type etagProbe struct {
+	url    string            // first tags/list request of the listing
+	cached *cache.CacheEntry // entry revalidated with If-None-Match, if any
+	etag   string            // ETag returned with a 200 for url
+	paged  bool              // the first page linked to a next page
+}
+
+// etagTransport adds conditional request support to tag listing calls.
+// Registries that return an ETag (notably GHCR) are asked again with
+// If-None-Match; a 304 Not Modified does not count against rate limits and
+// ends the request with errNotModified. Only the first page of a listing made
+// with an etagProbe in its context is revalidated.
+type etagTransport struct {
+	base  http.RoundTripper
+	cache *cache.RegistryCache
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	probe, _ := req.Context().Value(etagProbeKey{}).(*etagProbe)
+	if probe == nil || req.Method != http.MethodGet || !strings.HasSuffix(req.URL.Path, "/tags/list") {
+		return t.base.RoundTrip(req)
+	}
+	// Later pages pass through; a retry of the first page (e.g. after a token
+	// refresh) is revalidated again
+	key := req.URL.String()
+	if probe.url != "" && probe.url != key {
+		return t.base.RoundTrip(req)
+	}
+	probe.url = key
+
+	cached, found := t.cache.GetETag(key)
+	if found {
+		probe.cached = cached
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && found:
+		_ = resp.Body.Close()
+		return nil, errNotModified
+	case resp.StatusCode == http.StatusOK:
+		probe.etag = resp.Header.Get("ETag")
+		probe.paged = resp.Header.Get("Link") != ""
+	}
+	return resp, nil
+}
+
+// withETagProbe returns a context whose tag-list requests etagTransport
+// records in the returned probe
+func withETagProbe(ctx context.Context) (context.Context, *etagProbe) {
+	probe := &etagProbe{}
+	return context.WithValue(ctx, etagProbeKey{}, probe), probe
+}
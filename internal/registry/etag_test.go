@@ -0,0 +1,228 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/user/docker-image-reporter/internal/cache"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+func TestGenericRegistryClient_ETagReusesCachedTags(t *testing.T) {
+	var fullResponses, notModified atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/org/app/tags/list":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fullResponses.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"org/app","tags":["1.0.0","1.1.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	registryCache := cache.NewRegistryCache(cache.Config{DefaultTTL: time.Minute})
+	defer registryCache.Close()
+
+	client := NewGenericRegistryClient(5*time.Second, "").WithETagCache(registryCache)
+	image := types.DockerImage{
+		Registry:   strings.TrimPrefix(srv.URL, "http://"),
+		Repository: "org/app",
+		Tag:        "1.0.0",
+	}
+
+	first, err := client.GetLatestTags(context.Background(), image)
+	if err != nil {
+		t.Fatalf("first GetLatestTags() error = %v", err)
+	}
+
+	second, err := client.GetLatestTags(context.Background(), image)
+	if err != nil {
+		t.Fatalf("second GetLatestTags() error = %v", err)
+	}
+
+	if fullResponses.Load() != 1 {
+		t.Errorf("expected 1 full response, got %d", fullResponses.Load())
+	}
+	if notModified.Load() != 1 {
+		t.Errorf("expected 1 conditional 304 response, got %d", notModified.Load())
+	}
+
+	if strings.Join(first, ",") != "1.0.0,1.1.0" {
+		t.Errorf("first tags = %v, want [1.0.0 1.1.0]", first)
+	}
+	if strings.Join(second, ",") != strings.Join(first, ",") {
+		t.Errorf("tags after 304 = %v, want cached %v", second, first)
+	}
+}
+
+func TestGenericRegistryClient_NoETagDoesNotCache(t *testing.T) {
+	var conditional atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/org/app/tags/list":
+			if r.Header.Get("If-None-Match") != "" {
+				conditional.Add(1)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"org/app","tags":["1.0.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	registryCache := cache.NewRegistryCache(cache.Config{DefaultTTL: time.Minute})
+	defer registryCache.Close()
+
+	client := NewGenericRegistryClient(5*time.Second, "").WithETagCache(registryCache)
+	image := types.DockerImage{
+		Registry:   strings.TrimPrefix(srv.URL, "http://"),
+		Repository: "org/app",
+		Tag:        "1.0.0",
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetLatestTags(context.Background(), image); err != nil {
+			t.Fatalf("GetLatestTags() error = %v", err)
+		}
+	}
+
+	if conditional.Load() != 0 {
+		t.Errorf("expected no conditional requests without ETag, got %d", conditional.Load())
+	}
+}
+
+func TestGenericRegistryClient_NotModifiedReturnsCachedTagList(t *testing.T) {
+	var notModified atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/org/app/tags/list":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"org/app","tags":["1.0.0","1.1.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	registryCache := cache.NewRegistryCache(cache.Config{DefaultTTL: time.Minute})
+	defer registryCache.Close()
+
+	client := NewGenericRegistryClient(5*time.Second, "").WithETagCache(registryCache)
+	image := types.DockerImage{
+		Registry:   strings.TrimPrefix(srv.URL, "http://"),
+		Repository: "org/app",
+		Tag:        "1.0.0",
+	}
+
+	if _, err := client.GetLatestTags(context.Background(), image); err != nil {
+		t.Fatalf("first GetLatestTags() error = %v", err)
+	}
+
+	// The parsed tag list is cached under the ETag of the listing
+	var key string
+	for _, entry := range registryCache.Entries() {
+		if entry.Kind == "etag" {
+			key = strings.TrimSuffix(entry.Key, "#etag")
+		}
+	}
+	cached, found := registryCache.GetETag(key)
+	if !found || cached.ETag != `"v1"` || strings.Join(cached.Tags, ",") != "1.0.0,1.1.0" {
+		t.Fatalf("GetETag(%q) = %+v, %v; want the parsed tags under \"v1\"", key, cached, found)
+	}
+
+	// A 304 returns the cached list itself: a marker tag that no response
+	// body contains proves nothing was parsed again
+	registryCache.SetETag(key, `"v1"`, []string{"1.0.0", "9.9.9"})
+	tags, err := client.GetLatestTags(context.Background(), image)
+	if err != nil {
+		t.Fatalf("second GetLatestTags() error = %v", err)
+	}
+	if notModified.Load() != 1 {
+		t.Errorf("expected 1 conditional 304 response, got %d", notModified.Load())
+	}
+	if strings.Join(tags, ",") != "1.0.0,9.9.9" {
+		t.Errorf("tags after 304 = %v, want the cached [1.0.0 9.9.9]", tags)
+	}
+}
+
+func TestGenericRegistryClient_ETagSkipsPagedListings(t *testing.T) {
+	var conditional atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/org/app/tags/list":
+			if r.Header.Get("If-None-Match") != "" {
+				conditional.Add(1)
+			}
+			w.Header().Set("ETag", `"page"`)
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/org/app/tags/list?last=1.0.0>; rel="next"`)
+				_, _ = w.Write([]byte(`{"name":"org/app","tags":["1.0.0"]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"name":"org/app","tags":["2.0.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	registryCache := cache.NewRegistryCache(cache.Config{DefaultTTL: time.Minute})
+	defer registryCache.Close()
+
+	client := NewGenericRegistryClient(5*time.Second, "").WithETagCache(registryCache)
+	image := types.DockerImage{
+		Registry:   strings.TrimPrefix(srv.URL, "http://"),
+		Repository: "org/app",
+		Tag:        "1.0.0",
+	}
+
+	// The first page's ETag cannot vouch for later pages, so nothing is cached
+	for i := 0; i < 2; i++ {
+		tags, err := client.GetLatestTags(context.Background(), image)
+		if err != nil {
+			t.Fatalf("GetLatestTags() error = %v", err)
+		}
+		if strings.Join(tags, ",") != "1.0.0,2.0.0" {
+			t.Errorf("tags = %v, want both pages", tags)
+		}
+	}
+	if conditional.Load() != 0 {
+		t.Errorf("expected no conditional requests for a paged listing, got %d", conditional.Load())
+	}
+	if len(registryCache.Entries()) != 0 {
+		t.Errorf("expected no ETag entries, got %+v", registryCache.Entries())
+	}
+}
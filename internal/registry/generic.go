@@ -2,12 +2,19 @@ package registry
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/user/docker-image-reporter/internal/cache"
 	"github.com/user/docker-image-reporter/pkg/errors"
 	"github.com/user/docker-image-reporter/pkg/types"
 )
@@ -16,19 +23,109 @@ import (
 // using the standard OCI Distribution Specification via google/go-containerregistry.
 // It is the primary registry client used by the scanner service.
 type GenericRegistryClient struct {
-	timeout  time.Duration
-	keychain authn.Keychain
+	timeout      time.Duration
+	keychain     authn.Keychain
+	transport    http.RoundTripper
+	rateLimits   *rateLimitTransport
+	etagCache    *cache.RegistryCache
+	trustedHosts map[string]bool
+	maxTags      int
+	// dockerHubMirror is the host (and optional path) queried instead of
+	// Docker Hub, empty to query Docker Hub itself
+	dockerHubMirror string
+	// hostTimeouts overrides timeout for specific registry hosts
+	hostTimeouts map[string]time.Duration
 }
 
+// defaultMaxTags caps the number of tags collected across all pages of a tag
+// listing, so a huge or malicious listing cannot exhaust memory.
+const defaultMaxTags = 50000
+
 // NewGenericRegistryClient creates a new generic OCI registry client.
 // ghcrToken is optional: when non-empty it is used as a Bearer token for ghcr.io,
 // which allows access to private GHCR images. All other registries fall back to
 // credentials from ~/.docker/config.json (authn.DefaultKeychain).
 func NewGenericRegistryClient(timeout time.Duration, ghcrToken string) *GenericRegistryClient {
+	rateLimits := newRateLimitTransport(remote.DefaultTransport)
 	return &GenericRegistryClient{
-		timeout:  timeout,
-		keychain: buildKeychain(ghcrToken),
+		timeout:    timeout,
+		keychain:   buildKeychain(ghcrToken),
+		transport:  rateLimits,
+		rateLimits: rateLimits,
+		maxTags:    defaultMaxTags,
+	}
+}
+
+// WithTrustedHosts allows tag-list pagination links to point at the given hosts
+// in addition to the registry being queried (e.g. a CDN fronting a proxy).
+// Links to any other host are rejected.
+func (g *GenericRegistryClient) WithTrustedHosts(hosts []string) *GenericRegistryClient {
+	g.trustedHosts = trustedHostSet(hosts)
+	return g
+}
+
+// WithETagCache enables conditional tag-list requests backed by the given cache.
+// Single-page listings from registries that return an ETag (notably GHCR) are
+// cached as parsed tag lists, revalidated with If-None-Match, and a 304 Not
+// Modified returns the cached tags as they are.
+func (g *GenericRegistryClient) WithETagCache(c *cache.RegistryCache) *GenericRegistryClient {
+	g.transport = &etagTransport{base: g.rateLimits, cache: c}
+	g.etagCache = c
+	return g
+}
+
+// WithDockerHubCredentials authenticates Docker Hub requests with username and
+// a password or personal access token, which raises the pull rate limit to the
+// authenticated tier. Other registries keep their existing keychain. Empty
+// credentials leave the client unchanged.
+func (g *GenericRegistryClient) WithDockerHubCredentials(username, password string) *GenericRegistryClient {
+	if username == "" || password == "" {
+		return g
+	}
+	g.keychain = &dockerHubKeychain{
+		auth:     authn.AuthConfig{Username: username, Password: password},
+		fallback: g.keychain,
+	}
+	return g
+}
+
+// WithEnvCredentials authenticates each registry with the credentials found in
+// the REGISTRY_<HOST>_USERNAME/_PASSWORD or REGISTRY_<HOST>_TOKEN environment
+// variables, where <HOST> is the registry host uppercased with every
+// non-alphanumeric character replaced by "_" (registry.example.com:5000 ->
+// REGISTRY_REGISTRY_EXAMPLE_COM_5000_TOKEN). Docker Hub uses DOCKER_IO.
+// Registries without such variables keep the existing keychain, as does the
+// whole client when enabled is false.
+func (g *GenericRegistryClient) WithEnvCredentials(enabled bool) *GenericRegistryClient {
+	if !enabled {
+		return g
+	}
+	g.keychain = &envKeychain{getenv: os.Getenv, fallback: g.keychain}
+	return g
+}
+
+// WithDockerHubMirror queries Docker Hub images at mirror (host[/path])
+// instead of the canonical Docker Hub. Mirrors do not use the library/
+// namespace of official images, so library/nginx is requested as nginx.
+// Docker Hub credentials are not sent to the mirror.
+func (g *GenericRegistryClient) WithDockerHubMirror(mirror string) *GenericRegistryClient {
+	g.dockerHubMirror = strings.TrimSuffix(mirror, "/")
+	return g
+}
+
+// WithHostTimeouts overrides the client timeout for the given registry hosts
+// (lowercase host[:port]), e.g. slow self-hosted registries.
+func (g *GenericRegistryClient) WithHostTimeouts(timeouts map[string]time.Duration) *GenericRegistryClient {
+	g.hostTimeouts = timeouts
+	return g
+}
+
+// timeoutFor returns the timeout for requests to registry
+func (g *GenericRegistryClient) timeoutFor(registry string) time.Duration {
+	if timeout, ok := g.hostTimeouts[strings.ToLower(registry)]; ok {
+		return timeout
 	}
+	return g.timeout
 }
 
 // buildKeychain returns a keychain that injects a Bearer token for ghcr.io when
@@ -57,46 +154,239 @@ func (k *tokenKeychain) Resolve(res authn.Resource) (authn.Authenticator, error)
 	return k.fallback.Resolve(res)
 }
 
+// dockerHubKeychain provides Docker Hub authentication via configured
+// credentials and delegates all other registries to fallback.
+type dockerHubKeychain struct {
+	auth     authn.AuthConfig
+	fallback authn.Keychain
+}
+
+func (k *dockerHubKeychain) Resolve(res authn.Resource) (authn.Authenticator, error) {
+	switch res.RegistryStr() {
+	case name.DefaultRegistry, "docker.io", "registry-1.docker.io":
+		return authn.FromConfig(k.auth), nil
+	}
+	return k.fallback.Resolve(res)
+}
+
+// envKeychain provides per-registry credentials from environment variables
+// and delegates registries without them to fallback.
+type envKeychain struct {
+	getenv   func(string) string
+	fallback authn.Keychain
+}
+
+func (k *envKeychain) Resolve(res authn.Resource) (authn.Authenticator, error) {
+	prefix := EnvCredentialPrefix(res.RegistryStr())
+	if token := k.getenv(prefix + "TOKEN"); token != "" {
+		return authn.FromConfig(authn.AuthConfig{RegistryToken: token}), nil
+	}
+	username, password := k.getenv(prefix+"USERNAME"), k.getenv(prefix+"PASSWORD")
+	if username != "" && password != "" {
+		return authn.FromConfig(authn.AuthConfig{Username: username, Password: password}), nil
+	}
+	return k.fallback.Resolve(res)
+}
+
+// EnvCredentialPrefix returns the environment variable prefix holding the
+// credentials of a registry host, e.g. "REGISTRY_GHCR_IO_" for ghcr.io. The
+// host is uppercased and every non-alphanumeric character becomes "_"; all
+// Docker Hub hosts share "REGISTRY_DOCKER_IO_".
+func EnvCredentialPrefix(host string) string {
+	switch host {
+	case name.DefaultRegistry, "registry-1.docker.io":
+		host = "docker.io"
+	}
+	sanitized := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, host)
+	return "REGISTRY_" + sanitized + "_"
+}
+
 // Name returns "generic" to indicate this client handles any registry.
 func (g *GenericRegistryClient) Name() string {
 	return "generic"
 }
 
-// GetLatestTags fetches all tags for the given image from any OCI-compatible registry.
-// Authentication is resolved automatically from ~/.docker/config.json via the default keychain.
+// GetLatestTags returns the names of all tags for the given image. It is a
+// thin wrapper around GetTags kept for callers that only need names.
 func (g *GenericRegistryClient) GetLatestTags(ctx context.Context, image types.DockerImage) ([]string, error) {
-	repoRef := buildRepoReference(image)
+	tags, err := g.GetTags(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	return types.TagNames(tags), nil
+}
+
+// GetTags fetches all tags for the given image from any OCI-compatible registry.
+// Authentication is resolved automatically from ~/.docker/config.json via the default keychain.
+// The OCI tag listing only carries names, so the remaining TagInfo fields are left empty.
+func (g *GenericRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	repoRef := g.repoReference(image)
 
 	repo, err := name.NewRepository(repoRef)
 	if err != nil {
-		return nil, errors.Wrapf("generic.GetLatestTags", err, "parsing repository %s", repoRef)
+		return nil, errors.Wrapf("generic.GetTags", err, "parsing repository %s", repoRef)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	ctx, cancel := context.WithTimeout(ctx, g.timeoutFor(image.Registry))
 	defer cancel()
 
-	tags, err := remote.List(repo,
-		remote.WithContext(ctx),
-		remote.WithAuthFromKeychain(g.keychain),
-	)
+	tags, err := g.listTags(ctx, repo)
 	if err != nil {
-		return nil, errors.Wrapf("generic.GetLatestTags", err, "listing tags for %s", repoRef)
+		return nil, errors.Wrapf("generic.GetTags", classifyAuthError(err), "listing tags for %s", repoRef)
 	}
 
-	filtered := make([]string, 0, len(tags))
+	filtered := make([]types.TagInfo, 0, len(tags))
 	for _, t := range tags {
 		if isValidGenericTag(t) {
-			filtered = append(filtered, t)
+			filtered = append(filtered, types.TagInfo{Name: t})
 		}
 	}
 
 	if len(filtered) == 0 {
-		return nil, errors.Newf("generic.GetLatestTags", "no valid tags found for %s", repoRef)
+		return nil, errors.Newf("generic.GetTags", "no valid tags found for %s", repoRef)
 	}
 
 	return filtered, nil
 }
 
+// classifyAuthError marks 401 and 403 responses with ErrAuthenticationError so
+// callers can tell a private repository from an unreachable registry.
+func classifyAuthError(err error) error {
+	var terr *transport.Error
+	if errors.AsType(err, &terr) &&
+		(terr.StatusCode == http.StatusUnauthorized || terr.StatusCode == http.StatusForbidden) {
+		return fmt.Errorf("%w: %w", errors.ErrAuthenticationError, err)
+	}
+	return err
+}
+
+// listTags walks every page of the repository's tag listing. Pagination links
+// are resolved against the registry URL, links to untrusted hosts are refused
+// and the listing is aborted once it exceeds maxTags. With an ETag cache, a
+// 304 for the first page returns the cached tags and a single-page listing
+// that carried an ETag is cached for the next call.
+func (g *GenericRegistryClient) listTags(ctx context.Context, repo name.Repository) ([]string, error) {
+	ctx, probe := withETagProbe(ctx)
+	tags, err := g.walkTags(ctx, repo)
+	switch {
+	case errors.IsType(err, errNotModified) && probe.cached != nil:
+		return probe.cached.Tags, nil
+	case err != nil:
+		return nil, err
+	}
+
+	// The ETag of the first page says nothing about later pages
+	if g.etagCache != nil && probe.etag != "" && !probe.paged {
+		g.etagCache.SetETag(probe.url, probe.etag, tags)
+	}
+	return tags, nil
+}
+
+// walkTags collects the tags of every page of the listing
+func (g *GenericRegistryClient) walkTags(ctx context.Context, repo name.Repository) ([]string, error) {
+	puller, err := remote.NewPuller(g.remoteOptions(ctx)...)
+	if err != nil {
+		return nil, err
+	}
+
+	lister, err := puller.Lister(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	base := &url.URL{Scheme: repo.Scheme(), Host: repo.RegistryStr(), Path: "/v2/" + repo.RepositoryStr() + "/tags/list"}
+	var tags []string
+	for lister.HasNext() {
+		page, err := lister.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, page.Tags...)
+		if len(tags) > g.maxTags {
+			return nil, errors.Newf("generic.listTags", "tag listing exceeds %d tags", g.maxTags)
+		}
+
+		if page.Next != "" {
+			// The lister follows page.Next, so validate it before the next request
+			if page.Next, err = resolveNextPage(base, page.Next, g.trustedHosts); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return tags, nil
+}
+
+// resolveNextPage turns a pagination link (absolute, or relative as rewritten by
+// some proxies) into an absolute URL, refusing hosts other than the registry's
+// own unless they are in trustedHosts.
+func resolveNextPage(base *url.URL, next string, trustedHosts map[string]bool) (string, error) {
+	link, err := url.Parse(next)
+	if err != nil {
+		return "", errors.Wrapf("registry.resolveNextPage", err, "parsing pagination link %q", next)
+	}
+
+	resolved := base.ResolveReference(link)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return "", errors.Newf("registry.resolveNextPage", "unsupported pagination link %q", next)
+	}
+
+	host := strings.ToLower(resolved.Host)
+	if host != strings.ToLower(base.Host) && !trustedHosts[host] {
+		return "", errors.Newf("registry.resolveNextPage", "refusing pagination link to untrusted host %s", resolved.Host)
+	}
+
+	return resolved.String(), nil
+}
+
+// trustedHostSet lowercases hosts into a set for resolveNextPage
+func trustedHostSet(hosts []string) map[string]bool {
+	set := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		set[strings.ToLower(host)] = true
+	}
+	return set
+}
+
+// GetDigest resolves the manifest digest the image tag currently points to.
+// It issues a HEAD request, which does not count against Docker Hub pull limits.
+// Once a registry reports rateLimitReserve or fewer remaining requests, digest
+// checks against it are skipped until the budget recovers.
+func (g *GenericRegistryClient) GetDigest(ctx context.Context, image types.DockerImage) (string, error) {
+	tagRef := g.repoReference(image) + ":" + image.Tag
+
+	ref, err := name.NewTag(tagRef)
+	if err != nil {
+		return "", errors.Wrapf("generic.GetDigest", err, "parsing reference %s", tagRef)
+	}
+
+	if limit, ok := g.rateLimits.get(ref.Context().RegistryStr()); ok && limit.Remaining <= rateLimitReserve {
+		return "", errors.Newf("generic.GetDigest", "rate limit nearly exhausted (%s), skipping digest check for %s", limit, tagRef)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeoutFor(image.Registry))
+	defer cancel()
+
+	desc, err := remote.Head(ref, g.remoteOptions(ctx)...)
+	if err != nil {
+		return "", errors.Wrapf("generic.GetDigest", err, "resolving digest for %s", tagRef)
+	}
+
+	return desc.Digest.String(), nil
+}
+
+// RateLimits returns the latest RateLimit-Limit/RateLimit-Remaining values
+// seen for each registry host.
+func (g *GenericRegistryClient) RateLimits() []types.RateLimit {
+	return g.rateLimits.snapshot()
+}
+
 // GetImageInfo returns basic image metadata. Tag listing is the primary use case.
 func (g *GenericRegistryClient) GetImageInfo(ctx context.Context, image types.DockerImage) (*types.ImageInfo, error) {
 	tags, err := g.GetLatestTags(ctx, image)
@@ -110,20 +400,71 @@ func (g *GenericRegistryClient) GetImageInfo(ctx context.Context, image types.Do
 	}, nil
 }
 
+// Ping checks that the registry's /v2/ endpoint answers. Any HTTP response,
+// including 401 Unauthorized, counts as reachable; only DNS, connection and
+// timeout failures are reported.
+func (g *GenericRegistryClient) Ping(ctx context.Context, registry string) error {
+	if registry == "" {
+		registry = name.DefaultRegistry
+	}
+	reg, err := name.NewRegistry(registry)
+	if err != nil {
+		return errors.Wrapf("generic.Ping", err, "parsing registry %s", registry)
+	}
+
+	url := reg.Scheme() + "://" + reg.RegistryStr() + "/v2/"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrapf("generic.Ping", err, "building request for %s", url)
+	}
+
+	resp, err := (&http.Client{Transport: remote.DefaultTransport, Timeout: g.timeoutFor(registry)}).Do(req)
+	if err != nil {
+		return errors.Wrapf("generic.Ping", err, "reaching %s", reg.RegistryStr())
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// remoteOptions returns the go-containerregistry options shared by all calls.
+func (g *GenericRegistryClient) remoteOptions(ctx context.Context) []remote.Option {
+	opts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(g.keychain),
+	}
+	if g.transport != nil {
+		opts = append(opts, remote.WithTransport(g.transport))
+	}
+	return opts
+}
+
+// repoReference returns the repository reference to query for image: Docker
+// Hub images go to the mirror, if one is configured, without library/.
+func (g *GenericRegistryClient) repoReference(image types.DockerImage) string {
+	ref := buildRepoReference(image)
+	if g.dockerHubMirror == "" || !isDockerHub(image.Registry) {
+		return ref
+	}
+	return g.dockerHubMirror + "/" + strings.TrimPrefix(ref, "library/")
+}
+
+// isDockerHub reports whether registry is the canonical Docker Hub
+func isDockerHub(registry string) bool {
+	return registry == "" || registry == "docker.io" || registry == "index.docker.io"
+}
+
 // buildRepoReference constructs the full repository reference understood by go-containerregistry.
-// go-containerregistry handles docker.io and library/ prefixes natively.
+// go-containerregistry handles docker.io and library/ prefixes natively. Other
+// registries get their repository verbatim: a single-segment name such as
+// myregistry.com/app is not an official image and must not gain library/.
 func buildRepoReference(image types.DockerImage) string {
-	repo := image.Repository
-	// Strip known registry prefixes; go-containerregistry adds them back correctly.
-	repo = strings.TrimPrefix(repo, "docker.io/")
-	repo = strings.TrimPrefix(repo, "index.docker.io/")
-
-	if image.Registry != "" &&
-		image.Registry != "docker.io" &&
-		image.Registry != "index.docker.io" {
-		return image.Registry + "/" + repo
+	if !isDockerHub(image.Registry) {
+		return image.Registry + "/" + image.Repository
 	}
-	return repo
+
+	// Strip known registry prefixes; go-containerregistry adds them back correctly.
+	repo := strings.TrimPrefix(image.Repository, "docker.io/")
+	return strings.TrimPrefix(repo, "index.docker.io/")
 }
 
 // isValidGenericTag returns true for tags that are useful for version comparison.
@@ -1,10 +1,16 @@
 package registry
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/user/docker-image-reporter/pkg/errors"
 	"github.com/user/docker-image-reporter/pkg/types"
 )
 
@@ -37,6 +43,14 @@ func TestBuildRepoReference(t *testing.T) {
 			},
 			expected: "ghcr.io/user/app",
 		},
+		{
+			name: "nested ghcr package",
+			image: types.DockerImage{
+				Registry:   "ghcr.io",
+				Repository: "org/team/app",
+			},
+			expected: "ghcr.io/org/team/app",
+		},
 		{
 			name: "quay image",
 			image: types.DockerImage{
@@ -45,6 +59,30 @@ func TestBuildRepoReference(t *testing.T) {
 			},
 			expected: "quay.io/org/img",
 		},
+		{
+			name: "single segment on a custom registry",
+			image: types.DockerImage{
+				Registry:   "myregistry.com",
+				Repository: "app",
+			},
+			expected: "myregistry.com/app",
+		},
+		{
+			name: "pull-through mirror keeps docker.io path segment",
+			image: types.DockerImage{
+				Registry:   "mirror.example.com",
+				Repository: "docker.io/library/nginx",
+			},
+			expected: "mirror.example.com/docker.io/library/nginx",
+		},
+		{
+			name: "official image",
+			image: types.DockerImage{
+				Registry:   "docker.io",
+				Repository: "library/nginx",
+			},
+			expected: "library/nginx",
+		},
 	}
 
 	for _, tt := range tests {
@@ -152,3 +190,457 @@ func (k *testKeychain) Resolve(_ authn.Resource) (authn.Authenticator, error) {
 	}
 	return authn.Anonymous, nil
 }
+
+func TestGenericRegistryClient_GetDigest(t *testing.T) {
+	const remoteDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodHead && r.URL.Path == "/v2/library/nginx/manifests/1.25":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+			w.Header().Set("Content-Length", "512")
+			w.Header().Set("Docker-Content-Digest", remoteDigest)
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewGenericRegistryClient(5*time.Second, "")
+	image := types.DockerImage{
+		Registry:   strings.TrimPrefix(srv.URL, "http://"),
+		Repository: "library/nginx",
+		Tag:        "1.25",
+		Digest:     "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+	}
+
+	digest, err := client.GetDigest(context.Background(), image)
+	if err != nil {
+		t.Fatalf("GetDigest() error = %v", err)
+	}
+	if digest != remoteDigest {
+		t.Errorf("GetDigest() = %s, want %s", digest, remoteDigest)
+	}
+
+	image.Tag = "missing"
+	if _, err := client.GetDigest(context.Background(), image); err == nil {
+		t.Error("GetDigest() for unknown tag expected error, got nil")
+	}
+}
+
+func TestGenericRegistryClient_GetLatestTags_NestedPackage(t *testing.T) {
+	// The distribution API addresses nested repositories with literal slashes,
+	// so org/team/app must not be URL-encoded as it is in the GitHub packages API.
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.EscapedPath())
+		switch r.URL.EscapedPath() {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/org/team/app/tags/list":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"org/team/app","tags":["1.0.0","1.1.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	image, err := types.ParseImageReference(host + "/org/team/app:1.0.0")
+	if err != nil {
+		t.Fatalf("ParseImageReference() error = %v", err)
+	}
+	if image.Repository != "org/team/app" {
+		t.Fatalf("Repository = %q, want %q", image.Repository, "org/team/app")
+	}
+
+	client := NewGenericRegistryClient(5*time.Second, "")
+	tags, err := client.GetLatestTags(context.Background(), image)
+	if err != nil {
+		t.Fatalf("GetLatestTags() error = %v (requested %v)", err, requested)
+	}
+	if len(tags) != 2 {
+		t.Errorf("GetLatestTags() = %v, want 2 tags", tags)
+	}
+}
+
+func TestGenericRegistryClient_GetTags(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/org/app/tags/list":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"org/app","tags":["1.0.0","tmp-build","1.1.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	image := types.DockerImage{Registry: strings.TrimPrefix(srv.URL, "http://"), Repository: "org/app", Tag: "1.0.0"}
+	client := NewGenericRegistryClient(5*time.Second, "")
+
+	tags, err := client.GetTags(context.Background(), image)
+	if err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+	// The OCI listing only carries names: invalid tags are dropped and no
+	// metadata is invented
+	want := []types.TagInfo{{Name: "1.0.0"}, {Name: "1.1.0"}}
+	if len(tags) != len(want) {
+		t.Fatalf("GetTags() = %+v, want %+v", tags, want)
+	}
+	for i := range want {
+		if tags[i].Name != want[i].Name || !tags[i].LastUpdated.IsZero() || tags[i].Digest != "" || tags[i].Architectures != nil {
+			t.Errorf("GetTags()[%d] = %+v, want %+v", i, tags[i], want[i])
+		}
+	}
+
+	names, err := client.GetLatestTags(context.Background(), image)
+	if err != nil {
+		t.Fatalf("GetLatestTags() error = %v", err)
+	}
+	if strings.Join(names, ",") != "1.0.0,1.1.0" {
+		t.Errorf("GetLatestTags() = %v, want the GetTags names", names)
+	}
+}
+
+func TestGenericRegistryClient_Ping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A registry that requires auth is still reachable
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	up := strings.TrimPrefix(srv.URL, "http://")
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	downHost := strings.TrimPrefix(down.URL, "http://")
+	down.Close()
+	defer srv.Close()
+
+	client := NewGenericRegistryClient(2*time.Second, "")
+
+	if err := client.Ping(context.Background(), up); err != nil {
+		t.Errorf("Ping(%s) error = %v, want nil", up, err)
+	}
+	if err := client.Ping(context.Background(), downHost); err == nil {
+		t.Errorf("Ping(%s) expected error for a registry that is down", downHost)
+	}
+}
+
+// newPagedRegistry serves org/app tags in two pages; the first page links to
+// the second with the given Link header value.
+func newPagedRegistry(t *testing.T, link func(host string) string) (string, *[]string) {
+	t.Helper()
+
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/org/app/tags/list" && r.URL.Query().Get("last") == "":
+			w.Header().Set("Link", link(r.Host))
+			_, _ = w.Write([]byte(`{"name":"org/app","tags":["1.0.0","1.1.0"]}`))
+		case r.URL.Path == "/v2/org/app/tags/list":
+			_, _ = w.Write([]byte(`{"name":"org/app","tags":["1.2.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return strings.TrimPrefix(srv.URL, "http://"), &requested
+}
+
+// localhostAlias rewrites 127.0.0.1:port as localhost:port
+func localhostAlias(host string) string {
+	return strings.Replace(host, "127.0.0.1", "localhost", 1)
+}
+
+func TestGenericRegistryClient_GetLatestTags_Pagination(t *testing.T) {
+	tests := []struct {
+		name           string
+		link           func(host string) string
+		trustLocalhost bool
+		maxTags        int
+		wantTags       int
+		wantErr        string
+	}{
+		{
+			name:     "relative next",
+			link:     func(string) string { return `</v2/org/app/tags/list?last=1.1.0>; rel="next"` },
+			wantTags: 3,
+		},
+		{
+			name:     "absolute next on the same host",
+			link:     func(host string) string { return `<http://` + host + `/v2/org/app/tags/list?last=1.1.0>; rel="next"` },
+			wantTags: 3,
+		},
+		{
+			// localhost reaches the same test server under a different host name
+			name: "cross-host next is rejected",
+			link: func(host string) string {
+				return `<http://` + localhostAlias(host) + `/v2/org/app/tags/list?last=1.1.0>; rel="next"`
+			},
+			wantErr: "untrusted host localhost",
+		},
+		{
+			name: "cross-host next to a trusted host is followed",
+			link: func(host string) string {
+				return `<http://` + localhostAlias(host) + `/v2/org/app/tags/list?last=1.1.0>; rel="next"`
+			},
+			trustLocalhost: true,
+			wantTags:       3,
+		},
+		{
+			name:    "total cap",
+			link:    func(string) string { return `</v2/org/app/tags/list?last=1.1.0>; rel="next"` },
+			maxTags: 2,
+			wantErr: "exceeds 2 tags",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, requested := newPagedRegistry(t, tt.link)
+			client := NewGenericRegistryClient(2*time.Second, "")
+			if tt.trustLocalhost {
+				client.WithTrustedHosts([]string{localhostAlias(host)})
+			}
+			if tt.maxTags > 0 {
+				client.maxTags = tt.maxTags
+			}
+
+			image := types.DockerImage{Registry: host, Repository: "org/app", Tag: "1.0.0"}
+			tags, err := client.GetLatestTags(context.Background(), image)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetLatestTags() error = %v, want %q (requested %v)", err, tt.wantErr, *requested)
+				}
+				for _, uri := range *requested {
+					if strings.Contains(tt.wantErr, "untrusted") && strings.Contains(uri, "last=") {
+						t.Errorf("untrusted next page was requested: %v", *requested)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetLatestTags() error = %v (requested %v)", err, *requested)
+			}
+			if len(tags) != tt.wantTags {
+				t.Errorf("GetLatestTags() = %v, want %d tags", tags, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestGenericRegistryClient_WithDockerHubCredentials(t *testing.T) {
+	client := NewGenericRegistryClient(time.Second, "").WithDockerHubCredentials("ci-user", "ci-token")
+
+	for _, registry := range []string{"index.docker.io", "docker.io", "registry-1.docker.io"} {
+		auth, err := client.keychain.Resolve(testResource{registry: registry})
+		if err != nil {
+			t.Fatalf("Resolve(%s) error = %v", registry, err)
+		}
+		cfg, err := auth.Authorization()
+		if err != nil {
+			t.Fatalf("Authorization() error = %v", err)
+		}
+		if cfg.Username != "ci-user" || cfg.Password != "ci-token" {
+			t.Errorf("%s credentials = %q/%q, want ci-user/ci-token", registry, cfg.Username, cfg.Password)
+		}
+	}
+
+	// Other registries keep using the previous keychain
+	fallback := &testKeychain{}
+	client.keychain.(*dockerHubKeychain).fallback = fallback
+	if _, err := client.keychain.Resolve(testResource{registry: "ghcr.io"}); err != nil {
+		t.Fatalf("Resolve(ghcr.io) error = %v", err)
+	}
+	if !fallback.called {
+		t.Error("expected fallback keychain for ghcr.io")
+	}
+}
+
+func TestGenericRegistryClient_WithDockerHubCredentials_Empty(t *testing.T) {
+	client := NewGenericRegistryClient(time.Second, "")
+	before := client.keychain
+	if client.WithDockerHubCredentials("ci-user", "").keychain != before {
+		t.Error("expected keychain unchanged without a password")
+	}
+}
+
+func TestGenericRegistryClient_WithDockerHubMirror(t *testing.T) {
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/nginx/tags/list":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"nginx","tags":["1.25","1.26"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	image, err := types.ParseImageReference("nginx:1.25")
+	if err != nil {
+		t.Fatalf("ParseImageReference() error = %v", err)
+	}
+
+	// The mirror receives the official image without library/
+	mirror := strings.TrimPrefix(srv.URL, "http://")
+	client := NewGenericRegistryClient(5*time.Second, "").WithDockerHubMirror(mirror + "/")
+	tags, err := client.GetLatestTags(context.Background(), image)
+	if err != nil {
+		t.Fatalf("GetLatestTags() error = %v (requested %v)", err, requested)
+	}
+	if len(tags) != 2 {
+		t.Errorf("GetLatestTags() = %v, want 2 tags", tags)
+	}
+
+	// Canonical Docker Hub still resolves to library/nginx
+	canonical := NewGenericRegistryClient(5*time.Second, "")
+	repo, err := name.NewRepository(canonical.repoReference(image))
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	if got := repo.Name(); got != "index.docker.io/library/nginx" {
+		t.Errorf("canonical repository = %q, want index.docker.io/library/nginx", got)
+	}
+
+	// Other registries are not redirected to the mirror
+	ghcr := types.DockerImage{Registry: "ghcr.io", Repository: "library/app"}
+	if got := client.repoReference(ghcr); got != "ghcr.io/library/app" {
+		t.Errorf("repoReference(ghcr) = %q, want ghcr.io/library/app", got)
+	}
+}
+
+func TestGenericRegistryClient_GetTags_Unauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"errors":[{"code":"UNAUTHORIZED","message":"authentication required"}]}`))
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	image := types.DockerImage{Registry: host, Repository: "org/private", Tag: "1.0.0"}
+
+	_, err := NewGenericRegistryClient(5*time.Second, "").GetTags(context.Background(), image)
+	if !errors.IsType(err, errors.ErrAuthenticationError) {
+		t.Fatalf("GetTags() error = %v, want ErrAuthenticationError", err)
+	}
+}
+
+func TestGenericRegistryClient_WithHostTimeouts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"team/app","tags":["1.0.0"]}`))
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	image := types.DockerImage{Registry: host, Repository: "team/app", Tag: "1.0.0"}
+
+	// The global timeout is too short for this registry
+	if _, err := NewGenericRegistryClient(20*time.Millisecond, "").GetTags(context.Background(), image); err == nil {
+		t.Fatal("GetTags() with the global timeout succeeded, want a timeout")
+	}
+
+	client := NewGenericRegistryClient(20*time.Millisecond, "").
+		WithHostTimeouts(map[string]time.Duration{host: 2 * time.Second})
+	if _, err := client.GetTags(context.Background(), image); err != nil {
+		t.Fatalf("GetTags() with a host timeout error = %v", err)
+	}
+}
+
+func TestEnvCredentialPrefix(t *testing.T) {
+	tests := map[string]string{
+		"ghcr.io":                   "REGISTRY_GHCR_IO_",
+		"registry.example.com:5000": "REGISTRY_REGISTRY_EXAMPLE_COM_5000_",
+		"my-registry.local":         "REGISTRY_MY_REGISTRY_LOCAL_",
+		"index.docker.io":           "REGISTRY_DOCKER_IO_",
+		"registry-1.docker.io":      "REGISTRY_DOCKER_IO_",
+		"docker.io":                 "REGISTRY_DOCKER_IO_",
+	}
+	for host, want := range tests {
+		if got := EnvCredentialPrefix(host); got != want {
+			t.Errorf("EnvCredentialPrefix(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestGenericRegistryClient_WithEnvCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !(ok && user == "ci-user" && pass == "ci-secret") && r.Header.Get("Authorization") != "Bearer ci-token" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/org/app/tags/list":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"org/app","tags":["1.0.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	image := types.DockerImage{Registry: host, Repository: "org/app", Tag: "1.0.0"}
+	prefix := EnvCredentialPrefix(host)
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		enabled bool
+		wantErr bool
+	}{
+		{name: "username and password", env: map[string]string{prefix + "USERNAME": "ci-user", prefix + "PASSWORD": "ci-secret"}, enabled: true},
+		{name: "token", env: map[string]string{prefix + "TOKEN": "ci-token"}, enabled: true},
+		{name: "disabled", env: map[string]string{prefix + "TOKEN": "ci-token"}, wantErr: true},
+		{name: "username without password", env: map[string]string{prefix + "USERNAME": "ci-user"}, enabled: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			client := NewGenericRegistryClient(5*time.Second, "").WithEnvCredentials(tt.enabled)
+
+			_, err := client.GetTags(context.Background(), image)
+			if tt.wantErr {
+				if !errors.IsType(err, errors.ErrAuthenticationError) {
+					t.Errorf("GetTags() error = %v, want an authentication error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("GetTags() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// gitlabDefaultUsername is sent with the access token when no username is
+// configured; GitLab identifies the user from the token itself
+const gitlabDefaultUsername = "oauth2"
+
+// gitlabComHost serves the token endpoint of registry.gitlab.com
+const gitlabComHost = "gitlab.com"
+
+// manifestAccept lists the manifest media types accepted when resolving digests
+var manifestAccept = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+// GitLabClient implements RegistryClient for GitLab Container Registries
+// (registry.gitlab.com or self-hosted). It speaks the registry's /v2 API and
+// performs GitLab's token exchange itself: the registry answers 401 with a
+// Bearer challenge pointing at GitLab's JWT endpoint, which trades the
+// personal access token for a registry token scoped to the repository. The
+// token is only sent to an https realm on the registry's own host, gitlab.com
+// (for *.gitlab.com registries) or a host trusted with WithTrustedHosts.
+type GitLabClient struct {
+	username     string
+	token        string
+	httpClient   *http.Client
+	trustedHosts map[string]bool
+}
+
+// gitlabTagList is the /v2/{path}/tags/list response
+type gitlabTagList struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// gitlabToken is the JWT endpoint response
+type gitlabToken struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// NewGitLabClient creates a GitLab registry client. token is a personal (or
+// project/group) access token with read_registry scope; username defaults to
+// "oauth2" when empty. Without a token only public projects can be read.
+func NewGitLabClient(timeout time.Duration, username, token string) *GitLabClient {
+	if username == "" {
+		username = gitlabDefaultUsername
+	}
+	return &GitLabClient{
+		username:   username,
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// WithTrustedHosts allows token realms and pagination links on these hosts in
+// addition to the registry's own, e.g. the web host of a self-hosted GitLab
+// whose registry runs on a separate domain.
+func (g *GitLabClient) WithTrustedHosts(hosts []string) *GitLabClient {
+	g.trustedHosts = trustedHostSet(hosts)
+	return g
+}
+
+// Name returns "gitlab"; the scanner routes gitlab.com and the configured
+// self-hosted GitLab host to this client.
+func (g *GitLabClient) Name() string {
+	return "gitlab"
+}
+
+// GetLatestTags returns the names of all tags for the given image.
+func (g *GitLabClient) GetLatestTags(ctx context.Context, image types.DockerImage) ([]string, error) {
+	tags, err := g.GetTags(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	return types.TagNames(tags), nil
+}
+
+// GetTags walks every page of the repository's tag listing, following Link
+// headers on the registry host, and keeps the tags that pass the same filter
+// as the generic client.
+func (g *GitLabClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	session := &gitlabSession{client: g, registry: image.Registry, repository: image.Repository}
+	base := &url.URL{Scheme: "https", Host: image.Registry, Path: "/v2/" + image.Repository + "/tags/list"}
+
+	var tags []string
+	next := base.String()
+	for next != "" {
+		resp, err := session.do(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, errors.Wrapf("gitlab.GetTags", err, "listing tags for %s", image.Repository)
+		}
+
+		var page gitlabTagList
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrapf("gitlab.GetTags", err, "decoding tags for %s", image.Repository)
+		}
+
+		tags = append(tags, page.Tags...)
+		if len(tags) > defaultMaxTags {
+			return nil, errors.Newf("gitlab.GetTags", "tag listing exceeds %d tags", defaultMaxTags)
+		}
+
+		next = ""
+		if link := nextLink(resp.Header.Get("Link")); link != "" {
+			if next, err = resolveNextPage(base, link, g.trustedHosts); err != nil {
+				return nil, errors.Wrap("gitlab.GetTags", err)
+			}
+		}
+	}
+
+	filtered := make([]types.TagInfo, 0, len(tags))
+	for _, t := range tags {
+		if isValidGenericTag(t) {
+			filtered = append(filtered, types.TagInfo{Name: t})
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, errors.Newf("gitlab.GetTags", "no valid tags found for %s", image.Repository)
+	}
+	return filtered, nil
+}
+
+// GetDigest resolves the manifest digest the image tag currently points to
+// with a HEAD request.
+func (g *GitLabClient) GetDigest(ctx context.Context, image types.DockerImage) (string, error) {
+	session := &gitlabSession{client: g, registry: image.Registry, repository: image.Repository}
+	manifest := &url.URL{Scheme: "https", Host: image.Registry, Path: "/v2/" + image.Repository + "/manifests/" + image.Tag}
+
+	resp, err := session.do(ctx, http.MethodHead, manifest.String(), http.Header{"Accept": {manifestAccept}})
+	if err != nil {
+		return "", errors.Wrapf("gitlab.GetDigest", err, "resolving digest for %s:%s", image.Repository, image.Tag)
+	}
+	_ = resp.Body.Close()
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", errors.Newf("gitlab.GetDigest", "registry returned no digest for %s:%s", image.Repository, image.Tag)
+	}
+	return digest, nil
+}
+
+// GetImageInfo returns basic image metadata. Tag listing is the primary use case.
+func (g *GitLabClient) GetImageInfo(ctx context.Context, image types.DockerImage) (*types.ImageInfo, error) {
+	tags, err := g.GetLatestTags(ctx, image)
+	if err != nil {
+		tags = []string{image.Tag}
+	}
+	return &types.ImageInfo{
+		Tags:         tags,
+		LastModified: time.Now(),
+		Architecture: "amd64",
+	}, nil
+}
+
+// gitlabSession holds the registry token obtained for one repository, so the
+// pages of a listing share a single token exchange.
+type gitlabSession struct {
+	client     *GitLabClient
+	registry   string
+	repository string
+	bearer     string
+}
+
+// do sends a request to the registry. When it is challenged with 401 it
+// exchanges the access token at the challenge's realm and retries once.
+func (s *gitlabSession) do(ctx context.Context, method, target string, header http.Header) (*http.Response, error) {
+	resp, err := s.send(ctx, method, target, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && s.bearer == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+		if s.bearer, err = s.exchangeToken(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = s.send(ctx, method, target, header); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: registry returned %s", errors.ErrAuthenticationError, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("registry returned %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// send performs one request with the session's registry token, if any
+func (s *gitlabSession) send(ctx context.Context, method, target string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if s.bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+s.bearer)
+	}
+	return s.client.httpClient.Do(req)
+}
+
+// exchangeToken trades the access token for a registry token at the realm of
+// a Bearer challenge. Without an access token the exchange is anonymous,
+// which GitLab grants for public projects.
+func (s *gitlabSession) exchangeToken(ctx context.Context, challenge string) (string, error) {
+	params, ok := parseBearerChallenge(challenge)
+	if !ok || params["realm"] == "" {
+		return "", fmt.Errorf("%w: registry sent no bearer challenge", errors.ErrAuthenticationError)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme != "https" {
+		return "", fmt.Errorf("%w: refusing token realm %q: it must be an https URL", errors.ErrAuthenticationError, params["realm"])
+	}
+	if !s.trustedRealm(realm) {
+		return "", fmt.Errorf("%w: refusing token realm %q: host %s is neither the registry nor a trusted host (registry.trusted_hosts)",
+			errors.ErrAuthenticationError, params["realm"], realm.Host)
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + s.repository + ":pull"
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if s.client.token != "" {
+		req.SetBasicAuth(s.client.username, s.client.token)
+	}
+
+	resp, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("requesting registry token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: token endpoint returned %s", errors.ErrAuthenticationError, resp.Status)
+	}
+
+	var token gitlabToken
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("decoding registry token: %w", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return "", fmt.Errorf("%w: token endpoint returned no token", errors.ErrAuthenticationError)
+	}
+	return token.Token, nil
+}
+
+// trustedRealm reports whether the access token may be sent to realm: its host
+// must be the registry's, gitlab.com for registries under gitlab.com, or one
+// of the client's trusted hosts
+func (s *gitlabSession) trustedRealm(realm *url.URL) bool {
+	host := strings.ToLower(realm.Host)
+	registry := strings.ToLower(s.registry)
+	switch {
+	case host == registry:
+		return true
+	case host == gitlabComHost && strings.HasSuffix(registry, "."+gitlabComHost):
+		return true
+	default:
+		return s.client.trustedHosts[host]
+	}
+}
+
+// parseBearerChallenge parses a `Bearer realm="...",service="...",scope="..."`
+// WWW-Authenticate header into its parameters.
+func parseBearerChallenge(header string) (map[string]string, bool) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				return nil, false
+			}
+			params[key] = value[1 : end+1]
+			rest = value[end+2:]
+		} else {
+			params[key], rest, _ = strings.Cut(value, ",")
+		}
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return params, true
+}
+
+// nextLink returns the target of the rel="next" entry of a Link header, or ""
+// on the last page
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, found := strings.Cut(link, ";")
+		if found && strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>")
+		}
+	}
+	return ""
+}
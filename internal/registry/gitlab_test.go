@@ -0,0 +1,235 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// newGitLabRegistry serves a GitLab-like registry over TLS: /v2 requests
+// without the registry token are challenged towards realm (srv's own
+// /jwt/auth when empty), which only hands out the token for the given
+// personal access token.
+func newGitLabRegistry(t *testing.T, accessToken, realm string) *httptest.Server {
+	t.Helper()
+
+	const registryToken = "registry-jwt"
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		challengeRealm := realm
+		if challengeRealm == "" {
+			challengeRealm = srv.URL + "/jwt/auth"
+		}
+		switch {
+		case r.URL.Path == "/jwt/auth":
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "oauth2" || pass != accessToken ||
+				r.URL.Query().Get("service") != "container_registry" ||
+				r.URL.Query().Get("scope") != "repository:group/project/app:pull" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": registryToken})
+		case r.Header.Get("Authorization") != "Bearer "+registryToken:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+challengeRealm+`",service="container_registry",scope="repository:group/project/app:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/group/project/app/tags/list" && r.URL.Query().Get("last") == "":
+			w.Header().Set("Link", `</v2/group/project/app/tags/list?last=1.1.0&n=2>; rel="next"`)
+			_, _ = w.Write([]byte(`{"name":"group/project/app","tags":["1.0.0","1.1.0"]}`))
+		case r.URL.Path == "/v2/group/project/app/tags/list":
+			_, _ = w.Write([]byte(`{"name":"group/project/app","tags":["2.0.0","tmp-ci"]}`))
+		case r.Method == http.MethodHead && r.URL.Path == "/v2/group/project/app/manifests/1.0.0":
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestGitLabClient returns a GitLabClient trusting the test servers'
+// certificate, and the image srv serves
+func newTestGitLabClient(srv *httptest.Server, token string) (*GitLabClient, types.DockerImage) {
+	client := NewGitLabClient(5*time.Second, "", token)
+	client.httpClient = srv.Client()
+	image := types.DockerImage{Registry: strings.TrimPrefix(srv.URL, "https://"), Repository: "group/project/app", Tag: "1.0.0"}
+	return client, image
+}
+
+// newTokenCollector serves a token endpoint that records every access token
+// it receives
+func newTokenCollector(t *testing.T, tls bool) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var received []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pass, ok := r.BasicAuth(); ok {
+			received = append(received, pass)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "registry-jwt"})
+	})
+	var srv *httptest.Server
+	if tls {
+		srv = httptest.NewTLSServer(handler)
+	} else {
+		srv = httptest.NewServer(handler)
+	}
+	t.Cleanup(srv.Close)
+	return srv, &received
+}
+
+func TestGitLabClient_Name(t *testing.T) {
+	if got := NewGitLabClient(time.Second, "", "").Name(); got != "gitlab" {
+		t.Fatalf("Name() = %q, want %q", got, "gitlab")
+	}
+}
+
+func TestGitLabClient_GetTags_TokenExchange(t *testing.T) {
+	srv := newGitLabRegistry(t, "glpat-secret", "")
+	client, image := newTestGitLabClient(srv, "glpat-secret")
+
+	tags, err := client.GetTags(context.Background(), image)
+	if err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+	// Both pages are read with the exchanged token and temporary tags dropped
+	if got := strings.Join(types.TagNames(tags), ","); got != "1.0.0,1.1.0,2.0.0" {
+		t.Errorf("tags = %s, want 1.0.0,1.1.0,2.0.0", got)
+	}
+
+	digest, err := client.GetDigest(context.Background(), image)
+	if err != nil || digest != "sha256:abc" {
+		t.Errorf("GetDigest() = %q, %v; want sha256:abc", digest, err)
+	}
+}
+
+func TestGitLabClient_GetTags_Unauthorized(t *testing.T) {
+	srv := newGitLabRegistry(t, "glpat-secret", "")
+	client, image := newTestGitLabClient(srv, "wrong-token")
+
+	_, err := client.GetTags(context.Background(), image)
+	if !errors.IsType(err, errors.ErrAuthenticationError) {
+		t.Errorf("GetTags() error = %v, want ErrAuthenticationError", err)
+	}
+}
+
+func TestGitLabClient_RefusesUntrustedRealm(t *testing.T) {
+	foreign, foreignTokens := newTokenCollector(t, true)
+	plain, plainTokens := newTokenCollector(t, false)
+
+	tests := []struct {
+		name  string
+		realm string
+		want  string
+	}{
+		{name: "other host", realm: foreign.URL + "/jwt/auth", want: "is neither the registry nor a trusted host"},
+		{name: "plain http", realm: plain.URL + "/jwt/auth", want: "must be an https URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newGitLabRegistry(t, "glpat-secret", tt.realm)
+			client, image := newTestGitLabClient(srv, "glpat-secret")
+
+			_, err := client.GetTags(context.Background(), image)
+			if !errors.IsType(err, errors.ErrAuthenticationError) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("GetTags() error = %v, want ErrAuthenticationError containing %q", err, tt.want)
+			}
+		})
+	}
+
+	// The access token never reaches a refused realm
+	if len(*foreignTokens) != 0 || len(*plainTokens) != 0 {
+		t.Errorf("access token sent to untrusted realms: %v %v", *foreignTokens, *plainTokens)
+	}
+}
+
+func TestGitLabClient_TrustedRealmHost(t *testing.T) {
+	realm, tokens := newTokenCollector(t, true)
+	srv := newGitLabRegistry(t, "glpat-secret", realm.URL+"/jwt/auth")
+	client, image := newTestGitLabClient(srv, "glpat-secret")
+	client.WithTrustedHosts([]string{strings.TrimPrefix(realm.URL, "https://")})
+
+	// The trusted realm receives the access token and its registry token works
+	if _, err := client.GetTags(context.Background(), image); err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+	if len(*tokens) != 1 || (*tokens)[0] != "glpat-secret" {
+		t.Errorf("tokens sent to trusted realm = %v, want [glpat-secret]", *tokens)
+	}
+}
+
+func TestGitLabSession_TrustedRealm(t *testing.T) {
+	client := NewGitLabClient(time.Second, "", "token").WithTrustedHosts([]string{"GitLab.Example.com"})
+
+	tests := []struct {
+		registry string
+		realm    string
+		want     bool
+	}{
+		{registry: "registry.gitlab.com", realm: "https://gitlab.com/jwt/auth", want: true},
+		{registry: "registry.example.com", realm: "https://registry.example.com/jwt/auth", want: true},
+		{registry: "registry.example.com", realm: "https://gitlab.example.com/jwt/auth", want: true},
+		{registry: "registry.example.com", realm: "https://gitlab.com/jwt/auth", want: false},
+		{registry: "registry.gitlab.com", realm: "https://evil.example.net/jwt/auth", want: false},
+		{registry: "registry.gitlab.com", realm: "https://gitlab.com.evil.net/jwt/auth", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.registry+" "+tt.realm, func(t *testing.T) {
+			realm, err := url.Parse(tt.realm)
+			if err != nil {
+				t.Fatalf("parsing realm: %v", err)
+			}
+			session := &gitlabSession{client: client, registry: tt.registry}
+			if got := session.trustedRealm(realm); got != tt.want {
+				t.Errorf("trustedRealm() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBearerChallenge(t *testing.T) {
+	tests := []struct {
+		header string
+		want   map[string]string
+		ok     bool
+	}{
+		{
+			header: `Bearer realm="https://gitlab.com/jwt/auth",service="container_registry",scope="repository:g/p:pull"`,
+			want:   map[string]string{"realm": "https://gitlab.com/jwt/auth", "service": "container_registry", "scope": "repository:g/p:pull"},
+			ok:     true,
+		},
+		{
+			header: `Bearer realm="https://gitlab.example.com/jwt/auth", service=container_registry`,
+			want:   map[string]string{"realm": "https://gitlab.example.com/jwt/auth", "service": "container_registry"},
+			ok:     true,
+		},
+		{header: `Basic realm="registry"`, ok: false},
+		{header: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, ok := parseBearerChallenge(tt.header)
+			if ok != tt.ok {
+				t.Fatalf("parseBearerChallenge() ok = %v, want %v", ok, tt.ok)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("%s = %q, want %q", key, got[key], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+const (
+	// quayRegistry is the registry host served by QuayClient
+	quayRegistry = "quay.io"
+	// quayPageSize is the number of tags requested per page (Quay's maximum)
+	quayPageSize = 100
+	// quayMaxPages bounds pagination so a misbehaving API cannot loop forever
+	quayMaxPages = defaultMaxTags / quayPageSize
+)
+
+// QuayClient implements RegistryClient for quay.io through the Quay REST API
+// (/api/v1/repository/{namespace}/{repo}/tag/). Unlike the OCI tag listing,
+// the API reports each tag's push date, manifest digest and size.
+type QuayClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// quayTagPage is one page of the Quay tag listing
+type quayTagPage struct {
+	Tags          []quayTag `json:"tags"`
+	Page          int       `json:"page"`
+	HasAdditional bool      `json:"has_additional"`
+}
+
+// quayTag is a tag as reported by the Quay API
+type quayTag struct {
+	Name           string `json:"name"`
+	ManifestDigest string `json:"manifest_digest"`
+	Size           int64  `json:"size"`
+	LastModified   string `json:"last_modified"`
+}
+
+// NewQuayClient creates a Quay client. token is optional: when non-empty it
+// is sent as a Bearer token (an OAuth application token or robot token),
+// which allows access to private repositories.
+func NewQuayClient(timeout time.Duration, token string) *QuayClient {
+	return &QuayClient{
+		baseURL:    "https://" + quayRegistry,
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns "quay.io", the only registry this client handles.
+func (q *QuayClient) Name() string {
+	return quayRegistry
+}
+
+// GetLatestTags returns the names of all active tags for the given image.
+func (q *QuayClient) GetLatestTags(ctx context.Context, image types.DockerImage) ([]string, error) {
+	tags, err := q.GetTags(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	return types.TagNames(tags), nil
+}
+
+// GetTags walks every page of the repository's active tags, keeping those
+// that pass the same filter as the generic client.
+func (q *QuayClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	var filtered []types.TagInfo
+	for page := 1; page <= quayMaxPages; page++ {
+		result, err := q.fetchTags(ctx, image, url.Values{"page": {strconv.Itoa(page)}})
+		if err != nil {
+			return nil, errors.Wrapf("quay.GetTags", err, "listing tags for %s", image.Repository)
+		}
+
+		for _, t := range result.Tags {
+			if isValidGenericTag(t.Name) {
+				filtered = append(filtered, t.tagInfo())
+			}
+		}
+		if !result.HasAdditional {
+			break
+		}
+	}
+
+	if len(filtered) == 0 {
+		return nil, errors.Newf("quay.GetTags", "no valid tags found for %s", image.Repository)
+	}
+	return filtered, nil
+}
+
+// GetDigest returns the manifest digest the image tag currently points to.
+func (q *QuayClient) GetDigest(ctx context.Context, image types.DockerImage) (string, error) {
+	result, err := q.fetchTags(ctx, image, url.Values{"specificTag": {image.Tag}})
+	if err != nil {
+		return "", errors.Wrapf("quay.GetDigest", err, "resolving digest for %s:%s", image.Repository, image.Tag)
+	}
+	for _, t := range result.Tags {
+		if t.Name == image.Tag && t.ManifestDigest != "" {
+			return t.ManifestDigest, nil
+		}
+	}
+	return "", errors.Newf("quay.GetDigest", "tag %s not found for %s", image.Tag, image.Repository)
+}
+
+// GetImageInfo returns basic image metadata. Tag listing is the primary use case.
+func (q *QuayClient) GetImageInfo(ctx context.Context, image types.DockerImage) (*types.ImageInfo, error) {
+	tags, err := q.GetLatestTags(ctx, image)
+	if err != nil {
+		tags = []string{image.Tag}
+	}
+	return &types.ImageInfo{
+		Tags:         tags,
+		LastModified: time.Now(),
+		Architecture: "amd64",
+	}, nil
+}
+
+// fetchTags requests one page of the repository's active tags
+func (q *QuayClient) fetchTags(ctx context.Context, image types.DockerImage, query url.Values) (*quayTagPage, error) {
+	query.Set("limit", strconv.Itoa(quayPageSize))
+	query.Set("onlyActiveTags", "true")
+	endpoint := fmt.Sprintf("%s/api/v1/repository/%s/tag/?%s", q.baseURL, image.Repository, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if q.token != "" {
+		req.Header.Set("Authorization", "Bearer "+q.token)
+	}
+
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("%w: quay returned %s", errors.ErrAuthenticationError, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("quay returned %s", resp.Status)
+	}
+
+	var page quayTagPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("decoding tag listing: %w", err)
+	}
+	return &page, nil
+}
+
+// tagInfo converts a Quay tag into a TagInfo. Quay reports last_modified in
+// RFC 1123 form with a numeric zone; an unparsable date is left empty.
+func (t quayTag) tagInfo() types.TagInfo {
+	info := types.TagInfo{Name: t.Name, Digest: t.ManifestDigest, Size: t.Size}
+	if modified, err := time.Parse(time.RFC1123Z, t.LastModified); err == nil {
+		info.LastUpdated = modified
+	}
+	return info
+}
@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// newTestQuayClient returns a QuayClient pointed at srv instead of quay.io
+func newTestQuayClient(srv *httptest.Server, token string) *QuayClient {
+	client := NewQuayClient(5*time.Second, token)
+	client.baseURL = srv.URL
+	return client
+}
+
+func TestQuayClient_Name(t *testing.T) {
+	if got := NewQuayClient(time.Second, "").Name(); got != "quay.io" {
+		t.Fatalf("Name() = %q, want %q", got, "quay.io")
+	}
+}
+
+func TestQuayClient_GetTags(t *testing.T) {
+	var authHeaders []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if r.URL.Path != "/api/v1/repository/org/app/tag/" || r.URL.Query().Get("onlyActiveTags") != "true" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = w.Write([]byte(`{"page":1,"has_additional":true,"tags":[
+				{"name":"1.0.0","manifest_digest":"sha256:aaa","size":1000,"last_modified":"Mon, 02 Jan 2023 15:04:05 -0000"},
+				{"name":"tmp-build","manifest_digest":"sha256:bbb"}]}`))
+		case "2":
+			_, _ = w.Write([]byte(`{"page":2,"has_additional":false,"tags":[
+				{"name":"1.1.0","manifest_digest":"sha256:ccc","size":2000,"last_modified":"not a date"},
+				{"name":"0123456789abcdef"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client := newTestQuayClient(srv, "robot-token")
+	tags, err := client.GetTags(context.Background(), types.DockerImage{Registry: "quay.io", Repository: "org/app", Tag: "1.0.0"})
+	if err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+
+	// Both pages are read and temporary or digest-like tags are dropped
+	if got := strings.Join(types.TagNames(tags), ","); got != "1.0.0,1.1.0" {
+		t.Fatalf("tags = %s, want 1.0.0,1.1.0", got)
+	}
+	first := tags[0]
+	if first.Digest != "sha256:aaa" || first.Size != 1000 || !first.LastUpdated.Equal(time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("tags[0] = %+v, want digest, size and push date from the API", first)
+	}
+	if !tags[1].LastUpdated.IsZero() {
+		t.Errorf("tags[1].LastUpdated = %v, want zero for an unparsable date", tags[1].LastUpdated)
+	}
+	for _, header := range authHeaders {
+		if header != "Bearer robot-token" {
+			t.Errorf("Authorization = %q, want the bearer token", header)
+		}
+	}
+}
+
+func TestQuayClient_GetDigest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("specificTag") != "1.0.0" {
+			_, _ = w.Write([]byte(`{"page":1,"has_additional":false,"tags":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"page":1,"has_additional":false,"tags":[{"name":"1.0.0","manifest_digest":"sha256:aaa"}]}`))
+	}))
+	defer srv.Close()
+
+	client := newTestQuayClient(srv, "")
+	digest, err := client.GetDigest(context.Background(), types.DockerImage{Registry: "quay.io", Repository: "org/app", Tag: "1.0.0"})
+	if err != nil || digest != "sha256:aaa" {
+		t.Errorf("GetDigest() = %q, %v; want sha256:aaa", digest, err)
+	}
+
+	if _, err := client.GetDigest(context.Background(), types.DockerImage{Registry: "quay.io", Repository: "org/app", Tag: "9.9.9"}); err == nil {
+		t.Error("expected an error for a missing tag")
+	}
+}
+
+func TestQuayClient_GetTags_Unauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	client := newTestQuayClient(srv, "")
+	_, err := client.GetTags(context.Background(), types.DockerImage{Registry: "quay.io", Repository: "org/private", Tag: "1.0.0"})
+	if !errors.IsType(err, errors.ErrAuthenticationError) {
+		t.Errorf("GetTags() error = %v, want ErrAuthenticationError", err)
+	}
+}
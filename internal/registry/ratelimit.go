@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// rateLimitReserve is the number of remaining requests at or below which
+// digest checks against a registry are skipped, leaving the rest of the
+// budget for real pulls.
+const rateLimitReserve = 5
+
+// rateLimitTransport records the RateLimit-Limit and RateLimit-Remaining
+// headers that Docker Hub returns on manifest requests, keyed by host.
+type rateLimitTransport struct {
+	base   http.RoundTripper
+	mu     sync.Mutex
+	limits map[string]types.RateLimit
+}
+
+func newRateLimitTransport(base http.RoundTripper) *rateLimitTransport {
+	return &rateLimitTransport{base: base, limits: make(map[string]types.RateLimit)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.record(req.URL.Host, resp.Header)
+	return resp, nil
+}
+
+// record stores the limit advertised in header, if any.
+func (t *rateLimitTransport) record(host string, header http.Header) {
+	limit, window, ok := parseRateLimitHeader(header.Get("RateLimit-Limit"))
+	if !ok {
+		return
+	}
+	remaining, _, ok := parseRateLimitHeader(header.Get("RateLimit-Remaining"))
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limits[host] = types.RateLimit{Registry: host, Limit: limit, Remaining: remaining, Window: window}
+}
+
+// get returns the latest limit seen for host.
+func (t *rateLimitTransport) get(host string) (types.RateLimit, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	limit, ok := t.limits[host]
+	return limit, ok
+}
+
+// snapshot returns the latest limit of every host, sorted by host.
+func (t *rateLimitTransport) snapshot() []types.RateLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limits := make([]types.RateLimit, 0, len(t.limits))
+	for _, limit := range t.limits {
+		limits = append(limits, limit)
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Registry < limits[j].Registry })
+	return limits
+}
+
+// parseRateLimitHeader parses values such as "100;w=21600" into the count and
+// the optional window in seconds.
+func parseRateLimitHeader(value string) (int, time.Duration, bool) {
+	if value == "" {
+		return 0, 0, false
+	}
+
+	fields := strings.Split(value, ";")
+	count, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+	if err != nil || count < 0 {
+		return 0, 0, false
+	}
+
+	var window time.Duration
+	for _, field := range fields[1:] {
+		if seconds, ok := strings.CutPrefix(strings.TrimSpace(field), "w="); ok {
+			if n, err := strconv.Atoi(seconds); err == nil {
+				window = time.Duration(n) * time.Second
+			}
+		}
+	}
+	return count, window, true
+}
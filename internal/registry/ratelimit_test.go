@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/user/docker-image-reporter/internal/metrics"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+func TestParseRateLimitHeader(t *testing.T) {
+	tests := []struct {
+		value      string
+		wantCount  int
+		wantWindow time.Duration
+		wantOK     bool
+	}{
+		{value: "100;w=21600", wantCount: 100, wantWindow: 6 * time.Hour, wantOK: true},
+		{value: "42", wantCount: 42, wantOK: true},
+		{value: " 7 ; w=60", wantCount: 7, wantWindow: time.Minute, wantOK: true},
+		{value: ""},
+		{value: "many;w=60"},
+		{value: "-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			count, window, ok := parseRateLimitHeader(tt.value)
+			if ok != tt.wantOK || count != tt.wantCount || window != tt.wantWindow {
+				t.Errorf("parseRateLimitHeader(%q) = %d, %s, %t; want %d, %s, %t",
+					tt.value, count, window, ok, tt.wantCount, tt.wantWindow, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenericRegistryClient_RateLimits(t *testing.T) {
+	const remoteDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+
+	var remaining, manifestRequests atomic.Int64
+	remaining.Store(42)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodHead && r.URL.Path == "/v2/library/nginx/manifests/1.25":
+			manifestRequests.Add(1)
+			w.Header().Set("RateLimit-Limit", "100;w=21600")
+			w.Header().Set("RateLimit-Remaining", strconv.FormatInt(remaining.Load(), 10)+";w=21600")
+			w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+			w.Header().Set("Content-Length", "512")
+			w.Header().Set("Docker-Content-Digest", remoteDigest)
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	client := NewGenericRegistryClient(5*time.Second, "")
+	image := types.DockerImage{Registry: host, Repository: "library/nginx", Tag: "1.25"}
+
+	if limits := client.RateLimits(); len(limits) != 0 {
+		t.Fatalf("RateLimits() before any request = %v, want none", limits)
+	}
+
+	if _, err := client.GetDigest(context.Background(), image); err != nil {
+		t.Fatalf("GetDigest() error = %v", err)
+	}
+
+	limits := client.RateLimits()
+	want := types.RateLimit{Registry: host, Limit: 100, Remaining: 42, Window: 6 * time.Hour}
+	if len(limits) != 1 || limits[0] != want {
+		t.Fatalf("RateLimits() = %+v, want [%+v]", limits, want)
+	}
+
+	// The captured values are exported as Prometheus gauges
+	m := metrics.New()
+	m.RecordScan(types.ScanResult{RateLimits: limits}, time.Second)
+	var out strings.Builder
+	m.Write(&out)
+	for _, line := range []string{
+		`icr_registry_rate_limit{registry="` + host + `"} 100`,
+		`icr_registry_rate_limit_remaining{registry="` + host + `"} 42`,
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("metrics missing %q:\n%s", line, out.String())
+		}
+	}
+
+	// Once the budget is nearly exhausted, digest checks stop hitting the registry
+	remaining.Store(rateLimitReserve)
+	if _, err := client.GetDigest(context.Background(), image); err != nil {
+		t.Fatalf("GetDigest() error = %v", err)
+	}
+	before := manifestRequests.Load()
+	_, err := client.GetDigest(context.Background(), image)
+	if err == nil || !strings.Contains(err.Error(), "rate limit nearly exhausted") {
+		t.Fatalf("GetDigest() with exhausted budget error = %v, want rate limit error", err)
+	}
+	if manifestRequests.Load() != before {
+		t.Error("GetDigest() contacted the registry despite the exhausted budget")
+	}
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// csvHeader son las columnas de la salida CSV, en orden
+var csvHeader = []string{"service", "registry", "repository", "current_tag", "latest_tag", "update_type", "compose_file"}
+
+// CSVFormatter implementa ReportFormatter generando CSV: una cabecera y una
+// fila por actualización, para abrirlo en una hoja de cálculo o importarlo.
+// Sin actualizaciones solo se escribe la cabecera.
+type CSVFormatter struct{}
+
+// Format convierte un ScanResult en CSV, sin salto de línea final
+func (f CSVFormatter) Format(result types.ScanResult) (string, error) {
+	var b strings.Builder
+	if err := f.FormatTo(&b, result); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// FormatTo escribe el CSV en w, terminando cada fila con un salto de línea
+func (f CSVFormatter) FormatTo(w io.Writer, result types.ScanResult) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, update := range result.UpdatesAvailable {
+		record := []string{
+			update.ServiceName,
+			update.CurrentImage.Registry,
+			update.CurrentImage.Repository,
+			update.CurrentImage.Tag,
+			update.LatestImage.Tag,
+			update.UpdateType.String(),
+			update.CurrentImage.ComposeFile,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// FormatName devuelve el nombre del formato
+func (f CSVFormatter) FormatName() string {
+	return "csv"
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// githubDataEscaper y githubPropertyEscaper escapan el mensaje y las propiedades
+// de un workflow command (::warning file=...::mensaje)
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// GitHubFormatter implementa ReportFormatter generando el resumen Markdown de
+// un job de GitHub Actions (GITHUB_STEP_SUMMARY) y las anotaciones de aviso
+type GitHubFormatter struct {
+	// Workspace es el checkout del repositorio (GITHUB_WORKSPACE); las rutas de
+	// los compose files bajo él se anotan en relativo para que GitHub las enlace
+	Workspace string
+}
+
+// Format convierte un ScanResult en una tabla Markdown con una fila por actualización
+func (f GitHubFormatter) Format(result types.ScanResult) (string, error) {
+	var b strings.Builder
+	b.WriteString("## Image updates\n\n")
+	fmt.Fprintf(&b, "%s\n", result.Summary())
+
+	if result.HasUpdates() {
+		b.WriteString("\n| Service | Current | Latest | Type | File |\n")
+		b.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, update := range result.UpdatesAvailable {
+			fmt.Fprintf(&b, "| %s | `%s` | `%s` | %s | %s |\n",
+				markdownCellEscaper.Replace(update.ServiceName),
+				markdownCellEscaper.Replace(update.CurrentImage.String()),
+				markdownCellEscaper.Replace(update.LatestImage.String()),
+				update.UpdateType,
+				markdownCellEscaper.Replace(f.relativePath(update.CurrentImage.ComposeFile)))
+		}
+	}
+
+	return b.String(), nil
+}
+
+// FormatName devuelve el nombre del formato
+func (f GitHubFormatter) FormatName() string {
+	return "github"
+}
+
+// Annotations devuelve una línea ::warning por actualización, asociada a su
+// compose file cuando se conoce
+func (f GitHubFormatter) Annotations(result types.ScanResult) []string {
+	lines := make([]string, 0, len(result.UpdatesAvailable))
+	for _, update := range result.UpdatesAvailable {
+		properties := ""
+		if file := f.relativePath(update.CurrentImage.ComposeFile); file != "" {
+			properties = " file=" + githubPropertyEscaper.Replace(file)
+		}
+		message := fmt.Sprintf("%s update available: %s -> %s (%s)",
+			update.ServiceName, update.CurrentImage.String(), update.LatestImage.String(), update.UpdateType)
+		lines = append(lines, fmt.Sprintf("::warning%s::%s", properties, githubDataEscaper.Replace(message)))
+	}
+	return lines
+}
+
+// relativePath expresa path respecto a Workspace si está dentro de él
+func (f GitHubFormatter) relativePath(path string) string {
+	if path == "" || f.Workspace == "" {
+		return filepath.ToSlash(path)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(f.Workspace, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
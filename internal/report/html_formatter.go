@@ -1,10 +1,10 @@
 package report
 
 import (
-	"bytes"
 	"embed"
 	"fmt"
 	"html/template"
+	"io"
 	"strings"
 
 	"github.com/user/docker-image-reporter/pkg/types"
@@ -22,7 +22,13 @@ func capitalizeFirst(s string) string {
 }
 
 // HTMLFormatter implementa ReportFormatter para generar reportes en formato HTML
-type HTMLFormatter struct{}
+type HTMLFormatter struct {
+	// MaxUpToDate colapsa la lista de servicios al día a un contador cuando
+	// la supera. 0 = sin límite
+	MaxUpToDate int
+	// GroupBy agrupa las actualizaciones en secciones. Vacío = listado plano
+	GroupBy types.GroupBy
+}
 
 // UpdateDistributionItem representa un ítem de distribución de actualizaciones
 type UpdateDistributionItem struct {
@@ -39,33 +45,57 @@ type UpdateItem struct {
 	LatestImage  string
 	UpdateType   string
 	BadgeClass   string
+	Reason       string
+	Priority     bool
+	Behind       string // "3 releases behind", vacío si no se conoce
+	SizeDelta    string // "+42 MB", vacío si no se conoce algún tamaño
+	LatestAlias  string // "maintainer's latest = 1.22; newest published = 1.25"
+}
+
+// UpdateGroupItem representa una sección de actualizaciones para el template
+type UpdateGroupItem struct {
+	Name    string
+	Updates []UpdateItem
 }
 
 // templateData estructura los datos para el template
 type templateData struct {
 	ProjectName        string
 	ScanTimestamp      string
+	Summary            string
 	TotalServices      int
 	UpdatesCount       int
 	UpToDateCount      int
+	UpToDateServices   []string
+	UpToDateCollapsed  bool
 	HasUpdates         bool
 	UpdateDistribution []UpdateDistributionItem
 	Updates            []UpdateItem
+	Groups             []UpdateGroupItem
 	Errors             []string
 }
 
 // Format convierte un ScanResult en un string HTML formateado
 func (f HTMLFormatter) Format(result types.ScanResult) (string, error) {
+	var b strings.Builder
+	if err := f.FormatTo(&b, result); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// FormatTo ejecuta el template directamente sobre w
+func (f HTMLFormatter) FormatTo(w io.Writer, result types.ScanResult) error {
 	// Cargar y parsear el template. El CSS esta inlined directamente en
 	// report_template.html — no se necesita inyeccion ni placeholders.
 	tmplBytes, err := reportAssets.ReadFile("assets/report_template.html")
 	if err != nil {
-		return "", fmt.Errorf("error loading template: %w", err)
+		return fmt.Errorf("error loading template: %w", err)
 	}
 
 	tmpl, err := template.New("report").Parse(string(tmplBytes))
 	if err != nil {
-		return "", fmt.Errorf("error parsing template: %w", err)
+		return fmt.Errorf("error parsing template: %w", err)
 	}
 
 	// Calcular distribución de actualizaciones
@@ -75,6 +105,7 @@ func (f HTMLFormatter) Format(result types.ScanResult) (string, error) {
 		"minor":   "#d29922",
 		"major":   "#f85149",
 		"unknown": "#8b949e",
+		"digest":  "#a371f7",
 	}
 
 	for _, update := range result.UpdatesAvailable {
@@ -84,7 +115,7 @@ func (f HTMLFormatter) Format(result types.ScanResult) (string, error) {
 
 	// Preparar items de distribución para el template
 	var distributionItems []UpdateDistributionItem
-	for _, updateType := range []string{"patch", "minor", "major", "unknown"} {
+	for _, updateType := range []string{"patch", "minor", "major", "unknown", "digest"} {
 		if count, ok := distribution[updateType]; ok && count > 0 {
 			distributionItems = append(distributionItems, UpdateDistributionItem{
 				Type:  capitalizeFirst(updateType),
@@ -94,52 +125,93 @@ func (f HTMLFormatter) Format(result types.ScanResult) (string, error) {
 		}
 	}
 
-	// Preparar items de actualización
+	// Preparar items de actualización, agrupados según GroupBy
 	var updateItems []UpdateItem
-	for _, update := range result.UpdatesAvailable {
-		badgeClass := "badge-unknown"
-		switch strings.ToLower(update.UpdateType.String()) {
-		case "patch":
-			badgeClass = "badge-patch"
-		case "minor":
-			badgeClass = "badge-minor"
-		case "major":
-			badgeClass = "badge-major"
+	var groups []UpdateGroupItem
+	for _, group := range result.GroupUpdates(f.GroupBy) {
+		groupItem := UpdateGroupItem{Name: group.Name}
+		for _, update := range group.Updates {
+			groupItem.Updates = append(groupItem.Updates, newUpdateItem(update))
 		}
-
-		updateItems = append(updateItems, UpdateItem{
-			ServiceName:  update.ServiceName,
-			SourceFile:   update.CurrentImage.ComposeFile,
-			CurrentImage: update.CurrentImage.String(),
-			LatestImage:  update.LatestImage.String(),
-			UpdateType:   update.UpdateType.String(),
-			BadgeClass:   badgeClass,
-		})
+		updateItems = append(updateItems, groupItem.Updates...)
+		groups = append(groups, groupItem)
 	}
 
 	// Preparar datos del template
 	data := templateData{
-		ProjectName:   result.ProjectName,
+		ProjectName:        result.ProjectName,
 		ScanTimestamp:      result.ScanTimestamp.Format("Jan 2, 2006 15:04 MST"),
+		Summary:            result.Summary(),
 		TotalServices:      result.TotalServicesFound,
 		UpdatesCount:       len(result.UpdatesAvailable),
 		UpToDateCount:      len(result.UpToDateServices),
+		UpToDateCollapsed:  result.UpToDateCollapsed(f.MaxUpToDate),
 		HasUpdates:         result.HasUpdates(),
 		UpdateDistribution: distributionItems,
 		Updates:            updateItems,
+		Groups:             groups,
 		Errors:             result.Errors,
 	}
 
+	if !data.UpToDateCollapsed {
+		data.UpToDateServices = result.UpToDateServices
+	}
+
 	// Renderizar template
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("error executing template: %w", err)
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
 	}
 
-	return buf.String(), nil
+	return nil
 }
 
 // FormatName devuelve el nombre del formato
 func (f HTMLFormatter) FormatName() string {
 	return "html"
 }
+
+// newUpdateItem convierte una actualización en un ítem del template
+func newUpdateItem(update types.ImageUpdate) UpdateItem {
+	badgeClass := "badge-unknown"
+	switch strings.ToLower(update.UpdateType.String()) {
+	case "patch":
+		badgeClass = "badge-patch"
+	case "minor":
+		badgeClass = "badge-minor"
+	case "major":
+		badgeClass = "badge-major"
+	case "digest":
+		badgeClass = "badge-digest"
+	}
+
+	currentImage := update.CurrentImage.String()
+	latestImage := update.LatestImage.String()
+	if update.UpdateType == types.UpdateTypeDigest {
+		// El tag no cambia: mostrar el digest para que se vea la diferencia
+		currentImage += "@" + shortDigest(update.CurrentImage.Digest)
+		latestImage += "@" + shortDigest(update.LatestImage.Digest)
+	}
+
+	return UpdateItem{
+		ServiceName:  update.ServiceName,
+		SourceFile:   update.CurrentImage.ComposeFile,
+		CurrentImage: currentImage,
+		LatestImage:  latestImage,
+		UpdateType:   update.UpdateType.String(),
+		BadgeClass:   badgeClass,
+		Reason:       update.Reason.String(),
+		Priority:     update.Priority,
+		Behind:       update.BehindText(),
+		SizeDelta:    update.SizeDeltaText(),
+		LatestAlias:  update.LatestAliasText(),
+	}
+}
+
+// shortDigest acorta un digest "sha256:..." a 12 caracteres hexadecimales
+func shortDigest(digest string) string {
+	algorithm, hex, found := strings.Cut(digest, ":")
+	if !found || len(hex) <= 12 {
+		return digest
+	}
+	return algorithm + ":" + hex[:12]
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// influxMeasurement es la measurement de cada línea emitida
+const influxMeasurement = "image_updates"
+
+// influxTagEscaper escapa comas, signos igual y espacios en claves y valores de tags
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+
+// InfluxFormatter implementa ReportFormatter generando line protocol de
+// InfluxDB, una línea por actualización, para capturarlo con un input exec de telegraf
+type InfluxFormatter struct{}
+
+// Format convierte un ScanResult en líneas
+// image_updates,service=web,registry=docker.io,type=minor value=1 <ts>
+// usando como timestamp la fecha del escaneo en nanosegundos
+func (f InfluxFormatter) Format(result types.ScanResult) (string, error) {
+	timestamp := result.ScanTimestamp.UnixNano()
+
+	lines := make([]string, 0, len(result.UpdatesAvailable))
+	for _, update := range result.UpdatesAvailable {
+		var b strings.Builder
+		b.WriteString(influxMeasurement)
+		writeInfluxTag(&b, "service", update.ServiceName)
+		writeInfluxTag(&b, "registry", update.CurrentImage.Registry)
+		writeInfluxTag(&b, "type", string(update.UpdateType))
+		fmt.Fprintf(&b, " value=1 %d", timestamp)
+		lines = append(lines, b.String())
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// FormatName devuelve el nombre del formato
+func (f InfluxFormatter) FormatName() string {
+	return "influx"
+}
+
+// writeInfluxTag añade ",key=value" escapado; el line protocol no admite
+// valores de tag vacíos, así que esos tags se omiten
+func writeInfluxTag(b *strings.Builder, key, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, ",%s=%s", influxTagEscaper.Replace(key), influxTagEscaper.Replace(value))
+}
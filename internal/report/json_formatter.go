@@ -2,22 +2,44 @@ package report
 
 import (
 	"encoding/json"
+	"io"
 
 	"github.com/user/docker-image-reporter/pkg/types"
 )
 
-// JSONFormatter implementa ReportFormatter para generar reportes en formato JSON
-type JSONFormatter struct{}
+// JSONFormatter implementa ReportFormatter para generar reportes en formato JSON.
+// Por defecto indenta la salida; con Compact la escribe en una sola línea.
+type JSONFormatter struct {
+	Compact bool
+}
 
-// Format convierte un ScanResult en un string JSON formateado
+// Format convierte un ScanResult en un string JSON. Los campos siguen el orden
+// de declaración de los structs y las claves de los mapas se ordenan, así que
+// la salida es estable entre ejecuciones.
 func (f JSONFormatter) Format(result types.ScanResult) (string, error) {
-	data, err := json.MarshalIndent(result, "", "  ")
+	var data []byte
+	var err error
+	if f.Compact {
+		data, err = json.Marshal(result)
+	} else {
+		data, err = json.MarshalIndent(result, "", "  ")
+	}
 	if err != nil {
 		return "", err
 	}
 	return string(data), nil
 }
 
+// FormatTo codifica el ScanResult en w con un json.Encoder. La salida es la
+// de Format seguida de un salto de línea.
+func (f JSONFormatter) FormatTo(w io.Writer, result types.ScanResult) error {
+	encoder := json.NewEncoder(w)
+	if !f.Compact {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(result)
+}
+
 // FormatName devuelve el nombre del formato
 func (f JSONFormatter) FormatName() string {
 	return "json"
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// Valores del discriminador type de cada línea JSONL
+const (
+	jsonlTypeUpdate  = "update"
+	jsonlTypeSummary = "summary"
+)
+
+// jsonlUpdate es una línea por actualización: el ImageUpdate con un campo type
+type jsonlUpdate struct {
+	Type string `json:"type"`
+	types.ImageUpdate
+}
+
+// jsonlSummary es la última línea, con los totales del escaneo
+type jsonlSummary struct {
+	Type               string    `json:"type"`
+	ProjectName        string    `json:"project_name"`
+	ScanTimestamp      time.Time `json:"scan_timestamp"`
+	Updates            int       `json:"updates"`
+	UpToDate           int       `json:"up_to_date"`
+	Errors             []string  `json:"errors"`
+	TotalServicesFound int       `json:"total_services_found"`
+	Summary            string    `json:"summary"`
+}
+
+// JSONLinesFormatter implementa ReportFormatter generando JSON Lines: un
+// objeto por actualización y un objeto final con type "summary", para
+// procesarlo línea a línea con jq o cargarlo en un pipeline de logs
+type JSONLinesFormatter struct{}
+
+// Format convierte un ScanResult en líneas JSON independientes
+func (f JSONLinesFormatter) Format(result types.ScanResult) (string, error) {
+	lines := make([]string, 0, len(result.UpdatesAvailable)+1)
+	for _, update := range result.UpdatesAvailable {
+		data, err := json.Marshal(jsonlUpdate{Type: jsonlTypeUpdate, ImageUpdate: update})
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, string(data))
+	}
+
+	errs := result.Errors
+	if errs == nil {
+		errs = []string{}
+	}
+	data, err := json.Marshal(jsonlSummary{
+		Type:               jsonlTypeSummary,
+		ProjectName:        result.ProjectName,
+		ScanTimestamp:      result.ScanTimestamp,
+		Updates:            len(result.UpdatesAvailable),
+		UpToDate:           len(result.UpToDateServices),
+		Errors:             errs,
+		TotalServicesFound: result.TotalServicesFound,
+		Summary:            result.Summary(),
+	})
+	if err != nil {
+		return "", err
+	}
+	lines = append(lines, string(data))
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// FormatName devuelve el nombre del formato
+func (f JSONLinesFormatter) FormatName() string {
+	return "jsonl"
+}
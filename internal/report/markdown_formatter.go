@@ -0,0 +1,52 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// markdownCellEscaper evita que una barra vertical o un salto de línea rompan la tabla Markdown
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// MarkdownFormatter implementa ReportFormatter generando un informe Markdown
+// para pegar en una PR o un issue: una tabla con una fila por actualización,
+// el resumen y una sección con los errores del escaneo
+type MarkdownFormatter struct{}
+
+// Format convierte un ScanResult en un documento Markdown
+func (f MarkdownFormatter) Format(result types.ScanResult) (string, error) {
+	var b strings.Builder
+	b.WriteString("## Docker image updates")
+	if result.ProjectName != "" {
+		fmt.Fprintf(&b, " — %s", markdownCellEscaper.Replace(result.ProjectName))
+	}
+	fmt.Fprintf(&b, "\n\n%s\n", result.Summary())
+
+	if result.HasUpdates() {
+		b.WriteString("\n| Service | Current | Latest | Type |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, update := range result.UpdatesAvailable {
+			fmt.Fprintf(&b, "| %s | `%s` | `%s` | %s |\n",
+				markdownCellEscaper.Replace(update.ServiceName),
+				markdownCellEscaper.Replace(update.CurrentImage.String()),
+				markdownCellEscaper.Replace(update.LatestImage.String()),
+				update.UpdateType)
+		}
+	}
+
+	if result.HasErrors() {
+		b.WriteString("\n### Errors\n\n")
+		for _, scanErr := range result.Errors {
+			fmt.Fprintf(&b, "- %s\n", strings.ReplaceAll(scanErr, "\n", " "))
+		}
+	}
+
+	return b.String(), nil
+}
+
+// FormatName devuelve el nombre del formato
+func (f MarkdownFormatter) FormatName() string {
+	return "markdown"
+}
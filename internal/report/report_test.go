@@ -1,7 +1,10 @@
 package report
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -66,6 +69,39 @@ func TestJSONFormatter_Format(t *testing.T) {
 	}
 }
 
+func TestJSONFormatter_Compact(t *testing.T) {
+	result := types.ScanResult{
+		ProjectName:      "test-project",
+		UpdatesAvailable: []types.ImageUpdate{{ServiceName: "web", UpdateType: types.UpdateTypeMinor}},
+		UpToDateServices: []string{"db"},
+	}
+
+	pretty, err := JSONFormatter{}.Format(result)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	compact, err := JSONFormatter{Compact: true}.Format(result)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	if !strings.Contains(pretty, "\n  \"project_name\"") {
+		t.Errorf("Expected indented output, got %s", pretty)
+	}
+	if strings.Contains(compact, "\n") {
+		t.Errorf("Expected compact output without newlines, got %s", compact)
+	}
+
+	// Ambas salidas representan el mismo contenido, en el mismo orden
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(pretty)); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if buf.String() != compact {
+		t.Errorf("Compact output differs from the indented one:\n%s\n%s", buf.String(), compact)
+	}
+}
+
 func TestJSONFormatter_FormatName(t *testing.T) {
 	formatter := JSONFormatter{}
 
@@ -116,10 +152,11 @@ func TestHTMLFormatter_Format(t *testing.T) {
 		"<!DOCTYPE html>",
 		"<html",
 		"<head>",
-		"<title>Docker Image Scan Report - Devidence</title>",
+		"<title>Docker Image Scan Report - test-project</title>",
 		"<style>",
 		"Docker Image Scanner",
 		"test-project",
+		"1 update (1 minor), 1 up to date, 1 error",
 		"Available Updates",
 		"<table class=\"table mb-0\">",
 		"<th style=\"width: 15%;\">Service</th>",
@@ -177,3 +214,574 @@ func TestHTMLFormatter_Format_NoUpdates(t *testing.T) {
 		t.Error("Expected success message for up-to-date services")
 	}
 }
+
+func TestHTMLFormatter_Format_DigestUpdate(t *testing.T) {
+	formatter := HTMLFormatter{}
+
+	// Crear un ScanResult con un digest obsoleto para el mismo tag
+	result := types.ScanResult{
+		ProjectName:   "test-project",
+		ScanTimestamp: time.Date(2025, 9, 28, 12, 0, 0, 0, time.UTC),
+		UpdatesAvailable: []types.ImageUpdate{
+			{
+				ServiceName: "web",
+				CurrentImage: types.DockerImage{
+					Registry: "docker.io", Repository: "library/nginx", Tag: "1.25",
+					Digest: "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+				},
+				LatestImage: types.DockerImage{
+					Registry: "docker.io", Repository: "library/nginx", Tag: "1.25",
+					Digest: "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+				},
+				UpdateType: types.UpdateTypeDigest,
+			},
+		},
+		TotalServicesFound: 1,
+	}
+
+	output, err := formatter.Format(result)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	for _, expected := range []string{"badge-digest", "library/nginx:1.25@sha256:111111111111", "library/nginx:1.25@sha256:222222222222"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q", expected)
+		}
+	}
+}
+
+func TestSARIFFormatter_Format(t *testing.T) {
+	formatter := SARIFFormatter{}
+
+	result := types.ScanResult{
+		ProjectName: "test-project",
+		UpdatesAvailable: []types.ImageUpdate{
+			{
+				ServiceName: "web",
+				CurrentImage: types.DockerImage{
+					Registry: "docker.io", Repository: "library/nginx", Tag: "1.20",
+					ComposeFile: "stacks/web/docker-compose.yml",
+				},
+				LatestImage: types.DockerImage{
+					Registry: "docker.io", Repository: "library/nginx", Tag: "2.0",
+				},
+				UpdateType: types.UpdateTypeMajor,
+			},
+		},
+		TotalServicesFound: 1,
+	}
+
+	output, err := formatter.Format(result)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	var parsed struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+
+	if parsed.Schema != "https://json.schemastore.org/sarif-2.1.0.json" {
+		t.Errorf("Unexpected $schema %q", parsed.Schema)
+	}
+	if parsed.Version != "2.1.0" {
+		t.Errorf("Expected version 2.1.0, got %q", parsed.Version)
+	}
+	if len(parsed.Runs) != 1 || len(parsed.Runs[0].Results) != 1 {
+		t.Fatalf("Expected 1 run with 1 result, got %+v", parsed.Runs)
+	}
+
+	res := parsed.Runs[0].Results[0]
+	if res.RuleID != "outdated-image" {
+		t.Errorf("Expected ruleId outdated-image, got %q", res.RuleID)
+	}
+	if res.Level != "error" {
+		t.Errorf("Expected level error for major update, got %q", res.Level)
+	}
+	if len(res.Locations) != 1 || res.Locations[0].PhysicalLocation.ArtifactLocation.URI != "stacks/web/docker-compose.yml" {
+		t.Errorf("Unexpected locations %+v", res.Locations)
+	}
+}
+
+func TestGitHubFormatter(t *testing.T) {
+	formatter := GitHubFormatter{Workspace: "/work"}
+
+	result := types.ScanResult{
+		UpdatesAvailable: []types.ImageUpdate{
+			{
+				ServiceName: "web",
+				CurrentImage: types.DockerImage{
+					Registry: "docker.io", Repository: "library/nginx", Tag: "1.20",
+					ComposeFile: "/work/stacks/web,prod/docker-compose.yml",
+				},
+				LatestImage: types.DockerImage{
+					Registry: "docker.io", Repository: "library/nginx", Tag: "1.21",
+				},
+				UpdateType: types.UpdateTypeMinor,
+			},
+		},
+		TotalServicesFound: 1,
+	}
+
+	output, err := formatter.Format(result)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if !strings.Contains(output, "| web | `library/nginx:1.20` | `library/nginx:1.21` | minor | stacks/web,prod/docker-compose.yml |") {
+		t.Errorf("Expected update row in summary:\n%s", output)
+	}
+
+	// La ruta se anota en relativo al workspace y con las comas escapadas
+	annotations := formatter.Annotations(result)
+	want := "::warning file=stacks/web%2Cprod/docker-compose.yml::web update available: library/nginx:1.20 -> library/nginx:1.21 (minor)"
+	if len(annotations) != 1 || annotations[0] != want {
+		t.Errorf("Annotations() = %q, want [%q]", annotations, want)
+	}
+}
+
+func TestSARIFLevel(t *testing.T) {
+	tests := []struct {
+		updateType types.UpdateType
+		want       string
+	}{
+		{types.UpdateTypeMajor, "error"},
+		{types.UpdateTypeMinor, "warning"},
+		{types.UpdateTypePatch, "note"},
+		{types.UpdateTypeDigest, "note"},
+	}
+
+	for _, tt := range tests {
+		if got := sarifLevel(tt.updateType); got != tt.want {
+			t.Errorf("sarifLevel(%s) = %q, want %q", tt.updateType, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLFormatter_Format_UpToDateCollapse(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxUpToDate   int
+		services      []string
+		wantCollapsed bool
+	}{
+		{name: "no limit", maxUpToDate: 0, services: []string{"db", "cache", "web"}, wantCollapsed: false},
+		{name: "below limit", maxUpToDate: 3, services: []string{"db", "cache"}, wantCollapsed: false},
+		{name: "at limit", maxUpToDate: 3, services: []string{"db", "cache", "web"}, wantCollapsed: false},
+		{name: "above limit", maxUpToDate: 2, services: []string{"db", "cache", "web"}, wantCollapsed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := HTMLFormatter{MaxUpToDate: tt.maxUpToDate}
+			result := types.ScanResult{
+				ProjectName:        "test-project",
+				ScanTimestamp:      time.Date(2025, 9, 28, 12, 0, 0, 0, time.UTC),
+				UpToDateServices:   tt.services,
+				TotalServicesFound: len(tt.services),
+			}
+
+			output, err := formatter.Format(result)
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			collapsed := strings.Contains(output, fmt.Sprintf("and %d services up to date", len(tt.services)))
+			if collapsed != tt.wantCollapsed {
+				t.Errorf("collapsed = %v, want %v", collapsed, tt.wantCollapsed)
+			}
+
+			listed := strings.Contains(output, "<li>db</li>")
+			if listed == tt.wantCollapsed {
+				t.Errorf("service list rendered = %v, want %v", listed, !tt.wantCollapsed)
+			}
+		})
+	}
+}
+
+func TestHTMLFormatter_Format_GroupBy(t *testing.T) {
+	result := types.ScanResult{
+		ProjectName: "test-project",
+		UpdatesAvailable: []types.ImageUpdate{
+			{
+				ServiceName:  "web",
+				CurrentImage: types.DockerImage{Registry: "ghcr.io", Repository: "org/web", Tag: "1.0.0"},
+				LatestImage:  types.DockerImage{Registry: "ghcr.io", Repository: "org/web", Tag: "1.0.1"},
+				UpdateType:   types.UpdateTypePatch,
+			},
+			{
+				ServiceName:  "db",
+				CurrentImage: types.DockerImage{Registry: "docker.io", Repository: "library/postgres", Tag: "15"},
+				LatestImage:  types.DockerImage{Registry: "docker.io", Repository: "library/postgres", Tag: "16"},
+				UpdateType:   types.UpdateTypeMajor,
+			},
+		},
+		TotalServicesFound: 2,
+	}
+
+	tests := []struct {
+		name        string
+		groupBy     types.GroupBy
+		wantHeaders []string
+	}{
+		{name: "registry", groupBy: types.GroupByRegistry, wantHeaders: []string{"docker.io (1)", "ghcr.io (1)"}},
+		{name: "severity", groupBy: types.GroupBySeverity, wantHeaders: []string{"major (1)", "patch (1)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := HTMLFormatter{GroupBy: tt.groupBy}.Format(result)
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			last := -1
+			for _, header := range tt.wantHeaders {
+				idx := strings.Index(output, header)
+				if idx < 0 {
+					t.Fatalf("expected section header %q in output", header)
+				}
+				if idx < last {
+					t.Errorf("section header %q out of order", header)
+				}
+				last = idx
+			}
+			if got := strings.Count(output, `class="group-header"`); got != len(tt.wantHeaders) {
+				t.Errorf("got %d group headers, want %d", got, len(tt.wantHeaders))
+			}
+		})
+	}
+
+	flat, err := HTMLFormatter{}.Format(result)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if strings.Contains(flat, `class="group-header"`) {
+		t.Error("expected no group headers in the default flat listing")
+	}
+}
+
+func TestInfluxFormatter_Format(t *testing.T) {
+	scanTime := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	result := types.ScanResult{
+		ScanTimestamp: scanTime,
+		UpdatesAvailable: []types.ImageUpdate{
+			{
+				ServiceName:  "web",
+				CurrentImage: types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.24"},
+				LatestImage:  types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+				UpdateType:   types.UpdateTypeMinor,
+			},
+			{
+				// Los valores con espacios, comas o signos igual se escapan
+				ServiceName:  "my api,v=2",
+				CurrentImage: types.DockerImage{Registry: "ghcr.io", Repository: "org/api", Tag: "1.0.0"},
+				LatestImage:  types.DockerImage{Registry: "ghcr.io", Repository: "org/api", Tag: "2.0.0"},
+				UpdateType:   types.UpdateTypeMajor,
+			},
+		},
+		UpToDateServices: []string{"db"},
+	}
+
+	output, err := InfluxFormatter{}.Format(result)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	ts := scanTime.UnixNano()
+	want := []string{
+		fmt.Sprintf("image_updates,service=web,registry=docker.io,type=minor value=1 %d", ts),
+		fmt.Sprintf(`image_updates,service=my\ api\,v\=2,registry=ghcr.io,type=major value=1 %d`, ts),
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d lines, got %d:\n%s", len(want), len(lines), output)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestJSONLinesFormatter_Format(t *testing.T) {
+	result := types.ScanResult{
+		ProjectName:   "test-project",
+		ScanTimestamp: time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC),
+		UpdatesAvailable: []types.ImageUpdate{
+			{
+				ServiceName:  "web",
+				CurrentImage: types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.24"},
+				LatestImage:  types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+				UpdateType:   types.UpdateTypeMinor,
+			},
+			{
+				ServiceName:  "api",
+				CurrentImage: types.DockerImage{Registry: "ghcr.io", Repository: "org/api", Tag: "1.0.0"},
+				LatestImage:  types.DockerImage{Registry: "ghcr.io", Repository: "org/api", Tag: "2.0.0"},
+				UpdateType:   types.UpdateTypeMajor,
+			},
+		},
+		UpToDateServices:   []string{"db"},
+		TotalServicesFound: 3,
+	}
+
+	output, err := JSONLinesFormatter{}.Format(result)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	lines := strings.Split(output, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines (2 updates + summary), got %d:\n%s", len(lines), output)
+	}
+
+	// Cada línea es un JSON válido por sí sola
+	for i, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Fatalf("line %d is not valid JSON: %s", i, line)
+		}
+	}
+
+	var update struct {
+		Type        string `json:"type"`
+		ServiceName string `json:"service_name"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &update); err != nil {
+		t.Fatalf("unmarshal update line: %v", err)
+	}
+	if update.Type != "update" || update.ServiceName != "web" {
+		t.Errorf("first line = %+v, want type update for service web", update)
+	}
+
+	var summary struct {
+		Type     string   `json:"type"`
+		Project  string   `json:"project_name"`
+		Updates  int      `json:"updates"`
+		UpToDate int      `json:"up_to_date"`
+		Errors   []string `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil {
+		t.Fatalf("unmarshal summary line: %v", err)
+	}
+	if summary.Type != "summary" {
+		t.Errorf("last line type = %q, want summary", summary.Type)
+	}
+	if summary.Project != "test-project" || summary.Updates != 2 || summary.UpToDate != 1 {
+		t.Errorf("summary = %+v, want project test-project with 2 updates and 1 up to date", summary)
+	}
+	if summary.Errors == nil {
+		t.Error("expected errors to be an empty array, not null")
+	}
+}
+
+func TestJSONLinesFormatter_Format_NoUpdates(t *testing.T) {
+	output, err := JSONLinesFormatter{}.Format(types.ScanResult{UpToDateServices: []string{"db"}})
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	// Sin actualizaciones solo queda la línea de resumen
+	if strings.Contains(output, "\n") || !strings.Contains(output, `"type":"summary"`) {
+		t.Errorf("expected a single summary line, got:\n%s", output)
+	}
+}
+
+// countingWriter cuenta las llamadas a Write para comprobar que la salida se
+// escribe por partes
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestStreamFormatter_FormatTo(t *testing.T) {
+	result := types.ScanResult{
+		ProjectName:   "test-project",
+		ScanTimestamp: time.Date(2025, 9, 28, 12, 0, 0, 0, time.UTC),
+		UpdatesAvailable: []types.ImageUpdate{{
+			ServiceName:  "web",
+			CurrentImage: types.DockerImage{Registry: "docker.io", Repository: "nginx", Tag: "1.20"},
+			LatestImage:  types.DockerImage{Registry: "docker.io", Repository: "nginx", Tag: "1.21"},
+			UpdateType:   types.UpdateTypeMinor,
+		}},
+		UpToDateServices:   []string{"db"},
+		TotalServicesFound: 2,
+	}
+
+	tests := []struct {
+		name            string
+		formatter       types.StreamFormatter
+		wantIncremental bool
+	}{
+		{name: "html", formatter: HTMLFormatter{}, wantIncremental: true},
+		{name: "json", formatter: JSONFormatter{}},
+		{name: "json compact", formatter: JSONFormatter{Compact: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := tt.formatter.Format(result)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			w := &countingWriter{}
+			if err := tt.formatter.FormatTo(w, result); err != nil {
+				t.Fatalf("FormatTo() error = %v", err)
+			}
+
+			// FormatTo puede añadir como mucho un salto de línea final
+			if got := strings.TrimSuffix(w.String(), "\n"); got != strings.TrimSuffix(want, "\n") {
+				t.Errorf("FormatTo() output differs from Format()")
+			}
+			if tt.wantIncremental && w.writes < 2 {
+				t.Errorf("FormatTo() wrote in %d call(s), want incremental writes", w.writes)
+			}
+		})
+	}
+}
+
+func TestCSVFormatter_Format(t *testing.T) {
+	result := types.ScanResult{
+		UpdatesAvailable: []types.ImageUpdate{
+			{
+				ServiceName:  "web",
+				CurrentImage: types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.24", ComposeFile: "/srv/app/docker-compose.yml"},
+				LatestImage:  types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+				UpdateType:   types.UpdateTypeMinor,
+			},
+			{
+				// Las comas y comillas se escapan según RFC 4180
+				ServiceName:  `api "v2", internal`,
+				CurrentImage: types.DockerImage{Registry: "ghcr.io", Repository: "org/api", Tag: "1.0.0"},
+				LatestImage:  types.DockerImage{Registry: "ghcr.io", Repository: "org/api", Tag: "2.0.0"},
+				UpdateType:   types.UpdateTypeMajor,
+			},
+		},
+		UpToDateServices: []string{"db"},
+	}
+
+	formatter := CSVFormatter{}
+	if formatter.FormatName() != "csv" {
+		t.Errorf("FormatName() = %q, want csv", formatter.FormatName())
+	}
+	output, err := formatter.Format(result)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("parsing CSV output: %v\n%s", err, output)
+	}
+	want := [][]string{
+		{"service", "registry", "repository", "current_tag", "latest_tag", "update_type", "compose_file"},
+		{"web", "docker.io", "library/nginx", "1.24", "1.25", "minor", "/srv/app/docker-compose.yml"},
+		{`api "v2", internal`, "ghcr.io", "org/api", "1.0.0", "2.0.0", "major", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Expected %d records (header + 2 updates), got %d:\n%s", len(want), len(records), output)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("record %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+
+	// FormatTo escribe lo mismo con el salto de línea final
+	var buf bytes.Buffer
+	if err := formatter.FormatTo(&buf, result); err != nil {
+		t.Fatalf("FormatTo failed: %v", err)
+	}
+	if buf.String() != output+"\n" {
+		t.Errorf("FormatTo() = %q, want Format() plus a newline", buf.String())
+	}
+}
+
+func TestCSVFormatter_Format_NoUpdates(t *testing.T) {
+	output, err := CSVFormatter{}.Format(types.ScanResult{UpToDateServices: []string{"db"}})
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	// Sin actualizaciones solo queda la cabecera
+	if output != "service,registry,repository,current_tag,latest_tag,update_type,compose_file" {
+		t.Errorf("expected only the header, got:\n%s", output)
+	}
+}
+
+func TestMarkdownFormatter_Format(t *testing.T) {
+	result := types.ScanResult{
+		ProjectName: "shop",
+		UpdatesAvailable: []types.ImageUpdate{
+			{
+				ServiceName:  "web",
+				CurrentImage: types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.24"},
+				LatestImage:  types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+				UpdateType:   types.UpdateTypeMinor,
+			},
+			{
+				// Una barra vertical en el repositorio no debe partir la fila
+				ServiceName:  "odd",
+				CurrentImage: types.DockerImage{Registry: "registry.local", Repository: "team/a|b", Tag: "1.0.0"},
+				LatestImage:  types.DockerImage{Registry: "registry.local", Repository: "team/a|b", Tag: "2.0.0"},
+				UpdateType:   types.UpdateTypeMajor,
+			},
+		},
+		UpToDateServices:   []string{"db"},
+		Errors:             []string{"failed to check cache:\nregistry timeout"},
+		TotalServicesFound: 3,
+	}
+
+	formatter := MarkdownFormatter{}
+	if formatter.FormatName() != "markdown" {
+		t.Errorf("FormatName() = %q, want markdown", formatter.FormatName())
+	}
+	output, err := formatter.Format(result)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"## Docker image updates — shop\n",
+		result.Summary() + "\n",
+		"| Service | Current | Latest | Type |\n| --- | --- | --- | --- |\n",
+		"| web | `library/nginx:1.24` | `library/nginx:1.25` | minor |\n",
+		"| odd | `registry.local/team/a\\|b:1.0.0` | `registry.local/team/a\\|b:2.0.0` | major |\n",
+		"### Errors\n\n- failed to check cache: registry timeout\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in Markdown output:\n%s", want, output)
+		}
+	}
+}
+
+func TestMarkdownFormatter_Format_NoUpdates(t *testing.T) {
+	output, err := MarkdownFormatter{}.Format(types.ScanResult{UpToDateServices: []string{"db"}, TotalServicesFound: 1})
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	// Sin actualizaciones ni errores no hay tabla ni sección de errores
+	if strings.Contains(output, "| Service |") || strings.Contains(output, "### Errors") {
+		t.Errorf("expected only the title and summary, got:\n%s", output)
+	}
+}
@@ -0,0 +1,137 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	// sarifRuleOutdatedImage identifica los hallazgos de imágenes desactualizadas
+	sarifRuleOutdatedImage = "outdated-image"
+)
+
+// SARIFFormatter implementa ReportFormatter generando un log SARIF 2.1.0,
+// pensado para herramientas de seguridad que ingieren hallazgos de dependencias
+type SARIFFormatter struct{}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Format convierte un ScanResult en un log SARIF con un result por actualización
+func (f SARIFFormatter) Format(result types.ScanResult) (string, error) {
+	results := make([]sarifResult, 0, len(result.UpdatesAvailable))
+	for _, update := range result.UpdatesAvailable {
+		res := sarifResult{
+			RuleID: sarifRuleOutdatedImage,
+			Level:  sarifLevel(update.UpdateType),
+			Message: sarifMessage{
+				Text: fmt.Sprintf("Service %s uses %s; %s update available: %s",
+					update.ServiceName, update.CurrentImage.String(), update.UpdateType, update.LatestImage.String()),
+			},
+		}
+
+		if update.CurrentImage.ComposeFile != "" {
+			res.Locations = []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(update.CurrentImage.ComposeFile)},
+				},
+			}}
+		}
+
+		results = append(results, res)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{
+				Driver: sarifDriver{
+					Name:           "icr",
+					InformationURI: "https://github.com/devidence-dev/image-container-reporter",
+					Rules: []sarifRule{{
+						ID:               sarifRuleOutdatedImage,
+						Name:             "OutdatedImage",
+						ShortDescription: sarifMessage{Text: "Container image has a newer version available"},
+					}},
+				},
+			},
+			Results: results,
+		}},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// FormatName devuelve el nombre del formato
+func (f SARIFFormatter) FormatName() string {
+	return "sarif"
+}
+
+// sarifLevel traduce la severidad de la actualización a un nivel SARIF
+func sarifLevel(updateType types.UpdateType) string {
+	if updateType == types.UpdateTypeMajor {
+		return "error"
+	}
+	if updateType == types.UpdateTypeMinor {
+		return "warning"
+	}
+	return "note"
+}
@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// EventType identifies a scan progress event
+type EventType string
+
+const (
+	// EventImageChecked is emitted once an image has been checked, whatever the outcome
+	EventImageChecked EventType = "image_checked"
+	// EventUpdateFound is emitted for every available update
+	EventUpdateFound EventType = "update_found"
+	// EventError is emitted for every scan error
+	EventError EventType = "error"
+)
+
+// Event is a single progress event, written as one JSON object per line
+type Event struct {
+	Type       EventType        `json:"type"`
+	Time       time.Time        `json:"time"`
+	Service    string           `json:"service,omitempty"`
+	Image      string           `json:"image,omitempty"`
+	Latest     string           `json:"latest,omitempty"`
+	UpdateType types.UpdateType `json:"update_type,omitempty"`
+	Message    string           `json:"message,omitempty"`
+}
+
+// EventWriter writes newline-delimited JSON events. It is safe for
+// concurrent use by the scanner's worker goroutines.
+type EventWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewEventWriter creates an EventWriter that writes to w
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{enc: json.NewEncoder(w)}
+}
+
+// Write encodes the event as a single line
+func (w *EventWriter) Write(event Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(event)
+}
@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/user/docker-image-reporter/internal/clock"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+func TestService_ScanImages_EmitsEvents(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"1.0.0", "2.0.0"}}
+
+	var buf bytes.Buffer
+	service := NewService(nil, []types.RegistryClient{registry}, logger).
+		WithClock(clock.NewFake(now)).
+		WithEvents(NewEventWriter(&buf))
+
+	images := []types.DockerImage{
+		{Registry: "docker.io", Repository: "library/nginx", Tag: "1.0.0", ServiceName: "web"},
+		{Registry: "docker.io", Repository: "library/redis", Tag: "1.0.0", ServiceName: "cache"},
+		{Registry: "docker.io", Repository: "library/postgres", Tag: "2.0.0", ServiceName: "db"},
+		// No client handles ghcr.io, so this image produces an error event
+		{Registry: "ghcr.io", Repository: "org/app", Tag: "1.0.0", ServiceName: "app"},
+	}
+
+	if _, err := service.ScanImages(context.Background(), images, "test"); err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+
+	counts := map[EventType]int{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid event line %q: %v", scanner.Text(), err)
+		}
+		if !event.Time.Equal(now) {
+			t.Errorf("event time = %v, want %v", event.Time, now)
+		}
+		if event.Type == EventUpdateFound && (event.Latest == "" || event.UpdateType != types.UpdateTypeMajor) {
+			t.Errorf("update_found event missing details: %+v", event)
+		}
+		if event.Type == EventError && event.Message == "" {
+			t.Errorf("error event without message: %+v", event)
+		}
+		counts[event.Type]++
+	}
+
+	want := map[EventType]int{
+		EventImageChecked: 4,
+		EventUpdateFound:  2,
+		EventError:        1,
+	}
+	for eventType, n := range want {
+		if counts[eventType] != n {
+			t.Errorf("%s events = %d, want %d", eventType, counts[eventType], n)
+		}
+	}
+	if len(counts) != len(want) {
+		t.Errorf("unexpected event types: %v", counts)
+	}
+}
+
+func TestService_WithoutEvents(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "generic", tags: []string{"1.0.0", "2.0.0"}}
+	service := NewService(nil, []types.RegistryClient{registry}, logger)
+
+	images := []types.DockerImage{
+		{Registry: "docker.io", Repository: "library/nginx", Tag: "1.0.0", ServiceName: "web"},
+	}
+
+	// Without an event writer the scan must behave exactly as before
+	result, err := service.ScanImages(context.Background(), images, "test")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+	if len(result.UpdatesAvailable) != 1 {
+		t.Errorf("Expected 1 update, got %d", len(result.UpdatesAvailable))
+	}
+}
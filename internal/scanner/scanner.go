@@ -4,13 +4,20 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/user/docker-image-reporter/internal/clock"
 	"github.com/user/docker-image-reporter/internal/compose"
+	"github.com/user/docker-image-reporter/pkg/errors"
 	"github.com/user/docker-image-reporter/pkg/types"
 	"github.com/user/docker-image-reporter/pkg/utils"
 )
@@ -20,14 +27,64 @@ type Service struct {
 	parser     types.ComposeParser
 	registries []types.RegistryClient
 	logger     *slog.Logger
+	clock      clock.Clock
+	priority   []string
+	events     *EventWriter
+	preflight  time.Duration
+	aliases    map[string]string
+	rolling    []string
+	// suffixPolicy controls how strictly candidates keep the current tag suffix
+	suffixPolicy utils.SuffixPolicy
+	// maxUpdateType bounds the recommended update; empty disables it
+	maxUpdateType types.UpdateType
+	// targetOS enables OS-specific tag conventions such as Windows releases
+	targetOS utils.TargetOS
+	// codenames orders distro codename tags (bookworm -> trixie)
+	codenames *utils.Codenames
+	// registryTimeouts overrides Config.RegistryTimeout per registry host
+	registryTimeouts map[string]time.Duration
+	// registryConcurrency caps the concurrent checks per registry host
+	registryConcurrency map[string]int
+	// maxTags caps the tags considered per image; 0 disables it
+	maxTags int
+	// gitlabHost is a self-hosted GitLab registry served by the gitlab client
+	gitlabHost string
+	// resolveLatest looks up the version the registry's latest tag points to
+	resolveLatest bool
+	// strictSemver only compares tags with the precision of the current one
+	strictSemver bool
+	// digestChangesOnly reports pinned digest drift and skips version checks
+	digestChangesOnly bool
+	// checkBuiltImages also checks images of services that have a build section
+	checkBuiltImages bool
+	// registriesMu guards registries, which grows when an auto registry is enabled
+	registriesMu sync.RWMutex
+	// autoRegistries are enabled once a scan finds an image they handle
+	autoRegistries []AutoRegistry
 }
 
 // Config holds configuration for scanning operations
 type Config struct {
-	Recursive       bool
-	Patterns        []string
+	Recursive bool
+	Patterns  []string
+	// MaxConcurrency bounds the number of images checked at once.
+	// AutoConcurrency (0) derives it from the image count and GOMAXPROCS.
 	MaxConcurrency  int
 	RegistryTimeout time.Duration
+	// FollowSymlinks makes recursive scans enter symlinked directories
+	FollowSymlinks bool
+}
+
+// AutoConcurrency makes the scanner pick MaxConcurrency from the workload
+const AutoConcurrency = 0
+
+// concurrency returns the number of concurrent checks to run for n images:
+// MaxConcurrency when set, otherwise min(n, 4*GOMAXPROCS).
+func (c Config) concurrency(n int) int {
+	if c.MaxConcurrency > 0 {
+		return c.MaxConcurrency
+	}
+	return max(1, min(n, 4*runtime.GOMAXPROCS(0)))
 }
 
 // DefaultConfig returns sensible default configuration
@@ -46,6 +103,187 @@ func NewService(parser types.ComposeParser, registries []types.RegistryClient, l
 		parser:     parser,
 		registries: registries,
 		logger:     logger,
+		clock:      clock.New(),
+		codenames:  utils.DefaultCodenames(),
+	}
+}
+
+// AutoRegistry is a registry client that is only enabled once a scan finds an
+// image it handles (--registries-from-compose).
+type AutoRegistry struct {
+	Client types.RegistryClient
+	// Anonymous reports that no credentials are configured for the client, so
+	// only public repositories can be listed
+	Anonymous bool
+}
+
+// WithAutoRegistries sets clients that are enabled, ahead of the configured
+// ones, the first time a scan finds an image whose registry they handle. Once
+// enabled they stay enabled for later scans of the same Service.
+func (s *Service) WithAutoRegistries(registries []AutoRegistry) *Service {
+	s.autoRegistries = registries
+	return s
+}
+
+// WithPriority sets repository globs (e.g. "*/openssl", "nginx") whose updates
+// are marked as priority and listed first.
+func (s *Service) WithPriority(patterns []string) *Service {
+	s.priority = patterns
+	return s
+}
+
+// WithRollingImages sets repository globs (e.g. "gcr.io/distroless/*") of image
+// families that do not use semver tags. Their tags are never compared as
+// versions; only a pinned digest is checked for drift.
+func (s *Service) WithRollingImages(patterns []string) *Service {
+	s.rolling = patterns
+	return s
+}
+
+// WithSuffixPolicy sets how strictly update candidates must keep the suffix
+// of the current tag. The zero value behaves like utils.SuffixPolicyPrefer.
+func (s *Service) WithSuffixPolicy(policy utils.SuffixPolicy) *Service {
+	s.suffixPolicy = policy
+	return s
+}
+
+// WithMaxUpdateType makes each update also carry a RecommendedImage: the newest
+// version whose update type does not exceed maxUpdateType. LatestImage keeps
+// the absolute newest version. An empty value disables recommendations.
+func (s *Service) WithMaxUpdateType(maxUpdateType types.UpdateType) *Service {
+	s.maxUpdateType = maxUpdateType
+	return s
+}
+
+// WithLatestAlias makes each update also carry MaintainerLatest: the version
+// tag whose digest matches the registry's latest tag, which maintainers may
+// keep on an LTS release rather than the newest one.
+func (s *Service) WithLatestAlias(enabled bool) *Service {
+	s.resolveLatest = enabled
+	return s
+}
+
+// WithDigestChangesOnly restricts the scan to images pinned by tag and
+// digest, reporting only those whose digest has drifted from the tag's
+// current one. Version updates are never looked up and images without a
+// pinned digest are left out of the scan.
+func (s *Service) WithDigestChangesOnly(enabled bool) *Service {
+	s.digestChangesOnly = enabled
+	return s
+}
+
+// WithCheckBuiltImages makes the scan check images of compose services that
+// also have a build section. By default such an image is the tag of a local
+// build: it is listed in ScanResult.LocallyBuilt instead of being looked up.
+func (s *Service) WithCheckBuiltImages(enabled bool) *Service {
+	s.checkBuiltImages = enabled
+	return s
+}
+
+// WithStrictSemver stops one- and two-part tags from being padded into full
+// versions: candidates must have as many version parts as the current tag,
+// so "18" only moves to "19" and "18.1" to "18.2" or "19.0". By default a
+// tag pinned to "18" may be reported as outdated by "18.1" when the registry
+// publishes no other one-part tags.
+func (s *Service) WithStrictSemver(enabled bool) *Service {
+	s.strictSemver = enabled
+	return s
+}
+
+// WithClock replaces the clock used for scan timestamps. Intended for tests.
+func (s *Service) WithClock(c clock.Clock) *Service {
+	s.clock = c
+	return s
+}
+
+// WithEvents streams scan progress events to w. A nil writer disables events.
+func (s *Service) WithEvents(w *EventWriter) *Service {
+	s.events = w
+	return s
+}
+
+// WithPreflight enables a connectivity check of every registry before its
+// images are checked, bounded by timeout. Images of an unreachable registry are
+// skipped with a single error instead of timing out one by one. A zero timeout
+// disables the check.
+func (s *Service) WithPreflight(timeout time.Duration) *Service {
+	s.preflight = timeout
+	return s
+}
+
+// WithRepositoryAliases rewrites repository prefixes (e.g. an internal mirror)
+// to their canonical upstream before selecting a registry client, so the
+// upstream is queried and reported. The original reference is kept in
+// ImageUpdate.OriginalReference.
+func (s *Service) WithRepositoryAliases(aliases map[string]string) *Service {
+	s.aliases = aliases
+	return s
+}
+
+// WithRegistryTimeouts overrides Config.RegistryTimeout for images served by
+// the given registry hosts (lowercase, after repository aliases), so slow
+// registries get a longer deadline without raising it for everyone.
+func (s *Service) WithRegistryTimeouts(timeouts map[string]time.Duration) *Service {
+	s.registryTimeouts = timeouts
+	return s
+}
+
+// WithRegistryConcurrency caps how many images of each registry host are
+// checked at once, below Config.MaxConcurrency, so that rate-limited
+// registries such as Docker Hub are queried steadily instead of in a burst.
+// Hosts are matched case-insensitively after repository aliases.
+func (s *Service) WithRegistryConcurrency(limits map[string]int) *Service {
+	s.registryConcurrency = limits
+	return s
+}
+
+// WithTargetOS enables the tag conventions of the given OS, e.g. ordering
+// Windows release tags (ltsc2019 -> ltsc2022) by OS build.
+func (s *Service) WithTargetOS(target utils.TargetOS) *Service {
+	s.targetOS = target
+	return s
+}
+
+// WithCodenames sets the distro codenames compared through their versions,
+// e.g. the built-in ones extended with scan.codenames (see utils.NewCodenames).
+func (s *Service) WithCodenames(codenames *utils.Codenames) *Service {
+	s.codenames = codenames
+	return s
+}
+
+// WithMaxTags caps the number of tags considered per image after fetching
+// them (see utils.LimitTags); 0 disables the cap.
+func (s *Service) WithMaxTags(maxTags int) *Service {
+	s.maxTags = maxTags
+	return s
+}
+
+// WithGitLabHost routes the self-hosted GitLab registry host (host[:port]) to
+// the gitlab client, in addition to gitlab.com.
+func (s *Service) WithGitLabHost(host string) *Service {
+	s.gitlabHost = strings.ToLower(host)
+	return s
+}
+
+// registryTimeout returns the per-image deadline for image
+func (s *Service) registryTimeout(image types.DockerImage, fallback time.Duration) time.Duration {
+	if aliased, ok := types.ApplyRepositoryAliases(image, s.aliases); ok {
+		image = aliased
+	}
+	if timeout, ok := s.registryTimeouts[strings.ToLower(image.Registry)]; ok {
+		return timeout
+	}
+	return fallback
+}
+
+// emit writes a progress event if an event writer is configured
+func (s *Service) emit(event Event) {
+	if s.events == nil {
+		return
+	}
+	event.Time = s.clock.Now()
+	if err := s.events.Write(event); err != nil {
+		s.logger.Warn("Failed to write scan event", "type", event.Type, "error", err)
 	}
 }
 
@@ -63,7 +301,7 @@ func (s *Service) ScanDirectory(ctx context.Context, path string, config Config)
 		s.logger.Warn("No compose files found", "path", path)
 		return &types.ScanResult{
 			ProjectName:      s.getProjectName(path),
-			ScanTimestamp:    time.Now(),
+			ScanTimestamp:    s.clock.Now(),
 			UpdatesAvailable: []types.ImageUpdate{},
 			UpToDateServices: []string{},
 			Errors:           []string{"No compose files found"},
@@ -73,10 +311,14 @@ func (s *Service) ScanDirectory(ctx context.Context, path string, config Config)
 	s.logger.Info("Found compose files", "count", len(files), "files", files)
 
 	// Parse all compose files to extract images
-	allImages, parseErrors := s.parseComposeFiles(ctx, files)
+	allImages, parseErrors, notes, summaries := s.parseComposeFiles(ctx, files)
+
+	totalServices := len(allImages)
+	allImages, locallyBuilt := s.splitLocallyBuilt(allImages)
 
 	// Check for updates concurrently
-	updates, upToDate, checkErrors := s.checkForUpdates(ctx, allImages, config)
+	updates, upToDate, checkErrors, lookups, warnings := s.checkForUpdates(ctx, allImages, config)
+	notes = append(notes, warnings...)
 
 	// Combine all errors
 	var allErrors []string
@@ -84,14 +326,20 @@ func (s *Service) ScanDirectory(ctx context.Context, path string, config Config)
 	allErrors = append(allErrors, checkErrors...)
 
 	result := &types.ScanResult{
-		ProjectName:        s.getProjectName(path),
-		ScanTimestamp:      time.Now(),
+		ProjectName:        s.projectName(path, files),
+		ScanTimestamp:      s.clock.Now(),
 		UpdatesAvailable:   updates,
 		UpToDateServices:   upToDate,
 		Errors:             allErrors,
-		TotalServicesFound: len(allImages),
+		TotalServicesFound: totalServices,
 		FilesScanned:       files,
+		Lookups:            lookups,
+		RateLimits:         s.rateLimits(),
+		Notes:              notes,
+		LocallyBuilt:       locallyBuilt,
+		FilesSummary:       summaries,
 	}
+	result.PrioritizeUpdates()
 
 	s.logger.Info("Scan completed",
 		"updates_found", len(updates),
@@ -104,50 +352,98 @@ func (s *Service) ScanDirectory(ctx context.Context, path string, config Config)
 // ScanImages checks a pre-supplied list of images for updates.
 // It is the counterpart of ScanDirectory for non-compose sources (e.g. Docker daemon).
 func (s *Service) ScanImages(ctx context.Context, images []types.DockerImage, projectName string) (*types.ScanResult, error) {
+	return s.ScanImagesWithConfig(ctx, images, projectName, DefaultConfig())
+}
+
+// ScanImagesWithConfig is ScanImages with explicit concurrency and timeout settings.
+func (s *Service) ScanImagesWithConfig(ctx context.Context, images []types.DockerImage, projectName string, config Config) (*types.ScanResult, error) {
 	imageMap := make(map[string]types.DockerImage, len(images))
 	for _, img := range images {
 		key := fmt.Sprintf("%s:%s", img.ServiceName, img.String())
 		imageMap[key] = img
 	}
 
-	updates, upToDate, errors := s.checkForUpdates(ctx, imageMap, DefaultConfig())
+	imageMap, locallyBuilt := s.splitLocallyBuilt(imageMap)
+
+	updates, upToDate, errors, lookups, warnings := s.checkForUpdates(ctx, imageMap, config)
 
-	return &types.ScanResult{
+	result := &types.ScanResult{
 		ProjectName:        projectName,
-		ScanTimestamp:      time.Now(),
+		ScanTimestamp:      s.clock.Now(),
 		UpdatesAvailable:   updates,
 		UpToDateServices:   upToDate,
 		Errors:             errors,
 		TotalServicesFound: len(images),
-	}, nil
+		Lookups:            lookups,
+		RateLimits:         s.rateLimits(),
+		Notes:              warnings,
+		LocallyBuilt:       locallyBuilt,
+	}
+	result.PrioritizeUpdates()
+
+	return result, nil
+}
+
+// splitLocallyBuilt removes the images of services that also have a build
+// section, unless checkBuiltImages is set, and returns their sorted service
+// names.
+func (s *Service) splitLocallyBuilt(images map[string]types.DockerImage) (map[string]types.DockerImage, []string) {
+	if s.checkBuiltImages {
+		return images, nil
+	}
+	var locallyBuilt []string
+	for key, image := range images {
+		if !image.Built {
+			continue
+		}
+		s.logger.Debug("Skipping locally built image", "service", image.ServiceName, "image", image.String())
+		locallyBuilt = append(locallyBuilt, image.ServiceName)
+		delete(images, key)
+	}
+	slices.Sort(locallyBuilt)
+	return images, locallyBuilt
 }
 
 // findComposeFiles finds all compose files in the given path
 func (s *Service) findComposeFiles(path string, config Config) ([]string, error) {
 	scanner := compose.NewScanner()
 	scanConfig := types.ScanConfig{
-		Recursive: config.Recursive,
-		Patterns:  config.Patterns,
+		Recursive:      config.Recursive,
+		Patterns:       config.Patterns,
+		FollowSymlinks: config.FollowSymlinks,
 	}
 	return scanner.FindComposeFiles(context.Background(), path, scanConfig)
 }
 
-// parseComposeFiles parses all compose files and extracts images
-func (s *Service) parseComposeFiles(ctx context.Context, files []string) (map[string]types.DockerImage, []string) {
+// parseComposeFiles parses all compose files and extracts images. It also
+// returns informational notes for services whose image is changed by an
+// override file scanned alongside its base file, and how many services and
+// images each file contributed.
+func (s *Service) parseComposeFiles(ctx context.Context, files []string) (map[string]types.DockerImage, []string, []string, []types.FileSummary) {
 	allImages := make(map[string]types.DockerImage)
+	fileImages := make(map[string][]types.DockerImage, len(files))
+	summaries := make([]types.FileSummary, 0, len(files))
 	var errors []string
 
 	for _, file := range files {
 		s.logger.Debug("Parsing compose file", "file", file)
 
-		images, err := s.parser.ParseFile(ctx, file)
+		images, services, err := s.parseFile(ctx, file)
 		if err != nil {
 			errMsg := fmt.Sprintf("parsing %s: %v", file, err)
 			errors = append(errors, errMsg)
+			summaries = append(summaries, types.FileSummary{File: file, Error: err.Error()})
+			s.emit(Event{Type: EventError, Message: errMsg})
 			s.logger.Error("Failed to parse compose file", "file", file, "error", err)
 			continue
 		}
 
+		fileImages[file] = images
+		summaries = append(summaries, types.FileSummary{File: file, Services: services, Images: len(images)})
+		if len(images) == 0 {
+			s.logger.Info("Compose file contributed no images", "file", file, "services", services)
+		}
+
 		// Add images with service context - images already have ServiceName set
 		for _, image := range images {
 			key := fmt.Sprintf("%s:%s", image.ServiceName, image.String())
@@ -157,13 +453,119 @@ func (s *Service) parseComposeFiles(ctx context.Context, files []string) (map[st
 		s.logger.Debug("Parsed compose file", "file", file, "images_found", len(images))
 	}
 
-	return allImages, errors
+	var notes []string
+	for _, file := range files {
+		base, ok := overrideBase(file)
+		if !ok {
+			continue
+		}
+		if baseImages, found := fileImages[base]; found {
+			notes = append(notes, overrideNotes(baseImages, fileImages[file])...)
+		}
+	}
+	for _, note := range notes {
+		s.logger.Info("Override changes image", "note", note)
+	}
+
+	return allImages, errors, notes, summaries
+}
+
+// parseFile parses a compose file and counts its services. Parsers that
+// cannot report the count get the number of distinct services with an image.
+func (s *Service) parseFile(ctx context.Context, file string) ([]types.DockerImage, int, error) {
+	if counter, ok := s.parser.(types.ServiceCounter); ok {
+		return counter.ParseFileWithServices(ctx, file)
+	}
+	images, err := s.parser.ParseFile(ctx, file)
+	services := make(map[string]bool, len(images))
+	for _, image := range images {
+		services[image.ServiceName] = true
+	}
+	return images, len(services), err
+}
+
+// overrideBase returns the base file an override file extends, following the
+// docker compose convention (docker-compose.override.yml -> docker-compose.yml,
+// compose.override.yaml -> compose.yaml).
+func overrideBase(file string) (string, bool) {
+	dir, name := filepath.Split(file)
+	ext := filepath.Ext(name)
+	stem, ok := strings.CutSuffix(strings.TrimSuffix(name, ext), ".override")
+	if !ok || stem == "" {
+		return "", false
+	}
+	return dir + stem + ext, true
+}
+
+// overrideNotes describes, per service defined in both files, how the override
+// changes the service's images. Images present in both files are ignored.
+func overrideNotes(base, override []types.DockerImage) []string {
+	collect := func(images []types.DockerImage) map[string]map[string]bool {
+		byService := make(map[string]map[string]bool)
+		for _, image := range images {
+			if byService[image.ServiceName] == nil {
+				byService[image.ServiceName] = make(map[string]bool)
+			}
+			byService[image.ServiceName][image.String()] = true
+		}
+		return byService
+	}
+	// difference returns the sorted references in a that are not in b
+	difference := func(a, b map[string]bool) []string {
+		var refs []string
+		for ref := range a {
+			if !b[ref] {
+				refs = append(refs, ref)
+			}
+		}
+		sort.Strings(refs)
+		return refs
+	}
+
+	baseServices := collect(base)
+	overrideServices := collect(override)
+
+	services := make([]string, 0, len(overrideServices))
+	for service := range overrideServices {
+		if baseServices[service] != nil {
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+
+	var notes []string
+	for _, service := range services {
+		from := difference(baseServices[service], overrideServices[service])
+		to := difference(overrideServices[service], baseServices[service])
+		if len(from) == 0 || len(to) == 0 {
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("service %s: override changes image from %s to %s",
+			service, strings.Join(from, ", "), strings.Join(to, ", ")))
+	}
+	return notes
 }
 
-// checkForUpdates checks all images for available updates concurrently
-func (s *Service) checkForUpdates(ctx context.Context, images map[string]types.DockerImage, config Config) ([]types.ImageUpdate, []string, []string) {
+// checkForUpdates checks all images for available updates concurrently.
+// Besides updates, up-to-date services and errors it returns, sorted by service,
+// which registry client resolved each image and how long the lookup took.
+func (s *Service) checkForUpdates(ctx context.Context, images map[string]types.DockerImage, config Config) ([]types.ImageUpdate, []string, []string, []types.ImageLookup, []string) {
 	if len(images) == 0 {
-		return nil, nil, nil
+		return nil, nil, nil, nil, nil
+	}
+
+	if s.digestChangesOnly {
+		images = s.digestPinnedImages(images)
+		if len(images) == 0 {
+			return nil, nil, nil, nil, nil
+		}
+	}
+
+	s.enableAutoRegistries(images)
+
+	images, preflightErrors := s.preflightCheck(ctx, images)
+	for _, errMsg := range preflightErrors {
+		s.emit(Event{Type: EventError, Message: errMsg})
 	}
 
 	// Create channels for results
@@ -172,26 +574,54 @@ func (s *Service) checkForUpdates(ctx context.Context, images map[string]types.D
 	errorsChan := make(chan string, len(images))
 
 	// Create semaphore for concurrency control
-	semaphore := make(chan struct{}, config.MaxConcurrency)
+	semaphore := make(chan struct{}, config.concurrency(len(images)))
 
 	var wg sync.WaitGroup
+	var lookupsMu sync.Mutex
+	lookups := make([]types.ImageLookup, 0, len(images))
+	var warnings []string
 
-	// Process each image concurrently
-	for serviceKey, image := range images {
-		wg.Add(1)
-		go func(key string, img types.DockerImage) {
-			defer wg.Done()
+	check := func(job checkJob) {
+		// Acquire semaphore
+		semaphore <- struct{}{}
+		defer func() { <-semaphore }()
 
-			// Acquire semaphore
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+		// Create context with timeout for this operation
+		opCtx, cancel := context.WithTimeout(ctx, s.registryTimeout(job.image, config.RegistryTimeout))
+		defer cancel()
 
-			// Create context with timeout for this operation
-			opCtx, cancel := context.WithTimeout(ctx, config.RegistryTimeout)
-			defer cancel()
+		start := s.clock.Now()
+		warning := s.checkImageForUpdates(opCtx, job.key, job.image, updatesChan, upToDateChan, errorsChan)
+		lookup := s.newLookup(job.image, s.clock.Now().Sub(start))
+
+		lookupsMu.Lock()
+		lookups = append(lookups, lookup)
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+		lookupsMu.Unlock()
 
-			s.checkImageForUpdates(opCtx, key, img, updatesChan, upToDateChan, errorsChan)
-		}(serviceKey, image)
+		s.emit(Event{Type: EventImageChecked, Service: job.image.ServiceName, Image: job.image.String()})
+	}
+
+	// Each registry is drained by its own workers, so a capped registry is
+	// paced while the others keep using the rest of the semaphore
+	for _, queue := range s.scheduleChecks(images, cap(semaphore)) {
+		jobs := make(chan checkJob, len(queue.jobs))
+		for _, job := range queue.jobs {
+			jobs <- job
+		}
+		close(jobs)
+
+		for range queue.workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for job := range jobs {
+					check(job)
+				}
+			}()
+		}
 	}
 
 	// Close channels when all goroutines complete
@@ -205,7 +635,7 @@ func (s *Service) checkForUpdates(ctx context.Context, images map[string]types.D
 	// Collect results
 	var updates []types.ImageUpdate
 	var upToDate []string
-	var errors []string
+	errors := preflightErrors
 
 	for updatesChan != nil || upToDateChan != nil || errorsChan != nil {
 		select {
@@ -214,6 +644,13 @@ func (s *Service) checkForUpdates(ctx context.Context, images map[string]types.D
 				updatesChan = nil
 			} else {
 				updates = append(updates, update)
+				s.emit(Event{
+					Type:       EventUpdateFound,
+					Service:    update.ServiceName,
+					Image:      update.CurrentImage.String(),
+					Latest:     update.LatestImage.String(),
+					UpdateType: update.UpdateType,
+				})
 			}
 		case service, ok := <-upToDateChan:
 			if !ok {
@@ -226,45 +663,344 @@ func (s *Service) checkForUpdates(ctx context.Context, images map[string]types.D
 				errorsChan = nil
 			} else {
 				errors = append(errors, err)
+				s.emit(Event{Type: EventError, Message: err})
 			}
 		case <-ctx.Done():
-			return updates, upToDate, append(errors, "scan cancelled: "+ctx.Err().Error())
+			lookupsMu.Lock()
+			defer lookupsMu.Unlock()
+			return updates, upToDate, append(errors, "scan cancelled: "+ctx.Err().Error()), sortLookups(slices.Clone(lookups)), slices.Sorted(slices.Values(warnings))
 		}
 	}
 
-	return updates, upToDate, errors
+	sort.Strings(warnings)
+	return updates, upToDate, errors, sortLookups(lookups), warnings
 }
 
-// checkImageForUpdates checks a single image for updates
-func (s *Service) checkImageForUpdates(ctx context.Context, serviceKey string, image types.DockerImage, updatesChan chan<- types.ImageUpdate, upToDateChan chan<- string, errorsChan chan<- string) {
-	serviceName := strings.Split(serviceKey, ":")[0]
+// checkJob is a single image check scheduled by checkForUpdates
+type checkJob struct {
+	key   string
+	image types.DockerImage
+}
 
-	s.logger.Debug("Checking image for updates", "service", serviceName, "image", image.String())
+// registryQueue holds the checks of one registry host and the number of
+// workers that drain it
+type registryQueue struct {
+	registry string
+	jobs     []checkJob
+	workers  int
+}
 
-	// Find appropriate registry client
-	var client types.RegistryClient
-	for _, reg := range s.registries {
+// scheduleChecks groups images by upstream registry host. Each queue gets
+// up to concurrency workers, or the registry's cap from
+// WithRegistryConcurrency when lower. Queues are ordered by the remaining
+// rate-limit budget seen so far, registries without an advertised limit
+// first, so that checks against a nearly exhausted registry start last.
+func (s *Service) scheduleChecks(images map[string]types.DockerImage, concurrency int) []registryQueue {
+	byRegistry := make(map[string]*registryQueue)
+	for _, key := range slices.Sorted(maps.Keys(images)) {
+		image := images[key]
+		upstream, _ := types.ApplyRepositoryAliases(image, s.aliases)
+		host := rateLimitHost(upstream.Registry)
+		queue, ok := byRegistry[host]
+		if !ok {
+			queue = &registryQueue{registry: host}
+			byRegistry[host] = queue
+		}
+		queue.jobs = append(queue.jobs, checkJob{key: key, image: image})
+	}
+
+	remaining := make(map[string]int)
+	for _, limit := range s.rateLimits() {
+		remaining[rateLimitHost(limit.Registry)] = limit.Remaining
+	}
+
+	queues := make([]registryQueue, 0, len(byRegistry))
+	for host, queue := range byRegistry {
+		workers := concurrency
+		for limitHost, limit := range s.registryConcurrency {
+			if rateLimitHost(limitHost) == host && limit > 0 {
+				workers = min(workers, limit)
+			}
+		}
+		queue.workers = max(1, min(workers, len(queue.jobs)))
+		queues = append(queues, *queue)
+	}
+
+	slices.SortFunc(queues, func(a, b registryQueue) int {
+		budgetA, limitedA := remaining[a.registry]
+		budgetB, limitedB := remaining[b.registry]
+		switch {
+		case limitedA != limitedB:
+			if limitedA {
+				return 1
+			}
+			return -1
+		case budgetA != budgetB:
+			return budgetB - budgetA
+		}
+		return strings.Compare(a.registry, b.registry)
+	})
+	return queues
+}
+
+// rateLimitHost normalizes a registry host for scheduling; Docker Hub
+// images and its API hosts share one budget under docker.io
+func rateLimitHost(host string) string {
+	host = strings.ToLower(host)
+	switch host {
+	case "", "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return host
+}
+
+// newLookup describes which client resolved image (after repository aliases)
+func (s *Service) newLookup(image types.DockerImage, latency time.Duration) types.ImageLookup {
+	upstream, _ := types.ApplyRepositoryAliases(image, s.aliases)
+	lookup := types.ImageLookup{
+		Service:  image.ServiceName,
+		Image:    image.String(),
+		Registry: upstream.Registry,
+		Latency:  latency,
+	}
+	if client := s.clientFor(upstream); client != nil {
+		lookup.Client = client.Name()
+	}
+	return lookup
+}
+
+// sortLookups orders lookups by service and image for a deterministic report
+func sortLookups(lookups []types.ImageLookup) []types.ImageLookup {
+	sort.Slice(lookups, func(i, j int) bool {
+		if lookups[i].Service != lookups[j].Service {
+			return lookups[i].Service < lookups[j].Service
+		}
+		return lookups[i].Image < lookups[j].Image
+	})
+	return lookups
+}
+
+// enableAutoRegistries enables the auto registries that handle the registry of
+// any of images, warning when one has no credentials configured
+func (s *Service) enableAutoRegistries(images map[string]types.DockerImage) {
+	s.registriesMu.Lock()
+	defer s.registriesMu.Unlock()
+
+	if len(s.autoRegistries) == 0 {
+		return
+	}
+
+	var pending []AutoRegistry
+	for _, auto := range s.autoRegistries {
+		host := ""
+		for _, image := range images {
+			image, _ = types.ApplyRepositoryAliases(image, s.aliases)
+			if s.canHandleRegistry(auto.Client, image.Registry) {
+				host = image.Registry
+				break
+			}
+		}
+		if host == "" {
+			pending = append(pending, auto)
+			continue
+		}
+
+		s.registries = append([]types.RegistryClient{auto.Client}, s.registries...)
+		if auto.Anonymous {
+			s.logger.Warn("Enabled registry client found in compose files without credentials; private repositories will be reported as auth_required",
+				"client", auto.Client.Name(), "registry", host)
+		} else {
+			s.logger.Info("Enabled registry client found in compose files", "client", auto.Client.Name(), "registry", host)
+		}
+	}
+	s.autoRegistries = pending
+}
+
+// clients returns the registry clients currently enabled
+func (s *Service) clients() []types.RegistryClient {
+	s.registriesMu.RLock()
+	defer s.registriesMu.RUnlock()
+	return s.registries
+}
+
+// rateLimits collects the latest rate limits reported by the registry clients
+func (s *Service) rateLimits() []types.RateLimit {
+	var limits []types.RateLimit
+	for _, reg := range s.clients() {
+		if reporter, ok := reg.(types.RateLimitReporter); ok {
+			limits = append(limits, reporter.RateLimits()...)
+		}
+	}
+	return limits
+}
+
+// preflightCheck pings each registry referenced by images once, concurrently,
+// and returns the images whose registry is reachable together with one
+// "registry unreachable" error per failed registry. Registries whose client
+// cannot ping are assumed reachable.
+func (s *Service) preflightCheck(ctx context.Context, images map[string]types.DockerImage) (map[string]types.DockerImage, []string) {
+	if s.preflight <= 0 {
+		return images, nil
+	}
+
+	// Aliased images are queried against their upstream, so ping that instead
+	pingers := make(map[string]types.RegistryPinger)
+	for _, image := range images {
+		image, _ = types.ApplyRepositoryAliases(image, s.aliases)
+		if _, seen := pingers[image.Registry]; seen {
+			continue
+		}
+		pinger, _ := s.clientFor(image).(types.RegistryPinger)
+		pingers[image.Registry] = pinger
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	unreachable := make(map[string]error)
+	for registry, pinger := range pingers {
+		if pinger == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(registry string, pinger types.RegistryPinger) {
+			defer wg.Done()
+
+			pingCtx, cancel := context.WithTimeout(ctx, s.preflight)
+			defer cancel()
+
+			if err := pinger.Ping(pingCtx, registry); err != nil {
+				mu.Lock()
+				unreachable[registry] = err
+				mu.Unlock()
+			}
+		}(registry, pinger)
+	}
+	wg.Wait()
+
+	if len(unreachable) == 0 {
+		return images, nil
+	}
+
+	reachable := make(map[string]types.DockerImage, len(images))
+	skipped := make(map[string]int)
+	for key, image := range images {
+		upstream, _ := types.ApplyRepositoryAliases(image, s.aliases)
+		if _, down := unreachable[upstream.Registry]; down {
+			skipped[upstream.Registry]++
+			continue
+		}
+		reachable[key] = image
+	}
+
+	messages := make([]string, 0, len(unreachable))
+	for _, registry := range sortedRegistries(unreachable) {
+		displayName := registry
+		if displayName == "" {
+			displayName = "docker.io"
+		}
+		messages = append(messages, fmt.Sprintf("registry unreachable: %s: %v (%d images skipped)", displayName, unreachable[registry], skipped[registry]))
+		s.logger.Error("Registry unreachable, skipping its images", "registry", displayName, "images", skipped[registry], "error", unreachable[registry])
+	}
+
+	return reachable, messages
+}
+
+// sortedRegistries returns the registries of m in a deterministic order
+func sortedRegistries(m map[string]error) []string {
+	registries := make([]string, 0, len(m))
+	for registry := range m {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	return registries
+}
+
+// originalReference returns the reference of original when a repository alias
+// rewrote it to image, or "" when no alias applied
+func originalReference(original, image types.DockerImage) string {
+	if original.Registry == image.Registry && original.Repository == image.Repository {
+		return ""
+	}
+	return original.String()
+}
+
+// clientFor returns the first registry client able to handle the image, or nil
+func (s *Service) clientFor(image types.DockerImage) types.RegistryClient {
+	for _, reg := range s.clients() {
 		if s.canHandleRegistry(reg, image.Registry) {
-			client = reg
-			break
+			return reg
 		}
 	}
+	return nil
+}
+
+// checkImageForUpdates checks a single image for updates. The returned
+// warning is a note for the report, such as a pinned tag that no longer
+// exists upstream, or "" when there is nothing to add.
+func (s *Service) checkImageForUpdates(ctx context.Context, serviceKey string, image types.DockerImage, updatesChan chan<- types.ImageUpdate, upToDateChan chan<- string, errorsChan chan<- string) (warning string) {
+	serviceName := strings.Split(serviceKey, ":")[0]
+
+	original := image
+	if aliased, ok := types.ApplyRepositoryAliases(image, s.aliases); ok {
+		s.logger.Debug("Applied repository alias", "image", original.String(), "upstream", aliased.String())
+		image = aliased
+	}
+
+	s.logger.Debug("Checking image for updates", "service", serviceName, "image", image.String())
+
+	// Find appropriate registry client
+	client := s.clientFor(image)
 
 	if client == nil {
-		errMsg := fmt.Sprintf("no registry client available for %s (registry: %s)", image.String(), image.Registry)
-		errorsChan <- errMsg
-		s.logger.Warn("No registry client available", "image", image.String(), "registry", image.Registry)
+		scanErr := s.noClientError(image)
+		errorsChan <- scanErr.Error()
+		s.logger.Warn("No registry client available",
+			"image", image.String(),
+			"registry", image.Registry,
+			"category", scanErr.Category,
+			"suggestion", scanErr.Suggestion)
+		return
+	}
+
+	if s.digestChangesOnly {
+		s.checkDigestDrift(ctx, client, serviceName, original, image, updatesChan, upToDateChan, errorsChan)
+		return
+	}
+
+	// Rolling families (distroless, chainguard...) tag by channel, not version:
+	// comparing tags would only produce noise such as "latest -> nonroot"
+	if matchesRepository(image, s.rolling) {
+		s.logger.Debug("Rolling image, skipping version comparison", "service", serviceName, "image", image.String())
+		s.checkDigestDrift(ctx, client, serviceName, original, image, updatesChan, upToDateChan, errorsChan)
 		return
 	}
 
-	// Get latest tags from registry
-	tags, err := client.GetLatestTags(ctx, image)
+	// Get tags and their metadata from registry. Version selection only needs
+	// the names; the metadata is there for filters that rely on it.
+	tagInfos, err := client.GetTags(ctx, image)
 	if err != nil {
+		if errors.IsType(err, errors.ErrAuthenticationError) {
+			scanErr := s.authError(image, client, err)
+			errorsChan <- scanErr.Error()
+			s.logger.Warn("Registry requires credentials",
+				"image", image.String(),
+				"registry", image.Registry,
+				"category", scanErr.Category,
+				"suggestion", scanErr.Suggestion)
+			return
+		}
 		errMsg := fmt.Sprintf("getting tags for %s: %v", image.String(), err)
 		errorsChan <- errMsg
 		s.logger.Error("Failed to get tags", "image", image.String(), "error", err)
 		return
 	}
+	// Checked against the full list: the cap below may drop an old pinned tag
+	warning = s.danglingTagWarning(serviceName, image, tagInfos)
+	if limited := utils.LimitTags(tagInfos, s.maxTags); len(limited) != len(tagInfos) {
+		s.logger.Debug("Limited tags considered", "image", image.String(), "fetched", len(tagInfos), "kept", len(limited))
+		tagInfos = limited
+	}
+	tags := types.TagNames(tagInfos)
 
 	if len(tags) == 0 {
 		errMsg := fmt.Sprintf("no tags found for %s", image.String())
@@ -273,6 +1009,132 @@ func (s *Service) checkImageForUpdates(ctx context.Context, serviceKey string, i
 		return
 	}
 
+	// A digest-only reference has no version to compare until the digest is
+	// matched to a tag; when none matches it is compared as "latest".
+	if image.DigestOnly {
+		if tag, ok := s.resolveDigestTag(ctx, client, image, tags); ok {
+			s.logger.Debug("Resolved digest to tag", "image", image.String(), "digest", image.Digest, "tag", tag)
+			image.Tag, image.DigestOnly = tag, false
+			original.Tag, original.DigestOnly = tag, false
+		} else {
+			s.logger.Warn("Digest not found among upstream tags", "image", image.String(), "digest", image.Digest)
+		}
+	}
+
+	// With the Windows hint, release tags (ltsc2022, 20H2, 1809) are ordered
+	// by OS build. They bypass the pre-release and family filters, which
+	// would drop them ("servercore" contains "rc") or treat 1809 as a number.
+	windows := s.targetOS == utils.TargetOSWindows && utils.IsWindowsReleaseTag(image.Tag)
+	var latestTag string
+	var tagsToUse []string
+	if windows {
+		tagsToUse = tags
+		latestTag = utils.FindWindowsUpdateTag(image.Tag, tags)
+	} else {
+		latestTag, tagsToUse = s.selectLatestTag(image, tags)
+	}
+
+	// Compare versions
+	codename := !windows && s.codenames.IsCodename(image.Tag)
+	updateType := types.UpdateTypeNone
+	switch {
+	case latestTag == "":
+	case windows:
+		updateType = utils.CompareWindowsVersions(image.Tag, latestTag)
+	case codename:
+		updateType = s.codenames.Compare(image.Tag, latestTag)
+	default:
+		updateType = utils.CompareVersions(image.Tag, latestTag)
+	}
+
+	if updateType == types.UpdateTypeNone {
+		s.checkDigestDrift(ctx, client, serviceName, original, image, updatesChan, upToDateChan, errorsChan)
+		return
+	}
+
+	// Create update record
+	update := types.ImageUpdate{
+		ServiceName:  serviceName,
+		CurrentImage: image,
+		LatestImage: types.DockerImage{
+			Registry:   image.Registry,
+			Repository: image.Repository,
+			Tag:        latestTag,
+		},
+		UpdateType:        updateType,
+		Reason:            utils.ClassifyUpdateReason(image.Tag, latestTag),
+		Priority:          s.isPriority(image),
+		OriginalReference: originalReference(original, image),
+		VersionsBehind:    utils.CountVersionsBehind(image.Tag, latestTag, tagsToUse),
+		UpdatedAt:         tagPublishedAt(tagInfos, latestTag),
+		CurrentSize:       tagSize(tagInfos, image.Tag),
+		LatestSize:        tagSize(tagInfos, latestTag),
+	}
+	update.CurrentRef, update.SuggestedRef = update.CurrentImage.String(), update.LatestImage.String()
+	if s.resolveLatest && image.Tag != "latest" {
+		update.MaintainerLatest = s.resolveLatestAlias(ctx, client, image, tags)
+	}
+	if windows {
+		update.VersionsBehind = utils.CountWindowsReleasesBehind(image.Tag, latestTag, tagsToUse)
+	}
+	if s.maxUpdateType != "" && !windows {
+		var recommended string
+		if codename {
+			recommended = s.codenames.FindRecommendedUpdate(image.Tag, tagsToUse, s.maxUpdateType)
+		} else {
+			recommended = utils.FindRecommendedUpdateTag(image.Tag, tagsToUse, s.maxUpdateType)
+		}
+		if recommended != "" {
+			update.RecommendedImage = &types.DockerImage{
+				Registry:   image.Registry,
+				Repository: image.Repository,
+				Tag:        recommended,
+			}
+		}
+	}
+
+	updatesChan <- update
+	s.logger.Info("Update available",
+		"service", serviceName,
+		"current", image.Tag,
+		"latest", latestTag,
+		"type", updateType)
+	return warning
+}
+
+// danglingTagWarning reports a pinned tag missing from the registry's tags,
+// e.g. a yanked version or a deleted mutable tag, which would otherwise be
+// compared silently against the newest version. Digest-pinned images and
+// channel tags (latest, stable, bookworm...) are not checked.
+func (s *Service) danglingTagWarning(serviceName string, image types.DockerImage, tags []types.TagInfo) string {
+	if image.Digest != "" || len(tags) == 0 || utils.ClassifyTagFamily(image.Tag) == utils.TagFamilyCustom {
+		return ""
+	}
+	for _, tag := range tags {
+		if tag.Name == image.Tag {
+			return ""
+		}
+	}
+
+	s.logger.Warn("Current tag not found in registry", "service", serviceName, "image", image.String())
+	return fmt.Sprintf("service %s: current tag %s not found in registry for %s", serviceName, image.Tag, image.Repository)
+}
+
+// selectLatestTag picks the update candidate for image among tags, keeping
+// its suffix as the suffix policy requires. It returns "" when the image is
+// already on the newest version, along with the tags it chose from.
+func (s *Service) selectLatestTag(image types.DockerImage, tags []string) (string, []string) {
+	// An inline "# image-reporter: allow=..." constraint narrows the candidates
+	if image.Constraint != "" {
+		allowed, err := utils.FilterTagsByConstraint(tags, image.Constraint)
+		if err != nil {
+			s.logger.Warn("Ignoring invalid version constraint", "image", image.String(), "error", err)
+		} else {
+			s.logger.Debug("Filtered tags by constraint", "image", image.String(), "constraint", image.Constraint, "original_count", len(tags), "filtered_count", len(allowed))
+			tags = allowed
+		}
+	}
+
 	// Filter and sort tags to find the latest stable version
 	stableTags := utils.FilterPreReleases(tags)
 	if len(stableTags) == 0 {
@@ -280,56 +1142,238 @@ func (s *Service) checkImageForUpdates(ctx context.Context, serviceKey string, i
 		stableTags = tags
 	}
 
-	// Filter by suffix if the current image has one (e.g., -alpine, -slim)
-	suffixFilteredTags := utils.FilterTagsBySuffix(stableTags, image.Tag)
-	tagsToUse := suffixFilteredTags
-	if len(suffixFilteredTags) == 0 {
-		// No compatible tags found for image with suffix, use all stable tags
-		s.logger.Debug("No suffix-compatible updates found, falling back to all stable tags", "image", image.String())
-		tagsToUse = stableTags
-	} else if len(suffixFilteredTags) != len(stableTags) {
-		s.logger.Debug("Filtered tags by suffix", "image", image.String(), "original_count", len(stableTags), "filtered_count", len(suffixFilteredTags))
+	// Filter by suffix if the current image has one (e.g., -alpine, -slim),
+	// as strictly as the configured suffix policy requires
+	tagsToUse := utils.FilterTagsBySuffixPolicy(stableTags, image.Tag, s.suffixPolicy)
+	if len(tagsToUse) != len(stableTags) {
+		s.logger.Debug("Filtered tags by suffix", "image", image.String(), "policy", s.suffixPolicy, "original_count", len(stableTags), "filtered_count", len(tagsToUse))
+	}
+
+	if s.strictSemver {
+		precise := utils.FilterTagsByPrecision(tagsToUse, image.Tag)
+		s.logger.Debug("Filtered tags by precision", "image", image.String(), "original_count", len(tagsToUse), "filtered_count", len(precise))
+		tagsToUse = precise
 	}
 
 	// Choose the best candidate tag considering semver and suffix preference.
 	// FindBestUpdateTag returns "" when no update is found (current is already
 	// the latest in its variant/family). Do not fall back to SortVersions here
 	// because it bypasses variant filtering and causes false positives (e.g.
-	// suggesting "5.1.4-lt2-2" as an update for "5.1.4-2").
-	latestTag := utils.FindBestUpdateTag(image.Tag, tagsToUse)
-	if latestTag == "" {
+	// suggesting "5.1.4-lt2-2" as an update for "5.1.4-2"). Distro codenames
+	// are ordered through the configured codename map instead.
+	var latestTag string
+	if s.codenames.IsCodename(image.Tag) {
+		latestTag = s.codenames.FindUpdate(image.Tag, tagsToUse)
+	} else {
+		latestTag = utils.FindBestUpdateTag(image.Tag, tagsToUse)
+	}
+
+	// A candidate that only changes the suffix of the same version (e.g.
+	// 1.20.0 -> 1.20.0-alpine) is never an update by itself, whichever tag
+	// the selection surfaced, unless suffix policy "any" opts into migrating
+	// between suffixes.
+	if latestTag != "" && s.suffixPolicy != utils.SuffixPolicyAny && utils.IsSuffixOnlyChange(image.Tag, latestTag) {
+		s.logger.Debug("Ignoring suffix-only change", "image", image.String(), "candidate", latestTag)
+		latestTag = ""
+	}
+
+	return latestTag, tagsToUse
+}
+
+// digestLookupLimit bounds the registry digest lookups spent matching a
+// digest-only reference to a tag
+const digestLookupLimit = 25
+
+// resolveDigestTag finds the tag whose digest is image.Digest, checking the
+// newest semantic versions first and, within a version, the most specific tag
+// (1.20.0 before 1.20). ok is false when the client cannot resolve digests or
+// none of the first digestLookupLimit candidates matches: the digest may be
+// an old build of a tag that has since moved.
+func (s *Service) resolveDigestTag(ctx context.Context, client types.RegistryClient, image types.DockerImage, tags []string) (string, bool) {
+	resolver, ok := client.(types.DigestResolver)
+	if !ok {
+		return "", false
+	}
+
+	candidates := utils.SortVersionsBySpecificity(tags)
+	if len(candidates) > digestLookupLimit {
+		candidates = candidates[:digestLookupLimit]
+	}
+	for _, tag := range candidates {
+		candidate := image
+		candidate.Tag = tag
+		digest, err := resolver.GetDigest(ctx, candidate)
+		if errors.IsType(err, errors.ErrUnsupported) {
+			return "", false
+		}
+		if err != nil {
+			s.logger.Debug("Failed to get digest", "image", candidate.String(), "error", err)
+			continue
+		}
+		if digest == image.Digest {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// resolveLatestAlias returns the version tag the registry's latest tag points
+// to, or "" when the image has no latest tag or its digest matches none of
+// the checked versions
+func (s *Service) resolveLatestAlias(ctx context.Context, client types.RegistryClient, image types.DockerImage, tags []string) string {
+	resolver, ok := client.(types.DigestResolver)
+	if !ok || !slices.Contains(tags, "latest") {
+		return ""
+	}
+
+	latest := image
+	latest.Tag = "latest"
+	digest, err := resolver.GetDigest(ctx, latest)
+	if err != nil {
+		s.logger.Debug("Failed to get digest", "image", latest.String(), "error", err)
+		return ""
+	}
+	latest.Digest = digest
+
+	tag, ok := s.resolveDigestTag(ctx, client, latest, tags)
+	if !ok {
+		s.logger.Debug("Latest tag matches no version tag", "image", latest.String(), "digest", digest)
+		return ""
+	}
+	return tag
+}
+
+// tagPublishedAt returns when the registry last pushed tag, or the zero time
+// when the client does not report tag timestamps.
+func tagPublishedAt(tagInfos []types.TagInfo, tag string) time.Time {
+	for _, info := range tagInfos {
+		if info.Name == tag {
+			return info.LastUpdated
+		}
+	}
+	return time.Time{}
+}
+
+// tagSize returns the image size the registry reported for tag, or 0 when
+// the client does not report sizes.
+func tagSize(tagInfos []types.TagInfo, tag string) int64 {
+	for _, info := range tagInfos {
+		if info.Name == tag {
+			return info.Size
+		}
+	}
+	return 0
+}
+
+// digestPinnedImages keeps the images pinned by both tag and digest, the only
+// ones whose digest can drift. A digest-only reference is immutable.
+func (s *Service) digestPinnedImages(images map[string]types.DockerImage) map[string]types.DockerImage {
+	pinned := make(map[string]types.DockerImage, len(images))
+	for key, image := range images {
+		if image.Digest == "" || image.DigestOnly {
+			s.logger.Debug("No pinned digest, skipping image", "image", image.String())
+			continue
+		}
+		pinned[key] = image
+	}
+	return pinned
+}
+
+// checkDigestDrift handles an image with no newer version. When the image is
+// pinned by tag and digest, the pinned digest is compared against the digest
+// the tag currently resolves to; a mismatch means the tag was rebuilt and is
+// reported as a digest update. Otherwise the service is up to date. original
+// is the reference as written in the file, before repository aliases.
+func (s *Service) checkDigestDrift(ctx context.Context, client types.RegistryClient, serviceName string, original, image types.DockerImage, updatesChan chan<- types.ImageUpdate, upToDateChan chan<- string, errorsChan chan<- string) {
+	resolver, ok := client.(types.DigestResolver)
+	if image.Digest == "" || !ok {
 		upToDateChan <- serviceName
 		s.logger.Debug("Image is up to date", "service", serviceName, "image", image.String())
 		return
 	}
 
-	// Compare versions
-	updateType := utils.CompareVersions(image.Tag, latestTag)
-
-	if updateType == types.UpdateTypeNone {
+	currentDigest, err := resolver.GetDigest(ctx, image)
+	if errors.IsType(err, errors.ErrUnsupported) {
 		upToDateChan <- serviceName
 		s.logger.Debug("Image is up to date", "service", serviceName, "image", image.String())
 		return
 	}
+	if err != nil {
+		errorsChan <- fmt.Sprintf("getting digest for %s: %v", image.String(), err)
+		s.logger.Error("Failed to get digest", "image", image.String(), "error", err)
+		return
+	}
+
+	if currentDigest == image.Digest {
+		upToDateChan <- serviceName
+		s.logger.Debug("Pinned digest is current", "service", serviceName, "image", image.String())
+		return
+	}
 
-	// Create update record
 	update := types.ImageUpdate{
 		ServiceName:  serviceName,
 		CurrentImage: image,
 		LatestImage: types.DockerImage{
 			Registry:   image.Registry,
 			Repository: image.Repository,
-			Tag:        latestTag,
+			Tag:        image.Tag,
+			Digest:     currentDigest,
 		},
-		UpdateType: updateType,
+		UpdateType:        types.UpdateTypeDigest,
+		Reason:            types.UpdateReasonDigest,
+		Priority:          s.isPriority(image),
+		OriginalReference: originalReference(original, image),
 	}
-
+	update.CurrentRef, update.SuggestedRef = update.CurrentImage.String(), update.LatestImage.String()
 	updatesChan <- update
-	s.logger.Info("Update available",
+	s.logger.Info("Pinned digest is stale for tag",
 		"service", serviceName,
-		"current", image.Tag,
-		"latest", latestTag,
-		"type", updateType)
+		"tag", image.Tag,
+		"pinned", image.Digest,
+		"current", currentDigest)
+}
+
+// isPriority reports whether the image repository matches any priority glob.
+func (s *Service) isPriority(image types.DockerImage) bool {
+	return matchesRepository(image, s.priority)
+}
+
+// matchesRepository reports whether the image repository matches any glob.
+// Patterns are matched against the repository, the registry-qualified
+// repository and, for Docker Hub official images only, the repository without
+// the "library/" prefix.
+func matchesRepository(image types.DockerImage, patterns []string) bool {
+	candidates := []string{
+		image.Repository,
+		image.Registry + "/" + image.Repository,
+	}
+	if image.Registry == "docker.io" {
+		if short, ok := strings.CutPrefix(image.Repository, "library/"); ok {
+			candidates = append(candidates, short)
+		}
+	}
+
+	for _, pattern := range patterns {
+		for _, candidate := range candidates {
+			if matched, err := path.Match(pattern, candidate); err == nil && matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// projectName returns the project name declared by the first compose file
+// that has one, when the parser supports it, or the name of the scan path
+func (s *Service) projectName(path string, files []string) string {
+	if namer, ok := s.parser.(types.ProjectNamer); ok {
+		for _, file := range files {
+			if name := namer.ProjectName(file); name != "" {
+				return name
+			}
+		}
+	}
+	return s.getProjectName(path)
 }
 
 // getProjectName determines a meaningful project name from the scan path
@@ -345,6 +1389,57 @@ func (s *Service) getProjectName(path string) string {
 	return filepath.Base(path)
 }
 
+// registryKeys are the config keys of a registry host: enable turns on its
+// dedicated client (empty when the generic client serves it) and token holds
+// its credentials.
+type registryKeys struct {
+	enable string
+	token  string
+}
+
+// registryConfigKeys maps well-known registry hosts to their config keys. The
+// Quay and GitLab tokens only take effect once the matching client is enabled.
+var registryConfigKeys = map[string]registryKeys{
+	"ghcr.io":             {token: "registry.ghcr.token"},
+	"docker.io":           {token: "registry.dockerhub.token"},
+	"quay.io":             {enable: "registry.quay.enabled", token: "registry.quay.token"},
+	"registry.gitlab.com": {enable: "registry.gitlab.enabled", token: "registry.gitlab.token"},
+}
+
+// configKeysFor returns the config keys of host, treating the configured
+// self-managed GitLab host like registry.gitlab.com
+func (s *Service) configKeysFor(host string) (registryKeys, bool) {
+	if s.gitlabHost != "" && host == s.gitlabHost {
+		host = "registry.gitlab.com"
+	}
+	keys, ok := registryConfigKeys[host]
+	return keys, ok
+}
+
+// noClientError builds the no_client scan error for an image whose registry
+// none of the configured clients can handle, suggesting how to fix it. The
+// CLI always registers the generic client, which handles every host, so this
+// only happens when a Service is built without it.
+func (s *Service) noClientError(image types.DockerImage) *types.ScanError {
+	host := strings.ToLower(image.Registry)
+
+	suggestion := fmt.Sprintf("register the generic registry client, which handles any OCI registry such as %s", host)
+	if keys, ok := s.configKeysFor(host); ok {
+		if keys.enable != "" {
+			suggestion = fmt.Sprintf("enable the %s client with `icr config set %s true`", host, keys.enable)
+		} else {
+			suggestion = fmt.Sprintf("register the generic registry client for %s and set credentials with `icr config set %s <value>`", host, keys.token)
+		}
+	}
+
+	return &types.ScanError{
+		Category:   types.ScanErrorNoClient,
+		Image:      image.String(),
+		Message:    fmt.Sprintf("no registry client available for %s (registry: %s)", image.String(), image.Registry),
+		Suggestion: suggestion,
+	}
+}
+
 // canHandleRegistry checks if a registry client can handle the given registry
 func (s *Service) canHandleRegistry(client types.RegistryClient, registry string) bool {
 	clientName := strings.ToLower(client.Name())
@@ -353,7 +1448,34 @@ func (s *Service) canHandleRegistry(client types.RegistryClient, registry string
 	switch clientName {
 	case "generic":
 		return true
+	case "gitlab":
+		return registryLower == "gitlab.com" || strings.HasSuffix(registryLower, ".gitlab.com") ||
+			(s.gitlabHost != "" && registryLower == s.gitlabHost)
 	default:
 		return clientName == registryLower || (clientName == "docker.io" && registryLower == "")
 	}
 }
+
+// authError builds the auth_required scan error for an image whose registry
+// rejected the tag listing through client, pointing at where its credentials
+// are configured and, when the generic client answered for a host with its own
+// client, how to enable it.
+func (s *Service) authError(image types.DockerImage, client types.RegistryClient, err error) *types.ScanError {
+	host := strings.ToLower(image.Registry)
+
+	suggestion := fmt.Sprintf("log in with `docker login %s`; credentials from ~/.docker/config.json are used for any registry", host)
+	if keys, ok := s.configKeysFor(host); ok {
+		if keys.enable != "" && client.Name() == "generic" {
+			suggestion = fmt.Sprintf("enable the %s client with `icr config set %s true` and set credentials with `icr config set %s <value>`", host, keys.enable, keys.token)
+		} else {
+			suggestion = fmt.Sprintf("set credentials for %s with `icr config set %s <value>` or `docker login`", host, keys.token)
+		}
+	}
+
+	return &types.ScanError{
+		Category:   types.ScanErrorAuth,
+		Image:      image.String(),
+		Message:    fmt.Sprintf("%s requires credentials for %s: %v", image.Registry, image.String(), err),
+		Suggestion: suggestion,
+	}
+}
@@ -1,16 +1,27 @@
 package scanner
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/user/docker-image-reporter/internal/clock"
 	"github.com/user/docker-image-reporter/internal/compose"
+	apperrors "github.com/user/docker-image-reporter/pkg/errors"
 	"github.com/user/docker-image-reporter/pkg/types"
+	"github.com/user/docker-image-reporter/pkg/utils"
 )
 
 // Mock implementations for testing
@@ -26,6 +37,14 @@ func (m *mockRegistryClient) Name() string {
 }
 
 func (m *mockRegistryClient) GetLatestTags(ctx context.Context, image types.DockerImage) ([]string, error) {
+	tags, err := m.GetTags(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	return types.TagNames(tags), nil
+}
+
+func (m *mockRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
 	if m.delay > 0 {
 		select {
 		case <-time.After(m.delay):
@@ -38,7 +57,11 @@ func (m *mockRegistryClient) GetLatestTags(ctx context.Context, image types.Dock
 		return nil, m.err
 	}
 
-	return m.tags, nil
+	tags := make([]types.TagInfo, 0, len(m.tags))
+	for _, tag := range m.tags {
+		tags = append(tags, types.TagInfo{Name: tag})
+	}
+	return tags, nil
 }
 
 func (m *mockRegistryClient) GetImageInfo(ctx context.Context, image types.DockerImage) (*types.ImageInfo, error) {
@@ -153,6 +176,7 @@ func TestService_checkImageForUpdates(t *testing.T) {
 		registryTags   []string
 		registryError  error
 		expectUpdate   bool
+		expectReason   types.UpdateReason
 		expectUpToDate bool
 		expectError    bool
 	}{
@@ -165,6 +189,7 @@ func TestService_checkImageForUpdates(t *testing.T) {
 			},
 			registryTags:   []string{"1.22", "1.21", "1.20"},
 			expectUpdate:   true,
+			expectReason:   types.UpdateReasonSemver,
 			expectUpToDate: false,
 			expectError:    false,
 		},
@@ -180,6 +205,18 @@ func TestService_checkImageForUpdates(t *testing.T) {
 			expectUpToDate: true,
 			expectError:    false,
 		},
+		{
+			name: "floating major tag is up to date",
+			image: types.DockerImage{
+				Registry:   "docker.io",
+				Repository: "postgres",
+				Tag:        "19",
+			},
+			registryTags:   []string{"18", "19", "19.1", "19.1.2"},
+			expectUpdate:   false,
+			expectUpToDate: true,
+			expectError:    false,
+		},
 		{
 			name: "registry error",
 			image: types.DockerImage{
@@ -248,6 +285,9 @@ func TestService_checkImageForUpdates(t *testing.T) {
 			if tt.expectUpdate && len(updates) == 0 {
 				t.Error("Expected update but got none")
 			}
+			if tt.expectReason != "" && len(updates) > 0 && updates[0].Reason != tt.expectReason {
+				t.Errorf("Reason = %s, want %s", updates[0].Reason, tt.expectReason)
+			}
 			if !tt.expectUpdate && len(updates) > 0 {
 				t.Errorf("Expected no update but got %d", len(updates))
 			}
@@ -271,7 +311,7 @@ func TestService_checkImageForUpdates(t *testing.T) {
 
 func TestService_canHandleRegistry(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	service := NewService(nil, nil, logger)
+	service := NewService(nil, nil, logger).WithGitLabHost("registry.example.com")
 
 	tests := []struct {
 		name       string
@@ -297,6 +337,36 @@ func TestService_canHandleRegistry(t *testing.T) {
 			registry:   "ghcr.io",
 			expected:   true,
 		},
+		{
+			name:       "quay client handles quay.io",
+			clientName: "quay.io",
+			registry:   "quay.io",
+			expected:   true,
+		},
+		{
+			name:       "quay client cannot handle docker.io",
+			clientName: "quay.io",
+			registry:   "",
+			expected:   false,
+		},
+		{
+			name:       "gitlab client handles registry.gitlab.com",
+			clientName: "gitlab",
+			registry:   "registry.gitlab.com",
+			expected:   true,
+		},
+		{
+			name:       "gitlab client handles the configured self-hosted host",
+			clientName: "gitlab",
+			registry:   "Registry.Example.com",
+			expected:   true,
+		},
+		{
+			name:       "gitlab client cannot handle lookalike hosts",
+			clientName: "gitlab",
+			registry:   "notgitlab.com",
+			expected:   false,
+		},
 		{
 			name:       "docker.io client cannot handle ghcr.io",
 			clientName: "docker.io",
@@ -376,7 +446,7 @@ func TestService_ConcurrencyControl(t *testing.T) {
 	defer cancel()
 
 	start := time.Now()
-	updates, upToDate, errors := service.checkForUpdates(ctx, images, config)
+	updates, upToDate, errors, _, _ := service.checkForUpdates(ctx, images, config)
 	duration := time.Since(start)
 
 	// With 20 images, 100ms delay each, and max concurrency of 5,
@@ -418,7 +488,7 @@ func TestService_ContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 	defer cancel()
 
-	updates, upToDate, errors := service.checkForUpdates(ctx, images, config)
+	updates, upToDate, errors, _, _ := service.checkForUpdates(ctx, images, config)
 
 	// Should have been cancelled
 	totalResults := len(updates) + len(upToDate) + len(errors)
@@ -426,3 +496,1689 @@ func TestService_ContextCancellation(t *testing.T) {
 		t.Errorf("Expected cancellation to limit results, got %d total results", totalResults)
 	}
 }
+
+func TestService_ScanImages_UsesClock(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	service := NewService(compose.NewParser(), nil, logger).WithClock(clock.NewFake(now))
+
+	result, err := service.ScanImages(context.Background(), nil, "test")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+
+	if !result.ScanTimestamp.Equal(now) {
+		t.Errorf("ScanTimestamp = %v, want %v", result.ScanTimestamp, now)
+	}
+}
+
+// mockDigestRegistryClient is a mockRegistryClient that also resolves digests
+type mockDigestRegistryClient struct {
+	mockRegistryClient
+	digest    string
+	digestErr error
+}
+
+func (m *mockDigestRegistryClient) GetDigest(ctx context.Context, image types.DockerImage) (string, error) {
+	return m.digest, m.digestErr
+}
+
+func TestService_checkImageForUpdates_DigestDrift(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const (
+		pinned  = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		rebuilt = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	tests := []struct {
+		name           string
+		image          types.DockerImage
+		remoteDigest   string
+		digestErr      error
+		expectDigest   bool
+		expectUpToDate bool
+		expectError    bool
+	}{
+		{
+			name:         "pinned digest is stale",
+			image:        types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25", Digest: pinned},
+			remoteDigest: rebuilt,
+			expectDigest: true,
+		},
+		{
+			name:           "pinned digest is current",
+			image:          types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25", Digest: pinned},
+			remoteDigest:   pinned,
+			expectUpToDate: true,
+		},
+		{
+			name:           "not pinned by digest",
+			image:          types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+			remoteDigest:   rebuilt,
+			expectUpToDate: true,
+		},
+		{
+			name:        "digest lookup fails",
+			image:       types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25", Digest: pinned},
+			digestErr:   errors.New("manifest unknown"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &mockDigestRegistryClient{
+				mockRegistryClient: mockRegistryClient{name: "generic", tags: []string{"1.24", "1.25"}},
+				digest:             tt.remoteDigest,
+				digestErr:          tt.digestErr,
+			}
+			service := NewService(nil, []types.RegistryClient{registry}, logger)
+
+			updatesChan := make(chan types.ImageUpdate, 1)
+			upToDateChan := make(chan string, 1)
+			errorsChan := make(chan string, 1)
+
+			service.checkImageForUpdates(context.Background(), "web:"+tt.image.String(), tt.image,
+				updatesChan, upToDateChan, errorsChan)
+
+			close(updatesChan)
+			close(upToDateChan)
+			close(errorsChan)
+
+			var updates []types.ImageUpdate
+			for update := range updatesChan {
+				updates = append(updates, update)
+			}
+
+			if tt.expectDigest {
+				if len(updates) != 1 {
+					t.Fatalf("Expected 1 digest update, got %d", len(updates))
+				}
+				update := updates[0]
+				if update.UpdateType != types.UpdateTypeDigest {
+					t.Errorf("UpdateType = %s, want %s", update.UpdateType, types.UpdateTypeDigest)
+				}
+				if update.Reason != types.UpdateReasonDigest {
+					t.Errorf("Reason = %s, want %s", update.Reason, types.UpdateReasonDigest)
+				}
+				if update.LatestImage.Tag != tt.image.Tag {
+					t.Errorf("LatestImage.Tag = %s, want %s", update.LatestImage.Tag, tt.image.Tag)
+				}
+				if update.LatestImage.Digest != tt.remoteDigest {
+					t.Errorf("LatestImage.Digest = %s, want %s", update.LatestImage.Digest, tt.remoteDigest)
+				}
+			} else if len(updates) > 0 {
+				t.Errorf("Expected no update but got %d", len(updates))
+			}
+
+			if got := countStrings(upToDateChan); (got > 0) != tt.expectUpToDate {
+				t.Errorf("up-to-date = %v, want %v", got > 0, tt.expectUpToDate)
+			}
+			if got := countStrings(errorsChan); (got > 0) != tt.expectError {
+				t.Errorf("errors = %d, expectError %v", got, tt.expectError)
+			}
+		})
+	}
+}
+
+func countStrings(ch <-chan string) int {
+	count := 0
+	for range ch {
+		count++
+	}
+	return count
+}
+
+func TestService_isPriority(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewService(nil, nil, logger).WithPriority([]string{"*/openssl", "nginx", "ghcr.io/org/*"})
+
+	tests := []struct {
+		name  string
+		image types.DockerImage
+		want  bool
+	}{
+		{"namespace glob", types.DockerImage{Registry: "docker.io", Repository: "acme/openssl"}, true},
+		{"official image by short name", types.DockerImage{Registry: "docker.io", Repository: "library/nginx"}, true},
+		{"registry qualified glob", types.DockerImage{Registry: "ghcr.io", Repository: "org/app"}, true},
+		{"glob does not cross segments", types.DockerImage{Registry: "docker.io", Repository: "a/b/openssl"}, false},
+		{"no match", types.DockerImage{Registry: "docker.io", Repository: "library/redis"}, false},
+		{"library/ is only special on docker.io", types.DockerImage{Registry: "myregistry.com", Repository: "library/nginx"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := service.isPriority(tt.image); got != tt.want {
+				t.Errorf("isPriority(%s) = %v, want %v", tt.image.FullName(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ScanImages_PriorityFirst(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "generic", tags: []string{"1.0.0", "2.0.0"}}
+	service := NewService(nil, []types.RegistryClient{registry}, logger).WithPriority([]string{"*/openssl"})
+
+	images := []types.DockerImage{
+		{Registry: "docker.io", Repository: "library/nginx", Tag: "1.0.0", ServiceName: "web"},
+		{Registry: "docker.io", Repository: "library/redis", Tag: "1.0.0", ServiceName: "cache"},
+		{Registry: "docker.io", Repository: "acme/openssl", Tag: "1.0.0", ServiceName: "tls"},
+		{Registry: "docker.io", Repository: "library/postgres", Tag: "1.0.0", ServiceName: "db"},
+	}
+
+	result, err := service.ScanImages(context.Background(), images, "test")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+
+	if len(result.UpdatesAvailable) != len(images) {
+		t.Fatalf("Expected %d updates, got %d", len(images), len(result.UpdatesAvailable))
+	}
+
+	first := result.UpdatesAvailable[0]
+	if first.ServiceName != "tls" || !first.Priority {
+		t.Errorf("First update = %s (priority %v), want tls marked as priority", first.ServiceName, first.Priority)
+	}
+	for _, update := range result.UpdatesAvailable[1:] {
+		if update.Priority {
+			t.Errorf("Update %s unexpectedly marked as priority", update.ServiceName)
+		}
+	}
+}
+
+func TestService_checkImageForUpdates_NoClient(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name           string
+		image          types.DockerImage
+		wantSuggestion string
+	}{
+		{
+			name:           "ghcr.io without a client",
+			image:          types.DockerImage{Registry: "ghcr.io", Repository: "org/app", Tag: "1.0.0"},
+			wantSuggestion: "`icr config set registry.ghcr.token <value>`",
+		},
+		{
+			name:           "quay.io without its client",
+			image:          types.DockerImage{Registry: "quay.io", Repository: "org/app", Tag: "1.0.0"},
+			wantSuggestion: "`icr config set registry.quay.enabled true`",
+		},
+		{
+			name:           "self-managed GitLab host",
+			image:          types.DockerImage{Registry: "registry.example.org", Repository: "group/app", Tag: "1.0.0"},
+			wantSuggestion: "`icr config set registry.gitlab.enabled true`",
+		},
+		{
+			name:           "unknown host",
+			image:          types.DockerImage{Registry: "registry.example.com", Repository: "team/app", Tag: "1.0.0"},
+			wantSuggestion: "handles any OCI registry such as registry.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only Docker Hub has a client: without the generic client every
+			// other host ends up as no_client
+			registry := &mockRegistryClient{name: "docker.io", tags: []string{"1.0.0"}}
+			service := NewService(nil, []types.RegistryClient{registry}, logger).WithGitLabHost("registry.example.org")
+
+			updatesChan := make(chan types.ImageUpdate, 1)
+			upToDateChan := make(chan string, 1)
+			errorsChan := make(chan string, 1)
+
+			service.checkImageForUpdates(context.Background(), "app:"+tt.image.String(), tt.image,
+				updatesChan, upToDateChan, errorsChan)
+			close(errorsChan)
+
+			var msgs []string
+			for msg := range errorsChan {
+				msgs = append(msgs, msg)
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 error, got %v", msgs)
+			}
+			if !strings.Contains(msgs[0], "no registry client available") || !strings.Contains(msgs[0], tt.wantSuggestion) {
+				t.Errorf("error = %q, want suggestion %q", msgs[0], tt.wantSuggestion)
+			}
+
+			scanErr := service.noClientError(tt.image)
+			if scanErr.Category != types.ScanErrorNoClient {
+				t.Errorf("Category = %s, want %s", scanErr.Category, types.ScanErrorNoClient)
+			}
+		})
+	}
+}
+
+func TestService_ScanDirectory_RegistryFromComposeNeedsAuth(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	dir := t.TempDir()
+	composeYAML := "services:\n  app:\n    image: ghcr.io/org/private:1.0.0\n  web:\n    image: ghcr.io/org/public:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(composeYAML), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	// No ghcr.io-specific client is configured: the generic client serves it
+	registry := &authRegistryClient{
+		mockRegistryClient: mockRegistryClient{name: "generic", tags: []string{"1.0.0", "1.1.0"}},
+		private:            "org/private",
+	}
+	service := NewService(compose.NewParser(), []types.RegistryClient{registry}, logger)
+
+	result, err := service.ScanDirectory(context.Background(), dir, DefaultConfig())
+	if err != nil {
+		t.Fatalf("ScanDirectory() error = %v", err)
+	}
+
+	if len(result.UpdatesAvailable) != 1 || result.UpdatesAvailable[0].ServiceName != "web" {
+		t.Errorf("UpdatesAvailable = %v, want the public image only", result.UpdatesAvailable)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("Errors = %v, want 1", result.Errors)
+	}
+	want := "`icr config set registry.ghcr.token <value>`"
+	if !strings.Contains(result.Errors[0], "requires credentials") || !strings.Contains(result.Errors[0], want) {
+		t.Errorf("error = %q, want auth suggestion %q", result.Errors[0], want)
+	}
+}
+
+func TestService_authError_Suggestion(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewService(nil, nil, logger)
+	image := types.DockerImage{Registry: "quay.io", Repository: "org/private", Tag: "1.0.0"}
+
+	tests := []struct {
+		name   string
+		client string
+		want   string
+		reject string
+	}{
+		// The Quay token has no effect until the Quay client is enabled
+		{name: "generic client", client: "generic", want: "`icr config set registry.quay.enabled true` and set credentials with `icr config set registry.quay.token <value>`"},
+		{name: "quay client", client: "quay.io", want: "`icr config set registry.quay.token <value>`", reject: "registry.quay.enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanErr := service.authError(image, &mockRegistryClient{name: tt.client}, apperrors.ErrAuthenticationError)
+			if scanErr.Category != types.ScanErrorAuth {
+				t.Errorf("Category = %s, want %s", scanErr.Category, types.ScanErrorAuth)
+			}
+			if !strings.Contains(scanErr.Suggestion, tt.want) {
+				t.Errorf("Suggestion = %q, want %q", scanErr.Suggestion, tt.want)
+			}
+			if tt.reject != "" && strings.Contains(scanErr.Suggestion, tt.reject) {
+				t.Errorf("Suggestion = %q, should not mention %q", scanErr.Suggestion, tt.reject)
+			}
+		})
+	}
+}
+
+func TestService_ScanDirectory_AutoRegistries(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	dir := t.TempDir()
+	composeYAML := "services:\n  app:\n    image: quay.io/org/app:1.0.0\n  web:\n    image: nginx:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(composeYAML), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	// The generic client sees no update; the Quay client, once enabled, does
+	generic := &mockRegistryClient{name: "generic", tags: []string{"1.0.0"}}
+	quay := &mockRegistryClient{name: "quay.io", tags: []string{"1.0.0", "1.1.0"}}
+	gitlab := &mockRegistryClient{name: "gitlab", tags: []string{"1.0.0", "2.0.0"}}
+	service := NewService(compose.NewParser(), []types.RegistryClient{generic}, logger).
+		WithAutoRegistries([]AutoRegistry{{Client: quay, Anonymous: true}, {Client: gitlab}})
+
+	result, err := service.ScanDirectory(context.Background(), dir, DefaultConfig())
+	if err != nil {
+		t.Fatalf("ScanDirectory() error = %v", err)
+	}
+
+	if len(result.UpdatesAvailable) != 1 || result.UpdatesAvailable[0].LatestImage.Tag != "1.1.0" {
+		t.Fatalf("UpdatesAvailable = %+v, want app 1.1.0 from the Quay client", result.UpdatesAvailable)
+	}
+	// Only the client of a registry found in the compose files is enabled
+	if clients := service.clients(); len(clients) != 2 || clients[0] != quay {
+		t.Errorf("clients = %v, want the Quay client ahead of the generic one", clients)
+	}
+	if !strings.Contains(logs.String(), "without credentials") || !strings.Contains(logs.String(), "registry=quay.io") {
+		t.Errorf("expected a warning about missing Quay credentials, got:\n%s", logs.String())
+	}
+}
+
+// authRegistryClient rejects the private repository as unauthorized
+type authRegistryClient struct {
+	mockRegistryClient
+	private string
+}
+
+func (m *authRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	if image.Repository == m.private {
+		return nil, fmt.Errorf("%w: UNAUTHORIZED", apperrors.ErrAuthenticationError)
+	}
+	return m.mockRegistryClient.GetTags(ctx, image)
+}
+
+// mockPingRegistryClient is a mockRegistryClient that also answers preflight pings
+type mockPingRegistryClient struct {
+	mockRegistryClient
+	down  map[string]bool
+	calls atomic.Int32
+}
+
+func (m *mockPingRegistryClient) Ping(ctx context.Context, registry string) error {
+	if m.down[registry] {
+		return errors.New("dial tcp: connection refused")
+	}
+	return nil
+}
+
+func (m *mockPingRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	m.calls.Add(1)
+	return m.mockRegistryClient.GetTags(ctx, image)
+}
+
+func TestService_ScanImages_PreflightUnreachable(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	images := []types.DockerImage{
+		{Registry: "ghcr.io", Repository: "org/api", Tag: "1.0.0", ServiceName: "api"},
+		{Registry: "ghcr.io", Repository: "org/worker", Tag: "1.0.0", ServiceName: "worker"},
+		{Registry: "docker.io", Repository: "library/nginx", Tag: "1.0.0", ServiceName: "web"},
+	}
+
+	tests := []struct {
+		name        string
+		preflight   time.Duration
+		wantErrors  int
+		wantUpdates int
+		wantCalls   int32
+	}{
+		{name: "preflight enabled", preflight: time.Second, wantErrors: 1, wantUpdates: 1, wantCalls: 1},
+		{name: "preflight disabled", preflight: 0, wantErrors: 0, wantUpdates: 3, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &mockPingRegistryClient{
+				mockRegistryClient: mockRegistryClient{name: "generic", tags: []string{"1.0.0", "2.0.0"}},
+				down:               map[string]bool{"ghcr.io": true},
+			}
+			service := NewService(nil, []types.RegistryClient{registry}, logger).WithPreflight(tt.preflight)
+
+			result, err := service.ScanImages(context.Background(), images, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+
+			if len(result.Errors) != tt.wantErrors {
+				t.Fatalf("Errors = %v, want %d", result.Errors, tt.wantErrors)
+			}
+			if tt.wantErrors > 0 {
+				msg := result.Errors[0]
+				if !strings.Contains(msg, "registry unreachable: ghcr.io") || !strings.Contains(msg, "2 images skipped") {
+					t.Errorf("unexpected error message: %s", msg)
+				}
+			}
+			if len(result.UpdatesAvailable) != tt.wantUpdates {
+				t.Errorf("Expected %d updates, got %d", tt.wantUpdates, len(result.UpdatesAvailable))
+			}
+			if got := registry.calls.Load(); got != tt.wantCalls {
+				t.Errorf("GetLatestTags called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// recordingRegistryClient is a mockRegistryClient that records the queried images
+type recordingRegistryClient struct {
+	mockRegistryClient
+	mu      sync.Mutex
+	queried []string
+}
+
+func (m *recordingRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	m.mu.Lock()
+	m.queried = append(m.queried, image.FullName())
+	m.mu.Unlock()
+	return m.mockRegistryClient.GetTags(ctx, image)
+}
+
+func TestService_ScanImages_RepositoryAliases(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &recordingRegistryClient{mockRegistryClient: mockRegistryClient{name: "generic", tags: []string{"1.25", "1.26"}}}
+	service := NewService(nil, []types.RegistryClient{registry}, logger).
+		WithRepositoryAliases(map[string]string{"internal.example.com/mirror/": "docker.io/"})
+
+	images := []types.DockerImage{{
+		Registry:    "internal.example.com",
+		Repository:  "mirror/nginx",
+		Tag:         "1.25",
+		ServiceName: "web",
+		ComposeFile: "/srv/web/docker-compose.yml",
+	}}
+
+	result, err := service.ScanImages(context.Background(), images, "test")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+
+	if len(registry.queried) != 1 || registry.queried[0] != "docker.io/library/nginx:1.25" {
+		t.Errorf("queried %v, want the upstream docker.io/library/nginx:1.25", registry.queried)
+	}
+	if len(result.UpdatesAvailable) != 1 {
+		t.Fatalf("Expected 1 update, got %d", len(result.UpdatesAvailable))
+	}
+
+	update := result.UpdatesAvailable[0]
+	if update.CurrentImage.Registry != "docker.io" || update.LatestImage.Repository != "library/nginx" {
+		t.Errorf("update reports %s -> %s, want the upstream", update.CurrentImage.FullName(), update.LatestImage.FullName())
+	}
+	if update.OriginalReference != "internal.example.com/mirror/nginx:1.25" {
+		t.Errorf("OriginalReference = %q, want the mirror reference", update.OriginalReference)
+	}
+	if update.CurrentImage.ComposeFile != "/srv/web/docker-compose.yml" {
+		t.Errorf("ComposeFile = %q, want the original file", update.CurrentImage.ComposeFile)
+	}
+}
+
+func TestService_ScanImages_RollingImages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const (
+		pinned  = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		rebuilt = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	images := []types.DockerImage{
+		// Pinned by digest: a rebuilt tag is reported as a digest update
+		{Registry: "gcr.io", Repository: "distroless/static-debian12", Tag: "nonroot", Digest: pinned, ServiceName: "api"},
+		// Not pinned: nothing to compare, so it is up to date
+		{Registry: "gcr.io", Repository: "distroless/base", Tag: "latest", ServiceName: "worker"},
+	}
+
+	registry := &mockDigestRegistryClient{
+		mockRegistryClient: mockRegistryClient{name: "generic", tags: []string{"latest", "nonroot", "debug", "debug-nonroot", "1.0.0"}},
+		digest:             rebuilt,
+	}
+	service := NewService(nil, []types.RegistryClient{registry}, logger).
+		WithRollingImages([]string{"gcr.io/distroless/*"})
+
+	result, err := service.ScanImages(context.Background(), images, "test")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+
+	if len(result.UpdatesAvailable) != 1 {
+		t.Fatalf("Expected 1 update, got %d: %+v", len(result.UpdatesAvailable), result.UpdatesAvailable)
+	}
+	update := result.UpdatesAvailable[0]
+	if update.ServiceName != "api" || update.UpdateType != types.UpdateTypeDigest {
+		t.Errorf("update = %s [%s], want api [%s]", update.ServiceName, update.UpdateType, types.UpdateTypeDigest)
+	}
+	if update.LatestImage.Tag != "nonroot" {
+		t.Errorf("LatestImage.Tag = %s, want the same channel tag", update.LatestImage.Tag)
+	}
+	if len(result.UpToDateServices) != 1 || result.UpToDateServices[0] != "worker" {
+		t.Errorf("UpToDateServices = %v, want [worker]", result.UpToDateServices)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", result.Errors)
+	}
+}
+
+func TestService_ScanDirectory_RegistryTimeouts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	dir := t.TempDir()
+	composeYAML := "services:\n  app:\n    image: slow.example.com/team/app:1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(composeYAML), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.RegistryTimeout = 20 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		timeouts map[string]time.Duration
+		wantErr  bool
+	}{
+		{name: "global timeout is too short", wantErr: true},
+		{name: "slow registry gets a longer timeout", timeouts: map[string]time.Duration{"slow.example.com": time.Second}},
+		{name: "override for another host", timeouts: map[string]time.Duration{"other.example.com": time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &mockRegistryClient{name: "generic", tags: []string{"1.0.0", "1.1.0"}, delay: 100 * time.Millisecond}
+			service := NewService(compose.NewParser(), []types.RegistryClient{registry}, logger).
+				WithRegistryTimeouts(tt.timeouts)
+
+			result, err := service.ScanDirectory(context.Background(), dir, config)
+			if err != nil {
+				t.Fatalf("ScanDirectory() error = %v", err)
+			}
+			if tt.wantErr {
+				if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "deadline exceeded") {
+					t.Errorf("Errors = %v, want a deadline error", result.Errors)
+				}
+				return
+			}
+			if len(result.Errors) != 0 || len(result.UpdatesAvailable) != 1 {
+				t.Errorf("updates = %v, errors = %v, want one update", result.UpdatesAvailable, result.Errors)
+			}
+		})
+	}
+}
+
+func TestService_ScanImages_SuffixPolicy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"1.24-alpine", "1.25-alpine", "1.26", "1.27"}}
+
+	tests := []struct {
+		name       string
+		policy     utils.SuffixPolicy
+		current    string
+		wantLatest string // "" = up to date
+	}{
+		{name: "strict keeps alpine", policy: utils.SuffixPolicyStrict, current: "1.24-alpine", wantLatest: "1.25-alpine"},
+		{name: "strict without matching suffix", policy: utils.SuffixPolicyStrict, current: "1.24-slim"},
+		{name: "prefer keeps alpine", policy: utils.SuffixPolicyPrefer, current: "1.24-alpine", wantLatest: "1.25-alpine"},
+		{name: "prefer falls back without matching suffix", policy: utils.SuffixPolicyPrefer, current: "1.24-slim", wantLatest: "1.27"},
+		{name: "default behaves like prefer", current: "1.24-slim", wantLatest: "1.27"},
+		{name: "any ignores alpine", policy: utils.SuffixPolicyAny, current: "1.24-alpine", wantLatest: "1.27"},
+		{name: "any without matching suffix", policy: utils.SuffixPolicyAny, current: "1.24-slim", wantLatest: "1.27"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, []types.RegistryClient{registry}, logger).WithSuffixPolicy(tt.policy)
+			images := []types.DockerImage{{Registry: "docker.io", Repository: "library/nginx", Tag: tt.current, ServiceName: "web"}}
+
+			result, err := service.ScanImages(context.Background(), images, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+
+			if tt.wantLatest == "" {
+				if len(result.UpdatesAvailable) != 0 {
+					t.Errorf("expected no update, got %s", result.UpdatesAvailable[0].LatestImage.Tag)
+				}
+				return
+			}
+			if len(result.UpdatesAvailable) != 1 {
+				t.Fatalf("Expected 1 update, got %d", len(result.UpdatesAvailable))
+			}
+			if got := result.UpdatesAvailable[0].LatestImage.Tag; got != tt.wantLatest {
+				t.Errorf("LatestImage.Tag = %s, want %s", got, tt.wantLatest)
+			}
+		})
+	}
+}
+
+func TestService_ScanImages_SameVersionOtherSuffix(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name    string
+		current string
+		tags    []string
+		policy  utils.SuffixPolicy
+	}{
+		{name: "plain to alpine", current: "1.20.0", tags: []string{"1.20.0", "1.20.0-alpine"}},
+		{name: "alpine to plain", current: "1.20.0-alpine", tags: []string{"1.20.0", "1.20.0-alpine"}},
+		{name: "alpine to plain without alpine tags", current: "1.20.0-alpine", tags: []string{"1.20.0"}},
+		{name: "alpine to slim", current: "1.20.0-alpine", tags: []string{"1.20.0-slim"}},
+		{name: "alpine to plain with any policy", current: "1.20.0-alpine", tags: []string{"1.20.0", "1.20.0-alpine"}, policy: utils.SuffixPolicyAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &mockRegistryClient{name: "docker.io", tags: tt.tags}
+			service := NewService(nil, []types.RegistryClient{registry}, logger).WithSuffixPolicy(tt.policy)
+			images := []types.DockerImage{{Registry: "docker.io", Repository: "library/nginx", Tag: tt.current, ServiceName: "web"}}
+
+			result, err := service.ScanImages(context.Background(), images, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+			if len(result.UpdatesAvailable) != 0 {
+				t.Errorf("expected no update, got %s", result.UpdatesAvailable[0].LatestImage.Tag)
+			}
+			if len(result.UpToDateServices) != 1 {
+				t.Errorf("UpToDateServices = %v, want [web]", result.UpToDateServices)
+			}
+		})
+	}
+}
+
+func TestService_ScanImages_RecommendedImage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"14.10", "15.4", "15.5", "15.6", "16.0", "16.2", "17.1"}}
+
+	tests := []struct {
+		name            string
+		maxUpdateType   types.UpdateType
+		current         string
+		wantRecommended string // "" = sin RecommendedImage
+	}{
+		{name: "minor max", maxUpdateType: types.UpdateTypeMinor, current: "15.4", wantRecommended: "15.6"},
+		{name: "no update within minor max", maxUpdateType: types.UpdateTypeMinor, current: "15.6"},
+		{name: "no policy", current: "15.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, []types.RegistryClient{registry}, logger).WithMaxUpdateType(tt.maxUpdateType)
+			images := []types.DockerImage{{Registry: "docker.io", Repository: "library/postgres", Tag: tt.current, ServiceName: "db"}}
+
+			result, err := service.ScanImages(context.Background(), images, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+			if len(result.UpdatesAvailable) != 1 {
+				t.Fatalf("Expected 1 update, got %d", len(result.UpdatesAvailable))
+			}
+
+			update := result.UpdatesAvailable[0]
+			if update.LatestImage.Tag != "17.1" || update.UpdateType != types.UpdateTypeMajor {
+				t.Errorf("LatestImage = %s [%s], want 17.1 [%s]", update.LatestImage.Tag, update.UpdateType, types.UpdateTypeMajor)
+			}
+			if tt.wantRecommended == "" {
+				if update.RecommendedImage != nil {
+					t.Errorf("RecommendedImage = %s, want nil", update.RecommendedImage.Tag)
+				}
+				return
+			}
+			if update.RecommendedImage == nil || update.RecommendedImage.Tag != tt.wantRecommended {
+				t.Fatalf("RecommendedImage = %+v, want tag %s", update.RecommendedImage, tt.wantRecommended)
+			}
+			if update.RecommendedImage.Repository != "library/postgres" {
+				t.Errorf("RecommendedImage.Repository = %s, want library/postgres", update.RecommendedImage.Repository)
+			}
+		})
+	}
+}
+
+func TestService_ScanImages_References(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "generic", tags: []string{"1.20", "1.21"}}
+
+	tests := []struct {
+		name          string
+		image         types.DockerImage
+		wantCurrent   string
+		wantSuggested string
+	}{
+		{
+			name:          "docker hub",
+			image:         types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.20", ServiceName: "web"},
+			wantCurrent:   "library/nginx:1.20",
+			wantSuggested: "library/nginx:1.21",
+		},
+		{
+			name:          "other registry",
+			image:         types.DockerImage{Registry: "ghcr.io", Repository: "org/app", Tag: "1.20", ServiceName: "app"},
+			wantCurrent:   "ghcr.io/org/app:1.20",
+			wantSuggested: "ghcr.io/org/app:1.21",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, []types.RegistryClient{registry}, logger)
+			result, err := service.ScanImages(context.Background(), []types.DockerImage{tt.image}, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+			if len(result.UpdatesAvailable) != 1 {
+				t.Fatalf("Expected 1 update, got %d", len(result.UpdatesAvailable))
+			}
+
+			update := result.UpdatesAvailable[0]
+			if update.CurrentRef != tt.wantCurrent {
+				t.Errorf("CurrentRef = %q, want %q", update.CurrentRef, tt.wantCurrent)
+			}
+			if update.SuggestedRef != tt.wantSuggested {
+				t.Errorf("SuggestedRef = %q, want %q", update.SuggestedRef, tt.wantSuggested)
+			}
+		})
+	}
+}
+
+// tagDigestRegistryClient is a mockRegistryClient serving a digest per tag
+type tagDigestRegistryClient struct {
+	mockRegistryClient
+	digests map[string]string
+}
+
+func (m *tagDigestRegistryClient) GetDigest(ctx context.Context, image types.DockerImage) (string, error) {
+	return m.digests[image.Tag], nil
+}
+
+func TestService_ScanImages_LatestAlias(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &tagDigestRegistryClient{
+		mockRegistryClient: mockRegistryClient{name: "docker.io", tags: []string{"1.20", "1.22", "1.25", "latest"}},
+		digests: map[string]string{
+			"1.20":   "sha256:120",
+			"1.22":   "sha256:122",
+			"1.25":   "sha256:125",
+			"latest": "sha256:122",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		want    string
+	}{
+		{name: "enabled", enabled: true, want: "1.22"},
+		{name: "disabled", enabled: false, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, []types.RegistryClient{registry}, logger).WithLatestAlias(tt.enabled)
+			images := []types.DockerImage{{Registry: "docker.io", Repository: "library/nginx", Tag: "1.20", ServiceName: "web"}}
+
+			result, err := service.ScanImages(context.Background(), images, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+			if len(result.UpdatesAvailable) != 1 {
+				t.Fatalf("Expected 1 update, got %d", len(result.UpdatesAvailable))
+			}
+
+			update := result.UpdatesAvailable[0]
+			if update.LatestImage.Tag != "1.25" {
+				t.Errorf("LatestImage.Tag = %s, want 1.25", update.LatestImage.Tag)
+			}
+			if update.MaintainerLatest != tt.want {
+				t.Errorf("MaintainerLatest = %q, want %q", update.MaintainerLatest, tt.want)
+			}
+			if tt.enabled && update.LatestAliasText() != "maintainer's latest = 1.22; newest published = 1.25" {
+				t.Errorf("LatestAliasText() = %q", update.LatestAliasText())
+			}
+		})
+	}
+}
+
+// peakRegistryClient is a mockRegistryClient that records the peak number of
+// concurrent GetLatestTags calls
+type peakRegistryClient struct {
+	mockRegistryClient
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (m *peakRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	n := m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+	for {
+		peak := m.peak.Load()
+		if n <= peak || m.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	return m.mockRegistryClient.GetTags(ctx, image)
+}
+
+func TestService_ScanImagesWithConfig_MaxConcurrency(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var images []types.DockerImage
+	for i := 0; i < 8; i++ {
+		images = append(images, types.DockerImage{
+			Registry: "docker.io", Repository: fmt.Sprintf("org/app%d", i), Tag: "1.0.0", ServiceName: fmt.Sprintf("svc%d", i),
+		})
+	}
+
+	tests := []struct {
+		name        string
+		concurrency int
+		wantPeak    int32
+	}{
+		{name: "serialized", concurrency: 1, wantPeak: 1},
+		{name: "bounded", concurrency: 3, wantPeak: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &peakRegistryClient{
+				mockRegistryClient: mockRegistryClient{name: "generic", tags: []string{"1.0.0"}, delay: 20 * time.Millisecond},
+			}
+			service := NewService(nil, []types.RegistryClient{registry}, logger)
+
+			config := DefaultConfig()
+			config.MaxConcurrency = tt.concurrency
+			result, err := service.ScanImagesWithConfig(context.Background(), images, "test", config)
+			if err != nil {
+				t.Fatalf("ScanImagesWithConfig() error = %v", err)
+			}
+
+			if got := registry.peak.Load(); got != tt.wantPeak {
+				t.Errorf("peak concurrent checks = %d, want %d", got, tt.wantPeak)
+			}
+			if len(result.UpToDateServices) != len(images) {
+				t.Errorf("Expected %d up-to-date services, got %d", len(images), len(result.UpToDateServices))
+			}
+		})
+	}
+}
+
+func TestConfig_concurrency(t *testing.T) {
+	procs := runtime.GOMAXPROCS(0)
+
+	tests := []struct {
+		name   string
+		config Config
+		images int
+		want   int
+	}{
+		{name: "explicit", config: Config{MaxConcurrency: 7}, images: 100, want: 7},
+		{name: "auto small scan", config: Config{MaxConcurrency: AutoConcurrency}, images: 1, want: 1},
+		{name: "auto no images", config: Config{MaxConcurrency: AutoConcurrency}, images: 0, want: 1},
+		{name: "auto large scan", config: Config{MaxConcurrency: AutoConcurrency}, images: 10000, want: 4 * procs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.concurrency(tt.images); got != tt.want {
+				t.Errorf("concurrency(%d) = %d, want %d", tt.images, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ScanImages_RecordsLookups(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"1.0.0", "1.1.0"}, delay: 20 * time.Millisecond}
+	service := NewService(nil, []types.RegistryClient{registry}, logger)
+
+	images := []types.DockerImage{
+		{Registry: "docker.io", Repository: "library/nginx", Tag: "1.0.0", ServiceName: "web"},
+		{Registry: "docker.io", Repository: "library/redis", Tag: "1.1.0", ServiceName: "cache"},
+		{Registry: "quay.io", Repository: "org/app", Tag: "1.0.0", ServiceName: "app"},
+	}
+
+	result, err := service.ScanImages(context.Background(), images, "test")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+
+	if len(result.Lookups) != len(images) {
+		t.Fatalf("Expected %d lookups, got %d: %+v", len(images), len(result.Lookups), result.Lookups)
+	}
+
+	// Lookups are sorted by service: app, cache, web
+	want := []struct {
+		service, registry, client string
+		delayed                   bool
+	}{
+		{service: "app", registry: "quay.io", client: ""},
+		{service: "cache", registry: "docker.io", client: "docker.io", delayed: true},
+		{service: "web", registry: "docker.io", client: "docker.io", delayed: true},
+	}
+	for i, w := range want {
+		lookup := result.Lookups[i]
+		if lookup.Service != w.service || lookup.Registry != w.registry || lookup.Client != w.client {
+			t.Errorf("lookup[%d] = %+v, want service %s registry %s client %q", i, lookup, w.service, w.registry, w.client)
+		}
+		if w.delayed && lookup.Latency < 20*time.Millisecond {
+			t.Errorf("lookup[%d] latency = %s, want at least the registry delay", i, lookup.Latency)
+		}
+	}
+}
+
+// mockRateLimitRegistryClient reports a fixed rate limit
+type mockRateLimitRegistryClient struct {
+	mockRegistryClient
+	limits []types.RateLimit
+}
+
+func (m *mockRateLimitRegistryClient) RateLimits() []types.RateLimit {
+	return m.limits
+}
+
+func TestService_ScanImages_ReportsRateLimits(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	limit := types.RateLimit{Registry: "index.docker.io", Limit: 100, Remaining: 42}
+	registry := &mockRateLimitRegistryClient{
+		mockRegistryClient: mockRegistryClient{name: "docker.io", tags: []string{"1.0.0"}},
+		limits:             []types.RateLimit{limit},
+	}
+	service := NewService(nil, []types.RegistryClient{registry}, logger)
+
+	images := []types.DockerImage{
+		{Registry: "docker.io", Repository: "library/nginx", Tag: "1.0.0", ServiceName: "web"},
+	}
+	result, err := service.ScanImages(context.Background(), images, "test")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+
+	if len(result.RateLimits) != 1 || result.RateLimits[0] != limit {
+		t.Errorf("RateLimits = %+v, want [%+v]", result.RateLimits, limit)
+	}
+}
+
+func TestService_ScanDirectory_OverrideNotes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"docker-compose.yml": "services:\n" +
+			"  web:\n    image: nginx:1.20\n" +
+			"  db:\n    image: postgres:15\n",
+		"docker-compose.override.yml": "services:\n" +
+			"  web:\n    image: nginx:1.25\n" +
+			"  db:\n    image: postgres:15\n" +
+			"  debug:\n    image: busybox:1.36\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"1.20", "1.25", "15", "1.36"}}
+	service := NewService(compose.NewParser(), []types.RegistryClient{registry}, logger)
+
+	result, err := service.ScanDirectory(context.Background(), dir, DefaultConfig())
+	if err != nil {
+		t.Fatalf("ScanDirectory() error = %v", err)
+	}
+
+	// Only web changes image; db is identical and debug only exists in the override
+	want := "service web: override changes image from library/nginx:1.20 to library/nginx:1.25"
+	if len(result.Notes) != 1 || result.Notes[0] != want {
+		t.Errorf("Notes = %q, want [%q]", result.Notes, want)
+	}
+}
+
+func TestService_ScanDirectory_MalformedServicesContinues(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"broken/docker-compose.yml": "services:\n  - web:\n      image: nginx:1.20\n",
+		"app/docker-compose.yml":    "services:\n  db:\n    image: postgres:15\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("creating %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"15"}}
+	service := NewService(compose.NewParser(), []types.RegistryClient{registry}, logger)
+
+	result, err := service.ScanDirectory(context.Background(), dir, DefaultConfig())
+	if err != nil {
+		t.Fatalf("ScanDirectory() error = %v", err)
+	}
+
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "services must be a mapping of service name to config") {
+		t.Errorf("Errors = %q, want one malformed services error", result.Errors)
+	}
+	if len(result.UpToDateServices) != 1 {
+		t.Errorf("UpToDateServices = %v, want the db service from the valid file", result.UpToDateServices)
+	}
+}
+
+func TestService_ScanImages_DanglingTag(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"1.19", "1.21", "latest"}}
+
+	tests := []struct {
+		name  string
+		image types.DockerImage
+		want  []string
+	}{
+		{
+			name:  "tag missing upstream",
+			image: types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.20", ServiceName: "web"},
+			want:  []string{"service web: current tag 1.20 not found in registry for library/nginx"},
+		},
+		{
+			name:  "tag present",
+			image: types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.19", ServiceName: "web"},
+		},
+		{
+			name:  "digest pinned",
+			image: types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.20", Digest: "sha256:abc", ServiceName: "web"},
+		},
+		{
+			name:  "channel tag",
+			image: types.DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "stable", ServiceName: "web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, []types.RegistryClient{registry}, logger)
+			result, err := service.ScanImages(context.Background(), []types.DockerImage{tt.image}, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+			if !slices.Equal(result.Notes, tt.want) {
+				t.Errorf("Notes = %q, want %q", result.Notes, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ScanImages_Constraint(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"1.20.0", "1.20.4", "1.21.0", "1.25.1"}}
+
+	tests := []struct {
+		name       string
+		constraint string
+		wantLatest string // "" = up to date
+	}{
+		{name: "no constraint", wantLatest: "1.25.1"},
+		{name: "patch line only", constraint: "~1.20", wantLatest: "1.20.4"},
+		{name: "nothing newer allowed", constraint: "<=1.20.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, []types.RegistryClient{registry}, logger)
+			images := []types.DockerImage{{Registry: "docker.io", Repository: "library/nginx", Tag: "1.20.0", ServiceName: "web", Constraint: tt.constraint}}
+
+			result, err := service.ScanImages(context.Background(), images, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+			if tt.wantLatest == "" {
+				if len(result.UpdatesAvailable) != 0 {
+					t.Errorf("expected no update, got %s", result.UpdatesAvailable[0].LatestImage.Tag)
+				}
+				return
+			}
+			if len(result.UpdatesAvailable) != 1 || result.UpdatesAvailable[0].LatestImage.Tag != tt.wantLatest {
+				t.Errorf("UpdatesAvailable = %+v, want latest %s", result.UpdatesAvailable, tt.wantLatest)
+			}
+		})
+	}
+}
+
+func TestOverrideBase(t *testing.T) {
+	tests := []struct {
+		file   string
+		base   string
+		isOver bool
+	}{
+		{file: "/srv/docker-compose.override.yml", base: "/srv/docker-compose.yml", isOver: true},
+		{file: "/srv/compose.override.yaml", base: "/srv/compose.yaml", isOver: true},
+		{file: "/srv/docker-compose.yml"},
+		{file: "/srv/docker-compose.prod.yml"},
+		{file: "/srv/.override.yml"},
+	}
+
+	for _, tt := range tests {
+		base, ok := overrideBase(tt.file)
+		if ok != tt.isOver || base != tt.base {
+			t.Errorf("overrideBase(%s) = %q, %t; want %q, %t", tt.file, base, ok, tt.base, tt.isOver)
+		}
+	}
+}
+
+func TestService_ScanImages_WindowsTargetOS(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "mcr.microsoft.com", tags: []string{
+		"ltsc2019", "ltsc2022", "1809", "1909", "20H2",
+		"windowsservercore-ltsc2019", "windowsservercore-ltsc2022",
+	}}
+
+	tests := []struct {
+		name       string
+		target     utils.TargetOS
+		current    string
+		wantLatest string // "" = up to date
+		wantBehind int
+	}{
+		{name: "ltsc year", target: utils.TargetOSWindows, current: "ltsc2019", wantLatest: "ltsc2022", wantBehind: 3},
+		{name: "build number to half-year release", target: utils.TargetOSWindows, current: "1809", wantLatest: "ltsc2022", wantBehind: 3},
+		{name: "servercore is not a pre-release", target: utils.TargetOSWindows, current: "windowsservercore-ltsc2019", wantLatest: "windowsservercore-ltsc2022", wantBehind: 1},
+		{name: "newest release is up to date", target: utils.TargetOSWindows, current: "ltsc2022"},
+		// Without the hint 1809 is a plain number: 1909 is newer, 20H2 and
+		// ltsc2022 are not comparable
+		{name: "without hint", current: "1809", wantLatest: "1909", wantBehind: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, []types.RegistryClient{registry}, logger).WithTargetOS(tt.target)
+			images := []types.DockerImage{{Registry: "mcr.microsoft.com", Repository: "windows/servercore", Tag: tt.current, ServiceName: "app"}}
+
+			result, err := service.ScanImages(context.Background(), images, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+
+			if tt.wantLatest == "" {
+				if len(result.UpdatesAvailable) != 0 {
+					t.Errorf("expected no update, got %s", result.UpdatesAvailable[0].LatestImage.Tag)
+				}
+				return
+			}
+			if len(result.UpdatesAvailable) != 1 {
+				t.Fatalf("Expected 1 update, got %d", len(result.UpdatesAvailable))
+			}
+			update := result.UpdatesAvailable[0]
+			if update.LatestImage.Tag != tt.wantLatest || update.UpdateType != types.UpdateTypeMajor {
+				t.Errorf("update = %s [%s], want %s [major]", update.LatestImage.Tag, update.UpdateType, tt.wantLatest)
+			}
+			if update.VersionsBehind != tt.wantBehind {
+				t.Errorf("VersionsBehind = %d, want %d", update.VersionsBehind, tt.wantBehind)
+			}
+		})
+	}
+}
+
+func TestService_ScanImages_CustomCodenames(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"vanessa", "wilma", "latest"}}
+	images := []types.DockerImage{{Registry: "docker.io", Repository: "linuxmintd/mint", Tag: "vanessa", ServiceName: "app"}}
+
+	// Without the codenames the tags are not comparable
+	result, err := NewService(nil, []types.RegistryClient{registry}, logger).ScanImages(context.Background(), images, "test")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+	if len(result.UpdatesAvailable) != 0 {
+		t.Fatalf("expected no update without codenames, got %s", result.UpdatesAvailable[0].LatestImage.Tag)
+	}
+
+	codenames, err := utils.NewCodenames(map[string]map[string]string{"mint": {"vanessa": "21", "wilma": "22"}})
+	if err != nil {
+		t.Fatalf("NewCodenames() error = %v", err)
+	}
+	service := NewService(nil, []types.RegistryClient{registry}, logger).WithCodenames(codenames)
+	result, err = service.ScanImages(context.Background(), images, "test")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+	if len(result.UpdatesAvailable) != 1 {
+		t.Fatalf("Expected 1 update, got %d", len(result.UpdatesAvailable))
+	}
+	if update := result.UpdatesAvailable[0]; update.LatestImage.Tag != "wilma" || update.UpdateType != types.UpdateTypeMajor {
+		t.Errorf("update = %s [%s], want wilma [major]", update.LatestImage.Tag, update.UpdateType)
+	}
+}
+
+func TestService_ScanImages_DigestChangesOnly(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &tagDigestRegistryClient{
+		mockRegistryClient: mockRegistryClient{name: "docker.io", tags: []string{"1.20", "1.25", "7", "15"}},
+		digests: map[string]string{
+			"1.20": "sha256:rebuilt-nginx",
+			"7":    "sha256:redis",
+			"15":   "sha256:rebuilt-postgres",
+		},
+	}
+	images := []types.DockerImage{
+		{Registry: "docker.io", Repository: "library/nginx", Tag: "1.20", Digest: "sha256:nginx", ServiceName: "web"},
+		{Registry: "docker.io", Repository: "library/redis", Tag: "7", Digest: "sha256:redis", ServiceName: "cache"},
+		{Registry: "docker.io", Repository: "library/postgres", Tag: "15", Digest: "sha256:postgres", ServiceName: "db"},
+		{Registry: "docker.io", Repository: "library/httpd", Tag: "2.4", ServiceName: "proxy"},
+		{Registry: "docker.io", Repository: "library/alpine", Tag: "latest", Digest: "sha256:alpine", DigestOnly: true, ServiceName: "tools"},
+	}
+
+	service := NewService(nil, []types.RegistryClient{registry}, logger).WithDigestChangesOnly(true)
+	result, err := service.ScanImages(context.Background(), images, "test")
+	if err != nil {
+		t.Fatalf("ScanImages() error = %v", err)
+	}
+
+	// nginx has a newer version too, but only the drifted digest is reported
+	var drifted []string
+	for _, update := range result.UpdatesAvailable {
+		if update.UpdateType != types.UpdateTypeDigest {
+			t.Errorf("%s UpdateType = %s, want %s", update.ServiceName, update.UpdateType, types.UpdateTypeDigest)
+		}
+		drifted = append(drifted, update.ServiceName)
+	}
+	slices.Sort(drifted)
+	if want := []string{"db", "web"}; !slices.Equal(drifted, want) {
+		t.Errorf("drifted services = %v, want %v", drifted, want)
+	}
+	if want := []string{"cache"}; !slices.Equal(result.UpToDateServices, want) {
+		t.Errorf("UpToDateServices = %v, want %v", result.UpToDateServices, want)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("unexpected errors: %v", result.Errors)
+	}
+}
+
+func TestService_ScanImages_LocallyBuilt(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"1.0", "2.0"}}
+	images := []types.DockerImage{
+		{Registry: "docker.io", Repository: "myorg/api", Tag: "1.0", ServiceName: "api", Built: true},
+		{Registry: "docker.io", Repository: "myorg/worker", Tag: "1.0", ServiceName: "worker", Built: true},
+		{Registry: "docker.io", Repository: "library/postgres", Tag: "1.0", ServiceName: "db"},
+	}
+
+	tests := []struct {
+		name             string
+		checkBuilt       bool
+		wantUpdates      []string
+		wantLocallyBuilt []string
+	}{
+		{name: "built images skipped by default", wantUpdates: []string{"db"}, wantLocallyBuilt: []string{"api", "worker"}},
+		{name: "built images checked when enabled", checkBuilt: true, wantUpdates: []string{"api", "db", "worker"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, []types.RegistryClient{registry}, logger).WithCheckBuiltImages(tt.checkBuilt)
+			result, err := service.ScanImages(context.Background(), images, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+
+			var updated []string
+			for _, update := range result.UpdatesAvailable {
+				updated = append(updated, update.ServiceName)
+			}
+			slices.Sort(updated)
+			if !slices.Equal(updated, tt.wantUpdates) {
+				t.Errorf("updated services = %v, want %v", updated, tt.wantUpdates)
+			}
+			if !slices.Equal(result.LocallyBuilt, tt.wantLocallyBuilt) {
+				t.Errorf("LocallyBuilt = %v, want %v", result.LocallyBuilt, tt.wantLocallyBuilt)
+			}
+			if result.TotalServicesFound != len(images) {
+				t.Errorf("TotalServicesFound = %d, want %d", result.TotalServicesFound, len(images))
+			}
+		})
+	}
+}
+
+func TestService_ScanDirectory_FilesSummary(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"app/docker-compose.yml": "services:\n" +
+			"  web:\n    image: nginx:1.20\n" +
+			"  worker:\n    build: ./worker\n",
+		"builder/docker-compose.yml": "services:\n" +
+			"  api:\n    build: ./api\n" +
+			"  jobs:\n    build:\n      context: ./jobs\n",
+		"broken/docker-compose.yml": "services:\n  - web:\n      image: nginx:1.20\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("creating %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"1.20"}}
+	service := NewService(compose.NewParser(), []types.RegistryClient{registry}, logger)
+
+	result, err := service.ScanDirectory(context.Background(), dir, DefaultConfig())
+	if err != nil {
+		t.Fatalf("ScanDirectory() error = %v", err)
+	}
+
+	want := map[string]types.FileSummary{
+		"app":     {Services: 2, Images: 1},
+		"builder": {Services: 2, Images: 0},
+		"broken":  {Services: 0, Images: 0},
+	}
+	if len(result.FilesSummary) != len(want) {
+		t.Fatalf("FilesSummary = %+v, want %d files", result.FilesSummary, len(want))
+	}
+	for _, summary := range result.FilesSummary {
+		name := filepath.Base(filepath.Dir(summary.File))
+		expected := want[name]
+		if summary.Services != expected.Services || summary.Images != expected.Images {
+			t.Errorf("%s summary = %+v, want %d services and %d images", name, summary, expected.Services, expected.Images)
+		}
+		if summary.Empty() != (name != "app") {
+			t.Errorf("%s Empty() = %v", name, summary.Empty())
+		}
+		if (summary.Error != "") != (name == "broken") {
+			t.Errorf("%s Error = %q", name, summary.Error)
+		}
+	}
+}
+
+func TestService_ScanImages_StrictSemver(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		tag        string
+		tags       []string
+		strict     bool
+		wantLatest string // "" = up to date
+	}{
+		{name: "major pin lenient pads 18 to 18.0.0", tag: "18", tags: []string{"18.1.2", "18.2.0"}, wantLatest: "18.2.0"},
+		{name: "major pin strict ignores finer tags", tag: "18", tags: []string{"18.1.2", "18.2.0"}, strict: true},
+		{name: "major pin strict moves on its track", tag: "18", tags: []string{"18", "18.2.0", "19"}, strict: true, wantLatest: "19"},
+		{name: "minor pin lenient pads 18.1 to 18.1.0", tag: "18.1", tags: []string{"18.1.2", "18.1.3"}, wantLatest: "18.1.3"},
+		{name: "minor pin strict ignores patch tags", tag: "18.1", tags: []string{"18.1.2", "18.1.3"}, strict: true},
+		{name: "minor pin strict moves on its track", tag: "18.1", tags: []string{"18", "18.1.3", "18.2", "19"}, strict: true, wantLatest: "18.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &mockRegistryClient{name: "docker.io", tags: tt.tags}
+			service := NewService(nil, []types.RegistryClient{registry}, logger).WithStrictSemver(tt.strict)
+			images := []types.DockerImage{{Registry: "docker.io", Repository: "library/node", Tag: tt.tag, ServiceName: "app"}}
+
+			result, err := service.ScanImages(context.Background(), images, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+			if tt.wantLatest == "" {
+				if len(result.UpdatesAvailable) != 0 {
+					t.Errorf("expected no update, got %s", result.UpdatesAvailable[0].LatestImage.Tag)
+				}
+				return
+			}
+			if len(result.UpdatesAvailable) != 1 || result.UpdatesAvailable[0].LatestImage.Tag != tt.wantLatest {
+				t.Errorf("UpdatesAvailable = %+v, want latest %s", result.UpdatesAvailable, tt.wantLatest)
+			}
+		})
+	}
+}
+
+// countingRegistryClient is a mockRegistryClient that records the images it is asked about
+type countingRegistryClient struct {
+	mockRegistryClient
+	mu     sync.Mutex
+	images []string
+}
+
+func (m *countingRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	m.mu.Lock()
+	m.images = append(m.images, image.String())
+	m.mu.Unlock()
+	return m.mockRegistryClient.GetTags(ctx, image)
+}
+
+func TestService_ScanDirectory_UnresolvedVariablesSkipped(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+	content := "services:\n" +
+		"  app:\n    image: ${REGISTRY}/${APP}:${TAG}\n" +
+		"  web:\n    image: nginx:1.20\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(content), 0600); err != nil {
+		t.Fatalf("writing compose file: %v", err)
+	}
+
+	registry := &countingRegistryClient{mockRegistryClient: mockRegistryClient{name: "generic", tags: []string{"1.20"}}}
+	service := NewService(compose.NewParser(), []types.RegistryClient{registry}, logger)
+
+	result, err := service.ScanDirectory(context.Background(), dir, DefaultConfig())
+	if err != nil {
+		t.Fatalf("ScanDirectory() error = %v", err)
+	}
+
+	// Only web reaches the registry; app is skipped before any lookup
+	if !slices.Equal(registry.images, []string{"library/nginx:1.20"}) {
+		t.Errorf("registry lookups = %v, want only library/nginx:1.20", registry.images)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("unexpected errors: %v", result.Errors)
+	}
+}
+
+// limitedRegistryClient fails any lookup that would exceed limit concurrent
+// requests to Docker Hub, like a strict anonymous pull limit, and records the
+// peak concurrency seen per registry
+type limitedRegistryClient struct {
+	mockRegistryClient
+	limit    int
+	limits   []types.RateLimit
+	mu       sync.Mutex
+	inFlight map[string]int
+	peak     map[string]int
+}
+
+func (m *limitedRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	m.mu.Lock()
+	m.inFlight[image.Registry]++
+	m.peak[image.Registry] = max(m.peak[image.Registry], m.inFlight[image.Registry])
+	exceeded := image.Registry == "docker.io" && m.inFlight[image.Registry] > m.limit
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.inFlight[image.Registry]--
+		m.mu.Unlock()
+	}()
+
+	if exceeded {
+		return nil, errors.New("toomanyrequests: rate limit exceeded")
+	}
+	return m.mockRegistryClient.GetTags(ctx, image)
+}
+
+func (m *limitedRegistryClient) RateLimits() []types.RateLimit {
+	return m.limits
+}
+
+func newLimitedRegistryClient(limit int) *limitedRegistryClient {
+	return &limitedRegistryClient{
+		mockRegistryClient: mockRegistryClient{name: "generic", tags: []string{"1.0", "2.0"}, delay: 20 * time.Millisecond},
+		limit:              limit,
+		inFlight:           make(map[string]int),
+		peak:               make(map[string]int),
+	}
+}
+
+func TestService_ScanImages_RegistryConcurrency(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var images []types.DockerImage
+	for i := range 4 {
+		images = append(images,
+			types.DockerImage{Registry: "docker.io", Repository: fmt.Sprintf("library/app%d", i), Tag: "1.0", ServiceName: fmt.Sprintf("hub%d", i)},
+			types.DockerImage{Registry: "ghcr.io", Repository: fmt.Sprintf("org/app%d", i), Tag: "1.0", ServiceName: fmt.Sprintf("ghcr%d", i)},
+		)
+	}
+	config := DefaultConfig()
+	config.MaxConcurrency = 8
+
+	// Without a cap the flat fan-out bursts Docker Hub past its limit
+	registry := newLimitedRegistryClient(1)
+	result, err := NewService(nil, []types.RegistryClient{registry}, logger).ScanImagesWithConfig(context.Background(), images, "test", config)
+	if err != nil {
+		t.Fatalf("ScanImagesWithConfig() error = %v", err)
+	}
+	if len(result.Errors) == 0 {
+		t.Fatal("expected rate limit errors without a per-registry cap")
+	}
+
+	// With docker.io capped at 1, its checks are paced and none fail
+	registry = newLimitedRegistryClient(1)
+	service := NewService(nil, []types.RegistryClient{registry}, logger).
+		WithRegistryConcurrency(map[string]int{"Docker.io": 1})
+	result, err = service.ScanImagesWithConfig(context.Background(), images, "test", config)
+	if err != nil {
+		t.Fatalf("ScanImagesWithConfig() error = %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("unexpected errors with a per-registry cap: %v", result.Errors)
+	}
+	if len(result.UpdatesAvailable) != len(images) {
+		t.Errorf("expected %d updates, got %d", len(images), len(result.UpdatesAvailable))
+	}
+	if registry.peak["docker.io"] != 1 {
+		t.Errorf("peak docker.io concurrency = %d, want 1", registry.peak["docker.io"])
+	}
+	// The other registries are not held back by the cap
+	if registry.peak["ghcr.io"] < 2 {
+		t.Errorf("peak ghcr.io concurrency = %d, want at least 2", registry.peak["ghcr.io"])
+	}
+}
+
+func TestService_ScheduleChecks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := newLimitedRegistryClient(1)
+	registry.limits = []types.RateLimit{
+		{Registry: "registry-1.docker.io", Limit: 100, Remaining: 3},
+		{Registry: "quay.io", Limit: 100, Remaining: 80},
+	}
+	service := NewService(nil, []types.RegistryClient{registry}, logger).
+		WithRegistryConcurrency(map[string]int{"docker.io": 2})
+
+	images := map[string]types.DockerImage{
+		"hub1:nginx":  {Registry: "docker.io", Repository: "library/nginx", Tag: "1.0"},
+		"hub2:redis":  {Registry: "docker.io", Repository: "library/redis", Tag: "1.0"},
+		"hub3:app":    {Registry: "docker.io", Repository: "org/app", Tag: "1.0"},
+		"quay:app":    {Registry: "quay.io", Repository: "org/app", Tag: "1.0"},
+		"ghcr1:app":   {Registry: "ghcr.io", Repository: "org/app", Tag: "1.0"},
+		"ghcr2:other": {Registry: "ghcr.io", Repository: "org/other", Tag: "1.0"},
+	}
+
+	queues := service.scheduleChecks(images, 10)
+
+	// Registries without an advertised limit first, then by remaining budget
+	var order []string
+	workers := map[string]int{}
+	for _, queue := range queues {
+		order = append(order, queue.registry)
+		workers[queue.registry] = queue.workers
+	}
+	if !slices.Equal(order, []string{"ghcr.io", "quay.io", "docker.io"}) {
+		t.Errorf("queue order = %v, want [ghcr.io quay.io docker.io]", order)
+	}
+	// Workers are capped by the registry limit and the number of images
+	if workers["docker.io"] != 2 || workers["ghcr.io"] != 2 || workers["quay.io"] != 1 {
+		t.Errorf("workers = %v, want docker.io:2 ghcr.io:2 quay.io:1", workers)
+	}
+}
+
+func TestService_ScanDirectory_ProjectName(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := &mockRegistryClient{name: "docker.io", tags: []string{"1.20"}}
+	service := NewService(compose.NewParser(), []types.RegistryClient{registry}, logger)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "declared by the compose file",
+			content: "x-image-reporter:\n  project: Media Stack\nservices:\n  web:\n    image: nginx:1.20\n",
+			want:    "Media Stack",
+		},
+		{
+			name:    "directory name by default",
+			content: "services:\n  web:\n    image: nginx:1.20\n",
+			want:    "stack",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "stack")
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				t.Fatalf("creating %s: %v", dir, err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(tt.content), 0600); err != nil {
+				t.Fatalf("writing compose file: %v", err)
+			}
+
+			result, err := service.ScanDirectory(context.Background(), dir, DefaultConfig())
+			if err != nil {
+				t.Fatalf("ScanDirectory() error = %v", err)
+			}
+			if result.ProjectName != tt.want {
+				t.Errorf("ProjectName = %q, want %q", result.ProjectName, tt.want)
+			}
+		})
+	}
+}
+
+// sizedRegistryClient is a mockRegistryClient reporting a size per tag
+type sizedRegistryClient struct {
+	mockRegistryClient
+	sizes map[string]int64
+}
+
+func (m *sizedRegistryClient) GetTags(ctx context.Context, image types.DockerImage) ([]types.TagInfo, error) {
+	tags, err := m.mockRegistryClient.GetTags(ctx, image)
+	for i := range tags {
+		tags[i].Size = m.sizes[tags[i].Name]
+	}
+	return tags, err
+}
+
+func TestService_ScanImages_SizeDelta(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name        string
+		sizes       map[string]int64
+		wantCurrent int64
+		wantLatest  int64
+		wantText    string
+	}{
+		{
+			name:        "both sizes known",
+			sizes:       map[string]int64{"1.24": 60_000_000, "1.25": 102_000_000},
+			wantCurrent: 60_000_000,
+			wantLatest:  102_000_000,
+			wantText:    "+42 MB",
+		},
+		{
+			name:       "current size unknown",
+			sizes:      map[string]int64{"1.25": 102_000_000},
+			wantLatest: 102_000_000,
+		},
+		{
+			name:  "client without sizes",
+			sizes: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &sizedRegistryClient{
+				mockRegistryClient: mockRegistryClient{name: "docker.io", tags: []string{"1.24", "1.25"}},
+				sizes:              tt.sizes,
+			}
+			images := []types.DockerImage{{Registry: "docker.io", Repository: "library/nginx", Tag: "1.24", ServiceName: "web"}}
+
+			result, err := NewService(nil, []types.RegistryClient{registry}, logger).ScanImages(context.Background(), images, "test")
+			if err != nil {
+				t.Fatalf("ScanImages() error = %v", err)
+			}
+			if len(result.UpdatesAvailable) != 1 {
+				t.Fatalf("expected 1 update, got %+v", result.UpdatesAvailable)
+			}
+			update := result.UpdatesAvailable[0]
+			if update.CurrentSize != tt.wantCurrent || update.LatestSize != tt.wantLatest {
+				t.Errorf("sizes = %d -> %d, want %d -> %d", update.CurrentSize, update.LatestSize, tt.wantCurrent, tt.wantLatest)
+			}
+			if got := update.SizeDeltaText(); got != tt.wantText {
+				t.Errorf("SizeDeltaText() = %q, want %q", got, tt.wantText)
+			}
+		})
+	}
+}
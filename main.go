@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -12,9 +13,9 @@ import (
 )
 
 func main() {
-	// Configurar logging estructurado
+	// Configurar logging estructurado; el nivel (info por defecto) lo ajusta -vvv
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: cmd.LogLevel,
 	}))
 	slog.SetDefault(logger)
 
@@ -26,6 +27,11 @@ func main() {
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		cancel()
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
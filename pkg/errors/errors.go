@@ -15,6 +15,7 @@ var (
 	ErrNetworkError        = errors.New("network error")
 	ErrAuthenticationError = errors.New("authentication error")
 	ErrRateLimitExceeded   = errors.New("rate limit exceeded")
+	ErrUnsupported         = errors.New("operation not supported")
 )
 
 // Error representa un error con contexto operacional
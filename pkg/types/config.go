@@ -1,16 +1,148 @@
 package types
 
+import (
+	"strings"
+	"time"
+)
+
 // ScanConfig representa la configuración para el escaneo
 type ScanConfig struct {
 	Recursive bool     `yaml:"recursive" json:"recursive"`
 	Patterns  []string `yaml:"patterns" json:"patterns"`
 	Timeout   int      `yaml:"timeout" json:"timeout"` // en segundos
+	DeepParse bool     `yaml:"deep_parse" json:"deep_parse"`
+	Priority  []string `yaml:"priority,omitempty" json:"priority,omitempty"` // globs de repositorio a destacar
+	// RollingImages son globs de familias de imágenes sin versiones semver
+	// (distroless, chainguard...): solo se comprueba el digest, nunca el tag
+	RollingImages []string `yaml:"rolling_images,omitempty" json:"rolling_images,omitempty"`
+	// SuffixPolicy indica cuánto se respeta el sufijo del tag actual (-alpine,
+	// -slim...) al buscar actualizaciones: strict, prefer (por defecto) o any
+	SuffixPolicy string `yaml:"suffix_policy,omitempty" json:"suffix_policy,omitempty"`
+	// TargetOS activa las convenciones de tags de un sistema operativo:
+	// con windows, ltsc2019 -> ltsc2022 o 1809 -> 20H2 se ordenan por build
+	TargetOS string `yaml:"target_os,omitempty" json:"target_os,omitempty"`
+	// MaxUpdateType limita la versión recomendada a actualizaciones de este tipo
+	// o inferior (patch, minor, major). Vacío = no se calcula recomendación
+	MaxUpdateType string `yaml:"max_update_type,omitempty" json:"max_update_type,omitempty"`
+	// ResolveLatest resuelve a qué versión apunta el tag latest del registro
+	// (por digest) para mostrarla junto a la más nueva publicada
+	ResolveLatest bool `yaml:"resolve_latest,omitempty" json:"resolve_latest,omitempty"`
+	// StrictSemver no completa los tags de una o dos partes ("18" -> 18.0.0):
+	// solo se comparan con tags de la misma precisión, así "18" no se
+	// actualiza a "18.1" ni "18.1" a "18.1.2"
+	StrictSemver bool `yaml:"strict_semver,omitempty" json:"strict_semver,omitempty"`
+	// FollowSymlinks entra en los directorios enlazados al buscar archivos
+	// compose; cada directorio real se recorre una sola vez
+	FollowSymlinks bool `yaml:"follow_symlinks,omitempty" json:"follow_symlinks,omitempty"`
+	// Codenames añade o corrige codenames de distribuciones (distro -> codename
+	// -> versión) a los de debian y ubuntu incluidos, para que p. ej.
+	// bookworm -> trixie se compare como 12 -> 13
+	Codenames map[string]map[string]string `yaml:"codenames,omitempty" json:"codenames,omitempty"`
 }
 
 // RegistryConfig representa la configuración de registros
 type RegistryConfig struct {
 	GHCRToken string `yaml:"ghcr_token" json:"ghcr_token"`
 	Timeout   int    `yaml:"timeout" json:"timeout"` // en segundos
+	// Timeouts sustituye Timeout para registros concretos (host -> segundos),
+	// p. ej. registros propios lentos, sin subirlo para todos los demás
+	Timeouts map[string]int `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
+	// Concurrency limita las imágenes de un registro que se comprueban a la
+	// vez (host -> comprobaciones), por debajo de max_concurrency, para
+	// repartir en el tiempo las peticiones a registros con límite de pulls
+	Concurrency map[string]int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+	// MaxTags limita los tags considerados por imagen tras descargarlos
+	// (0 = sin límite), para acotar memoria y comparaciones en repositorios
+	// con miles de tags
+	MaxTags int `yaml:"max_tags" json:"max_tags"`
+	// RepositoryAliases reescribe prefijos de repositorio (mirrors o copias
+	// internas) hacia su origen canónico antes de consultar el registro
+	RepositoryAliases map[string]string `yaml:"repository_aliases,omitempty" json:"repository_aliases,omitempty"`
+	// TrustedHosts son hosts adicionales a los que se permite seguir los enlaces
+	// de paginación del listado de tags; cualquier otro host se rechaza
+	TrustedHosts []string `yaml:"trusted_hosts,omitempty" json:"trusted_hosts,omitempty"`
+	// DockerHub credenciales opcionales para Docker Hub; autenticarse sube el
+	// límite de pulls al nivel de usuario registrado
+	DockerHub DockerHubConfig `yaml:"dockerhub,omitempty" json:"dockerhub,omitempty"`
+	// Quay activa el cliente de la API de Quay para las imágenes de quay.io
+	Quay QuayConfig `yaml:"quay,omitempty" json:"quay,omitempty"`
+	// GitLab activa el cliente de GitLab Container Registry para gitlab.com y
+	// para un GitLab propio
+	GitLab GitLabConfig `yaml:"gitlab,omitempty" json:"gitlab,omitempty"`
+	// AuthFromEnv lee las credenciales de cada registro de las variables
+	// REGISTRY_<HOST>_USERNAME/_PASSWORD o REGISTRY_<HOST>_TOKEN, con el host
+	// en mayúsculas y cada carácter no alfanumérico cambiado por "_"
+	AuthFromEnv bool `yaml:"auth_from_env,omitempty" json:"auth_from_env,omitempty"`
+}
+
+// HostTimeouts devuelve Timeouts como duraciones indexadas por host en minúsculas
+func (c RegistryConfig) HostTimeouts() map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(c.Timeouts))
+	for host, seconds := range c.Timeouts {
+		timeouts[strings.ToLower(host)] = time.Duration(seconds) * time.Second
+	}
+	return timeouts
+}
+
+// DockerHubConfig credenciales de Docker Hub. Token es un personal access
+// token y, si está definido, se usa en lugar de Password
+type DockerHubConfig struct {
+	Username string `yaml:"username,omitempty" json:"username,omitempty" env:"DOCKER_USERNAME"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty" env:"DOCKER_PASSWORD"`
+	Token    string `yaml:"token,omitempty" json:"token,omitempty" env:"DOCKER_TOKEN"`
+	// Mirror es el host (host[:puerto][/ruta]) de un mirror de Docker Hub al que
+	// se consultan sus imágenes. Las oficiales se piden sin el prefijo library/
+	Mirror string `yaml:"mirror,omitempty" json:"mirror,omitempty" env:"DOCKER_HUB_MIRROR"`
+}
+
+// Secret devuelve el secreto con el que autenticarse: Token si está definido,
+// si no Password
+func (c DockerHubConfig) Secret() string {
+	if c.Token != "" {
+		return c.Token
+	}
+	return c.Password
+}
+
+// QuayConfig configuración del cliente de quay.io. Sin Enabled, quay.io se
+// consulta con el cliente genérico como cualquier otro registro OCI
+type QuayConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty" env:"QUAY_ENABLED"`
+	// Timeout en segundos de cada petición a la API (0 = registry.timeout)
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// Token es un token OAuth o de robot para repositorios privados
+	Token string `yaml:"token,omitempty" json:"token,omitempty" env:"QUAY_TOKEN"`
+}
+
+// TimeoutOr devuelve Timeout como duración, o fallback si no está definido
+func (c QuayConfig) TimeoutOr(fallback time.Duration) time.Duration {
+	if c.Timeout > 0 {
+		return time.Duration(c.Timeout) * time.Second
+	}
+	return fallback
+}
+
+// GitLabConfig configuración del cliente de GitLab Container Registry. Sin
+// Enabled, los registros de GitLab se consultan con el cliente genérico
+type GitLabConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty" env:"GITLAB_ENABLED"`
+	// Host es el registro de un GitLab propio (p. ej. registry.example.com),
+	// además de gitlab.com
+	Host string `yaml:"host,omitempty" json:"host,omitempty"`
+	// Username acompaña al token en el intercambio; vacío usa "oauth2"
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	// Token es un access token con el scope read_registry
+	Token string `yaml:"token,omitempty" json:"token,omitempty" env:"GITLAB_TOKEN"`
+	// Timeout en segundos de cada petición (0 = registry.timeout)
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// TimeoutOr devuelve Timeout como duración, o fallback si no está definido
+func (c GitLabConfig) TimeoutOr(fallback time.Duration) time.Duration {
+	if c.Timeout > 0 {
+		return time.Duration(c.Timeout) * time.Second
+	}
+	return fallback
 }
 
 // TelegramConfig configuración para notificaciones Telegram
@@ -19,11 +151,100 @@ type TelegramConfig struct {
 	ChatID   string `yaml:"chat_id" json:"chat_id" env:"TELEGRAM_CHAT_ID"`
 	Enabled  bool   `yaml:"enabled" json:"enabled" env:"TELEGRAM_ENABLED"`
 	Template string `yaml:"template" json:"template"`
+	// MinUpdateType limita las notificaciones a actualizaciones de este tipo o superior
+	// (patch, minor, major). Vacío = todas
+	MinUpdateType string `yaml:"min_update_type,omitempty" json:"min_update_type,omitempty" env:"TELEGRAM_MIN_UPDATE_TYPE"`
+	// Channels asocia nombres de canal con chat IDs. Los servicios con el label
+	// image-reporter.notify-channel: telegram:<nombre> se notifican a ese chat
+	Channels map[string]string `yaml:"channels,omitempty" json:"channels,omitempty"`
+}
+
+// SlackConfig configuración para notificaciones a un incoming webhook de Slack
+type SlackConfig struct {
+	Enabled    bool   `yaml:"enabled" json:"enabled" env:"SLACK_ENABLED"`
+	WebhookURL string `yaml:"webhook_url" json:"webhook_url" env:"SLACK_WEBHOOK_URL"`
+	// Channel sustituye el canal del webhook (solo webhooks antiguos). Vacío =
+	// el canal con el que se creó el webhook
+	Channel string `yaml:"channel,omitempty" json:"channel,omitempty"`
+}
+
+// DiscordConfig configuración para notificaciones a un webhook de Discord
+type DiscordConfig struct {
+	Enabled    bool   `yaml:"enabled" json:"enabled" env:"DISCORD_ENABLED"`
+	WebhookURL string `yaml:"webhook_url" json:"webhook_url" env:"DISCORD_WEBHOOK_URL"`
+}
+
+// EmailConfig configuración para notificaciones por correo vía SMTP
+type EmailConfig struct {
+	Enabled bool   `yaml:"enabled" json:"enabled" env:"EMAIL_ENABLED"`
+	Host    string `yaml:"host" json:"host" env:"EMAIL_HOST"`
+	// Port del servidor SMTP (0 = 587, submission con STARTTLS)
+	Port     int    `yaml:"port,omitempty" json:"port,omitempty" env:"EMAIL_PORT"`
+	Username string `yaml:"username,omitempty" json:"username,omitempty" env:"EMAIL_USERNAME"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty" env:"EMAIL_PASSWORD"`
+	From     string `yaml:"from" json:"from" env:"EMAIL_FROM"`
+	// To son los destinatarios, direcciones como "ops@example.com" o
+	// "Ops <ops@example.com>"
+	To []string `yaml:"to" json:"to" env:"EMAIL_TO"`
+}
+
+// DefaultSMTPPort es el puerto de submission que se usa si no se configura otro
+const DefaultSMTPPort = 587
+
+// SMTPPort devuelve Port, o DefaultSMTPPort si no está definido
+func (c EmailConfig) SMTPPort() int {
+	if c.Port > 0 {
+		return c.Port
+	}
+	return DefaultSMTPPort
+}
+
+// WebhookConfig configura el envío del resultado del escaneo en JSON a una URL
+type WebhookConfig struct {
+	Enabled bool   `yaml:"enabled" json:"enabled" env:"WEBHOOK_ENABLED"`
+	URL     string `yaml:"url" json:"url" env:"WEBHOOK_URL"`
+	// Headers se añaden a cada petición (p. ej. Authorization: Bearer ...)
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	// Secret firma el cuerpo con HMAC-SHA256 en la cabecera X-Signature-256.
+	// Vacío = sin firma
+	Secret string `yaml:"secret,omitempty" json:"secret,omitempty" env:"WEBHOOK_SECRET"`
+}
+
+// ExecConfig configura el notificador que ejecuta un comando con el mensaje
+// en su stdin
+type ExecConfig struct {
+	// Command es el programa y sus argumentos, sin shell (p. ej.
+	// ["/usr/local/bin/notify", "--channel", "ops"]). Vacío = desactivado
+	Command []string `yaml:"command,omitempty" json:"command,omitempty"`
+	// Timeout en segundos de cada ejecución (0 = 30 segundos)
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// ReportConfig configuración de la presentación de los reportes
+type ReportConfig struct {
+	// MaxUpToDate colapsa la lista de servicios al día a un contador cuando la
+	// supera (solo consola y HTML). 0 = mostrar siempre la lista completa
+	MaxUpToDate int `yaml:"max_uptodate,omitempty" json:"max_uptodate,omitempty"`
+	// DefaultFormat es el formato de salida de scan cuando no se pasa --output
+	// (console, json, html, sarif, influx, markdown/md, jsonl o csv). Vacío = console
+	DefaultFormat string `yaml:"default_format,omitempty" json:"default_format,omitempty"`
 }
 
 // Config representa la configuración completa de la aplicación
 type Config struct {
-	Telegram TelegramConfig `yaml:"telegram" json:"telegram"`
-	Registry RegistryConfig `yaml:"registry" json:"registry"`
-	Scan     ScanConfig     `yaml:"scan" json:"scan"`
+	// SchemaVersion versión del esquema del archivo; guía `icr config migrate`
+	SchemaVersion int            `yaml:"schema_version" json:"schema_version"`
+	Telegram      TelegramConfig `yaml:"telegram" json:"telegram"`
+	Slack         SlackConfig    `yaml:"slack,omitempty" json:"slack,omitempty"`
+	Discord       DiscordConfig  `yaml:"discord,omitempty" json:"discord,omitempty"`
+	Email         EmailConfig    `yaml:"email,omitempty" json:"email,omitempty"`
+	Webhook       WebhookConfig  `yaml:"webhook,omitempty" json:"webhook,omitempty"`
+	Registry      RegistryConfig `yaml:"registry" json:"registry"`
+	Scan          ScanConfig     `yaml:"scan" json:"scan"`
+	Report        ReportConfig   `yaml:"report" json:"report"`
+	Exec          ExecConfig     `yaml:"exec,omitempty" json:"exec,omitempty"`
+	// Profiles son variantes con nombre (dev, prod...) que --profile aplica
+	// sobre la configuración base; cada perfil solo contiene las claves que
+	// cambia, con la misma estructura que el archivo
+	Profiles map[string]map[string]interface{} `yaml:"profiles,omitempty" json:"profiles,omitempty"`
 }
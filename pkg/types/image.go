@@ -12,8 +12,24 @@ type DockerImage struct {
 	ComposeFile   string `json:"compose_file,omitempty"`
 	ContainerID   string `json:"container_id,omitempty"`
 	ContainerName string `json:"container_name,omitempty"`
+	// NotifyChannel es el destino de notificación del servicio (label
+	// image-reporter.notify-channel, ej: "telegram:team-a"). Vacío = destino por defecto
+	NotifyChannel string `json:"notify_channel,omitempty"`
+	// DigestOnly indica que la referencia solo tenía digest (nginx@sha256:...):
+	// Tag es un "latest" provisional hasta que el escáner resuelva el tag real
+	DigestOnly bool `json:"digest_only,omitempty"`
+	// Constraint limita las versiones candidatas con una restricción semver
+	// (comentario "# image-reporter: allow=~1.20" en la línea image:)
+	Constraint string `json:"constraint,omitempty"`
+	// Built indica que el servicio también tiene build: y la imagen es el
+	// nombre con el que se etiqueta la construcción local
+	Built bool `json:"built,omitempty"`
 }
 
+// NotifyChannelLabel es el label de compose/contenedor que enruta las
+// notificaciones de un servicio a un destino concreto
+const NotifyChannelLabel = "image-reporter.notify-channel"
+
 // String devuelve la representación completa de la imagen Docker
 func (d DockerImage) String() string {
 	if d.Registry == "docker.io" {
@@ -1,12 +1,19 @@
 package types
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 // RegistryClient define la interfaz para clientes de registros Docker
 type RegistryClient interface {
 	// GetLatestTags obtiene las etiquetas más recientes de una imagen
 	GetLatestTags(ctx context.Context, image DockerImage) ([]string, error)
 
+	// GetTags obtiene las etiquetas de una imagen con los metadatos que
+	// proporcione el registro
+	GetTags(ctx context.Context, image DockerImage) ([]TagInfo, error)
+
 	// GetImageInfo obtiene información detallada de una imagen
 	GetImageInfo(ctx context.Context, image DockerImage) (*ImageInfo, error)
 
@@ -14,6 +21,27 @@ type RegistryClient interface {
 	Name() string
 }
 
+// DigestResolver es implementado opcionalmente por los clientes de registro
+// capaces de resolver el digest actual de un tag
+type DigestResolver interface {
+	// GetDigest devuelve el digest del manifest al que apunta el tag de la imagen
+	GetDigest(ctx context.Context, image DockerImage) (string, error)
+}
+
+// RegistryPinger es implementado opcionalmente por los clientes de registro
+// capaces de comprobar la conectividad con un registro antes del escaneo
+type RegistryPinger interface {
+	// Ping devuelve un error si el registro no es alcanzable
+	Ping(ctx context.Context, registry string) error
+}
+
+// RateLimitReporter es implementado opcionalmente por los clientes de registro
+// que leen las cabeceras de límite de peticiones de las respuestas
+type RateLimitReporter interface {
+	// RateLimits devuelve el último límite visto por cada registro
+	RateLimits() []RateLimit
+}
+
 // ComposeParser define la interfaz para parsear archivos docker-compose
 type ComposeParser interface {
 	// ParseFile parsea un archivo docker-compose y extrae las imágenes
@@ -23,6 +51,21 @@ type ComposeParser interface {
 	CanParse(filePath string) bool
 }
 
+// ServiceCounter es implementado opcionalmente por los parsers de compose
+// capaces de informar cuántos servicios declara un archivo
+type ServiceCounter interface {
+	// ParseFileWithServices es ParseFile que además devuelve el número de
+	// servicios del archivo, incluidos los que no aportan imagen
+	ParseFileWithServices(ctx context.Context, filePath string) ([]DockerImage, int, error)
+}
+
+// ProjectNamer es implementado opcionalmente por los parsers de compose
+// capaces de leer un nombre de proyecto declarado en el propio archivo
+type ProjectNamer interface {
+	// ProjectName devuelve el nombre declarado en filePath, o "" si no hay
+	ProjectName(filePath string) string
+}
+
 // NotificationClient define la interfaz para clientes de notificación
 type NotificationClient interface {
 	// SendNotification envía una notificación con el mensaje dado
@@ -35,6 +78,15 @@ type NotificationClient interface {
 	Name() string
 }
 
+// ScanResultNotifier es implementado opcionalmente por los clientes de
+// notificación que construyen su propio mensaje a partir del resultado (p. ej.
+// un embed de Discord) en lugar de recibir el texto del formatter
+type ScanResultNotifier interface {
+	// SendScanResult envía una notificación con la vista del resultado que
+	// corresponde al cliente
+	SendScanResult(ctx context.Context, result ScanResult) error
+}
+
 // ReportFormatter define la interfaz para formatear reportes
 type ReportFormatter interface {
 	// Format convierte un ScanResult en un string formateado
@@ -44,6 +96,16 @@ type ReportFormatter interface {
 	FormatName() string
 }
 
+// StreamFormatter es un ReportFormatter que además sabe escribir el reporte
+// directamente en un io.Writer, sin construir el string completo en memoria
+type StreamFormatter interface {
+	ReportFormatter
+
+	// FormatTo escribe en w el contenido que devolvería Format, como mucho
+	// con un salto de línea final añadido
+	FormatTo(w io.Writer, result ScanResult) error
+}
+
 // VersionComparator define la interfaz para comparar versiones
 type VersionComparator interface {
 	// Compare compara dos versiones y devuelve el tipo de actualización
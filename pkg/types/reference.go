@@ -0,0 +1,102 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/user/docker-image-reporter/pkg/errors"
+)
+
+// ParseImageReference parsea una referencia de imagen Docker en sus componentes.
+// Soporta registries con puerto, repositorios de varios segmentos, tag y digest
+// (ej: "registry:5000/a/b/c:tag@sha256:..."). El ':' de un puerto nunca se
+// interpreta como separador de tag: solo cuenta el último ':' tras el último '/'.
+func ParseImageReference(ref string) (DockerImage, error) {
+	const op = "types.ParseImageReference"
+
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return DockerImage{}, errors.New(op, "empty image string")
+	}
+
+	// Separar digest (@sha256:...)
+	name, digest, hasDigest := strings.Cut(ref, "@")
+	if hasDigest && (digest == "" || strings.Contains(digest, "@")) {
+		return DockerImage{}, errors.Newf(op, "invalid image format with digest: %s", ref)
+	}
+
+	// Separar tag: solo si el último ':' aparece después del último '/'
+	tag := "latest"
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		tag = name[colon+1:]
+		name = name[:colon]
+		if tag == "" {
+			return DockerImage{}, errors.Newf(op, "empty tag in image reference: %s", ref)
+		}
+	}
+
+	registry, repository := SplitRegistryRepository(name)
+	if registry == "" || repository == "" || strings.Contains(repository, "//") ||
+		strings.HasPrefix(repository, "/") || strings.HasSuffix(repository, "/") {
+		return DockerImage{}, errors.Newf(op, "invalid image reference: %s", ref)
+	}
+
+	return DockerImage{
+		Registry:   registry,
+		Repository: repository,
+		Tag:        tag,
+		Digest:     digest,
+	}, nil
+}
+
+// SplitRegistryRepository separa el registry del repository en un nombre sin tag ni digest.
+// El primer segmento es un registry solo si contiene '.', ':' o es "localhost";
+// en otro caso la imagen pertenece a Docker Hub. Las imágenes oficiales de
+// Docker Hub reciben el prefijo "library/".
+func SplitRegistryRepository(name string) (string, string) {
+	registry := "docker.io"
+	repository := name
+
+	if first, rest, found := strings.Cut(name, "/"); found &&
+		(strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry = first
+		repository = rest
+	}
+
+	if registry == "index.docker.io" || registry == "registry-1.docker.io" {
+		registry = "docker.io"
+	}
+
+	if registry == "docker.io" && repository != "" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	return registry, repository
+}
+
+// ApplyRepositoryAliases reescribe el registry/repository de la imagen con el
+// alias cuyo prefijo coincide (ej: "internal.example.com/mirror/" -> "docker.io/").
+// Gana el prefijo más largo. Devuelve la imagen original y false si ningún
+// alias aplica; el resto de campos (tag, servicio, archivo...) se conservan.
+func ApplyRepositoryAliases(image DockerImage, aliases map[string]string) (DockerImage, bool) {
+	name := image.Registry + "/" + image.Repository
+
+	match := ""
+	for prefix := range aliases {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	if match == "" {
+		return image, false
+	}
+
+	registry, repository := SplitRegistryRepository(aliases[match] + strings.TrimPrefix(name, match))
+	if repository == "" {
+		return image, false
+	}
+
+	aliased := image
+	aliased.Registry = registry
+	aliased.Repository = repository
+	return aliased, true
+}
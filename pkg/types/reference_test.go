@@ -0,0 +1,196 @@
+package types
+
+import "testing"
+
+func TestParseImageReference(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    DockerImage
+		wantErr bool
+	}{
+		{
+			name: "official image",
+			ref:  "nginx",
+			want: DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"},
+		},
+		{
+			name: "official image with tag",
+			ref:  "nginx:1.25",
+			want: DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+		},
+		{
+			name: "docker hub user image",
+			ref:  "user/app:v1",
+			want: DockerImage{Registry: "docker.io", Repository: "user/app", Tag: "v1"},
+		},
+		{
+			name: "docker hub multi segment without registry",
+			ref:  "team/app/sub:1.0",
+			want: DockerImage{Registry: "docker.io", Repository: "team/app/sub", Tag: "1.0"},
+		},
+		{
+			name: "explicit docker.io official image",
+			ref:  "docker.io/nginx:1.25",
+			want: DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+		},
+		{
+			name: "index.docker.io alias",
+			ref:  "index.docker.io/user/app:2",
+			want: DockerImage{Registry: "docker.io", Repository: "user/app", Tag: "2"},
+		},
+		{
+			name: "localhost without port",
+			ref:  "localhost/app:dev",
+			want: DockerImage{Registry: "localhost", Repository: "app", Tag: "dev"},
+		},
+		{
+			name: "registry with port no tag",
+			ref:  "localhost:5000/app",
+			want: DockerImage{Registry: "localhost:5000", Repository: "app", Tag: "latest"},
+		},
+		{
+			name: "registry with port and tag",
+			ref:  "localhost:5000/app:1.2.3",
+			want: DockerImage{Registry: "localhost:5000", Repository: "app", Tag: "1.2.3"},
+		},
+		{
+			name: "registry with port and multi segment path no tag",
+			ref:  "localhost:5000/team/app/sub",
+			want: DockerImage{Registry: "localhost:5000", Repository: "team/app/sub", Tag: "latest"},
+		},
+		{
+			name: "registry with port multi segment path and tag",
+			ref:  "registry:5000/a/b/c:tag",
+			want: DockerImage{Registry: "registry:5000", Repository: "a/b/c", Tag: "tag"},
+		},
+		{
+			name: "registry with port multi segment path and digest",
+			ref:  "registry:5000/a/b/c@" + digest,
+			want: DockerImage{Registry: "registry:5000", Repository: "a/b/c", Tag: "latest", Digest: digest},
+		},
+		{
+			name: "registry with port multi segment path tag and digest",
+			ref:  "registry.example.com:8443/a/b/c:1.0-alpine@" + digest,
+			want: DockerImage{Registry: "registry.example.com:8443", Repository: "a/b/c", Tag: "1.0-alpine", Digest: digest},
+		},
+		{
+			name: "ghcr nested path",
+			ref:  "ghcr.io/org/team/app:v2.0.0",
+			want: DockerImage{Registry: "ghcr.io", Repository: "org/team/app", Tag: "v2.0.0"},
+		},
+		{
+			name: "official image with digest only",
+			ref:  "nginx@" + digest,
+			want: DockerImage{Registry: "docker.io", Repository: "library/nginx", Tag: "latest", Digest: digest},
+		},
+		{
+			name: "surrounding whitespace",
+			ref:  "  redis:7  ",
+			want: DockerImage{Registry: "docker.io", Repository: "library/redis", Tag: "7"},
+		},
+		{name: "empty", ref: "", wantErr: true},
+		{name: "only whitespace", ref: "   ", wantErr: true},
+		{name: "double digest", ref: "nginx@sha256:a@sha256:b", wantErr: true},
+		{name: "empty digest", ref: "nginx@", wantErr: true},
+		{name: "empty tag", ref: "localhost:5000/app:", wantErr: true},
+		{name: "empty path segment", ref: "registry:5000/a//c:1", wantErr: true},
+		{name: "trailing slash", ref: "registry:5000/app/", wantErr: true},
+		{name: "registry without repository", ref: "registry:5000/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImageReference(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseImageReference(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseImageReference(%q) = %+v, want %+v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitRegistryRepository(t *testing.T) {
+	tests := []struct {
+		name           string
+		wantRegistry   string
+		wantRepository string
+	}{
+		{"nginx", "docker.io", "library/nginx"},
+		{"user/app", "docker.io", "user/app"},
+		{"a/b/c", "docker.io", "a/b/c"},
+		{"localhost/app", "localhost", "app"},
+		{"localhost:5000/a/b", "localhost:5000", "a/b"},
+		{"quay.io/org/app", "quay.io", "org/app"},
+		{"myregistry.com/app", "myregistry.com", "app"},
+		{"myregistry.com:5000/app", "myregistry.com:5000", "app"},
+		{"registry-1.docker.io/library/nginx", "docker.io", "library/nginx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, repository := SplitRegistryRepository(tt.name)
+			if registry != tt.wantRegistry || repository != tt.wantRepository {
+				t.Errorf("SplitRegistryRepository(%q) = (%q, %q), want (%q, %q)",
+					tt.name, registry, repository, tt.wantRegistry, tt.wantRepository)
+			}
+		})
+	}
+}
+
+func TestApplyRepositoryAliases(t *testing.T) {
+	aliases := map[string]string{
+		"internal.example.com/mirror/":      "docker.io/",
+		"internal.example.com/mirror/ghcr/": "ghcr.io/",
+	}
+
+	tests := []struct {
+		name      string
+		image     DockerImage
+		wantImage string
+		wantOK    bool
+	}{
+		{
+			name:      "mirror of official image",
+			image:     DockerImage{Registry: "internal.example.com", Repository: "mirror/nginx", Tag: "1.25"},
+			wantImage: "docker.io/library/nginx:1.25",
+			wantOK:    true,
+		},
+		{
+			name:      "longest prefix wins",
+			image:     DockerImage{Registry: "internal.example.com", Repository: "mirror/ghcr/org/app", Tag: "v1"},
+			wantImage: "ghcr.io/org/app:v1",
+			wantOK:    true,
+		},
+		{
+			name:      "no alias applies",
+			image:     DockerImage{Registry: "quay.io", Repository: "org/app", Tag: "v1"},
+			wantImage: "quay.io/org/app:v1",
+			wantOK:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.image.ServiceName = "svc"
+			got, ok := ApplyRepositoryAliases(tt.image, aliases)
+			if ok != tt.wantOK {
+				t.Errorf("ApplyRepositoryAliases() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got.FullName() != tt.wantImage {
+				t.Errorf("ApplyRepositoryAliases() = %s, want %s", got.FullName(), tt.wantImage)
+			}
+			// Los campos que no son del nombre se conservan
+			if got.ServiceName != "svc" {
+				t.Errorf("ServiceName = %q, want %q", got.ServiceName, "svc")
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package types
 
 import (
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,6 +17,59 @@ type ScanResult struct {
 	Errors             []string      `json:"errors"`
 	TotalServicesFound int           `json:"total_services_found"`
 	FilesScanned       []string      `json:"files_scanned"`
+	Lookups            []ImageLookup `json:"lookups,omitempty"`
+	RateLimits         []RateLimit   `json:"rate_limits,omitempty"`
+	// Notes son avisos informativos que no son errores (p. ej. un override que
+	// cambia la imagen de un servicio)
+	Notes []string `json:"notes,omitempty"`
+	// LocallyBuilt son los servicios con build: e image: cuya imagen se
+	// construye localmente y no se comprueba en el registro
+	LocallyBuilt []string `json:"locally_built,omitempty"`
+	// FilesSummary cuenta servicios e imágenes extraídos de cada archivo compose
+	FilesSummary []FileSummary `json:"files_summary,omitempty"`
+}
+
+// FileSummary resume lo que aportó un archivo compose al escaneo
+type FileSummary struct {
+	File     string `json:"file"`
+	Services int    `json:"services"`
+	Images   int    `json:"images"`
+	// Error es el motivo por el que no se pudo parsear el archivo
+	Error string `json:"error,omitempty"`
+}
+
+// Empty indica que el archivo no aportó ninguna imagen (todos sus servicios
+// usan build: o no se pudo parsear)
+func (f FileSummary) Empty() bool {
+	return f.Images == 0
+}
+
+// RateLimit es el último presupuesto de peticiones anunciado por un registro
+// mediante las cabeceras RateLimit-Limit y RateLimit-Remaining (Docker Hub)
+type RateLimit struct {
+	Registry  string        `json:"registry"`
+	Limit     int           `json:"limit"`
+	Remaining int           `json:"remaining"`
+	Window    time.Duration `json:"window_ns,omitempty"`
+}
+
+// String devuelve el presupuesto en formato legible, p. ej.
+// "DockerHub pulls remaining: 42/100"
+func (l RateLimit) String() string {
+	name := l.Registry
+	if name == "index.docker.io" || name == "registry-1.docker.io" || name == "docker.io" {
+		name = "DockerHub"
+	}
+	return fmt.Sprintf("%s pulls remaining: %d/%d", name, l.Remaining, l.Limit)
+}
+
+// ImageLookup registra qué cliente de registro resolvió una imagen y cuánto tardó
+type ImageLookup struct {
+	Service  string        `json:"service"`
+	Image    string        `json:"image"`
+	Registry string        `json:"registry"`
+	Client   string        `json:"client,omitempty"` // vacío si ningún cliente soporta el registro
+	Latency  time.Duration `json:"latency_ns"`
 }
 
 // HasUpdates indica si hay actualizaciones disponibles
@@ -26,13 +82,314 @@ func (r ScanResult) HasErrors() bool {
 	return len(r.Errors) > 0
 }
 
-// Summary devuelve un resumen del resultado del escaneo
+// HasActionable indica si hay algo que notificar: actualizaciones y, si
+// includeErrors, también errores
+func (r ScanResult) HasActionable(includeErrors bool) bool {
+	return r.HasUpdates() || (includeErrors && r.HasErrors())
+}
+
+// Summary devuelve el resumen de una línea que comparten consola, HTML y
+// notificaciones, p. ej. "3 updates (1 major, 2 minor), 10 up to date, 2 errors".
+// El desglose sigue el orden de severidad y los errores solo aparecen si los hay.
 func (r ScanResult) Summary() string {
+	var b strings.Builder
 	if r.HasUpdates() {
-		return fmt.Sprintf("%d updates available, %d services up to date",
-			len(r.UpdatesAvailable), len(r.UpToDateServices))
+		b.WriteString(pluralize(len(r.UpdatesAvailable), "update", "updates"))
+		breakdown := make([]string, 0, len(severityOrder))
+		for _, group := range r.GroupUpdates(GroupBySeverity) {
+			breakdown = append(breakdown, fmt.Sprintf("%d %s", len(group.Updates), group.Name))
+		}
+		fmt.Fprintf(&b, " (%s)", strings.Join(breakdown, ", "))
+	} else {
+		b.WriteString("no updates")
+	}
+
+	fmt.Fprintf(&b, ", %d up to date", len(r.UpToDateServices))
+	if r.HasErrors() {
+		b.WriteString(", " + pluralize(len(r.Errors), "error", "errors"))
+	}
+	return b.String()
+}
+
+// pluralize devuelve "1 update" o "3 updates" según n
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// Diff devuelve una vista del resultado con solo lo que ha cambiado respecto
+// a baseline: las actualizaciones que no estaban (o que ahora proponen otra
+// versión o digest) y los errores nuevos. El resto de campos no se modifica.
+func (r ScanResult) Diff(baseline ScanResult) ScanResult {
+	known := make(map[string]bool, len(baseline.UpdatesAvailable))
+	for _, update := range baseline.UpdatesAvailable {
+		known[updateKey(update)] = true
+	}
+	updates := make([]ImageUpdate, 0, len(r.UpdatesAvailable))
+	for _, update := range r.UpdatesAvailable {
+		if !known[updateKey(update)] {
+			updates = append(updates, update)
+		}
+	}
+
+	knownErrors := make(map[string]bool, len(baseline.Errors))
+	for _, scanErr := range baseline.Errors {
+		knownErrors[scanErr] = true
+	}
+	var errs []string
+	for _, scanErr := range r.Errors {
+		if !knownErrors[scanErr] {
+			errs = append(errs, scanErr)
+		}
+	}
+
+	r.UpdatesAvailable = updates
+	r.Errors = errs
+	return r
+}
+
+// HideAcknowledged devuelve una vista del resultado sin las actualizaciones
+// que cubre algún aplazamiento de acks, y cuántas se ocultaron. Un tag más
+// nuevo que el aplazado no coincide y se sigue informando.
+func (r ScanResult) HideAcknowledged(acks []Acknowledgment) (ScanResult, int) {
+	if len(acks) == 0 {
+		return r, 0
+	}
+	updates := make([]ImageUpdate, 0, len(r.UpdatesAvailable))
+	for _, update := range r.UpdatesAvailable {
+		if !slices.ContainsFunc(acks, func(ack Acknowledgment) bool { return ack.Covers(update) }) {
+			updates = append(updates, update)
+		}
+	}
+	hidden := len(r.UpdatesAvailable) - len(updates)
+	r.UpdatesAvailable = updates
+	return r, hidden
+}
+
+// updateKey identifica una actualización propuesta: servicio, imagen actual y
+// versión o digest propuesto
+func updateKey(update ImageUpdate) string {
+	return strings.Join([]string{
+		update.ServiceName,
+		update.CurrentImage.FullName(),
+		update.LatestImage.FullName(),
+		update.LatestImage.Digest,
+	}, "|")
+}
+
+// UpToDateCollapsed indica si la lista de servicios al día debe mostrarse
+// como un contador por superar maxUpToDate (0 = sin límite)
+func (r ScanResult) UpToDateCollapsed(maxUpToDate int) bool {
+	return maxUpToDate > 0 && len(r.UpToDateServices) > maxUpToDate
+}
+
+// PrioritizeUpdates mueve las actualizaciones prioritarias al principio,
+// manteniendo el orden relativo del resto
+func (r *ScanResult) PrioritizeUpdates() {
+	sort.SliceStable(r.UpdatesAvailable, func(i, j int) bool {
+		return r.UpdatesAvailable[i].Priority && !r.UpdatesAvailable[j].Priority
+	})
+}
+
+// SortBy indica el orden de las actualizaciones al presentar un reporte
+type SortBy string
+
+const (
+	// SortBySeverity ordena de major a unknown (por defecto)
+	SortBySeverity SortBy = "severity"
+	// SortByBehind ordena por VersionsBehind, de más a menos versiones de retraso
+	SortByBehind SortBy = "behind"
+	// SortByRecency ordena por la fecha de publicación del tag propuesto, de
+	// la más reciente a la más antigua; las fechas desconocidas van al final
+	SortByRecency SortBy = "recency"
+	// SortByName ordena alfabéticamente por servicio
+	SortByName SortBy = "name"
+)
+
+// ParseSortBy valida el valor de --sort-by (vacío = severity)
+func ParseSortBy(value string) (SortBy, error) {
+	sortBy := SortBy(strings.ToLower(strings.TrimSpace(value)))
+	switch sortBy {
+	case "":
+		return SortBySeverity, nil
+	case SortBySeverity, SortByBehind, SortByRecency, SortByName:
+		return sortBy, nil
+	}
+	return SortBySeverity, fmt.Errorf("invalid sort-by %q (use severity, behind, recency or name)", value)
+}
+
+// SortUpdates ordena UpdatesAvailable según by. Las actualizaciones
+// prioritarias siguen al principio y los empates se ordenan por servicio e imagen.
+func (r *ScanResult) SortUpdates(by SortBy) {
+	sort.SliceStable(r.UpdatesAvailable, func(i, j int) bool {
+		a, b := r.UpdatesAvailable[i], r.UpdatesAvailable[j]
+		if a.Priority != b.Priority {
+			return a.Priority
+		}
+		switch by {
+		case SortBySeverity:
+			if sa, sb := severityRank(a.UpdateType.String()), severityRank(b.UpdateType.String()); sa != sb {
+				return sa < sb
+			}
+		case SortByBehind:
+			if a.VersionsBehind != b.VersionsBehind {
+				return a.VersionsBehind > b.VersionsBehind
+			}
+		case SortByRecency:
+			if !a.UpdatedAt.Equal(b.UpdatedAt) {
+				return a.UpdatedAt.After(b.UpdatedAt)
+			}
+		}
+		if a.ServiceName != b.ServiceName {
+			return a.ServiceName < b.ServiceName
+		}
+		return a.CurrentImage.String() < b.CurrentImage.String()
+	})
+}
+
+// GroupBy indica cómo agrupar las actualizaciones al presentar un reporte
+type GroupBy string
+
+const (
+	// GroupByNone mantiene el listado plano
+	GroupByNone     GroupBy = ""
+	GroupByFile     GroupBy = "file"
+	GroupByService  GroupBy = "service"
+	GroupByRegistry GroupBy = "registry"
+	GroupBySeverity GroupBy = "severity"
+)
+
+// ParseGroupBy valida el valor de --group-by (vacío = listado plano)
+func ParseGroupBy(value string) (GroupBy, error) {
+	groupBy := GroupBy(strings.ToLower(strings.TrimSpace(value)))
+	if groupBy == GroupByNone || groupBy == GroupByFile || groupBy == GroupByService ||
+		groupBy == GroupByRegistry || groupBy == GroupBySeverity {
+		return groupBy, nil
+	}
+	return GroupByNone, fmt.Errorf("invalid group-by %q (use file, service, registry or severity)", value)
+}
+
+// severityOrder ordena los grupos por severidad, de mayor a menor
+var severityOrder = map[UpdateType]int{
+	UpdateTypeMajor:   0,
+	UpdateTypeMinor:   1,
+	UpdateTypePatch:   2,
+	UpdateTypeDigest:  3,
+	UpdateTypeUnknown: 4,
+}
+
+// UpdateGroup es un grupo de actualizaciones con su título
+type UpdateGroup struct {
+	Name    string
+	Updates []ImageUpdate
+}
+
+// GroupUpdates agrupa UpdatesAvailable según by. Con GroupByNone devuelve un
+// único grupo sin nombre. Los grupos por severidad van de major a unknown;
+// el resto se ordena por nombre. Dentro de cada grupo se mantiene el orden.
+func (r ScanResult) GroupUpdates(by GroupBy) []UpdateGroup {
+	if by == GroupByNone {
+		return []UpdateGroup{{Updates: r.UpdatesAvailable}}
+	}
+
+	index := make(map[string]int)
+	var groups []UpdateGroup
+	for _, update := range r.UpdatesAvailable {
+		name := updateGroupName(update, by)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, UpdateGroup{Name: name})
+		}
+		groups[i].Updates = append(groups[i].Updates, update)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if by == GroupBySeverity {
+			return severityRank(groups[i].Name) < severityRank(groups[j].Name)
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// updateGroupName devuelve el nombre del grupo de una actualización
+func updateGroupName(update ImageUpdate, by GroupBy) string {
+	var name string
+	switch by {
+	case GroupByFile:
+		name = update.CurrentImage.ComposeFile
+	case GroupByService:
+		name = update.ServiceName
+	case GroupByRegistry:
+		name = update.CurrentImage.Registry
+	case GroupBySeverity:
+		name = update.UpdateType.String()
+	case GroupByNone:
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	return name
+}
+
+// severityRank devuelve la posición de un tipo de actualización en el orden por severidad
+func severityRank(name string) int {
+	if rank, ok := severityOrder[UpdateType(name)]; ok {
+		return rank
+	}
+	return len(severityOrder)
+}
+
+// ScanErrorCategory clasifica los errores de escaneo por imagen
+type ScanErrorCategory string
+
+const (
+	// ScanErrorNoClient indica que ningún cliente de registro puede consultar el host de la imagen
+	ScanErrorNoClient ScanErrorCategory = "no_client"
+	// ScanErrorAuth indica que el registro rechazó la consulta por falta de credenciales
+	ScanErrorAuth ScanErrorCategory = "auth_required"
+)
+
+// ScanError es un error de escaneo categorizado, con una sugerencia opcional
+// para resolverlo
+type ScanError struct {
+	Category   ScanErrorCategory
+	Image      string
+	Message    string
+	Suggestion string
+}
+
+// Error implementa la interfaz error
+func (e *ScanError) Error() string {
+	if e.Suggestion == "" {
+		return e.Message
+	}
+	return e.Message + "; " + e.Suggestion
+}
+
+// TagInfo describe un tag tal y como lo devuelve el registro. Solo Name es
+// obligatorio: los clientes rellenan el resto cuando su API lo proporciona
+type TagInfo struct {
+	Name          string    `json:"name"`
+	LastUpdated   time.Time `json:"last_updated,omitempty"`
+	Digest        string    `json:"digest,omitempty"`
+	Architectures []string  `json:"architectures,omitempty"`
+	// Size es el tamaño comprimido de la imagen en bytes cuando el registro
+	// lo informa; 0 si se desconoce
+	Size int64 `json:"size,omitempty"`
+}
+
+// TagNames devuelve los nombres de tags en el mismo orden
+func TagNames(tags []TagInfo) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
 	}
-	return fmt.Sprintf("All %d services are up to date", len(r.UpToDateServices))
+	return names
 }
 
 // ImageInfo contiene información detallada de una imagen desde el registro
@@ -1,6 +1,48 @@
 package types
 
-import "testing"
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanResult_Predicates(t *testing.T) {
+	updates := []ImageUpdate{{ServiceName: "nginx", UpdateType: UpdateTypeMinor}}
+	errs := []string{"getting tags for org/app: timeout"}
+
+	tests := []struct {
+		name              string
+		result            ScanResult
+		wantUpdates       bool
+		wantErrors        bool
+		wantActionable    bool // con errores
+		wantUpdatesActive bool // solo actualizaciones
+	}{
+		{name: "empty", result: ScanResult{}},
+		{name: "empty slices", result: ScanResult{UpdatesAvailable: []ImageUpdate{}, Errors: []string{}}},
+		{name: "updates only", result: ScanResult{UpdatesAvailable: updates}, wantUpdates: true, wantActionable: true, wantUpdatesActive: true},
+		{name: "errors only", result: ScanResult{Errors: errs}, wantErrors: true, wantActionable: true},
+		{name: "mixed", result: ScanResult{UpdatesAvailable: updates, Errors: errs}, wantUpdates: true, wantErrors: true, wantActionable: true, wantUpdatesActive: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.HasUpdates(); got != tt.wantUpdates || got != (len(tt.result.UpdatesAvailable) > 0) {
+				t.Errorf("HasUpdates() = %v, want %v", got, tt.wantUpdates)
+			}
+			if got := tt.result.HasErrors(); got != tt.wantErrors || got != (len(tt.result.Errors) > 0) {
+				t.Errorf("HasErrors() = %v, want %v", got, tt.wantErrors)
+			}
+			if got := tt.result.HasActionable(true); got != tt.wantActionable {
+				t.Errorf("HasActionable(true) = %v, want %v", got, tt.wantActionable)
+			}
+			if got := tt.result.HasActionable(false); got != tt.wantUpdatesActive {
+				t.Errorf("HasActionable(false) = %v, want %v", got, tt.wantUpdatesActive)
+			}
+		})
+	}
+}
 
 func TestScanResult_HasUpdates(t *testing.T) {
 	tests := []struct {
@@ -43,14 +85,14 @@ func TestScanResult_Summary(t *testing.T) {
 		expected string
 	}{
 		{
-			name: "with updates",
+			name: "single update",
 			result: ScanResult{
 				UpdatesAvailable: []ImageUpdate{
-					{ServiceName: "nginx"},
+					{ServiceName: "nginx", UpdateType: UpdateTypePatch},
 				},
 				UpToDateServices: []string{"redis", "postgres"},
 			},
-			expected: "1 updates available, 2 services up to date",
+			expected: "1 update (1 patch), 2 up to date",
 		},
 		{
 			name: "no updates",
@@ -58,7 +100,36 @@ func TestScanResult_Summary(t *testing.T) {
 				UpdatesAvailable: []ImageUpdate{},
 				UpToDateServices: []string{"nginx", "redis"},
 			},
-			expected: "All 2 services are up to date",
+			expected: "no updates, 2 up to date",
+		},
+		{
+			name: "only errors",
+			result: ScanResult{
+				Errors: []string{"getting tags for a", "getting tags for b"},
+			},
+			expected: "no updates, 0 up to date, 2 errors",
+		},
+		{
+			// El desglose sigue el orden de severidad, no el de llegada
+			name: "mixed",
+			result: ScanResult{
+				UpdatesAvailable: []ImageUpdate{
+					{ServiceName: "web", UpdateType: UpdateTypeMinor},
+					{ServiceName: "db", UpdateType: UpdateTypeMajor},
+					{ServiceName: "cache", UpdateType: UpdateTypeMinor},
+				},
+				UpToDateServices: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"},
+				Errors:           []string{"x", "y"},
+			},
+			expected: "3 updates (1 major, 2 minor), 10 up to date, 2 errors",
+		},
+		{
+			name: "single error",
+			result: ScanResult{
+				UpdatesAvailable: []ImageUpdate{{ServiceName: "app", UpdateType: UpdateTypeDigest}},
+				Errors:           []string{"x"},
+			},
+			expected: "1 update (1 digest), 0 up to date, 1 error",
 		},
 	}
 
@@ -117,3 +188,275 @@ func TestImageUpdate_IsSignificant(t *testing.T) {
 		})
 	}
 }
+
+func TestScanResult_PrioritizeUpdates(t *testing.T) {
+	result := ScanResult{
+		UpdatesAvailable: []ImageUpdate{
+			{ServiceName: "web"},
+			{ServiceName: "tls", Priority: true},
+			{ServiceName: "db"},
+			{ServiceName: "proxy", Priority: true},
+		},
+	}
+
+	result.PrioritizeUpdates()
+
+	expected := []string{"tls", "proxy", "web", "db"}
+	for i, name := range expected {
+		if result.UpdatesAvailable[i].ServiceName != name {
+			t.Errorf("UpdatesAvailable[%d] = %s, want %s", i, result.UpdatesAvailable[i].ServiceName, name)
+		}
+	}
+}
+
+func TestScanResult_GroupUpdates(t *testing.T) {
+	result := ScanResult{
+		UpdatesAvailable: []ImageUpdate{
+			{ServiceName: "web", CurrentImage: DockerImage{Registry: "ghcr.io"}, UpdateType: UpdateTypePatch},
+			{ServiceName: "db", CurrentImage: DockerImage{Registry: "docker.io"}, UpdateType: UpdateTypeMajor},
+			{ServiceName: "api", CurrentImage: DockerImage{Registry: "ghcr.io"}, UpdateType: UpdateTypeMinor},
+			{ServiceName: "cache", UpdateType: UpdateTypeMajor},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		groupBy   GroupBy
+		wantNames []string
+		wantSizes []int
+	}{
+		{"flat", GroupByNone, []string{""}, []int{4}},
+		{"registry", GroupByRegistry, []string{"docker.io", "ghcr.io", "unknown"}, []int{1, 2, 1}},
+		{"severity", GroupBySeverity, []string{"major", "minor", "patch"}, []int{2, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := result.GroupUpdates(tt.groupBy)
+			if len(groups) != len(tt.wantNames) {
+				t.Fatalf("got %d groups, want %d", len(groups), len(tt.wantNames))
+			}
+			for i, group := range groups {
+				if group.Name != tt.wantNames[i] || len(group.Updates) != tt.wantSizes[i] {
+					t.Errorf("group %d = %q (%d), want %q (%d)",
+						i, group.Name, len(group.Updates), tt.wantNames[i], tt.wantSizes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	for _, value := range []string{"", "file", "service", "Registry", "severity"} {
+		if _, err := ParseGroupBy(value); err != nil {
+			t.Errorf("ParseGroupBy(%q) unexpected error: %v", value, err)
+		}
+	}
+	if _, err := ParseGroupBy("team"); err == nil {
+		t.Error("ParseGroupBy(\"team\") expected error")
+	}
+}
+
+func TestScanResult_SortUpdates(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	fixture := []ImageUpdate{
+		{ServiceName: "redis", UpdateType: UpdateTypePatch, VersionsBehind: 4, UpdatedAt: day(10)},
+		{ServiceName: "api", UpdateType: UpdateTypeMinor, VersionsBehind: 2, UpdatedAt: day(20)},
+		{ServiceName: "db", UpdateType: UpdateTypeMajor, VersionsBehind: 1},
+		{ServiceName: "web", UpdateType: UpdateTypeMinor, VersionsBehind: 7, UpdatedAt: day(5)},
+	}
+
+	tests := []struct {
+		by       SortBy
+		expected []string
+	}{
+		{by: SortBySeverity, expected: []string{"db", "api", "web", "redis"}},
+		{by: SortByBehind, expected: []string{"web", "redis", "api", "db"}},
+		// Sin fecha conocida al final
+		{by: SortByRecency, expected: []string{"api", "redis", "web", "db"}},
+		{by: SortByName, expected: []string{"api", "db", "redis", "web"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.by), func(t *testing.T) {
+			result := ScanResult{UpdatesAvailable: slices.Clone(fixture)}
+			result.SortUpdates(tt.by)
+
+			var got []string
+			for _, update := range result.UpdatesAvailable {
+				got = append(got, update.ServiceName)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("SortUpdates(%s) = %v, want %v", tt.by, got, tt.expected)
+			}
+		})
+	}
+
+	// Las prioritarias siguen al principio en cualquier orden
+	result := ScanResult{UpdatesAvailable: slices.Clone(fixture)}
+	result.UpdatesAvailable[0].Priority = true
+	result.SortUpdates(SortByName)
+	if result.UpdatesAvailable[0].ServiceName != "redis" {
+		t.Errorf("expected the priority update first, got %s", result.UpdatesAvailable[0].ServiceName)
+	}
+}
+
+func TestParseSortBy(t *testing.T) {
+	for value, want := range map[string]SortBy{"": SortBySeverity, "behind": SortByBehind, "Recency": SortByRecency, "name": SortByName} {
+		got, err := ParseSortBy(value)
+		if err != nil || got != want {
+			t.Errorf("ParseSortBy(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseSortBy("age"); err == nil {
+		t.Error("ParseSortBy(\"age\") expected error")
+	}
+}
+
+func TestScanResult_Diff(t *testing.T) {
+	update := func(service, current, latest string) ImageUpdate {
+		return ImageUpdate{
+			ServiceName:  service,
+			CurrentImage: DockerImage{Registry: "docker.io", Repository: "library/" + service, Tag: current},
+			LatestImage:  DockerImage{Registry: "docker.io", Repository: "library/" + service, Tag: latest},
+		}
+	}
+
+	baseline := ScanResult{
+		UpdatesAvailable: []ImageUpdate{update("nginx", "1.24", "1.25"), update("redis", "7.0", "7.2")},
+		Errors:           []string{"registry unreachable: quay.io"},
+	}
+	current := ScanResult{
+		UpdatesAvailable: []ImageUpdate{
+			update("nginx", "1.24", "1.25"),    // ya notificada
+			update("redis", "7.0", "7.4"),      // nueva versión propuesta
+			update("postgres", "15.4", "16.1"), // nueva
+		},
+		UpToDateServices: []string{"app"},
+		Errors:           []string{"registry unreachable: quay.io", "timeout: ghcr.io"},
+	}
+
+	diff := current.Diff(baseline)
+
+	var got []string
+	for _, u := range diff.UpdatesAvailable {
+		got = append(got, u.ServiceName+":"+u.LatestImage.Tag)
+	}
+	if len(got) != 2 || got[0] != "redis:7.4" || got[1] != "postgres:16.1" {
+		t.Errorf("Diff() updates = %v, want [redis:7.4 postgres:16.1]", got)
+	}
+	if len(diff.Errors) != 1 || diff.Errors[0] != "timeout: ghcr.io" {
+		t.Errorf("Diff() errors = %v, want [timeout: ghcr.io]", diff.Errors)
+	}
+	if len(diff.UpToDateServices) != 1 {
+		t.Errorf("Diff() should keep the up-to-date services, got %v", diff.UpToDateServices)
+	}
+	if len(current.UpdatesAvailable) != 3 {
+		t.Error("Diff() must not modify the receiver")
+	}
+
+	// Contra un baseline vacío todo es nuevo
+	if all := current.Diff(ScanResult{}); len(all.UpdatesAvailable) != 3 || len(all.Errors) != 2 {
+		t.Errorf("Diff(empty) = %d updates, %d errors; want 3, 2", len(all.UpdatesAvailable), len(all.Errors))
+	}
+}
+
+func TestScanResult_HideAcknowledged(t *testing.T) {
+	update := func(service, current, latest string) ImageUpdate {
+		return ImageUpdate{
+			ServiceName:  service,
+			CurrentImage: DockerImage{Registry: "docker.io", Repository: "library/" + service, Tag: current},
+			LatestImage:  DockerImage{Registry: "docker.io", Repository: "library/" + service, Tag: latest},
+		}
+	}
+
+	result := ScanResult{
+		UpdatesAvailable: []ImageUpdate{
+			update("nginx", "1.24", "1.25"),    // aplazada
+			update("redis", "7.0", "7.4"),      // aplazada a 7.2, hay un tag más nuevo
+			update("postgres", "15.4", "16.1"), // sin aplazar
+		},
+	}
+	acks := []Acknowledgment{
+		{Service: "nginx", Tag: "1.25"},
+		{Service: "redis", Tag: "7.2"},
+	}
+
+	visible, hidden := result.HideAcknowledged(acks)
+
+	var got []string
+	for _, u := range visible.UpdatesAvailable {
+		got = append(got, u.ServiceName+":"+u.LatestImage.Tag)
+	}
+	if !slices.Equal(got, []string{"redis:7.4", "postgres:16.1"}) {
+		t.Errorf("HideAcknowledged() updates = %v, want [redis:7.4 postgres:16.1]", got)
+	}
+	if hidden != 1 {
+		t.Errorf("HideAcknowledged() hidden = %d, want 1", hidden)
+	}
+	if len(result.UpdatesAvailable) != 3 {
+		t.Error("HideAcknowledged() must not modify the receiver")
+	}
+
+	// Sin aplazamientos no se oculta nada
+	if all, n := result.HideAcknowledged(nil); len(all.UpdatesAvailable) != 3 || n != 0 {
+		t.Errorf("HideAcknowledged(nil) = %d updates, %d hidden; want 3, 0", len(all.UpdatesAvailable), n)
+	}
+}
+
+func TestImageUpdate_SizeDeltaText(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int64
+		latest  int64
+		want    string
+	}{
+		{name: "larger", current: 80_000_000, latest: 122_000_000, want: "+42 MB"},
+		{name: "smaller", current: 50_000_000, latest: 48_500_000, want: "-1.5 MB"},
+		{name: "small change", current: 1_000_000, latest: 1_000_512, want: "+512 B"},
+		{name: "same size", current: 1_000, latest: 1_000, want: "+0 B"},
+		{name: "gigabytes", current: 1_000_000_000, latest: 3_400_000_000, want: "+2.4 GB"},
+		// Sin alguno de los dos tamaños no hay diferencia que mostrar
+		{name: "unknown current", latest: 122_000_000, want: ""},
+		{name: "unknown latest", current: 80_000_000, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := ImageUpdate{CurrentSize: tt.current, LatestSize: tt.latest}
+			if got := update.SizeDeltaText(); got != tt.want {
+				t.Errorf("SizeDeltaText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimit_String(t *testing.T) {
+	tests := []struct {
+		limit    RateLimit
+		expected string
+	}{
+		{RateLimit{Registry: "index.docker.io", Limit: 100, Remaining: 42}, "DockerHub pulls remaining: 42/100"},
+		{RateLimit{Registry: "registry-1.docker.io", Limit: 200, Remaining: 0}, "DockerHub pulls remaining: 0/200"},
+		{RateLimit{Registry: "quay.io", Limit: 60, Remaining: 59}, "quay.io pulls remaining: 59/60"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.limit.String(); got != tt.expected {
+			t.Errorf("String() = %q, want %q", got, tt.expected)
+		}
+	}
+}
+
+func TestTagNames(t *testing.T) {
+	tags := []TagInfo{
+		{Name: "1.25", Digest: "sha256:abc"},
+		{Name: "1.24", Architectures: []string{"amd64"}},
+	}
+	if got := strings.Join(TagNames(tags), ","); got != "1.25,1.24" {
+		t.Errorf("TagNames() = %q, want %q", got, "1.25,1.24")
+	}
+	if got := TagNames(nil); got == nil || len(got) != 0 {
+		t.Errorf("TagNames(nil) = %#v, want an empty slice", got)
+	}
+}
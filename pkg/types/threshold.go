@@ -0,0 +1,87 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NotifyThreshold es el mínimo que debe alcanzar un escaneo para enviar
+// notificaciones (--notify-threshold). Basta con que se cumpla una de sus
+// condiciones; un umbral vacío siempre se cumple.
+type NotifyThreshold []ThresholdCondition
+
+// ThresholdCondition exige al menos Min actualizaciones de la métrica Metric:
+// "total" o una severidad (major, minor, patch), que incluye las más graves
+type ThresholdCondition struct {
+	Metric string
+	Min    int
+}
+
+// thresholdMetrics son las métricas admitidas, con la severidad mínima que cuentan
+var thresholdMetrics = map[string]UpdateType{
+	"total": "",
+	"major": UpdateTypeMajor,
+	"minor": UpdateTypeMinor,
+	"patch": UpdateTypePatch,
+}
+
+// ParseNotifyThreshold interpreta condiciones "métrica>=N" separadas por comas,
+// p. ej. "major>=1,total>=5" (vacío = sin umbral)
+func ParseNotifyThreshold(value string) (NotifyThreshold, error) {
+	var threshold NotifyThreshold
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		metric, count, ok := strings.Cut(part, ">=")
+		metric = strings.ToLower(strings.TrimSpace(metric))
+		if _, known := thresholdMetrics[metric]; !ok || !known {
+			return nil, fmt.Errorf("invalid notify threshold %q (use total, major, minor or patch with >=N, e.g. major>=1)", part)
+		}
+		minimum, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || minimum < 0 {
+			return nil, fmt.Errorf("invalid notify threshold %q: count must be a non-negative integer", part)
+		}
+		threshold = append(threshold, ThresholdCondition{Metric: metric, Min: minimum})
+	}
+	return threshold, nil
+}
+
+// Met indica si el resultado alcanza alguna de las condiciones del umbral
+func (t NotifyThreshold) Met(r ScanResult) bool {
+	if len(t) == 0 {
+		return true
+	}
+	for _, condition := range t {
+		if condition.count(r) >= condition.Min {
+			return true
+		}
+	}
+	return false
+}
+
+// String devuelve el umbral en el mismo formato que acepta ParseNotifyThreshold
+func (t NotifyThreshold) String() string {
+	parts := make([]string, len(t))
+	for i, condition := range t {
+		parts[i] = fmt.Sprintf("%s>=%d", condition.Metric, condition.Min)
+	}
+	return strings.Join(parts, ",")
+}
+
+// count cuenta las actualizaciones que mide la condición
+func (c ThresholdCondition) count(r ScanResult) int {
+	severity := thresholdMetrics[c.Metric]
+	if severity == "" {
+		return len(r.UpdatesAvailable)
+	}
+	n := 0
+	for _, update := range r.UpdatesAvailable {
+		if order, ok := severityOrder[update.UpdateType]; ok && order <= severityOrder[severity] {
+			n++
+		}
+	}
+	return n
+}
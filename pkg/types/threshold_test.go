@@ -0,0 +1,45 @@
+package types
+
+import "testing"
+
+func TestNotifyThreshold_Met(t *testing.T) {
+	result := ScanResult{UpdatesAvailable: []ImageUpdate{
+		{ServiceName: "api", UpdateType: UpdateTypeMinor},
+		{ServiceName: "db", UpdateType: UpdateTypePatch},
+		{ServiceName: "cache", UpdateType: UpdateTypeDigest},
+	}}
+
+	tests := []struct {
+		name      string
+		threshold string
+		want      bool
+	}{
+		{name: "empty threshold", threshold: "", want: true},
+		{name: "total met", threshold: "total>=3", want: true},
+		{name: "total not met", threshold: "total>=5", want: false},
+		{name: "no major", threshold: "major>=1", want: false},
+		{name: "minor counts itself", threshold: "minor>=1", want: true},
+		{name: "patch includes more severe", threshold: "patch>=2", want: true},
+		{name: "any condition", threshold: "major>=1, total>=3", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threshold, err := ParseNotifyThreshold(tt.threshold)
+			if err != nil {
+				t.Fatalf("ParseNotifyThreshold(%q) error = %v", tt.threshold, err)
+			}
+			if got := threshold.Met(result); got != tt.want {
+				t.Errorf("Met() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNotifyThreshold_Invalid(t *testing.T) {
+	for _, value := range []string{"major", "critical>=1", "total>=x", "total>=-1", "major>1"} {
+		if _, err := ParseNotifyThreshold(value); err == nil {
+			t.Errorf("ParseNotifyThreshold(%q) expected error", value)
+		}
+	}
+}
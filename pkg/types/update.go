@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // UpdateType representa el tipo de actualización disponible
 type UpdateType string
@@ -11,6 +14,8 @@ const (
 	UpdateTypePatch   UpdateType = "patch"
 	UpdateTypeUnknown UpdateType = "unknown"
 	UpdateTypeNone    UpdateType = "none"
+	// UpdateTypeDigest indica que el tag no cambió pero su digest sí (imagen reconstruida)
+	UpdateTypeDigest UpdateType = "digest"
 )
 
 // String devuelve la representación string del tipo de actualización
@@ -18,17 +23,141 @@ func (u UpdateType) String() string {
 	return string(u)
 }
 
+// UpdateReason explica por qué el scanner marcó una actualización
+type UpdateReason string
+
+const (
+	// UpdateReasonSemver indica un salto de versión semántica (1.20 -> 1.21)
+	UpdateReasonSemver UpdateReason = "semver"
+	// UpdateReasonCalver indica un tag basado en fecha más reciente (20240101 -> 20240301)
+	UpdateReasonCalver UpdateReason = "calver"
+	// UpdateReasonDigest indica que el tag se reconstruyó con otro digest
+	UpdateReasonDigest UpdateReason = "digest"
+	// UpdateReasonChannel indica que el tag propuesto cambia de variante (-alpine -> -slim)
+	UpdateReasonChannel UpdateReason = "channel"
+	// UpdateReasonBuildRevision indica la misma versión con otra revisión de build (5.1.4-1 -> 5.1.4-2)
+	UpdateReasonBuildRevision UpdateReason = "build-revision"
+)
+
+// String devuelve la representación string del motivo
+func (r UpdateReason) String() string {
+	return string(r)
+}
+
 // ImageUpdate representa una actualización disponible para una imagen Docker
 type ImageUpdate struct {
 	ServiceName      string      `json:"service_name"`
 	ServiceDirectory string      `json:"service_directory"`
 	CurrentImage     DockerImage `json:"current_image"`
 	LatestImage      DockerImage `json:"latest_image"`
-	UpdateType       UpdateType  `json:"update_type"`
-	UpdatedAt        time.Time   `json:"updated_at"`
+	// CurrentRef y SuggestedRef son CurrentImage y LatestImage en forma de
+	// referencia lista para docker pull ("library/nginx:1.21",
+	// "ghcr.io/org/app:2.0"), para que la automatización no tenga que montarla
+	CurrentRef   string `json:"current_ref"`
+	SuggestedRef string `json:"suggested_ref"`
+	// RecommendedImage es la versión más nueva dentro de scan.max_update_type
+	// (p. ej. el último patch de la minor actual); nil si no hay política o
+	// ninguna versión la cumple
+	RecommendedImage *DockerImage `json:"recommended_image,omitempty"`
+	// VersionsBehind cuenta las versiones estables publicadas entre la actual y
+	// la última, incluida esta; 0 si las versiones no son semánticas
+	VersionsBehind int          `json:"versions_behind,omitempty"`
+	UpdateType     UpdateType   `json:"update_type"`
+	Reason         UpdateReason `json:"reason,omitempty"`
+	// UpdatedAt es la fecha de publicación del tag propuesto cuando el
+	// registro la informa; cero si se desconoce
+	UpdatedAt time.Time `json:"updated_at"`
+	Priority  bool      `json:"priority,omitempty"` // la imagen coincide con scan.priority
+	// MaintainerLatest es la versión a la que apunta el tag latest del
+	// registro (scan.resolve_latest); puede no ser la más nueva si el
+	// mantenedor reserva latest para una versión LTS. Vacío si no se resolvió
+	MaintainerLatest string `json:"maintainer_latest,omitempty"`
+	// OriginalReference es la referencia tal como aparece en el archivo cuando
+	// CurrentImage se reescribió con registry.repository_aliases
+	OriginalReference string `json:"original_reference,omitempty"`
+	// CurrentSize y LatestSize son los tamaños en bytes de los tags actual y
+	// propuesto según el registro; 0 si no se conocen
+	CurrentSize int64 `json:"current_size,omitempty"`
+	LatestSize  int64 `json:"latest_size,omitempty"`
+}
+
+// Acknowledgment registra que el usuario aplazó a propósito la actualización
+// de un servicio a un tag. Solo oculta esa actualización: cuando el registro
+// propone un tag distinto, el aviso vuelve a mostrarse
+type Acknowledgment struct {
+	Service string    `json:"service"`
+	Tag     string    `json:"tag"`
+	AckedAt time.Time `json:"acked_at"`
+}
+
+// Covers indica si el aplazamiento corresponde a la actualización propuesta
+func (a Acknowledgment) Covers(update ImageUpdate) bool {
+	return a.Service == update.ServiceName && a.Tag == update.LatestImage.Tag
 }
 
 // IsSignificant determina si la actualización es significativa (major o minor)
 func (u ImageUpdate) IsSignificant() bool {
 	return u.UpdateType == UpdateTypeMajor || u.UpdateType == UpdateTypeMinor
 }
+
+// LatestAliasText describe la versión de latest cuando no es la más nueva
+// ("maintainer's latest = 1.22; newest published = 1.25"), o devuelve una
+// cadena vacía si coinciden o no se resolvió
+func (u ImageUpdate) LatestAliasText() string {
+	if u.MaintainerLatest == "" || u.MaintainerLatest == u.LatestImage.Tag {
+		return ""
+	}
+	return fmt.Sprintf("maintainer's latest = %s; newest published = %s", u.MaintainerLatest, u.LatestImage.Tag)
+}
+
+// SizeDelta devuelve cuántos bytes crece (o decrece) la imagen propuesta
+// respecto a la actual. ok es false si falta alguno de los dos tamaños
+func (u ImageUpdate) SizeDelta() (delta int64, ok bool) {
+	if u.CurrentSize <= 0 || u.LatestSize <= 0 {
+		return 0, false
+	}
+	return u.LatestSize - u.CurrentSize, true
+}
+
+// SizeDeltaText describe SizeDelta en unidades decimales ("+42 MB",
+// "-1.5 MB"), o devuelve una cadena vacía si no se conoce
+func (u ImageUpdate) SizeDeltaText() string {
+	delta, ok := u.SizeDelta()
+	if !ok {
+		return ""
+	}
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+		delta = -delta
+	}
+	return sign + formatBytes(delta)
+}
+
+// formatBytes escribe n bytes con la unidad decimal más grande que no deje
+// la cifra por debajo de 1, con un decimal por debajo de 10
+func formatBytes(n int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	value := float64(n)
+	unit := 0
+	for value >= 1000 && unit < len(units)-1 {
+		value /= 1000
+		unit++
+	}
+	if unit == 0 || value >= 10 {
+		return fmt.Sprintf("%.0f %s", value, units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+// BehindText describe VersionsBehind ("3 releases behind"), o devuelve una
+// cadena vacía si no se conoce
+func (u ImageUpdate) BehindText() string {
+	if u.VersionsBehind <= 0 {
+		return ""
+	}
+	if u.VersionsBehind == 1 {
+		return "1 release behind"
+	}
+	return fmt.Sprintf("%d releases behind", u.VersionsBehind)
+}
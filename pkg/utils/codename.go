@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// defaultCodenames maps well-known distro release codenames to the version
+// they stand for, so "bookworm" -> "trixie" compares like "12" -> "13".
+var defaultCodenames = map[string]map[string]string{
+	"debian": {
+		"jessie":   "8",
+		"stretch":  "9",
+		"buster":   "10",
+		"bullseye": "11",
+		"bookworm": "12",
+		"trixie":   "13",
+		"forky":    "14",
+		"duke":     "15",
+	},
+	"ubuntu": {
+		"xenial":   "16.04",
+		"bionic":   "18.04",
+		"focal":    "20.04",
+		"jammy":    "22.04",
+		"kinetic":  "22.10",
+		"lunar":    "23.04",
+		"mantic":   "23.10",
+		"noble":    "24.04",
+		"oracular": "24.10",
+		"plucky":   "25.04",
+		"questing": "25.10",
+	},
+}
+
+// codenameRelease is the distro and version a codename stands for
+type codenameRelease struct {
+	distro  string
+	version string
+}
+
+// builtinCodenames indexes defaultCodenames for the package-level version
+// functions. It is never modified; custom codenames live in a Codenames value.
+var builtinCodenames = &Codenames{index: buildCodenameIndex(defaultCodenames)}
+
+// Codenames orders distro release codenames through the versions they stand
+// for. A nil or zero Codenames knows no codenames; use DefaultCodenames or
+// NewCodenames.
+type Codenames struct {
+	index map[string]codenameRelease
+}
+
+// DefaultCodenames returns the built-in Debian and Ubuntu codenames
+func DefaultCodenames() *Codenames {
+	return builtinCodenames
+}
+
+// NewCodenames returns the built-in codenames with extra layered on top, as
+// distro -> codename -> version. An entry for a known codename replaces its
+// version. It fails if a version is not numeric.
+func NewCodenames(extra map[string]map[string]string) (*Codenames, error) {
+	if err := ValidateCodenames(extra); err != nil {
+		return nil, err
+	}
+
+	index := buildCodenameIndex(defaultCodenames)
+	for codename, release := range buildCodenameIndex(extra) {
+		index[codename] = release
+	}
+	return &Codenames{index: index}, nil
+}
+
+// buildCodenameIndex flattens a distro -> codename -> version map into a
+// codename lookup
+func buildCodenameIndex(distros map[string]map[string]string) map[string]codenameRelease {
+	index := make(map[string]codenameRelease)
+	for distro, releases := range distros {
+		for codename, version := range releases {
+			index[strings.ToLower(codename)] = codenameRelease{distro: strings.ToLower(distro), version: version}
+		}
+	}
+	return index
+}
+
+// ValidateCodenames checks that every codename maps to a numeric version
+func ValidateCodenames(distros map[string]map[string]string) error {
+	for distro, releases := range distros {
+		for codename, version := range releases {
+			if codename == "" {
+				return fmt.Errorf("empty codename for distro %q", distro)
+			}
+			if _, err := parseFlexibleSemver(version); err != nil {
+				return fmt.Errorf("invalid version %q for codename %s/%s: %w", version, distro, codename, err)
+			}
+		}
+	}
+	return nil
+}
+
+// IsCodename reports whether tag is a known codename, optionally followed by
+// a variant suffix ("bookworm-slim")
+func (c *Codenames) IsCodename(tag string) bool {
+	_, _, ok := c.lookup(tag)
+	return ok
+}
+
+// Compare compares two codename tags of the same distro and variant through
+// the versions they stand for. It returns UpdateTypeUnknown when the tags are
+// not comparable codenames.
+func (c *Codenames) Compare(currentVersion, newVersion string) types.UpdateType {
+	current, currentSuffix, ok := c.lookup(currentVersion)
+	if !ok {
+		return types.UpdateTypeUnknown
+	}
+	latest, latestSuffix, ok := c.lookup(newVersion)
+	if !ok || latest.distro != current.distro || latestSuffix != currentSuffix {
+		return types.UpdateTypeUnknown
+	}
+
+	return compareSemantic(current.version, latest.version)
+}
+
+// FindUpdate returns the newest codename tag of the same distro and variant
+// as currentVersion, or "" if there is none
+func (c *Codenames) FindUpdate(currentVersion string, tags []string) string {
+	best := ""
+	for _, tag := range tags {
+		if !c.isUpdate(currentVersion, tag) {
+			continue
+		}
+		if best == "" || c.isUpdate(best, tag) {
+			best = tag
+		}
+	}
+	return best
+}
+
+// FindRecommendedUpdate is FindUpdate restricted to the tags whose update
+// type does not exceed maxUpdateType
+func (c *Codenames) FindRecommendedUpdate(currentVersion string, tags []string, maxUpdateType types.UpdateType) string {
+	allowed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if c.isUpdate(currentVersion, tag) && IsUpdateTypeAcceptable(maxUpdateType, c.Compare(currentVersion, tag)) {
+			allowed = append(allowed, tag)
+		}
+	}
+	return c.FindUpdate(currentVersion, allowed)
+}
+
+// lookup splits a tag such as "bookworm-slim" into its release and variant
+// suffix. ok is false when the leading word is not a known codename.
+func (c *Codenames) lookup(tag string) (release codenameRelease, suffix string, ok bool) {
+	if c == nil {
+		return codenameRelease{}, "", false
+	}
+	name, suffix, _ := strings.Cut(strings.ToLower(tag), "-")
+	release, ok = c.index[name]
+	return release, suffix, ok
+}
+
+// isUpdate reports whether newVersion is a later release than currentVersion
+func (c *Codenames) isUpdate(currentVersion, newVersion string) bool {
+	updateType := c.Compare(currentVersion, newVersion)
+	return updateType != types.UpdateTypeUnknown && updateType != types.UpdateTypeNone
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+func TestCompareVersions_Codenames(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		latest  string
+		want    types.UpdateType
+	}{
+		{name: "debian bookworm to trixie", current: "bookworm", latest: "trixie", want: types.UpdateTypeMajor},
+		{name: "debian bullseye to bookworm slim", current: "bullseye-slim", latest: "bookworm-slim", want: types.UpdateTypeMajor},
+		{name: "debian downgrade", current: "trixie", latest: "bookworm", want: types.UpdateTypeNone},
+		{name: "ubuntu jammy to noble", current: "jammy", latest: "noble", want: types.UpdateTypeMajor},
+		{name: "ubuntu noble to oracular", current: "noble", latest: "oracular", want: types.UpdateTypeMinor},
+		{name: "same codename", current: "noble", latest: "noble", want: types.UpdateTypeNone},
+		// Unknown distros, variants or codenames fall back to string comparison
+		{name: "cross distro", current: "bookworm", latest: "noble", want: types.UpdateTypeUnknown},
+		{name: "different variant", current: "bookworm", latest: "trixie-slim", want: types.UpdateTypeUnknown},
+		{name: "unknown codename", current: "bookworm", latest: "zesty", want: types.UpdateTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareVersions(tt.current, tt.latest); got != tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindBestUpdateTag_Codenames(t *testing.T) {
+	tags := []string{"12", "13", "bullseye", "bookworm", "bookworm-slim", "trixie", "trixie-slim", "noble", "sid", "latest"}
+
+	tests := []struct {
+		current string
+		want    string
+	}{
+		{current: "bullseye", want: "trixie"},
+		{current: "bookworm-slim", want: "trixie-slim"},
+		{current: "trixie", want: ""},
+		{current: "jammy", want: "noble"},
+		{current: "noble", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			if got := FindBestUpdateTag(tt.current, tags); got != tt.want {
+				t.Errorf("FindBestUpdateTag(%q) = %q, want %q", tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCodenames(t *testing.T) {
+	if _, err := NewCodenames(map[string]map[string]string{"alpine": {"edge": "x"}}); err == nil {
+		t.Error("NewCodenames() accepted a non-numeric version")
+	}
+
+	codenames, err := NewCodenames(map[string]map[string]string{
+		"mint":   {"vanessa": "21", "wilma": "22"},
+		"ubuntu": {"resolute": "26.04"},
+	})
+	if err != nil {
+		t.Fatalf("NewCodenames() error = %v", err)
+	}
+
+	if got := codenames.Compare("vanessa", "wilma"); got != types.UpdateTypeMajor {
+		t.Errorf("Compare(vanessa, wilma) = %v, want major", got)
+	}
+	if got := codenames.Compare("questing", "resolute"); got != types.UpdateTypeMajor {
+		t.Errorf("Compare(questing, resolute) = %v, want major", got)
+	}
+	// The built-in codenames are still known
+	if got := codenames.FindUpdate("bookworm", []string{"bullseye", "trixie"}); got != "trixie" {
+		t.Errorf("FindUpdate(bookworm) = %q, want trixie", got)
+	}
+	if got := codenames.FindRecommendedUpdate("noble", []string{"oracular", "resolute"}, types.UpdateTypeMinor); got != "oracular" {
+		t.Errorf("FindRecommendedUpdate(noble, minor) = %q, want oracular", got)
+	}
+
+	// Custom codenames do not leak into the package-level functions or the defaults
+	if DefaultCodenames().IsCodename("vanessa") {
+		t.Error("DefaultCodenames() knows a custom codename")
+	}
+	if got := CompareVersions("vanessa", "wilma"); got == types.UpdateTypeMajor {
+		t.Error("CompareVersions() used a custom codename")
+	}
+
+	var none *Codenames
+	if none.IsCodename("bookworm") || none.FindUpdate("bookworm", []string{"trixie"}) != "" {
+		t.Error("a nil Codenames should know no codenames")
+	}
+}
@@ -0,0 +1,20 @@
+// Package utils implements the Docker tag version logic used by the scanner.
+// Besides semver it depends only on pkg/types, so other tools can import it
+// without pulling in the CLI.
+//
+// The stable public API is:
+//
+//   - CompareVersions returns the UpdateType (major, minor, patch...) between
+//     two tags, accepting Docker-style tags such as "1.25", "v2" or
+//     "1.2.3-alpine" and known distro codenames such as "bookworm".
+//   - ClassifyVersionUpdate wraps CompareVersions with pre-release and
+//     significance flags and a human readable description.
+//   - FindBestUpdateTag picks the newest tag from a registry listing that is a
+//     safe update for the current tag: same tag family, build variant and
+//     shape, preferring the current suffix.
+//   - IsPreRelease reports whether a tag is an alpha, beta, rc, nightly... build.
+//   - SortVersions orders tags newest first, semantic versions before others.
+//
+// The remaining exported helpers (suffix policies, tag families, filters) are
+// building blocks of FindBestUpdateTag and may change with the scanner.
+package utils
@@ -0,0 +1,44 @@
+package utils_test
+
+import (
+	"fmt"
+
+	"github.com/user/docker-image-reporter/pkg/utils"
+)
+
+func ExampleCompareVersions() {
+	fmt.Println(utils.CompareVersions("1.24.0", "1.25.3"))
+	fmt.Println(utils.CompareVersions("1.25", "2.0-alpine"))
+	fmt.Println(utils.CompareVersions("bookworm", "trixie"))
+	fmt.Println(utils.CompareVersions("1.25.3", "1.25.3"))
+	// Output:
+	// minor
+	// major
+	// major
+	// none
+}
+
+func ExampleClassifyVersionUpdate() {
+	classification := utils.ClassifyVersionUpdate("1.2.3", "2.0.0-rc.1")
+	fmt.Println(classification.UpdateType, classification.IsSignificant, classification.IsPreRelease)
+	fmt.Println(classification.Description)
+	// Output:
+	// major true true
+	// Major update available (pre-release)
+}
+
+func ExampleFindBestUpdateTag() {
+	tags := []string{"1.24-alpine", "1.25", "1.25-alpine", "latest"}
+	fmt.Println(utils.FindBestUpdateTag("1.24-alpine", tags))
+	// Output: 1.25-alpine
+}
+
+func ExampleSortVersions() {
+	fmt.Println(utils.SortVersions([]string{"1.2.0", "1.10.0", "latest", "1.9.1"}))
+	// Output: [1.10.0 1.9.1 1.2.0 latest]
+}
+
+func ExampleIsPreRelease() {
+	fmt.Println(utils.IsPreRelease("3.0.0-beta.2"), utils.IsPreRelease("3.0.0-alpine"))
+	// Output: true false
+}
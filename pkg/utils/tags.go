@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// DefaultMaxTags is the number of tags considered per image unless configured
+const DefaultMaxTags = 1000
+
+// LimitTags keeps at most limit tags so that version selection stays bounded
+// for repositories with thousands of tags (python, node...). The highest
+// semantic versions are kept first; tags that are not semantic versions are
+// ranked after them, most recently pushed first. Kept tags retain their
+// original order. A limit <= 0 disables the cap.
+//
+// The trade-off: an image pinned to an old line (e.g. python:3.8-slim) may
+// lose its own variant tags when newer lines alone fill the cap, in which case
+// no update is reported for it. Raise the limit for such repositories.
+func LimitTags(tags []types.TagInfo, limit int) []types.TagInfo {
+	if limit <= 0 || len(tags) <= limit {
+		return tags
+	}
+
+	versions := make([]*semver.Version, len(tags))
+	for i, tag := range tags {
+		if IsSemanticVersion(tag.Name) {
+			if v, err := parseFlexibleSemver(tag.Name); err == nil {
+				versions[i] = v
+			}
+		}
+	}
+
+	ranked := make([]int, len(tags))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	sort.SliceStable(ranked, func(a, b int) bool {
+		i, j := ranked[a], ranked[b]
+		switch {
+		case versions[i] != nil && versions[j] != nil:
+			return compareSemver(versions[i], versions[j]) > 0
+		case versions[i] != nil || versions[j] != nil:
+			return versions[i] != nil
+		case !tags[i].LastUpdated.Equal(tags[j].LastUpdated):
+			return tags[i].LastUpdated.After(tags[j].LastUpdated)
+		default:
+			return tags[i].Name > tags[j].Name
+		}
+	})
+
+	keep := make([]bool, len(tags))
+	for _, i := range ranked[:limit] {
+		keep[i] = true
+	}
+	limited := make([]types.TagInfo, 0, limit)
+	for i, tag := range tags {
+		if keep[i] {
+			limited = append(limited, tag)
+		}
+	}
+	return limited
+}
+
+// FilterTagsByArchitecture keeps the tags published for arch (e.g. "arm64").
+// Architecture entries may be plain ("arm64") or platforms ("linux/arm64/v8").
+// Empty and "unknown" entries, such as the unknown/unknown attestation
+// manifests that accompany multi-arch images, are ignored, so they neither
+// qualify a tag nor make it look arch-less. Tags with no known architecture
+// are kept, since the registry did not say what they support.
+func FilterTagsByArchitecture(tags []types.TagInfo, arch string) []types.TagInfo {
+	if arch == "" {
+		return tags
+	}
+
+	filtered := make([]types.TagInfo, 0, len(tags))
+	for _, tag := range tags {
+		known := false
+		matches := false
+		for _, entry := range tag.Architectures {
+			tagArch := platformArchitecture(entry)
+			if tagArch == "" || tagArch == "unknown" {
+				continue
+			}
+			known = true
+			if strings.EqualFold(tagArch, arch) {
+				matches = true
+				break
+			}
+		}
+		if matches || !known {
+			filtered = append(filtered, tag)
+		}
+	}
+	return filtered
+}
+
+// platformArchitecture returns the architecture of "arch" or "os/arch[/variant]"
+func platformArchitecture(entry string) string {
+	parts := strings.Split(strings.TrimSpace(entry), "/")
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return parts[1]
+}
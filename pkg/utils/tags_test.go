@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+func TestLimitTags(t *testing.T) {
+	// 5000 tags in registry order, with the newest version in the middle
+	// and a handful of non-version tags
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tags := make([]types.TagInfo, 0, 5000)
+	for i := range 4990 {
+		tags = append(tags, types.TagInfo{Name: fmt.Sprintf("1.%d.%d", i/100, i%100)})
+		if i == 2500 {
+			tags = append(tags, types.TagInfo{Name: "9.0.0"})
+		}
+	}
+	for i := range 9 {
+		tags = append(tags, types.TagInfo{Name: fmt.Sprintf("nightly-%d", i), LastUpdated: base.Add(time.Duration(i) * time.Hour)})
+	}
+
+	limited := LimitTags(tags, 1000)
+	if len(limited) != 1000 {
+		t.Fatalf("LimitTags() kept %d tags, want 1000", len(limited))
+	}
+
+	names := types.TagNames(limited)
+	if latest := GetLatestVersion(names); latest != "9.0.0" {
+		t.Errorf("latest kept version = %q, want 9.0.0", latest)
+	}
+	for _, name := range names {
+		if name == "1.0.0" || name == "nightly-8" {
+			t.Errorf("kept %q, expected lower-ranked tags to be dropped", name)
+		}
+	}
+
+	// Kept tags retain their registry order
+	if names[0] != "9.0.0" || names[1] != "1.39.91" || names[999] != "1.49.89" {
+		t.Errorf("kept tags are not in registry order: %v ... %s", names[:2], names[999])
+	}
+}
+
+func TestLimitTags_NonVersionTagsByDate(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tags := []types.TagInfo{
+		{Name: "edge", LastUpdated: base},
+		{Name: "2.0.0"},
+		{Name: "stable", LastUpdated: base.Add(time.Hour)},
+		{Name: "latest", LastUpdated: base.Add(2 * time.Hour)},
+	}
+
+	got := types.TagNames(LimitTags(tags, 3))
+	want := []string{"2.0.0", "stable", "latest"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("LimitTags() = %v, want %v", got, want)
+	}
+}
+
+func TestLimitTags_Disabled(t *testing.T) {
+	tags := []types.TagInfo{{Name: "1.0.0"}, {Name: "2.0.0"}}
+	for _, limit := range []int{0, -1, 2, 5} {
+		if got := LimitTags(tags, limit); len(got) != len(tags) {
+			t.Errorf("LimitTags(%d) kept %d tags, want %d", limit, len(got), len(tags))
+		}
+	}
+}
+
+func TestFilterTagsByArchitecture(t *testing.T) {
+	tags := []types.TagInfo{
+		// Multi-arch tags with their unknown/unknown attestation manifest
+		{Name: "1.0.0", Architectures: []string{"linux/arm64", "unknown/unknown"}},
+		{Name: "1.1.0", Architectures: []string{"amd64", "unknown"}},
+		// Only attestations: what the tag supports is unknown
+		{Name: "1.2.0", Architectures: []string{"unknown/unknown", ""}},
+		{Name: "1.3.0"},
+		{Name: "1.4.0", Architectures: []string{"linux/arm64/v8"}},
+	}
+
+	tests := []struct {
+		arch string
+		want []string
+	}{
+		{arch: "arm64", want: []string{"1.0.0", "1.2.0", "1.3.0", "1.4.0"}},
+		{arch: "amd64", want: []string{"1.1.0", "1.2.0", "1.3.0"}},
+		{arch: "", want: []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arch, func(t *testing.T) {
+			got := types.TagNames(FilterTagsByArchitecture(tags, tt.arch))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("FilterTagsByArchitecture(%q) = %v, want %v", tt.arch, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"cmp"
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	semver "github.com/Masterminds/semver/v3"
@@ -25,6 +28,9 @@ var (
 	twoPartSemverRegex = regexp.MustCompile(`^v?\d+\.\d+$`)
 	onePartSemverRegex = regexp.MustCompile(`^v?\d+$`)
 
+	// versionCoreRegex captures the leading dotted numeric core of a normalized tag
+	versionCoreRegex = regexp.MustCompile(`^\d+(?:\.\d+)*`)
+
 	// nonSemverPrefixRegex detects tags that start with text/words before numbers
 	// e.g. "smbd-wsdd2-a3.23.3", "synology-port-issue", "lt2-5.1.4"
 	nonSemverPrefixRegex = regexp.MustCompile(`^[a-zA-Z]`)
@@ -38,16 +44,38 @@ var (
 	// e.g. "5.1.4-lt2-2" -> "lt2", "18.1-custom-3" -> "custom"
 	// Does NOT match purely-numeric suffixes like "5.1.4-2".
 	buildVariantRegex = regexp.MustCompile(`^v?\d+(?:\.\d+)*[-_]([a-zA-Z][a-zA-Z0-9]*)`)
+
+	// preReleaseNumberRegex matches non-dotted pre-release counters such as
+	// "-rc2", "-rc-2" or "-beta1" so they can be rewritten as "-rc.2"/"-beta.1".
+	preReleaseNumberRegex = regexp.MustCompile(`(?i)([-.](?:alpha|beta|rc|pre|preview))[-_]?(\d+)`)
+
+	// revisionSuffixRegex matches a numeric core followed by a Debian-style
+	// build revision, e.g. "5.2.1-3" (core "5.2.1", revision 3)
+	revisionSuffixRegex = regexp.MustCompile(`^(\d+(?:\.\d+){0,2})-(\d+)$`)
+
+	// calVerRegex matches calendar versions YYYY.M.patch and YYYY.MM.patch,
+	// e.g. "2025.9.1" or "2024.01.0"
+	calVerRegex = regexp.MustCompile(`^((?:19|20)\d{2})\.(0?[1-9]|1[0-2])\.(\d+)$`)
 )
 
 // CompareVersions compares two version strings and returns the update type
 // Returns types.UpdateTypeNone if newVersion is not newer than currentVersion
 func CompareVersions(currentVersion, newVersion string) types.UpdateType {
-	// Try semantic version comparison first
+	// Calendar versions compare year, month and patch numerically
+	if updateType := compareCalVer(currentVersion, newVersion); updateType != types.UpdateTypeUnknown {
+		return updateType
+	}
+
+	// Try semantic version comparison
 	if updateType := compareSemantic(currentVersion, newVersion); updateType != types.UpdateTypeUnknown {
 		return updateType
 	}
 
+	// Distro codenames (bookworm, noble...) compare through their versions
+	if updateType := builtinCodenames.Compare(currentVersion, newVersion); updateType != types.UpdateTypeUnknown {
+		return updateType
+	}
+
 	// Fall back to string comparison
 	return compareString(currentVersion, newVersion)
 }
@@ -63,7 +91,7 @@ func compareSemantic(currentVersion, newVersion string) types.UpdateType {
 	}
 
 	// Compare versions
-	comparison := newSemver.Compare(currentSemver)
+	comparison := compareSemver(newSemver, currentSemver)
 	if comparison <= 0 {
 		return types.UpdateTypeNone
 	}
@@ -81,10 +109,56 @@ func compareSemantic(currentVersion, newVersion string) types.UpdateType {
 		return types.UpdateTypePatch
 	}
 
-	// Pre-release or metadata changes
+	// Pre-release or build revision changes
 	return types.UpdateTypePatch
 }
 
+// calVer is a parsed calendar version
+type calVer struct {
+	year, month, patch int
+}
+
+// parseCalVer parses YYYY.M.patch and YYYY.MM.patch tags (after removing a
+// "v" prefix and variant suffixes), e.g. "2025.9.1" or "2024.01.0-alpine".
+func parseCalVer(version string) (calVer, bool) {
+	m := calVerRegex.FindStringSubmatch(NormalizeVersion(version))
+	if m == nil {
+		return calVer{}, false
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	patch, err := strconv.Atoi(m[3])
+	if err != nil {
+		return calVer{}, false
+	}
+	return calVer{year: year, month: month, patch: patch}, true
+}
+
+// compareCalVer compares two calendar versions field by field: a new year is
+// a major update, a new month a minor one and a higher trailing number a
+// patch. It returns UpdateTypeUnknown unless both versions are CalVer.
+func compareCalVer(currentVersion, newVersion string) types.UpdateType {
+	current, ok1 := parseCalVer(currentVersion)
+	next, ok2 := parseCalVer(newVersion)
+	if !ok1 || !ok2 {
+		return types.UpdateTypeUnknown
+	}
+
+	switch {
+	case next.year != current.year:
+		if next.year > current.year {
+			return types.UpdateTypeMajor
+		}
+	case next.month != current.month:
+		if next.month > current.month {
+			return types.UpdateTypeMinor
+		}
+	case next.patch > current.patch:
+		return types.UpdateTypePatch
+	}
+	return types.UpdateTypeNone
+}
+
 // compareString performs simple string comparison as fallback
 func compareString(currentVersion, newVersion string) types.UpdateType {
 	if currentVersion == newVersion {
@@ -114,6 +188,40 @@ func NormalizeVersion(version string) string {
 	return normalized
 }
 
+// versionPartCount returns how many dotted numeric parts the version has
+// ("19" -> 1, "19.1-alpine" -> 2, "1.2.3" -> 3), or 0 if it has no numeric core.
+func versionPartCount(version string) int {
+	core := versionCoreRegex.FindString(NormalizeVersion(version))
+	if core == "" {
+		return 0
+	}
+	return strings.Count(core, ".") + 1
+}
+
+// versionLine returns the first parts numeric components of version's core
+// ("19.1.2" with 2 parts -> "19.1"), the line a floating tag of that
+// precision tracks.
+func versionLine(version string, parts int) string {
+	core := strings.Split(versionCoreRegex.FindString(NormalizeVersion(version)), ".")
+	if len(core) > parts {
+		core = core[:parts]
+	}
+	for i, part := range core {
+		if n, err := strconv.Atoi(part); err == nil {
+			core[i] = strconv.Itoa(n)
+		}
+	}
+	return strings.Join(core, ".")
+}
+
+// normalizePreRelease rewrites common non-standard pre-release spellings into
+// dotted identifiers ("1.2.0-rc2" -> "1.2.0-rc.2", "1.2.0-beta-1" -> "1.2.0-beta.1")
+// so semver precedence compares the counter numerically: otherwise "rc10"
+// sorts before "rc2" as a plain string.
+func normalizePreRelease(version string) string {
+	return preReleaseNumberRegex.ReplaceAllString(version, "${1}.${2}")
+}
+
 // parseFlexibleSemver parses Docker tags that may omit patch or minor parts by padding them.
 // Examples: "18.1" -> "18.1.0", "19" -> "19.0.0".
 // IMPORTANT: Tags that start with text (e.g. "smbd-wsdd2-") or have word text
@@ -135,7 +243,22 @@ func parseFlexibleSemver(version string) (*semver.Version, error) {
 		}
 	}
 
-	normalized := NormalizeVersion(version)
+	normalized := normalizePreRelease(NormalizeVersion(version))
+
+	// A numeric "-N" is a build revision of the core version, not a
+	// pre-release: it is kept as build metadata so compareSemver can rank
+	// "5.2.1-3" above "5.2.1-1" and "5.2.1"
+	if m := revisionSuffixRegex.FindStringSubmatch(normalized); m != nil {
+		core, err := parseFlexibleSemver(m[1])
+		if err != nil {
+			return nil, err
+		}
+		withRevision, err := core.SetMetadata(m[2])
+		if err != nil {
+			return nil, err
+		}
+		return &withRevision, nil
+	}
 
 	if sv, err := semver.NewVersion(normalized); err == nil {
 		return sv, nil
@@ -152,6 +275,22 @@ func parseFlexibleSemver(version string) (*semver.Version, error) {
 	return nil, fmt.Errorf("version is not semantic: %s", version)
 }
 
+// compareSemver compares versions parsed by parseFlexibleSemver like
+// (*semver.Version).Compare, breaking ties by the numeric build revision kept
+// in the metadata: 5.2.1 < 5.2.1-1 < 5.2.1-3 < 5.2.2.
+func compareSemver(a, b *semver.Version) int {
+	if c := a.Compare(b); c != 0 {
+		return c
+	}
+	return cmp.Compare(buildRevision(a), buildRevision(b))
+}
+
+// buildRevision returns the build revision of v, or 0 if it has none
+func buildRevision(v *semver.Version) int {
+	revision, _ := strconv.Atoi(v.Metadata())
+	return revision
+}
+
 // IsPreRelease checks if a version string contains pre-release indicators
 func IsPreRelease(version string) bool {
 	lowerVersion := strings.ToLower(version)
@@ -363,13 +502,9 @@ func sortSemanticVersions(versions []string) []string {
 	}
 
 	// Sort in descending order (newest first)
-	for i := 0; i < len(pairs)-1; i++ {
-		for j := i + 1; j < len(pairs); j++ {
-			if pairs[i].semver.LessThan(pairs[j].semver) {
-				pairs[i], pairs[j] = pairs[j], pairs[i]
-			}
-		}
-	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return compareSemver(pairs[i].semver, pairs[j].semver) > 0
+	})
 
 	// Extract original version strings
 	result := make([]string, len(pairs))
@@ -380,23 +515,52 @@ func sortSemanticVersions(versions []string) []string {
 	return result
 }
 
+// SortVersionsBySpecificity returns the semantic versions among tags, newest
+// first. Tags naming the same version keep the most specific one first
+// ("1.20.0" before "1.20"), so the first match is the most precise name.
+func SortVersionsBySpecificity(tags []string) []string {
+	var semantic []string
+	for _, tag := range tags {
+		if IsSemanticVersion(tag) {
+			semantic = append(semantic, tag)
+		}
+	}
+	sort.SliceStable(semantic, func(i, j int) bool {
+		return versionPartCount(semantic[i]) > versionPartCount(semantic[j])
+	})
+	return sortSemanticVersions(semantic)
+}
+
+// FilterTagsByConstraint keeps the semantic-version tags that satisfy a
+// semver constraint such as "~1.20" or ">=1.20, <2". Suffixes are ignored when
+// checking ("1.20.3-alpine" satisfies "~1.20"); other tags are dropped.
+func FilterTagsByConstraint(tags []string, constraint string) ([]string, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+
+	var allowed []string
+	for _, tag := range tags {
+		if !IsSemanticVersion(tag) {
+			continue
+		}
+		if v, err := parseFlexibleSemver(tag); err == nil && c.Check(v) {
+			allowed = append(allowed, tag)
+		}
+	}
+	return allowed, nil
+}
+
 // sortStringVersions sorts non-semantic versions lexicographically in descending order
 func sortStringVersions(versions []string) []string {
 	if len(versions) <= 1 {
 		return versions
 	}
 
-	// Simple bubble sort in descending order
 	sorted := make([]string, len(versions))
 	copy(sorted, versions)
-
-	for i := 0; i < len(sorted)-1; i++ {
-		for j := i + 1; j < len(sorted); j++ {
-			if sorted[i] < sorted[j] {
-				sorted[i], sorted[j] = sorted[j], sorted[i]
-			}
-		}
-	}
+	sort.Sort(sort.Reverse(sort.StringSlice(sorted)))
 
 	return sorted
 }
@@ -469,7 +633,7 @@ func ShouldIncludeUpdate(currentVersion, candidateVersion string, filter UpdateF
 		return false
 	}
 
-	return isUpdateTypeAcceptable(updateType, filter.MinUpdateType)
+	return IsUpdateTypeAcceptable(updateType, filter.MinUpdateType)
 }
 
 // matchesExcludePatterns checks if a version matches any of the exclude patterns
@@ -485,8 +649,9 @@ func matchesExcludePatterns(version string, patterns []string) bool {
 	return false
 }
 
-// isUpdateTypeAcceptable checks if an update type meets the minimum requirement
-func isUpdateTypeAcceptable(updateType, minUpdateType types.UpdateType) bool {
+// IsUpdateTypeAcceptable checks if an update type meets the minimum requirement.
+// An empty or unrecognised minimum accepts every update type.
+func IsUpdateTypeAcceptable(updateType, minUpdateType types.UpdateType) bool {
 	// Define update type hierarchy (higher values = more significant updates)
 	hierarchy := map[types.UpdateType]int{
 		types.UpdateTypeNone:    0,
@@ -494,6 +659,7 @@ func isUpdateTypeAcceptable(updateType, minUpdateType types.UpdateType) bool {
 		types.UpdateTypeMinor:   2,
 		types.UpdateTypeMajor:   3,
 		types.UpdateTypeUnknown: 1, // Treat unknown as patch level
+		types.UpdateTypeDigest:  1, // A rebuilt tag is at most a patch-level change
 	}
 
 	updateLevel, exists1 := hierarchy[updateType]
@@ -556,6 +722,8 @@ func ClassifyVersionUpdate(currentVersion, newVersion string) UpdateClassificati
 		description = "Major update available"
 	case types.UpdateTypeUnknown:
 		description = "Update available (version format unknown)"
+	case types.UpdateTypeDigest:
+		description = "Pinned digest is stale for tag"
 	}
 
 	if isPreRelease {
@@ -570,6 +738,29 @@ func ClassifyVersionUpdate(currentVersion, newVersion string) UpdateClassificati
 	}
 }
 
+// ClassifyUpdateReason explains why latestTag was selected as an update for
+// currentTag: a change of OS suffix or named build variant is a channel change,
+// date-based tags are calver, an unchanged numeric core is a build revision and
+// anything else is a regular semver bump. Digest drift is classified by the
+// scanner, since the tag does not change.
+func ClassifyUpdateReason(currentTag, latestTag string) types.UpdateReason {
+	if ExtractVersionSuffix(currentTag) != ExtractVersionSuffix(latestTag) ||
+		ExtractDockerBuildVariant(currentTag) != ExtractDockerBuildVariant(latestTag) {
+		return types.UpdateReasonChannel
+	}
+
+	if IsDateBasedTag(currentTag) {
+		return types.UpdateReasonCalver
+	}
+
+	currentCore := versionCoreRegex.FindString(NormalizeVersion(currentTag))
+	if currentCore != "" && currentCore == versionCoreRegex.FindString(NormalizeVersion(latestTag)) {
+		return types.UpdateReasonBuildRevision
+	}
+
+	return types.UpdateReasonSemver
+}
+
 // ExtractVersionSuffix extracts the suffix from a version tag (e.g., "-alpine" from "2.10.0-alpine")
 func ExtractVersionSuffix(version string) string {
 	// Common Docker image suffixes
@@ -596,6 +787,24 @@ func ExtractVersionSuffix(version string) string {
 	return bestMatch
 }
 
+// IsSuffixOnlyChange reports whether candidateTag has the same normalized
+// version as currentTag and only the OS suffix differs, e.g. "1.20.0" and
+// "1.20.0-alpine" or "1.20.0-alpine" and "1.20.0-slim".
+func IsSuffixOnlyChange(currentTag, candidateTag string) bool {
+	if strings.EqualFold(ExtractVersionSuffix(currentTag), ExtractVersionSuffix(candidateTag)) {
+		return false
+	}
+	current, err := parseFlexibleSemver(currentTag)
+	if err != nil {
+		return false
+	}
+	candidate, err := parseFlexibleSemver(candidateTag)
+	if err != nil {
+		return false
+	}
+	return compareSemver(current, candidate) == 0
+}
+
 // FilterTagsBySuffix filters tags to only include those with the same suffix as the current version
 func FilterTagsBySuffix(tags []string, currentVersion string) []string {
 	suffix := ExtractVersionSuffix(currentVersion)
@@ -621,25 +830,117 @@ func FilterTagsBySuffix(tags []string, currentVersion string) []string {
 	return filtered
 }
 
-// FindBestUpdateTag returns the best candidate tag to use as the latest update for the given currentVersion.
-// It finds the highest semantic version greater than the current one (after normalization). If multiple
-// original tags map to that semantic version (e.g., with and without suffix variants), it prefers a tag
-// that matches the current suffix. If none match, it returns a generic tag from that version group.
-//
-// Key improvement: tags are first filtered to the same "family" as the current version
-// (semver vs date-based vs custom) to prevent false positives from cross-family comparisons.
-func FindBestUpdateTag(currentVersion string, tags []string) string {
-	if len(tags) == 0 {
-		return ""
+// SuffixPolicy controls how strictly update candidates must keep the suffix
+// (e.g. -alpine, -slim) of the current tag.
+type SuffixPolicy string
+
+const (
+	// SuffixPolicyStrict only considers tags with the current suffix; when none
+	// exist the image is reported as up to date.
+	SuffixPolicyStrict SuffixPolicy = "strict"
+	// SuffixPolicyPrefer considers tags with the current suffix and falls back
+	// to all tags when none exist. This is the default.
+	SuffixPolicyPrefer SuffixPolicy = "prefer"
+	// SuffixPolicyAny ignores the suffix when choosing the newest version.
+	SuffixPolicyAny SuffixPolicy = "any"
+)
+
+// ParseSuffixPolicy validates value, returning SuffixPolicyPrefer when empty
+func ParseSuffixPolicy(value string) (SuffixPolicy, error) {
+	policy := SuffixPolicy(strings.ToLower(strings.TrimSpace(value)))
+	if policy == "" {
+		return SuffixPolicyPrefer, nil
+	}
+	if policy == SuffixPolicyStrict || policy == SuffixPolicyPrefer || policy == SuffixPolicyAny {
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid suffix policy %q (use strict, prefer or any)", value)
+}
+
+// FilterTagsBySuffixPolicy narrows tags to the candidates allowed by policy
+// for currentVersion. An empty policy behaves like SuffixPolicyPrefer.
+func FilterTagsBySuffixPolicy(tags []string, currentVersion string, policy SuffixPolicy) []string {
+	if policy == SuffixPolicyAny {
+		return tags
+	}
+	filtered := FilterTagsBySuffix(tags, currentVersion)
+	if len(filtered) == 0 && policy != SuffixPolicyStrict {
+		return tags
+	}
+	return filtered
+}
+
+// FindRecommendedUpdateTag returns the best update for currentVersion among the
+// tags whose update type does not exceed maxUpdateType, e.g. the newest patch
+// of the current minor for UpdateTypePatch. It returns "" when none qualifies.
+func FindRecommendedUpdateTag(currentVersion string, tags []string, maxUpdateType types.UpdateType) string {
+	allowed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		updateType := CompareVersions(currentVersion, tag)
+		// IsUpdateTypeAcceptable(a, b) reports level(a) >= level(b)
+		if updateType != types.UpdateTypeNone && IsUpdateTypeAcceptable(maxUpdateType, updateType) {
+			allowed = append(allowed, tag)
+		}
 	}
+	return FindBestUpdateTag(currentVersion, allowed)
+}
+
+// CountVersionsBehind returns how many distinct stable versions among tags are
+// newer than currentVersion and not newer than latestVersion, i.e. how many
+// releases the current tag is behind. Tags are narrowed like in
+// FindBestUpdateTag, and aliases of one version ("1.25", "1.25.0") count once.
+// It returns 0 when either version is not semantic.
+func CountVersionsBehind(currentVersion, latestVersion string, tags []string) int {
+	current, err := parseFlexibleSemver(currentVersion)
+	if err != nil {
+		return 0
+	}
+	latest, err := parseFlexibleSemver(latestVersion)
+	if err != nil || compareSemver(latest, current) <= 0 {
+		return 0
+	}
+
+	seen := make(map[string]bool)
+	for _, tag := range filterCandidateTags(currentVersion, FilterPreReleases(tags)) {
+		sv, err := parseFlexibleSemver(tag)
+		if err != nil || compareSemver(sv, current) <= 0 || compareSemver(sv, latest) > 0 {
+			continue
+		}
+		seen[sv.String()] = true
+	}
+	return len(seen)
+}
 
+// FilterTagsByPrecision keeps the tags with as many dotted version parts as
+// currentVersion ("18" keeps "19" but not "18.1"; "18.1" keeps "18.2" and
+// "19.0" but not "18" or "18.1.2"), so tags are only compared on the
+// precision the user pinned. Tags without a numeric core are dropped. When
+// currentVersion has no numeric core the tags are returned unchanged.
+func FilterTagsByPrecision(tags []string, currentVersion string) []string {
+	parts := versionPartCount(currentVersion)
+	if parts == 0 {
+		return tags
+	}
+	var filtered []string
+	for _, tag := range tags {
+		if versionPartCount(tag) == parts {
+			filtered = append(filtered, tag)
+		}
+	}
+	return filtered
+}
+
+// filterCandidateTags narrows tags to those comparable with currentVersion:
+// same tag family, same named build variant and, for floating tags, no more
+// version parts than the current tag within its line. It returns nil when no
+// tag shares the current family.
+func filterCandidateTags(currentVersion string, tags []string) []string {
 	// Step 1: Filter tags to the same family as current version.
 	// This prevents "28-synology-port-issue" from being treated as semver 28.0.0,
 	// and "smbd-wsdd2-a3.23.3" from being compared against "a3.23.3-s4.22.6".
 	familyFilteredTags := FilterTagsByFamily(tags, currentVersion)
 	if len(familyFilteredTags) == 0 {
-		// No tags in the same family — skip update detection for this image
-		return ""
+		return nil
 	}
 
 	// Step 2: Filter tags to the same named build variant as the current version.
@@ -656,6 +957,60 @@ func FindBestUpdateTag(currentVersion string, tags []string) string {
 		familyFilteredTags = variantFilteredTags
 	}
 
+	// Step 3: A current tag with fewer than three parts (e.g. "19" or "19.1") is a
+	// floating tag the user tracks on purpose; the registry moves it forward.
+	// Drop longer candidates inside the tracked line so "19" is not reported as
+	// outdated by "19.1", and longer candidates whose line has its own floating
+	// tag so "20" is preferred over "20.1". Longer tags from newer lines without
+	// a floating tag ("20.0.1") still count as updates.
+	if parts := versionPartCount(currentVersion); parts > 0 && parts < 3 {
+		currentLine := versionLine(currentVersion, parts)
+		floatingLines := make(map[string]bool)
+		for _, t := range familyFilteredTags {
+			if versionPartCount(t) == parts {
+				floatingLines[versionLine(t, parts)] = true
+			}
+		}
+		var shapeFilteredTags []string
+		for _, t := range familyFilteredTags {
+			if versionPartCount(t) > parts {
+				if line := versionLine(t, parts); line == currentLine || floatingLines[line] {
+					continue
+				}
+			}
+			shapeFilteredTags = append(shapeFilteredTags, t)
+		}
+		if len(shapeFilteredTags) > 0 {
+			familyFilteredTags = shapeFilteredTags
+		}
+	}
+
+	return familyFilteredTags
+}
+
+// FindBestUpdateTag returns the best candidate tag to use as the latest update for the given currentVersion.
+// It finds the highest semantic version greater than the current one (after normalization). If multiple
+// original tags map to that semantic version (e.g., with and without suffix variants), it prefers a tag
+// that matches the current suffix. If none match, it returns a generic tag from that version group.
+//
+// Key improvement: tags are first filtered to the same "family" as the current version
+// (semver vs date-based vs custom) to prevent false positives from cross-family comparisons.
+func FindBestUpdateTag(currentVersion string, tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	// Distro codenames are not semver: compare them through the codename map
+	if builtinCodenames.IsCodename(currentVersion) {
+		return builtinCodenames.FindUpdate(currentVersion, tags)
+	}
+
+	familyFilteredTags := filterCandidateTags(currentVersion, tags)
+	if len(familyFilteredTags) == 0 {
+		// No tags in the same family — skip update detection for this image
+		return ""
+	}
+
 	// Build mapping from normalized semver string to original tags
 	type group struct {
 		sem  *semver.Version
@@ -693,10 +1048,10 @@ func FindBestUpdateTag(currentVersion string, tags []string) string {
 	// Find highest semver greater than current
 	var best *group
 	for _, g := range groups {
-		if g.sem.Compare(currSv) <= 0 {
+		if compareSemver(g.sem, currSv) <= 0 {
 			continue
 		}
-		if best == nil || best.sem.LessThan(g.sem) {
+		if best == nil || compareSemver(best.sem, g.sem) < 0 {
 			best = g
 		}
 	}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/user/docker-image-reporter/pkg/types"
@@ -219,6 +221,21 @@ func TestSortVersions(t *testing.T) {
 			input:    []string{"v1.0.0", "v1.2.0", "v1.1.0"},
 			expected: []string{"v1.2.0", "v1.1.0", "v1.0.0"},
 		},
+		{
+			name:     "dotted release candidates sort numerically",
+			input:    []string{"1.2.0-rc.2", "1.2.0-rc.10", "1.2.0-rc.1"},
+			expected: []string{"1.2.0-rc.10", "1.2.0-rc.2", "1.2.0-rc.1"},
+		},
+		{
+			name:     "non-dotted release candidates sort numerically",
+			input:    []string{"1.2.0-rc2", "1.2.0-rc10", "1.2.0-rc-3"},
+			expected: []string{"1.2.0-rc10", "1.2.0-rc-3", "1.2.0-rc2"},
+		},
+		{
+			name:     "non-dotted betas sort numerically before release",
+			input:    []string{"1.2.0-beta1", "1.2.0", "1.2.0-beta2"},
+			expected: []string{"1.2.0", "1.2.0-beta2", "1.2.0-beta1"},
+		},
 		{
 			name:     "single version",
 			input:    []string{"1.0.0"},
@@ -260,6 +277,84 @@ func TestFindBestUpdateTagPrefersSemverOverCodenames(t *testing.T) {
 	}
 }
 
+func TestFindBestUpdateTag_FloatingTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		tags     []string
+		expected string
+	}{
+		{
+			name:     "major tag is current despite newer minors",
+			current:  "19",
+			tags:     []string{"18", "18.3", "19", "19.1", "19.1.2"},
+			expected: "",
+		},
+		{
+			name:     "major tag updates to next major",
+			current:  "19",
+			tags:     []string{"19", "19.1", "20", "20.1", "20.1.0"},
+			expected: "20",
+		},
+		{
+			name:     "minor tag is current despite newer patches",
+			current:  "19.1",
+			tags:     []string{"19", "19.1", "19.1.1", "19.1.2"},
+			expected: "",
+		},
+		{
+			name:     "minor tag updates to next minor",
+			current:  "19.1",
+			tags:     []string{"19.1", "19.1.4", "19.2", "19.2.1"},
+			expected: "19.2",
+		},
+		{
+			name:     "minor tag with suffix keeps variant and shape",
+			current:  "19.1-alpine",
+			tags:     []string{"19.1-alpine", "19.1.3-alpine", "19.2-alpine", "19.2.1-alpine"},
+			expected: "19.2-alpine",
+		},
+		{
+			name:     "falls back to fuller tags when no same-shape tags exist",
+			current:  "19",
+			tags:     []string{"19.0.0", "20.1.0"},
+			expected: "20.1.0",
+		},
+		{
+			name:     "major tag updates to fuller tag from a newer major",
+			current:  "19",
+			tags:     []string{"19", "19.1", "20.0.1"},
+			expected: "20.0.1",
+		},
+		{
+			name:     "minor tag updates to fuller tags from newer lines",
+			current:  "1.2",
+			tags:     []string{"1.2", "1.3.0", "2.0.0"},
+			expected: "2.0.0",
+		},
+		{
+			name:     "minor tag prefers the floating tag of a newer minor",
+			current:  "1.2",
+			tags:     []string{"1.2", "1.2.9", "1.3", "1.3.4"},
+			expected: "1.3",
+		},
+		{
+			name:     "full version still compares against full versions",
+			current:  "19.1.0",
+			tags:     []string{"19.1.0", "19.1.1", "19.2"},
+			expected: "19.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindBestUpdateTag(tt.current, tt.tags); got != tt.expected {
+				t.Errorf("FindBestUpdateTag(%q, %v) = %q, want %q", tt.current, tt.tags, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestGetLatestVersion(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -624,6 +719,31 @@ func TestClassifyVersionUpdate(t *testing.T) {
 	}
 }
 
+func TestClassifyUpdateReason(t *testing.T) {
+	tests := []struct {
+		name       string
+		currentTag string
+		latestTag  string
+		want       types.UpdateReason
+	}{
+		{"semver bump", "1.20", "1.21", types.UpdateReasonSemver},
+		{"semver bump with v prefix", "v5.5.4", "v5.5.5", types.UpdateReasonSemver},
+		{"semver bump keeping suffix", "1.20-alpine", "1.21-alpine", types.UpdateReasonSemver},
+		{"calver bump", "20240101", "20240301", types.UpdateReasonCalver},
+		{"build revision", "5.1.4-1", "5.1.4-2", types.UpdateReasonBuildRevision},
+		{"suffix change", "1.20-alpine", "1.21", types.UpdateReasonChannel},
+		{"build variant change", "5.1.4-2", "5.1.4-lt2-2", types.UpdateReasonChannel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyUpdateReason(tt.currentTag, tt.latestTag); got != tt.want {
+				t.Errorf("ClassifyUpdateReason(%q, %q) = %q, want %q", tt.currentTag, tt.latestTag, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestExtractVersionSuffix(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -710,6 +830,109 @@ func TestFilterTagsBySuffix(t *testing.T) {
 	}
 }
 
+func TestFilterTagsBySuffixPolicy(t *testing.T) {
+	tags := []string{"2.10.0", "2.10.0-alpine", "2.10.1", "2.10.1-alpine"}
+
+	tests := []struct {
+		name           string
+		policy         SuffixPolicy
+		currentVersion string
+		expected       []string
+	}{
+		{name: "strict match", policy: SuffixPolicyStrict, currentVersion: "2.10.0-alpine", expected: []string{"2.10.0-alpine", "2.10.1-alpine"}},
+		{name: "strict no match", policy: SuffixPolicyStrict, currentVersion: "2.10.0-slim", expected: []string{}},
+		{name: "prefer match", policy: SuffixPolicyPrefer, currentVersion: "2.10.0-alpine", expected: []string{"2.10.0-alpine", "2.10.1-alpine"}},
+		{name: "prefer no match falls back", policy: SuffixPolicyPrefer, currentVersion: "2.10.0-slim", expected: tags},
+		{name: "empty policy is prefer", currentVersion: "2.10.0-slim", expected: tags},
+		{name: "any", policy: SuffixPolicyAny, currentVersion: "2.10.0-alpine", expected: tags},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FilterTagsBySuffixPolicy(tags, tt.currentVersion, tt.policy)
+			if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("FilterTagsBySuffixPolicy() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsSuffixOnlyChange(t *testing.T) {
+	tests := []struct {
+		current   string
+		candidate string
+		want      bool
+	}{
+		{"1.20.0", "1.20.0-alpine", true},
+		{"1.20.0-alpine", "1.20.0", true},
+		{"1.20.0-alpine", "1.20.0-slim", true},
+		{"1.20-alpine", "1.20.0", true},
+		{"1.20.0-alpine", "1.20.0-alpine3.19", false},
+		{"1.20.0", "1.20.1-alpine", false},
+		{"1.20.0", "1.20.0", false},
+		{"latest", "latest-alpine", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.candidate, func(t *testing.T) {
+			if got := IsSuffixOnlyChange(tt.current, tt.candidate); got != tt.want {
+				t.Errorf("IsSuffixOnlyChange(%q, %q) = %v, want %v", tt.current, tt.candidate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindRecommendedUpdateTag(t *testing.T) {
+	postgresTags := []string{"14.10", "15.4", "15.5", "15.6", "15.6-alpine", "16.0", "16.2", "17.1"}
+
+	tests := []struct {
+		name    string
+		current string
+		tags    []string
+		max     types.UpdateType
+		want    string
+	}{
+		{name: "minor max stays on current major", current: "15.4", tags: postgresTags, max: types.UpdateTypeMinor, want: "15.6"},
+		{name: "major max is the latest", current: "15.4", tags: postgresTags, max: types.UpdateTypeMajor, want: "17.1"},
+		{name: "patch max stays on current minor", current: "1.20.1", tags: []string{"1.20.2", "1.20.3", "1.21.0", "1.25.0"}, max: types.UpdateTypePatch, want: "1.20.3"},
+		{name: "nothing within policy", current: "15.6", tags: postgresTags, max: types.UpdateTypeMinor, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindRecommendedUpdateTag(tt.current, tt.tags, tt.max); got != tt.want {
+				t.Errorf("FindRecommendedUpdateTag(%q, %s) = %q, want %q", tt.current, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSuffixPolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    SuffixPolicy
+		wantErr bool
+	}{
+		{value: "", want: SuffixPolicyPrefer},
+		{value: "strict", want: SuffixPolicyStrict},
+		{value: "Prefer", want: SuffixPolicyPrefer},
+		{value: " any ", want: SuffixPolicyAny},
+		{value: "loose", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSuffixPolicy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSuffixPolicy(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSuffixPolicy(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
 // ─── Regression tests for reported false positives ───────────────────────────
 
 // TestFalsePositive_IssueTagAsVersion covers the case:
@@ -1016,3 +1239,218 @@ func TestLiveReport_QbittorrentLt2Variant(t *testing.T) {
 		t.Errorf("expected no update, got %q", best)
 	}
 }
+
+func TestCountVersionsBehind(t *testing.T) {
+	tags := []string{"1.0.0", "1.1.0", "1.1", "1.2.0", "1.3.0-rc1", "1.3.0", "1.3.0-alpine", "latest"}
+
+	tests := []struct {
+		name    string
+		current string
+		latest  string
+		tags    []string
+		want    int
+	}{
+		// 1.1 y 1.1.0 cuentan una vez; la rc y la variante alpine no cuentan
+		{name: "known tag list", current: "1.0.0", latest: "1.3.0", tags: tags, want: 3},
+		{name: "one behind", current: "1.2.0", latest: "1.3.0", tags: tags, want: 1},
+		{name: "up to date", current: "1.3.0", latest: "1.3.0", tags: tags, want: 0},
+		{name: "non-semver current", current: "latest", latest: "1.3.0", tags: tags, want: 0},
+		{name: "non-semver tags", current: "bullseye", latest: "bookworm", tags: []string{"buster", "bullseye", "bookworm"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CountVersionsBehind(tt.current, tt.latest, tt.tags); got != tt.want {
+				t.Errorf("CountVersionsBehind(%q, %q) = %d, want %d", tt.current, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortVersionsBySpecificity(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{
+			name:     "same version keeps the most specific tag first",
+			input:    []string{"1.20", "latest", "1", "1.20.0", "1.21"},
+			expected: []string{"1.21", "1.20.0", "1.20", "1"},
+		},
+		{
+			name:     "no semantic versions",
+			input:    []string{"latest", "stable"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SortVersionsBySpecificity(tt.input)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("SortVersionsBySpecificity(%v) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterTagsByConstraint(t *testing.T) {
+	tags := []string{"1.19.9", "1.20", "1.20.3", "1.20.3-alpine", "1.21.0", "latest"}
+
+	tests := []struct {
+		constraint string
+		expected   []string
+	}{
+		{constraint: "~1.20", expected: []string{"1.20", "1.20.3", "1.20.3-alpine"}},
+		{constraint: ">=1.20, <1.21", expected: []string{"1.20", "1.20.3", "1.20.3-alpine"}},
+		{constraint: "^1", expected: []string{"1.19.9", "1.20", "1.20.3", "1.20.3-alpine", "1.21.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			got, err := FilterTagsByConstraint(tags, tt.constraint)
+			if err != nil {
+				t.Fatalf("FilterTagsByConstraint() error = %v", err)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("FilterTagsByConstraint(%q) = %v, want %v", tt.constraint, got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := FilterTagsByConstraint(tags, "not-a-constraint"); err == nil {
+		t.Error("expected an error for an invalid constraint")
+	}
+}
+
+func TestFilterTagsByPrecision(t *testing.T) {
+	tags := []string{"18", "18.1", "18.1.2", "19-alpine", "19.0-alpine", "latest"}
+
+	tests := []struct {
+		current  string
+		expected []string
+	}{
+		{current: "18", expected: []string{"18", "19-alpine"}},
+		{current: "18.1-alpine", expected: []string{"18.1", "19.0-alpine"}},
+		{current: "v18.1.0", expected: []string{"18.1.2"}},
+		{current: "latest", expected: tags},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			if got := FilterTagsByPrecision(tags, tt.current); !slices.Equal(got, tt.expected) {
+				t.Errorf("FilterTagsByPrecision(%q) = %v, want %v", tt.current, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompareVersions_CalVer(t *testing.T) {
+	tests := []struct {
+		current  string
+		next     string
+		expected types.UpdateType
+	}{
+		{"2025.8.1", "2025.9.0", types.UpdateTypeMinor},
+		{"2024.12.1", "2025.1.0", types.UpdateTypeMajor},
+		{"2025.9.2", "2025.10.1", types.UpdateTypeMinor},
+		{"2025.09.2", "2025.10.1", types.UpdateTypeMinor},
+		{"2025.10.1", "2025.10.2", types.UpdateTypePatch},
+		{"2025.10.1", "2025.9.9", types.UpdateTypeNone},
+		{"2025.1.0", "2024.12.9", types.UpdateTypeNone},
+		{"2025.9.1", "2025.9.1", types.UpdateTypeNone},
+		{"2025.9.1-alpine", "2025.10.0-alpine", types.UpdateTypeMinor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.next, func(t *testing.T) {
+			if got := CompareVersions(tt.current, tt.next); got != tt.expected {
+				t.Errorf("CompareVersions(%s, %s) = %v, want %v", tt.current, tt.next, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCalVer(t *testing.T) {
+	tests := []struct {
+		version string
+		want    calVer
+		ok      bool
+	}{
+		{"2025.9.1", calVer{2025, 9, 1}, true},
+		{"2024.01.0", calVer{2024, 1, 0}, true},
+		{"v2025.12.3", calVer{2025, 12, 3}, true},
+		{"2025.13.0", calVer{}, false},
+		{"1.2.3", calVer{}, false},
+		{"2025.9", calVer{}, false},
+		{"20250901", calVer{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, ok := parseCalVer(tt.version)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseCalVer(%s) = %+v, %v; want %+v, %v", tt.version, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCompareVersions_BuildRevision(t *testing.T) {
+	tests := []struct {
+		current  string
+		next     string
+		expected types.UpdateType
+	}{
+		{"5.2.1", "5.2.1-1", types.UpdateTypePatch},
+		{"5.2.1-1", "5.2.1-3", types.UpdateTypePatch},
+		{"5.2.1-3", "5.2.1-1", types.UpdateTypeNone},
+		{"5.2.1-3", "5.2.1", types.UpdateTypeNone},
+		{"5.2.0-1", "5.2.1-3", types.UpdateTypePatch},
+		{"5.1.4-2", "5.2.0-1", types.UpdateTypeMinor},
+		{"5.2.1-9", "5.2.1-10", types.UpdateTypePatch},
+		{"5.1.4-2", "5.1.4-2", types.UpdateTypeNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.next, func(t *testing.T) {
+			if got := CompareVersions(tt.current, tt.next); got != tt.expected {
+				t.Errorf("CompareVersions(%s, %s) = %v, want %v", tt.current, tt.next, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindBestUpdateTag_BuildRevision(t *testing.T) {
+	// qbittorrent-nox tags: a numeric revision ranks above its base version
+	tags := []string{
+		"5.1.0-1", "5.1.0-lt2-1",
+		"5.1.4-1", "5.1.4-lt2-1",
+		"5.1.4-2", "5.1.4-lt2-2",
+		"5.2.0-1", "5.2.1", "5.2.1-1", "5.2.1-3", "5.2.1-lt2-3",
+	}
+
+	tests := []struct {
+		current  string
+		expected string
+	}{
+		{"5.1.4-2", "5.2.1-3"},
+		{"5.2.1", "5.2.1-3"},
+		{"5.2.1-1", "5.2.1-3"},
+		{"5.2.1-3", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			if got := FindBestUpdateTag(tt.current, tags); got != tt.expected {
+				t.Errorf("FindBestUpdateTag(%s) = %q, want %q", tt.current, got, tt.expected)
+			}
+		})
+	}
+
+	sorted := SortVersions([]string{"5.2.1", "5.2.1-3", "5.2.0-1", "5.2.1-1"})
+	if got := strings.Join(sorted, ","); got != "5.2.1-3,5.2.1-1,5.2.1,5.2.0-1" {
+		t.Errorf("SortVersions() = %s, want 5.2.1-3,5.2.1-1,5.2.1,5.2.0-1", got)
+	}
+}
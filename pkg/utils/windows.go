@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+// TargetOS hints which operating system the scanned images are built for, so
+// tags that only make sense on that OS are recognised.
+type TargetOS string
+
+const (
+	// TargetOSAny applies no OS-specific tag conventions. This is the default.
+	TargetOSAny TargetOS = ""
+	// TargetOSWindows orders Windows base-image release tags (ltsc2022, 20H2,
+	// 1809...) by OS build instead of treating them as plain numbers or names.
+	TargetOSWindows TargetOS = "windows"
+)
+
+// ParseTargetOS validates value; empty and "linux" mean no OS-specific handling
+func ParseTargetOS(value string) (TargetOS, error) {
+	switch target := strings.ToLower(strings.TrimSpace(value)); target {
+	case "", "linux":
+		return TargetOSAny, nil
+	case string(TargetOSWindows):
+		return TargetOSWindows, nil
+	default:
+		return "", fmt.Errorf("invalid target OS %q (use linux or windows)", value)
+	}
+}
+
+// windowsBuilds maps Windows container release tags to their OS build, which
+// is what orders them: 20H2 (19042) is newer than 1809 (17763), and ltsc2019
+// is the same release as 1809.
+var windowsBuilds = map[string]int{
+	"ltsc2016": 14393,
+	"1607":     14393,
+	"1709":     16299,
+	"1803":     17134,
+	"ltsc2019": 17763,
+	"1809":     17763,
+	"1903":     18362,
+	"1909":     18363,
+	"2004":     19041,
+	"20h2":     19042,
+	"ltsc2022": 20348,
+	"23h2":     25398,
+	"ltsc2025": 26100,
+}
+
+// windowsRelease is a Windows release tag split around its release part,
+// e.g. "servercore-ltsc2022-amd64" -> servercore, ltsc2022, amd64
+type windowsRelease struct {
+	prefix string
+	build  int
+	suffix string
+}
+
+// parseWindowsRelease finds the release part of tag. Only alphabetic prefixes
+// (servercore, nanoserver...) are allowed before it, so tags such as
+// "6.0-nanoserver-1809" keep being compared by their leading version.
+func parseWindowsRelease(tag string) (windowsRelease, bool) {
+	parts := strings.Split(strings.ToLower(tag), "-")
+	for i, part := range parts {
+		if build, ok := windowsBuilds[part]; ok {
+			return windowsRelease{
+				prefix: strings.Join(parts[:i], "-"),
+				build:  build,
+				suffix: strings.Join(parts[i+1:], "-"),
+			}, true
+		}
+		if !isAlpha(part) {
+			return windowsRelease{}, false
+		}
+	}
+	return windowsRelease{}, false
+}
+
+// isAlpha reports whether s is a non-empty run of ASCII letters
+func isAlpha(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsWindowsReleaseTag reports whether tag names a Windows base-image release
+// (ltsc2022, 20H2, nanoserver-1809...)
+func IsWindowsReleaseTag(tag string) bool {
+	_, ok := parseWindowsRelease(tag)
+	return ok
+}
+
+// CompareWindowsVersions compares two Windows release tags with the same
+// prefix and suffix by OS build. A newer release is a major update: it needs
+// a matching container host. It returns UpdateTypeUnknown when the tags are
+// not comparable Windows releases.
+func CompareWindowsVersions(currentVersion, newVersion string) types.UpdateType {
+	current, ok := parseWindowsRelease(currentVersion)
+	if !ok {
+		return types.UpdateTypeUnknown
+	}
+	latest, ok := parseWindowsRelease(newVersion)
+	if !ok || latest.prefix != current.prefix || latest.suffix != current.suffix {
+		return types.UpdateTypeUnknown
+	}
+	if latest.build <= current.build {
+		return types.UpdateTypeNone
+	}
+	return types.UpdateTypeMajor
+}
+
+// FindWindowsUpdateTag returns the Windows release tag with the highest build
+// newer than currentVersion, or "" if there is none. Of the aliases of one
+// release (ltsc2019 and 1809) the first in tags wins.
+func FindWindowsUpdateTag(currentVersion string, tags []string) string {
+	best := ""
+	for _, tag := range tags {
+		if CompareWindowsVersions(currentVersion, tag) != types.UpdateTypeMajor {
+			continue
+		}
+		if best == "" || CompareWindowsVersions(best, tag) == types.UpdateTypeMajor {
+			best = tag
+		}
+	}
+	return best
+}
+
+// CountWindowsReleasesBehind counts the distinct builds newer than
+// currentVersion up to and including latestVersion among tags
+func CountWindowsReleasesBehind(currentVersion, latestVersion string, tags []string) int {
+	latest, ok := parseWindowsRelease(latestVersion)
+	if !ok {
+		return 0
+	}
+
+	builds := make(map[int]bool)
+	for _, tag := range tags {
+		if CompareWindowsVersions(currentVersion, tag) != types.UpdateTypeMajor {
+			continue
+		}
+		if release, _ := parseWindowsRelease(tag); release.build <= latest.build {
+			builds[release.build] = true
+		}
+	}
+	return len(builds)
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/user/docker-image-reporter/pkg/types"
+)
+
+func TestCompareWindowsVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		latest  string
+		want    types.UpdateType
+	}{
+		{name: "ltsc year", current: "ltsc2019", latest: "ltsc2022", want: types.UpdateTypeMajor},
+		{name: "build number to half-year release", current: "1809", latest: "20H2", want: types.UpdateTypeMajor},
+		{name: "half-year release is older than ltsc2022", current: "ltsc2022", latest: "20H2", want: types.UpdateTypeNone},
+		{name: "aliases of one release", current: "1809", latest: "ltsc2019", want: types.UpdateTypeNone},
+		{name: "same prefix", current: "nanoserver-1809", latest: "nanoserver-ltsc2022", want: types.UpdateTypeMajor},
+		{name: "different prefix", current: "nanoserver-1809", latest: "servercore-ltsc2022", want: types.UpdateTypeUnknown},
+		{name: "different suffix", current: "ltsc2019-amd64", latest: "ltsc2022", want: types.UpdateTypeUnknown},
+		{name: "versioned tag is not a release", current: "6.0-nanoserver-1809", latest: "6.0-nanoserver-ltsc2022", want: types.UpdateTypeUnknown},
+		{name: "unknown release", current: "ltsc2019", latest: "ltsc2030", want: types.UpdateTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareWindowsVersions(tt.current, tt.latest); got != tt.want {
+				t.Errorf("CompareWindowsVersions(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindWindowsUpdateTag(t *testing.T) {
+	tags := []string{"1809", "ltsc2019", "1909", "20H2", "2004", "ltsc2022", "ltsc2022-amd64", "latest"}
+
+	tests := []struct {
+		current string
+		want    string
+	}{
+		{current: "ltsc2019", want: "ltsc2022"},
+		{current: "1809", want: "ltsc2022"},
+		{current: "1909", want: "ltsc2022"},
+		{current: "ltsc2022", want: ""},
+		{current: "ltsc2022-amd64", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			if got := FindWindowsUpdateTag(tt.current, tags); got != tt.want {
+				t.Errorf("FindWindowsUpdateTag(%q) = %q, want %q", tt.current, got, tt.want)
+			}
+		})
+	}
+
+	// 1809 -> 20H2 when no LTSC release is newer
+	if got := FindWindowsUpdateTag("1809", []string{"1809", "1909", "20H2", "2004"}); got != "20H2" {
+		t.Errorf("FindWindowsUpdateTag(1809) = %q, want 20H2", got)
+	}
+	if got := CountWindowsReleasesBehind("1809", "20H2", tags); got != 3 {
+		t.Errorf("CountWindowsReleasesBehind(1809, 20H2) = %d, want 3", got)
+	}
+}
+
+func TestParseTargetOS(t *testing.T) {
+	for value, want := range map[string]TargetOS{"": TargetOSAny, "linux": TargetOSAny, "Windows": TargetOSWindows} {
+		if got, err := ParseTargetOS(value); err != nil || got != want {
+			t.Errorf("ParseTargetOS(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseTargetOS("darwin"); err == nil {
+		t.Error("ParseTargetOS(darwin) expected an error")
+	}
+}